@@ -1,11 +1,19 @@
 package main
 
 import (
-	"github.com/hashicorp/terraform/plugin"
+	"context"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
 	"github.com/terraform-providers/terraform-provider-postgresql/postgresql"
 )
 
 func main() {
+	server, err := postgresql.ProviderServer(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: postgresql.Provider})
+		GRPCProviderFunc: server})
 }
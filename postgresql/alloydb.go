@@ -0,0 +1,82 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const alloyDBAPITimeout = 30 * time.Second
+
+// alloyDBScopes are the scopes of the access tokens AlloyDB authenticates IAM
+// users with, in place of their password.
+var alloyDBScopes = []string{
+	"https://www.googleapis.com/auth/alloydb.login",
+	"https://www.googleapis.com/auth/userinfo.email",
+}
+
+// newAlloyDBTokenSource returns the source of the access tokens logging in to
+// AlloyDB, directly or through the AlloyDB Auth Proxy, with IAM database
+// authentication.
+func newAlloyDBTokenSource() *googleTokenSource {
+	return newGoogleTokenSource(&http.Client{Timeout: alloyDBAPITimeout}, alloyDBScopes...)
+}
+
+// checkAlloyDBTableAttrs returns an error if the attributes only AlloyDB
+// supports are set.
+func checkAlloyDBTableAttrs(d attrChanges) error {
+	if _, ok := d.GetOk(tableColumnarAttr); ok {
+		return &attrError{attr: tableColumnarAttr, err: fmt.Errorf("%s is only supported on AlloyDB", tableColumnarAttr)}
+	}
+
+	return nil
+}
+
+// setTableColumnarIfNeeded adds the table to the column store of AlloyDB's
+// columnar engine, or drops it from there.
+func setTableColumnarIfNeeded(c *Client, d *schema.ResourceData, db *sql.DB) error {
+	if !c.alloydb || !d.HasChange(tableColumnarAttr) {
+		return nil
+	}
+
+	columnar := d.Get(tableColumnarAttr).(bool)
+
+	tableName := d.Get(tableNameAttr).(string)
+	if !columnar {
+		if _, err := db.Exec("SELECT google_columnar_engine_drop($1)", pq.QuoteIdentifier(tableName)); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error dropping table %s from the column store: {{err}}", tableName), err)
+		}
+		return nil
+	}
+
+	var enabled bool
+	if err := db.QueryRow("SELECT COALESCE(pg_catalog.current_setting('google_columnar_engine.enabled', true), 'off') = 'on'").Scan(&enabled); err != nil {
+		return errwrap.Wrapf("Error reading google_columnar_engine.enabled: {{err}}", err)
+	}
+	if !enabled {
+		return fmt.Errorf("Error adding table %s to the column store: the columnar engine is disabled, set the google_columnar_engine.enabled flag of the instance to on", tableName)
+	}
+
+	if _, err := db.Exec("SELECT google_columnar_engine_add($1)", pq.QuoteIdentifier(tableName)); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error adding table %s to the column store: {{err}}", tableName), err)
+	}
+
+	return nil
+}
+
+// readTableColumnar reads whether the table is in the column store of AlloyDB's
+// columnar engine.
+func readTableColumnar(d *schema.ResourceData, db *sql.DB, tableName string) error {
+	var columnar bool
+	if err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM g_columnar_relations WHERE schema_name = 'public' AND relation_name = $1)", tableName).Scan(&columnar); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error reading the column store of TABLE (%s): {{err}}", tableName), err)
+	}
+	d.Set(tableColumnarAttr, columnar)
+
+	return nil
+}
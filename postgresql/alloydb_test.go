@@ -0,0 +1,69 @@
+package postgresql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestCheckTableFeaturesColumnar(t *testing.T) {
+	tests := []struct {
+		columnar bool
+		err      bool
+	}{
+		{columnar: false},
+		{columnar: true, err: true},
+	}
+
+	for _, test := range tests {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLTable().Schema, map[string]interface{}{
+			"name":     "events",
+			"columnar": test.columnar,
+		})
+		err := checkTableFeatures(&Client{}, d)
+		if (err != nil) != test.err {
+			t.Errorf("columnar %t: expected error %t, got %v", test.columnar, test.err, err)
+		}
+	}
+}
+
+func TestColumnarEngineStatements(t *testing.T) {
+	for _, query := range []string{
+		"SELECT google_columnar_engine_add($1)",
+		"SELECT google_columnar_engine_drop($1)",
+	} {
+		if readStatement(query) {
+			t.Errorf("expected %q to change the server", query)
+		}
+	}
+}
+
+func TestProviderAlloyDBIAMAuth(t *testing.T) {
+	p := Provider()
+	d := schema.TestResourceDataRaw(t, p.Schema, map[string]interface{}{
+		"host":             "127.0.0.1",
+		"username":         "terraform@my-project.iam",
+		"password":         "secret",
+		"alloydb_iam_auth": true,
+	})
+
+	meta, err := providerConfigure(context.Background(), d, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := meta.(*Client).config
+	if config.Password != "" || config.passwordFunc == nil {
+		t.Errorf("expected access tokens to be used instead of the password, got %+v", config)
+	}
+
+	d = schema.TestResourceDataRaw(t, p.Schema, map[string]interface{}{
+		"host":             "127.0.0.1",
+		"password_env":     "PGPASSWORD",
+		"alloydb_iam_auth": true,
+	})
+	if _, err := providerConfigure(context.Background(), d, ""); err == nil || !strings.Contains(err.Error(), "alloydb_iam_auth") {
+		t.Errorf("expected alloydb_iam_auth and password_env to conflict, got %v", err)
+	}
+}
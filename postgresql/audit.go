@@ -0,0 +1,112 @@
+package postgresql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/lib/pq"
+)
+
+// auditLog records the statements changing the server the provider runs, to
+// a file of JSON lines, to a table, or to both.
+type auditLog struct {
+	lock sync.Mutex
+
+	file io.Writer
+
+	// table is the name of the table, possibly schema-qualified, the
+	// entries are inserted into, through db.
+	table string
+	db    *sql.DB
+}
+
+// auditEntry is an entry of the audit log.
+type auditEntry struct {
+	Time         time.Time `json:"time"`
+	Database     string    `json:"database"`
+	User         string    `json:"user"`
+	Role         string    `json:"role,omitempty"`
+	Statement    string    `json:"statement"`
+	Args         []string  `json:"args,omitempty"`
+	DurationMS   float64   `json:"duration_ms"`
+	RowsAffected *int64    `json:"rows_affected,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// newAuditEntry returns the entry of the statement run with the result or
+// error.
+func newAuditEntry(start time.Time, query string, args []driver.Value, result driver.Result, err error) auditEntry {
+	entry := auditEntry{
+		Time:       start.UTC(),
+		Statement:  redactPasswords(strings.TrimSpace(query)),
+		DurationMS: milliseconds(start),
+	}
+	for _, arg := range args {
+		entry.Args = append(entry.Args, fmt.Sprint(arg))
+	}
+	if result != nil {
+		if rows, err := result.RowsAffected(); err == nil {
+			entry.RowsAffected = &rows
+		}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	return entry
+}
+
+// insertQuery returns the statement inserting entries into the table, which
+// is not recorded itself.
+func (a *auditLog) insertQuery() string {
+	var names []string
+	for _, name := range strings.Split(a.table, ".") {
+		names = append(names, pq.QuoteIdentifier(name))
+	}
+
+	return fmt.Sprintf(
+		"INSERT INTO %s (executed_at, database, username, role, statement, args, duration_ms, rows_affected, error) VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, NULLIF($9, ''))",
+		strings.Join(names, "."),
+	)
+}
+
+// record appends the entry to the audit log.
+func (a *auditLog) record(entry auditEntry) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.file != nil {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := a.file.Write(append(line, '\n')); err != nil {
+			return errwrap.Wrapf("Error writing the audit log: {{err}}", err)
+		}
+	}
+
+	if a.db != nil {
+		var rowsAffected sql.NullInt64
+		if entry.RowsAffected != nil {
+			rowsAffected = sql.NullInt64{Int64: *entry.RowsAffected, Valid: true}
+		}
+		args, err := json.Marshal(entry.Args)
+		if err != nil {
+			return err
+		}
+
+		_, err = a.db.Exec(a.insertQuery(), entry.Time, entry.Database, entry.User, entry.Role, entry.Statement, string(args), entry.DurationMS, rowsAffected, entry.Error)
+		if err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error inserting into the audit log table %s: {{err}}", a.table), err)
+		}
+	}
+
+	return nil
+}
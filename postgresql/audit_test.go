@@ -0,0 +1,58 @@
+package postgresql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	audit := &auditLog{file: &buf, table: "audit.statements"}
+	conn := &liveConn{Conn: lostConn{}, audit: audit, database: "app", user: "terraform"}
+
+	for _, query := range []string{
+		"SELECT 1",
+		"CREATE ROLE app LOGIN PASSWORD 'secret'",
+		audit.insertQuery(),
+	} {
+		if _, err := conn.Exec(query, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	conn.Conn = lostConn{errors.New("permission denied")}
+	conn.Exec("DROP ROLE app", nil)
+	conn.Query("SELECT rolname FROM pg_catalog.pg_roles", nil)
+	conn.Query("SELECT pg_catalog.setval('app_id_seq', 1)", nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected the statements changing the server to be recorded, got %q", lines)
+	}
+
+	var entry auditEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Statement != "CREATE ROLE app LOGIN PASSWORD '<redacted>'" || entry.Database != "app" || entry.User != "terraform" || entry.Time.IsZero() {
+		t.Errorf("unexpected entry %s", lines[0])
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Statement != "DROP ROLE app" || entry.Error != "permission denied" {
+		t.Errorf("unexpected entry %s", lines[1])
+	}
+	if err := json.Unmarshal([]byte(lines[2]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Statement != "SELECT pg_catalog.setval('app_id_seq', 1)" {
+		t.Errorf("expected the SELECTs of functions changing the server to be recorded, got %s", lines[2])
+	}
+
+	if query := audit.insertQuery(); !strings.HasPrefix(query, `INSERT INTO "audit"."statements" (`) {
+		t.Errorf("unexpected insert statement %q", query)
+	}
+}
@@ -0,0 +1,86 @@
+package postgresql
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/lib/pq"
+)
+
+// auroraAdminRole is the superuser of Aurora, which only AWS connects as.  The
+// provider's user is at best a member of rds_superuser, which is not a
+// superuser: it can neither become a member of rdsadmin nor change the owner
+// of its objects.
+const auroraAdminRole = "rdsadmin"
+
+// auroraExtension describes the requirements of an extension only Aurora
+// has.
+type auroraExtension struct {
+	// schema is the schema the extension is always created in, if any.
+	schema string
+
+	// preload is true if the library of the extension must be in
+	// shared_preload_libraries, which is set in the parameter group of the
+	// DB cluster.
+	preload bool
+}
+
+// auroraExtensions are the extensions only Aurora has.
+var auroraExtensions = map[string]auroraExtension{
+	"apg_plan_mgmt":     {schema: "apg_plan_mgmt", preload: true},
+	"aurora_stat_utils": {},
+}
+
+// checkAuroraExtension returns an error if the extension is one of Aurora's
+// and can not be created in the schema, if any, of the server.
+func checkAuroraExtension(c *Client, q queryer, name, schemaName string) error {
+	ext, ok := auroraExtensions[name]
+	if !ok {
+		return nil
+	}
+	if !c.aurora {
+		return fmt.Errorf("Extension %s is only available on Aurora PostgreSQL", name)
+	}
+	if ext.schema != "" && schemaName != "" && schemaName != ext.schema {
+		return fmt.Errorf("Extension %s can only be in schema %s, not %s", name, ext.schema, schemaName)
+	}
+	if !ext.preload {
+		return nil
+	}
+
+	var preloaded bool
+	if err := q.QueryRow(`SELECT $1 = ANY(pg_catalog.string_to_array(pg_catalog.replace(pg_catalog.current_setting('shared_preload_libraries'), ' ', ''), ','))`, name).Scan(&preloaded); err != nil {
+		return errwrap.Wrapf("Error reading shared_preload_libraries: {{err}}", err)
+	}
+	if !preloaded {
+		return fmt.Errorf("Extension %s requires %s in shared_preload_libraries, which is set in the parameter group of the DB cluster", name, name)
+	}
+
+	return nil
+}
+
+// checkAuroraOwner returns an error if the provider's user can not act as the
+// role on Aurora, e.g. to change the owner of its objects.
+func checkAuroraOwner(c *Client, role string) error {
+	if c.aurora && role == auroraAdminRole {
+		return fmt.Errorf("Role %s is Aurora's internal superuser: connection user (%q), at best a member of rds_superuser, can not act as it, e.g. to change the owner of its objects", role, c.config.Username)
+	}
+
+	return nil
+}
+
+// failoverError returns true if the error is, or wraps, the error of a
+// statement run on a server which was demoted to a replica, as the writer of
+// an Aurora cluster is during a failover, or which shut down.
+func failoverError(err error) bool {
+	var found bool
+	errwrap.Walk(err, func(err error) {
+		// read_only_sql_transaction, admin_shutdown and
+		// connection_exception.
+		if err, ok := err.(*pq.Error); ok && (err.Code == "25006" || err.Code == "57P01" || err.Code.Class() == "08") {
+			found = true
+		}
+	})
+
+	return found
+}
@@ -0,0 +1,66 @@
+package postgresql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/lib/pq"
+)
+
+func TestCheckAuroraExtension(t *testing.T) {
+	tests := []struct {
+		aurora bool
+		name   string
+		schema string
+		ok     bool
+	}{
+		{name: "pgcrypto", ok: true},
+		{aurora: true, name: "pgcrypto", schema: "public", ok: true},
+		{name: "aurora_stat_utils"},
+		{aurora: true, name: "aurora_stat_utils", ok: true},
+		{aurora: true, name: "aurora_stat_utils", schema: "public", ok: true},
+		{name: "apg_plan_mgmt", schema: "apg_plan_mgmt"},
+		{aurora: true, name: "apg_plan_mgmt", schema: "public"},
+	}
+
+	for _, test := range tests {
+		err := checkAuroraExtension(&Client{aurora: test.aurora}, nil, test.name, test.schema)
+		if (err == nil) != test.ok {
+			t.Errorf("%+v: expected ok %t, got %v", test, test.ok, err)
+		}
+	}
+}
+
+func TestCheckAuroraOwner(t *testing.T) {
+	if err := checkAuroraOwner(&Client{aurora: true}, "rdsadmin"); err == nil {
+		t.Error("expected an error acting as rdsadmin on Aurora")
+	}
+	if err := checkAuroraOwner(&Client{aurora: true}, "app"); err != nil {
+		t.Errorf("expected no error acting as app on Aurora, got %v", err)
+	}
+	if err := checkAuroraOwner(&Client{}, "rdsadmin"); err != nil {
+		t.Errorf("expected no error acting as rdsadmin off Aurora, got %v", err)
+	}
+}
+
+func TestFailoverError(t *testing.T) {
+	tests := []struct {
+		err      error
+		failover bool
+	}{
+		{err: &pq.Error{Code: "25006"}, failover: true},
+		{err: &pq.Error{Code: "57P01"}, failover: true},
+		{err: &pq.Error{Code: "08006"}, failover: true},
+		{err: errwrap.Wrapf("Error creating role: {{err}}", &pq.Error{Code: "25006"}), failover: true},
+		{err: &pq.Error{Code: "40001"}},
+		{err: &pq.Error{Code: "42501"}},
+		{err: errors.New("read-only")},
+	}
+
+	for _, test := range tests {
+		if failover := failoverError(test.err); failover != test.failover {
+			t.Errorf("%v: expected failover %t, got %t", test.err, test.failover, failover)
+		}
+	}
+}
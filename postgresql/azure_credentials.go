@@ -0,0 +1,167 @@
+package postgresql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+)
+
+const (
+	// azureDatabaseResource is the resource Azure AD tokens for Azure
+	// Database for PostgreSQL are issued for.
+	azureDatabaseResource = "https://ossrdbms-aad.database.windows.net"
+
+	azureLoginURL = "https://login.microsoftonline.com"
+	azureIMDSURL  = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+	// Refresh access tokens this long before they expire, so that a token
+	// handed out is good for at least the time it takes to log in with it.
+	azureTokenExpiryDelta = 5 * time.Minute
+
+	azureAPITimeout = 30 * time.Second
+)
+
+// azureTokenSource hands out Azure AD access tokens for Azure Database for
+// PostgreSQL, for a service principal when a client secret is set, or for the
+// managed identity of the Azure resource the provider runs on otherwise.
+type azureTokenSource struct {
+	tenantID     string
+	clientID     string
+	clientSecret string
+
+	loginURL string
+	imdsURL  string
+	client   *http.Client
+
+	lock   sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// azureTokenResponse is the token response of both Azure AD and the instance
+// metadata service, which returns the numbers as strings.
+type azureTokenResponse struct {
+	AccessToken string      `json:"access_token"`
+	ExpiresIn   json.Number `json:"expires_in"`
+}
+
+func newAzureTokenSource(tenantID, clientID, clientSecret string) *azureTokenSource {
+	return &azureTokenSource{
+		tenantID:     tenantID,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		loginURL:     azureLoginURL,
+		imdsURL:      azureIMDSURL,
+		client:       &http.Client{Timeout: azureAPITimeout},
+	}
+}
+
+// Token returns a valid access token, requesting a new one if needed.
+func (s *azureTokenSource) Token() (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.token != "" && time.Now().Add(azureTokenExpiryDelta).Before(s.expiry) {
+		return s.token, nil
+	}
+
+	var req *http.Request
+	var err error
+	if s.clientSecret != "" {
+		req, err = s.servicePrincipalRequest()
+	} else {
+		req, err = s.managedIdentityRequest()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	token, err := s.do(req)
+	if err != nil {
+		return "", errwrap.Wrapf("Error requesting Azure AD access token: {{err}}", err)
+	}
+
+	expiresIn, err := strconv.Atoi(token.ExpiresIn.String())
+	if err != nil {
+		return "", errwrap.Wrapf("Error requesting Azure AD access token: invalid expires_in: {{err}}", err)
+	}
+
+	s.token = token.AccessToken
+	s.expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+
+	return s.token, nil
+}
+
+func (s *azureTokenSource) servicePrincipalRequest() (*http.Request, error) {
+	if s.tenantID == "" || s.clientID == "" {
+		return nil, fmt.Errorf("the tenant and client IDs are required to authenticate as a service principal")
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {s.clientID},
+		"client_secret": {s.clientSecret},
+		"scope":         {azureDatabaseResource + "/.default"},
+	}
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s/oauth2/v2.0/token", s.loginURL, url.PathEscape(s.tenantID)), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return req, nil
+}
+
+func (s *azureTokenSource) managedIdentityRequest() (*http.Request, error) {
+	query := url.Values{
+		"api-version": {"2018-02-01"},
+		"resource":    {azureDatabaseResource},
+	}
+	if s.clientID != "" {
+		// A user-assigned identity
+		query.Set("client_id", s.clientID)
+	}
+
+	req, err := http.NewRequest("GET", s.imdsURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata", "true")
+
+	return req, nil
+}
+
+func (s *azureTokenSource) do(req *http.Request) (*azureTokenResponse, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(b))
+	}
+
+	var token azureTokenResponse
+	if err := json.Unmarshal(b, &token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("no access token in response")
+	}
+
+	return &token, nil
+}
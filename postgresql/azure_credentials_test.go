@@ -0,0 +1,70 @@
+package postgresql
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAzureTokenSource(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		switch r.URL.Path {
+		case "/tenant/oauth2/v2.0/token":
+			if r.FormValue("client_id") != "client" || r.FormValue("client_secret") != "secret" {
+				http.Error(w, "invalid client", http.StatusUnauthorized)
+				return
+			}
+			if r.FormValue("scope") != azureDatabaseResource+"/.default" {
+				http.Error(w, "invalid scope", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintf(w, `{"access_token": "sp-token-%d", "expires_in": 3599}`, requests)
+		case "/imds":
+			if r.Header.Get("Metadata") != "true" || r.FormValue("resource") != azureDatabaseResource {
+				http.Error(w, "invalid request", http.StatusBadRequest)
+				return
+			}
+			// The instance metadata service returns numbers as strings.
+			fmt.Fprintf(w, `{"access_token": "mi-token-%s", "expires_in": "60"}`, r.FormValue("client_id"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	sp := newAzureTokenSource("tenant", "client", "secret")
+	sp.loginURL = server.URL
+	for i := 0; i < 2; i++ {
+		token, err := sp.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "sp-token-1" {
+			t.Errorf("expected the service principal token to be reused, got %q", token)
+		}
+	}
+
+	// Tokens expiring within the expiry delta are refreshed every time.
+	mi := newAzureTokenSource("", "identity", "")
+	mi.imdsURL = server.URL + "/imds"
+	for i := 0; i < 2; i++ {
+		token, err := mi.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "mi-token-identity" {
+			t.Errorf("expected a token for the user-assigned identity, got %q", token)
+		}
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 token requests, got %d", requests)
+	}
+
+	if _, err := newAzureTokenSource("", "client", "secret").Token(); err == nil {
+		t.Error("expected a service principal without tenant to be rejected")
+	}
+}
@@ -0,0 +1,41 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// statementContext returns the context a statement runs with: ctx, or the
+// provider's stop context when ctx can not be cancelled, as for the statements
+// of resources.  When Terraform is interrupted, lib/pq then cancels the
+// statements running with a cancel request, so that long statements do not
+// keep running, and holding their locks, after Terraform exits.  The
+// operations running them fail, rolling their transactions back.
+func (c *liveConn) statementContext(ctx context.Context) context.Context {
+	if ctx.Done() == nil && c.stop != nil {
+		return c.stop
+	}
+
+	return ctx
+}
+
+// namedValues returns the arguments of a statement as the context methods of
+// lib/pq's connections take them.
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+
+	return named
+}
+
+// values returns the values of the arguments of a statement.
+func values(named []driver.NamedValue) []driver.Value {
+	args := make([]driver.Value, len(named))
+	for i, arg := range named {
+		args[i] = arg.Value
+	}
+
+	return args
+}
@@ -0,0 +1,50 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+// contextConn is a driver connection recording the context of its statement.
+type contextConn struct {
+	lostConn
+	ctx *context.Context
+}
+
+func (c contextConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	*c.ctx = ctx
+	return driver.RowsAffected(0), nil
+}
+
+func TestStatementContext(t *testing.T) {
+	stop, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ctx context.Context
+	conn := &liveConn{Conn: contextConn{ctx: &ctx}, stop: stop}
+	if _, err := conn.Exec("ALTER TABLE app ADD COLUMN id int", nil); err != nil {
+		t.Fatal(err)
+	}
+	if ctx != stop {
+		t.Error("expected the statement to run with the provider's stop context")
+	}
+
+	// The context of the caller, e.g. with a timeout, is kept.
+	timeout, cancelTimeout := context.WithCancel(context.Background())
+	defer cancelTimeout()
+	if _, err := conn.ExecContext(timeout, "SELECT pg_catalog.pg_advisory_lock(1)", nil); err != nil {
+		t.Fatal(err)
+	}
+	if ctx != timeout {
+		t.Error("expected the statement to run with the caller's context")
+	}
+
+	conn.stop = nil
+	if _, err := conn.Exec("ALTER TABLE app ADD COLUMN id int", nil); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Done() != nil {
+		t.Error("expected the statement to run with a context which is never done")
+	}
+}
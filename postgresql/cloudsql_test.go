@@ -0,0 +1,163 @@
+package postgresql
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseCloudSQLConnectionName(t *testing.T) {
+	tests := []struct {
+		name                      string
+		project, region, instance string
+		valid                     bool
+	}{
+		{"my-project:europe-west1:db", "my-project", "europe-west1", "db", true},
+		{"example.com:my-project:europe-west1:db", "example.com:my-project", "europe-west1", "db", true},
+		{"my-project:db", "", "", "", false},
+		{"my-project::db", "", "", "", false},
+		{"a:b:c:d:e", "", "", "", false},
+	}
+
+	for _, test := range tests {
+		project, region, instance, err := parseCloudSQLConnectionName(test.name)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("expected %q to be invalid", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.name, err)
+			continue
+		}
+		if project != test.project || region != test.region || instance != test.instance {
+			t.Errorf("%q: expected %s, %s, %s, got %s, %s, %s", test.name,
+				test.project, test.region, test.instance, project, region, instance)
+		}
+	}
+}
+
+func TestCloudSQLDialerRefresh(t *testing.T) {
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Google Cloud SQL Server CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	sign := func(cn string, pub interface{}) []byte {
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{CommonName: cn},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour).Truncate(time.Second),
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, pub, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return der
+	}
+
+	var certReq map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/projects/my-project/instances/db/connectSettings":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"serverCaCert": map[string]string{
+					"cert": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})),
+				},
+				"ipAddresses": []map[string]string{
+					{"type": "PRIVATE", "ipAddress": "10.0.0.1"},
+					{"type": "PRIMARY", "ipAddress": "192.0.2.1"},
+				},
+				"region": "europe-west1",
+			})
+		case "/projects/my-project/instances/db:generateEphemeralCert":
+			if err := json.NewDecoder(r.Body).Decode(&certReq); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			block, _ := pem.Decode([]byte(certReq["public_key"]))
+			pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"ephemeralCert": map[string]string{
+					"cert": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: sign("client", pub)})),
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	defer os.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"))
+	os.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "test-token")
+
+	d, err := newCloudSQLDialer("my-project:europe-west1:db", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.adminURL = server.URL
+
+	tlsConfig, addr, err := d.refresh()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr != "192.0.2.1" {
+		t.Errorf("expected the primary address, got %q", addr)
+	}
+	if certReq["access_token"] != "test-token" {
+		t.Errorf("expected the access token to be sent for IAM authentication, got %q", certReq["access_token"])
+	}
+	if leaf := tlsConfig.Certificates[0].Leaf; !d.expiry.Equal(leaf.NotAfter) {
+		t.Errorf("expected the dialer to expire with its certificate (%s), got %s", leaf.NotAfter, d.expiry)
+	}
+
+	if again, _, _ := d.refresh(); again != tlsConfig {
+		t.Error("expected the client certificate to be reused")
+	}
+
+	serverKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tlsConfig.VerifyPeerCertificate([][]byte{sign("my-project:db", &serverKey.PublicKey)}, nil); err != nil {
+		t.Errorf("expected the instance's certificate to be accepted: %v", err)
+	}
+	if err := tlsConfig.VerifyPeerCertificate([][]byte{sign("my-project:other", &serverKey.PublicKey)}, nil); err == nil {
+		t.Error("expected another instance's certificate to be rejected")
+	}
+}
@@ -2,61 +2,198 @@ package postgresql
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"net"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 
 	"github.com/blang/semver"
 	"github.com/hashicorp/errwrap"
-	_ "github.com/lib/pq" //PostgreSQL db
+	"github.com/lib/pq"
 )
 
 type featureName uint
 
 const (
-	featureCreateRoleWith featureName = iota
+	featureControlSystem featureName = iota
+	featureCreateRoleWith
 	featureDBAllowConnections
 	featureDBIsTemplate
+	featureDeclarativePartitioning
+	featureDefaultPrivilegesOnSchemas
 	featureFallbackApplicationName
+	featureGeneratedColumns
+	featureIdentityColumns
+	featureIdleInTransactionSessionTimeout
+	featureIdleSessionTimeout
+	featureLargeObjectPrivileges
+	featureLogicalReplication
+	featureProKind
+	featurePublicationTruncate
 	featureRLS
 	featureReassignOwnedCurrentUser
+	featureReplicationSlots
+	featureRestrictivePolicies
+	featureSCRAM
 	featureSchemaCreateIfNotExist
+	featureSequenceCatalog
+	featureSettingPendingRestart
+	featureTypePrivileges
+	featureWALFunctionNames
 )
 
 type dbRegistryEntry struct {
-	db      *sql.DB
-	version semver.Version
+	db *sql.DB
+
+	// The server is fingerprinted once, on first use of the database.
+	fingerprintOnce sync.Once
+	fingerprintErr  error
+	version         semver.Version
+	superuser       bool
+	flavor          serverFlavor
 }
 
+// serverFlavor is the kind of server, PostgreSQL or one of the databases
+// speaking its protocol.
+type serverFlavor int
+
+const (
+	flavorPostgreSQL serverFlavor = iota
+	flavorCockroachDB
+	flavorRedshift
+	flavorAurora
+	flavorAlloyDB
+	flavorGreenplum
+	flavorYugabyteDB
+)
+
+// hookedDriverName is the database/sql driver used to open DSNs, which
+// establishes the connections as the hook registered for the DSN says.
+const hookedDriverName = "postgres-hooked"
+
 var (
 	dbRegistryLock sync.Mutex
-	dbRegistry     = make(map[string]dbRegistryEntry, 1)
+	dbRegistry     = make(map[string]*dbRegistryEntry, 1)
+
+	// Mapping of DSNs to the hooks they connect with
+	connHooksLock sync.Mutex
+	connHooks     = make(map[string]connHook)
+
+	// Mapping of feature flags to the versions supporting them
+	featureVersions = map[featureName]string{
+		// pg_control_system()
+		featureControlSystem: ">=9.6.0",
 
-	// Mapping of feature flags to versions
-	featureSupported = map[featureName]semver.Range{
 		// CREATE ROLE WITH
-		featureCreateRoleWith: semver.MustParseRange(">=8.1.0"),
+		featureCreateRoleWith: ">=8.1.0",
 
 		// CREATE DATABASE has ALLOW_CONNECTIONS support
-		featureDBAllowConnections: semver.MustParseRange(">=9.5.0"),
+		featureDBAllowConnections: ">=9.5.0",
 
 		// CREATE DATABASE has IS_TEMPLATE support
-		featureDBIsTemplate: semver.MustParseRange(">=9.5.0"),
+		featureDBIsTemplate: ">=9.5.0",
 
 		// https://www.postgresql.org/docs/9.0/static/libpq-connect.html
-		featureFallbackApplicationName: semver.MustParseRange(">=9.0.0"),
+		featureFallbackApplicationName: ">=9.0.0",
 
 		// CREATE SCHEMA IF NOT EXISTS
-		featureSchemaCreateIfNotExist: semver.MustParseRange(">=9.3.0"),
+		featureSchemaCreateIfNotExist: ">=9.3.0",
 
 		// REASSIGN OWNED BY { old_role | CURRENT_USER
-		featureReassignOwnedCurrentUser: semver.MustParseRange(">=9.5.0"),
+		featureReassignOwnedCurrentUser: ">=9.5.0",
 
 		// row-level security
-		featureRLS: semver.MustParseRange(">=9.5.0"),
+		featureRLS: ">=9.5.0",
+
+		// pg_proc.prokind (and procedures)
+		featureProKind: ">=11.0.0",
+
+		// ALTER DEFAULT PRIVILEGES ... ON SCHEMAS
+		featureDefaultPrivilegesOnSchemas: ">=10.0.0",
+
+		// CREATE TABLE ... PARTITION BY
+		featureDeclarativePartitioning: ">=10.0.0",
+
+		// GENERATED ALWAYS AS (expr) STORED
+		featureGeneratedColumns: ">=12.0.0",
+
+		// GENERATED { ALWAYS | BY DEFAULT } AS IDENTITY
+		featureIdentityColumns: ">=10.0.0",
+
+		// idle_in_transaction_session_timeout
+		featureIdleInTransactionSessionTimeout: ">=9.6.0",
+
+		// idle_session_timeout
+		featureIdleSessionTimeout: ">=14.0.0",
+
+		// GRANT ... ON LARGE OBJECT, pg_largeobject_metadata
+		featureLargeObjectPrivileges: ">=9.0.0",
+
+		// CREATE PUBLICATION / CREATE SUBSCRIPTION
+		featureLogicalReplication: ">=10.0.0",
+
+		// CREATE PUBLICATION ... WITH (publish = 'truncate')
+		featurePublicationTruncate: ">=11.0.0",
+
+		// password_encryption = 'scram-sha-256'
+		featureSCRAM: ">=10.0.0",
+
+		// pg_sequence
+		featureSequenceCatalog: ">=10.0.0",
+
+		// pg_settings.pending_restart
+		featureSettingPendingRestart: ">=9.5.0",
+
+		// pg_replication_slots
+		featureReplicationSlots: ">=9.4.0",
+
+		// CREATE POLICY ... AS RESTRICTIVE
+		featureRestrictivePolicies: ">=10.0.0",
+
+		// GRANT ... ON TYPE / DOMAIN
+		featureTypePrivileges: ">=9.2.0",
+
+		// pg_current_wal_lsn() et al. (formerly pg_current_xlog_location())
+		featureWALFunctionNames: ">=10.0.0",
+	}
+
+	// Mapping of feature flags to the ranges of featureVersions
+	featureSupported = parseFeatureVersions(featureVersions)
+
+	// Feature flags CockroachDB does not support, whatever the version of
+	// PostgreSQL it reports compatibility with
+	cockroachUnsupported = map[featureName]bool{
+		featureControlSystem:           true,
+		featureDBAllowConnections:      true,
+		featureDBIsTemplate:            true,
+		featureDeclarativePartitioning: true,
+		featureLargeObjectPrivileges:   true,
+		featureLogicalReplication:      true,
+		featurePublicationTruncate:     true,
+		featureRLS:                     true,
+		featureReplicationSlots:        true,
+		featureRestrictivePolicies:     true,
+		featureSettingPendingRestart:   true,
+		featureWALFunctionNames:        true,
+	}
+
+	// Feature flags YugabyteDB does not support, whatever the version of
+	// PostgreSQL it is based on
+	yugabyteUnsupported = map[featureName]bool{
+		featureControlSystem:         true,
+		featureLargeObjectPrivileges: true,
+		featureLogicalReplication:    true,
+		featurePublicationTruncate:   true,
+		featureReplicationSlots:      true,
+		featureWALFunctionNames:      true,
 	}
 )
 
@@ -68,11 +205,192 @@ type Config struct {
 	Username          string
 	Password          string
 	SSLMode           string
+	SSLRootCert       string
+	SSLCert           string
+	SSLKey            string
 	ApplicationName   string
 	Timeout           int
 	ConnectTimeoutSec int
 	MaxConns          int
+	MaxIdleConns      int
+	ConnMaxLifetime   time.Duration
+	StatementTimeout  time.Duration
+	LockTimeout       time.Duration
+	DatabasePoolSize  int
 	ExpectedVersion   semver.Version
+
+	// CheckVersion makes connecting to a server whose major version is not
+	// ExpectedVersion's an error.
+	CheckVersion bool
+
+	// ExpectedSystemIdentifier, if set, makes connecting to a cluster whose
+	// system identifier is not this one an error.
+	ExpectedSystemIdentifier string
+
+	// SessionRole is the role the sessions act as, as if by SET ROLE, instead
+	// of Username.
+	SessionRole string
+
+	// Pooled is set when connecting through a pooler, e.g. RDS Proxy, which
+	// shares server connections between clients.  Queries are then sent
+	// without preparing them first, and sessions keep no state.
+	Pooled bool
+
+	// SSLMinProtocolVersion is lib/pq's ssl_min_protocol_version, e.g.
+	// "TLSv1.2", or empty for Go's default.
+	SSLMinProtocolVersion string
+
+	// KrbSrvname is the Kerberos service name of the server, with which
+	// connections authenticate when it asks for GSSAPI, or empty for
+	// postgres.
+	KrbSrvname string
+
+	// ChannelBinding is libpq's channel_binding, disable, prefer or require,
+	// for connections authenticated with SCRAM-SHA-256 by the provider in
+	// place of lib/pq, which does not support SCRAM-SHA-256-PLUS.  It
+	// requires the connections to be encrypted by dialer.  When empty,
+	// lib/pq authenticates them itself.
+	ChannelBinding string
+
+	// ReadHost and ReadPort, if set, are the address of a read-only replica
+	// of the server resources are refreshed from.  ReadPort defaults to
+	// Port.
+	ReadHost string
+	ReadPort int
+
+	// ReadPooled is set when ReadHost is the pooled endpoint of a server
+	// whose Host is the direct one, e.g. on Neon or Supabase.
+	ReadPooled bool
+
+	// ignoreSessionRole is set on the client of the pooled endpoint of
+	// ReadPooled, which refreshes resources as Username, without the
+	// session_role of the resources changed through Host.
+	ignoreSessionRole bool
+
+	// TargetSessionAttrs is "read-write" to only connect to a server, among
+	// the comma-separated hosts of Host, which accepts read-write sessions,
+	// or "any".
+	TargetSessionAttrs string
+
+	// SearchPath is the schema search path of the sessions, instead of the
+	// one configured for Username or Database.
+	SearchPath []string
+
+	// MaxConnectRetries is the number of times a failed connection attempt
+	// is retried, waiting ConnectRetryBackoff at first and twice as long
+	// every time up to ConnectRetryMaxBackoff.
+	MaxConnectRetries      int
+	ConnectRetryBackoff    time.Duration
+	ConnectRetryMaxBackoff time.Duration
+
+	// MaxSerializationRetries is the number of times a transaction failing
+	// with a serialization failure or a deadlock is retried, waiting
+	// SerializationRetryBackoff at first and twice as long every time.
+	MaxSerializationRetries   int
+	SerializationRetryBackoff time.Duration
+
+	// AdvisoryLocks makes changes take the catalog lock on the server too,
+	// as advisory locks, waiting up to AdvisoryLockTimeout, or forever if
+	// zero, for other Terraform runs to release it.
+	AdvisoryLocks       bool
+	AdvisoryLockTimeout time.Duration
+
+	// AllowUnsafeGrants makes the grants of privileges on system objects,
+	// and of superuser-equivalent combinations of privileges, warnings
+	// instead of errors.
+	AllowUnsafeGrants bool
+
+	// Superuser overrides whether the connection user is considered to be a
+	// superuser.  When nil, it is detected from pg_roles.
+	Superuser *bool
+
+	// dialer, if set, establishes the connections to the server in place of
+	// lib/pq, e.g. to connect through the Cloud SQL proxy.
+	dialer pq.Dialer
+
+	// tlsConfigMode, if set, is the sslmode of the TLS configuration
+	// registered with lib/pq SSL connections are established with, for the
+	// settings lib/pq does not support, e.g. in-memory certificates.
+	tlsConfigMode string
+
+	// passwordFunc, if set, is called for the password of every new
+	// connection in place of Password, e.g. to use short-lived tokens.
+	passwordFunc func() (string, error)
+
+	// dryRun, if set, collects the statements changing the server instead
+	// of running them.
+	dryRun *dryRun
+
+	// audit, if set, records the statements changing the server.
+	audit *auditLog
+
+	// stats, if set, counts the statements and retries.
+	stats *applyStats
+
+	// stop, if set, is the provider's stop context, which cancels the
+	// statements running when Terraform is interrupted.
+	stop context.Context
+
+	// partial is true if the provider is configured with values Terraform
+	// only knows at apply, e.g. the host of a server created by the same
+	// apply, so that plans do not connect to check the server's features.
+	partial bool
+}
+
+// connHook holds how connections to a DSN are established.
+type connHook struct {
+	dialer       pq.Dialer
+	password     string
+	passwordFunc func() (string, error)
+
+	// channelBinding, if set, makes the connections authenticate with
+	// SCRAM-SHA-256 through scramDialer.
+	channelBinding string
+
+	// dryRun, if set, collects the statements changing the server instead
+	// of the connections running them.
+	dryRun *dryRun
+
+	// audit, if set, records the statements changing the server, with the
+	// database, user and role of the connections.
+	audit    *auditLog
+	database string
+	user     string
+	role     string
+
+	stats *applyStats
+
+	// stop, if set, cancels the statements of the connections running when
+	// it is done.
+	stop context.Context
+
+	// sslModes are the sslmodes to try in turn, for the allow and prefer
+	// sslmodes with the TLS configuration registered with lib/pq, which
+	// it only uses to require SSL.
+	sslModes []string
+
+	// hosts are the hosts to try in turn, when there are several.
+	hosts []string
+
+	// readWrite requires the server to accept read-write sessions, as
+	// libpq's target_session_attrs=read-write does, to find the primary
+	// among hosts.
+	readWrite bool
+
+	maxRetries      int
+	retryBackoff    time.Duration
+	retryMaxBackoff time.Duration
+}
+
+// errReadOnlyServer is the error connecting to a server which only accepts
+// read-only sessions when read-write ones are required, as happens to a
+// primary being failed over.
+var errReadOnlyServer = errors.New("the server only accepts read-only sessions")
+
+// dbKey identifies the handles of Client.DBFor().
+type dbKey struct {
+	database string
+	role     string
 }
 
 // Client struct holding connection string
@@ -84,56 +402,485 @@ type Client struct {
 	// releasing their connections.
 	db *sql.DB
 
+	// dbEntry is the registry entry of db, holding its fingerprint once
+	// connect() succeeded.
+	dbEntry     *dbRegistryEntry
+	connectOnce sync.Once
+	connectErr  error
+
 	// version is the version number of the database as determined by parsing the
 	// output of `SELECT VERSION()`.x
 	version semver.Version
 
-	// PostgreSQL lock on pg_catalog.  Many of the operations that Terraform
+	// cockroach is true if the server is CockroachDB, whose version is the
+	// one of PostgreSQL it is compatible with.
+	cockroach bool
+
+	// redshift is true if the server is Amazon Redshift, whose version is
+	// the one of PostgreSQL it was forked from, 8.0.2.
+	redshift bool
+
+	// aurora is true if the server is Amazon Aurora PostgreSQL.
+	aurora bool
+
+	// alloydb is true if the server is Google AlloyDB for PostgreSQL.
+	alloydb bool
+
+	// greenplum is true if the server is Greenplum or Cloudberry, whose
+	// tables have a distribution policy.
+	greenplum bool
+
+	// yugabyte is true if the server is YugabyteDB, whose version is the
+	// one of PostgreSQL it is based on.
+	yugabyte bool
+
+	// superuser is true if the connection user is a superuser.  Cloud
+	// providers commonly hand out administrative roles which are not, in
+	// which case resources work around the missing privileges, e.g. by
+	// temporarily granting themselves membership of the roles they act on.
+	superuser bool
+
+	// reader is the client of the replica resources are refreshed from, if
+	// any.
+	reader *Client
+
+	// dbs holds the handles to the databases, or session roles, resources
+	// connect with instead of the provider's.  At most
+	// config.DatabasePoolSize of them, the most recently used ones, keep
+	// idle connections open.
+	dbsLock sync.Mutex
+	dbs     map[dbKey]*sql.DB
+	dbsLRU  []dbKey
+
+	// lockDB holds the sessions of the advisory locks, if
+	// config.AdvisoryLocks.
+	lockDB *sql.DB
+
+	// PostgreSQL locks on pg_catalog.  Many of the operations that Terraform
 	// performs are not permitted to be concurrent.  Unlike traditional
 	// PostgreSQL tables that use MVCC, many of the PostgreSQL system
 	// catalogs look like tables, but are not in-fact able to be
-	// concurrently updated.
-	catalogLock sync.RWMutex
+	// concurrently updated.  There is a lock per class of objects and
+	// database, see lockCatalog.
+	catalogLocks catalogLocks
 }
 
-// NewClient returns new client config
+// NewClient returns new client config.  No connection is established until
+// the client's connect() is called.
 func (c *Config) NewClient() (*Client, error) {
+	var reader *Client
+	if c.ReadHost != "" {
+		readConfig := *c
+		readConfig.Host = c.ReadHost
+		if c.ReadPort != 0 {
+			readConfig.Port = c.ReadPort
+		}
+		readConfig.ReadHost = ""
+		readConfig.ReadPort = 0
+		readConfig.TargetSessionAttrs = "any"
+		readConfig.Pooled = c.Pooled || c.ReadPooled
+		readConfig.ReadPooled = false
+		if c.ReadPooled {
+			withoutSessionState(&readConfig)
+		}
+
+		var err error
+		if reader, err = readConfig.NewClient(); err != nil {
+			return nil, err
+		}
+	}
+
 	dbRegistryLock.Lock()
 	defer dbRegistryLock.Unlock()
 
 	dsn := c.connStr()
 	dbEntry, found := dbRegistry[dsn]
 	if !found {
-		db, err := sql.Open("postgres", dsn)
+		db, err := c.open(dsn)
 		if err != nil {
 			return nil, errwrap.Wrapf("Error connecting to PostgreSQL server: {{err}}", err)
 		}
 
-		// only one connection
-		db.SetMaxIdleConns(1)
+		db.SetMaxIdleConns(c.MaxIdleConns)
 		db.SetMaxOpenConns(c.MaxConns)
+		db.SetConnMaxLifetime(c.ConnMaxLifetime)
 
-		version, err := fingerprintCapabilities(db)
-		if err != nil {
-			db.Close()
-			return nil, errwrap.Wrapf("error detecting capabilities: {{err}}", err)
-		}
-
-		dbEntry = dbRegistryEntry{
-			db:      db,
-			version: *version,
+		dbEntry = &dbRegistryEntry{
+			db: db,
 		}
 		dbRegistry[dsn] = dbEntry
 	}
 
 	client := Client{
-		config: *c,
-		db:     dbEntry.db,
+		config:  *c,
+		db:      dbEntry.db,
+		dbEntry: dbEntry,
+		dbs:     make(map[dbKey]*sql.DB),
+		reader:  reader,
+	}
+
+	if c.AdvisoryLocks {
+		// The sessions holding the locks must not wait for the connections
+		// of the operations holding them.
+		lockDB, err := c.open(dsn)
+		if err != nil {
+			return nil, errwrap.Wrapf("Error connecting to PostgreSQL server: {{err}}", err)
+		}
+		lockDB.SetMaxIdleConns(1)
+		client.lockDB = lockDB
 	}
 
 	return &client, nil
 }
 
+// forRead returns the client to refresh resources with: the replica's if
+// there is one, or c.
+func (c *Client) forRead() *Client {
+	if c.reader != nil {
+		return c.reader
+	}
+
+	return c
+}
+
+// connect connects to the server, if not done yet, to fingerprint it.  It must
+// be called before the client's version or superuser fields are used, and is
+// by every resource and data source operation, so that the provider does not
+// connect to the server unless it manages something in it.
+func (c *Client) connect() error {
+	c.connectOnce.Do(func() {
+		entry := c.dbEntry
+		entry.fingerprintOnce.Do(func() {
+			version, flavor, err := fingerprintCapabilities(entry.db)
+			if err != nil {
+				entry.fingerprintErr = errwrap.Wrapf("error detecting capabilities: {{err}}", err)
+				return
+			}
+
+			superuser, err := detectSuperuser(entry.db)
+			if err != nil {
+				entry.fingerprintErr = errwrap.Wrapf("error detecting superuser: {{err}}", err)
+				return
+			}
+
+			entry.version = *version
+			entry.superuser = superuser
+			entry.flavor = flavor
+		})
+		if entry.fingerprintErr != nil {
+			c.connectErr = errwrap.Wrapf("Error initializing PostgreSQL client: {{err}}", entry.fingerprintErr)
+			return
+		}
+
+		if c.config.CheckVersion && majorVersion(entry.version) != majorVersion(c.config.ExpectedVersion) {
+			c.connectErr = fmt.Errorf("PostgreSQL server version %s does not match expected_version %s", entry.version, c.config.ExpectedVersion)
+			return
+		}
+
+		if c.config.ExpectedSystemIdentifier != "" {
+			if c.connectErr = checkSystemIdentifier(entry.db, entry.version, c.config.ExpectedSystemIdentifier); c.connectErr != nil {
+				return
+			}
+		}
+
+		c.version = entry.version
+		c.superuser = entry.superuser
+		c.cockroach = entry.flavor == flavorCockroachDB
+		c.redshift = entry.flavor == flavorRedshift
+		c.aurora = entry.flavor == flavorAurora
+		c.alloydb = entry.flavor == flavorAlloyDB
+		c.greenplum = entry.flavor == flavorGreenplum
+		c.yugabyte = entry.flavor == flavorYugabyteDB
+		if c.config.Superuser != nil {
+			c.superuser = *c.config.Superuser
+		}
+	})
+
+	return c.connectErr
+}
+
+// checkSystemIdentifier returns an error unless the cluster db connects to,
+// running version, has the expected system identifier, which is unique to
+// every cluster and shared with its physical replicas.
+func checkSystemIdentifier(db *sql.DB, version semver.Version, expected string) error {
+	if !featureSupported[featureControlSystem](version) {
+		return fmt.Errorf("expected_system_identifier requires PostgreSQL 9.6 or later, the server is %s", version)
+	}
+
+	var systemIdentifier string
+	if err := db.QueryRow("SELECT system_identifier::text FROM pg_catalog.pg_control_system()").Scan(&systemIdentifier); err != nil {
+		return errwrap.Wrapf("Error reading the system identifier of the PostgreSQL cluster: {{err}}", err)
+	}
+	if systemIdentifier != expected {
+		return fmt.Errorf("PostgreSQL cluster system identifier %s does not match expected_system_identifier %s", systemIdentifier, expected)
+	}
+
+	return nil
+}
+
+// majorVersion returns the major version of a PostgreSQL release: its first
+// component since PostgreSQL 10, its first two before.
+func majorVersion(v semver.Version) string {
+	if v.Major >= 10 {
+		return strconv.FormatUint(v.Major, 10)
+	}
+
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// open returns a handle to the database at dsn, which must have been built from
+// the config.
+func (c *Config) open(dsn string) (*sql.DB, error) {
+	connHooksLock.Lock()
+	connHooks[dsn] = connHook{
+		dialer:          c.dialer,
+		password:        c.Password,
+		passwordFunc:    c.passwordFunc,
+		channelBinding:  c.ChannelBinding,
+		sslModes:        c.sslModes(),
+		hosts:           c.hosts(),
+		readWrite:       c.TargetSessionAttrs == "read-write",
+		maxRetries:      c.MaxConnectRetries,
+		retryBackoff:    c.ConnectRetryBackoff,
+		retryMaxBackoff: c.ConnectRetryMaxBackoff,
+		dryRun:          c.dryRun,
+		audit:           c.audit,
+		database:        c.Database,
+		user:            c.Username,
+		role:            c.SessionRole,
+		stats:           c.stats,
+		stop:            c.stop,
+	}
+	connHooksLock.Unlock()
+
+	return sql.Open(hookedDriverName, dsn)
+}
+
+// sslModes returns the sslmodes to try in turn with the TLS configuration
+// registered with lib/pq, or nil if there is none.
+func (c *Config) sslModes() []string {
+	if c.tlsConfigMode == "" {
+		return nil
+	}
+
+	switch c.SSLMode {
+	case "allow":
+		return []string{"disable", c.tlsConfigMode}
+	case "prefer":
+		return []string{c.tlsConfigMode, "disable"}
+	}
+
+	return []string{c.tlsConfigMode}
+}
+
+// hosts returns the hosts of a comma-separated Host, or nil if there is only
+// one.
+func (c *Config) hosts() []string {
+	if !strings.Contains(c.Host, ",") {
+		return nil
+	}
+
+	var hosts []string
+	for _, host := range strings.Split(c.Host, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return hosts
+}
+
+// hookedDriver is lib/pq's driver, connecting with the hook registered for the
+// DSN by Config.open().
+type hookedDriver struct{}
+
+func (hookedDriver) Open(dsn string) (driver.Conn, error) {
+	connHooksLock.Lock()
+	hook, found := connHooks[dsn]
+	connHooksLock.Unlock()
+	if !found {
+		return nil, fmt.Errorf("no connection hook registered for the connection")
+	}
+
+	backoff := hook.retryBackoff
+	for retry := 0; ; retry++ {
+		conn, err := hook.open(dsn)
+		if err == nil || retry == hook.maxRetries || !retryableConnError(err) {
+			return conn, err
+		}
+
+		logEvent("WARN", "Error connecting to PostgreSQL server, retrying", logFields{
+			"backoff":     backoff.String(),
+			"retry":       retry + 1,
+			"max_retries": hook.maxRetries,
+			"error":       err,
+		})
+		hook.stats.retry()
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if backoff > hook.retryMaxBackoff {
+			backoff = hook.retryMaxBackoff
+		}
+	}
+}
+
+// open establishes a connection to the DSN the hook is registered for.
+func (hook connHook) open(dsn string) (driver.Conn, error) {
+	password := hook.password
+	if hook.passwordFunc != nil {
+		var err error
+		if password, err = hook.passwordFunc(); err != nil {
+			return nil, err
+		}
+
+		// Later settings override earlier ones.
+		dsn += " password=" + quoteConnValue(password)
+	}
+
+	dialer := hook.dialer
+	if dialer == nil {
+		dialer = netDialer{}
+	}
+	if hook.channelBinding != "" {
+		dialer = &scramDialer{
+			forward:        dialer,
+			password:       password,
+			channelBinding: hook.channelBinding,
+		}
+	}
+	conns := &lastConnDialer{forward: dialer}
+
+	if hook.hosts == nil {
+		conn, err := hook.openHost(conns, dsn)
+		if err != nil {
+			return nil, err
+		}
+		return hook.liveConn(conn, conns.conn), nil
+	}
+
+	var err error
+	for _, host := range hook.hosts {
+		var conn driver.Conn
+		conn, err = hook.openHost(conns, dsn+" host="+quoteConnValue(host))
+		if err == nil {
+			return hook.liveConn(conn, conns.conn), nil
+		}
+
+		logEvent("DEBUG", "Error connecting to PostgreSQL server, trying the next host", logFields{"host": host, "error": err})
+	}
+
+	return nil, err
+}
+
+// liveConn returns the connection established over netConn.
+func (hook connHook) liveConn(conn driver.Conn, netConn net.Conn) *liveConn {
+	return &liveConn{
+		Conn:     conn,
+		netConn:  netConn,
+		dryRun:   hook.dryRun,
+		audit:    hook.audit,
+		database: hook.database,
+		user:     hook.user,
+		role:     hook.role,
+		stats:    hook.stats,
+		stop:     hook.stop,
+		used:     time.Now(),
+	}
+}
+
+// openHost establishes a connection to the single host of dsn, trying each of
+// the sslmodes in turn and checking the session attributes.
+func (hook connHook) openHost(dialer pq.Dialer, dsn string) (driver.Conn, error) {
+	var conn driver.Conn
+	var err error
+	if hook.sslModes == nil {
+		conn, err = pq.DialOpen(dialer, dsn)
+	} else {
+		for _, sslMode := range hook.sslModes {
+			conn, err = pq.DialOpen(dialer, dsn+" sslmode="+sslMode)
+			if !retryWithSSLMode(err) {
+				break
+			}
+		}
+	}
+	if err != nil || !hook.readWrite {
+		return conn, err
+	}
+
+	readOnly, err := sessionReadOnly(conn)
+	if err == nil && readOnly {
+		err = errReadOnlyServer
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// sessionReadOnly returns true if the session of conn is read-only, as on a
+// standby server.
+func sessionReadOnly(conn driver.Conn) (bool, error) {
+	queryer, ok := conn.(driver.Queryer)
+	if !ok {
+		return false, fmt.Errorf("the connection does not support queries")
+	}
+
+	rows, err := queryer.Query("SHOW transaction_read_only", nil)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	dest := make([]driver.Value, 1)
+	if err := rows.Next(dest); err != nil {
+		return false, err
+	}
+
+	var value string
+	switch v := dest[0].(type) {
+	case []byte:
+		value = string(v)
+	case string:
+		value = v
+	}
+
+	return value == "on", nil
+}
+
+// retryWithSSLMode returns true if a connection may succeed with another
+// sslmode after failing with err: the server does not support SSL, or
+// rejected the connection.
+func retryWithSSLMode(err error) bool {
+	if err == pq.ErrSSLNotSupported {
+		return true
+	}
+
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code.Class() == "28"
+}
+
+// retryableConnError returns true if err is a failure to connect which may
+// not happen on another attempt, as when the server is not up yet.
+func retryableConnError(err error) bool {
+	switch err := err.(type) {
+	case *pq.Error:
+		// connection_exception and cannot_connect_now, raised while the
+		// server is starting up, or while Neon wakes its compute up.
+		return err.Code.Class() == "08" || err.Code == "57P03" || neonWakeError(err)
+	case net.Error:
+		return true
+	}
+
+	return err == io.EOF || err == io.ErrUnexpectedEOF || err == errReadOnlyServer
+}
+
+func init() {
+	sql.Register(hookedDriverName, hookedDriver{})
+}
+
 // featureSupported returns true if a given feature is supported or not.  This
 // is slightly different from Client's featureSupported in that here we're
 // evaluating against the expected version, not the fingerprinted version.
@@ -147,6 +894,36 @@ func (c *Config) featureSupported(name featureName) bool {
 	return fn(c.ExpectedVersion)
 }
 
+// quoteConnValue quotes empty strings or strings that contain whitespace for
+// use as a value in a DSN.
+func quoteConnValue(s string) string {
+	b := bytes.NewBufferString(`'`)
+	b.Grow(len(s) + 2)
+	var haveWhitespace bool
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			haveWhitespace = true
+		}
+
+		switch r {
+		case '\'':
+			b.WriteString(`\'`)
+		case '\\':
+			b.WriteString(`\\`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	b.WriteString(`'`)
+
+	str := b.String()
+	if haveWhitespace || len(str) == 2 {
+		return str
+	}
+	return str[1 : len(str)-1]
+}
+
 func (c *Config) connStr() string {
 	// NOTE: dbname must come before user otherwise dbname will be set to
 	// user.
@@ -166,36 +943,19 @@ func (c *Config) connStr() string {
 			dsnFmtParts = append(dsnFmtParts, "fallback_application_name=%s")
 		}
 
+		for _, param := range c.optionalParams() {
+			dsnFmtParts = append(dsnFmtParts, param[0]+"=%s")
+		}
+
 		dsnFmt = strings.Join(dsnFmtParts, " ")
 	}
 
-	// Quote empty strings or strings that contain whitespace
-	quote := func(s string) string {
-		b := bytes.NewBufferString(`'`)
-		b.Grow(len(s) + 2)
-		var haveWhitespace bool
-		for _, r := range s {
-			if unicode.IsSpace(r) {
-				haveWhitespace = true
-			}
+	quote := quoteConnValue
 
-			switch r {
-			case '\'':
-				b.WriteString(`\'`)
-			case '\\':
-				b.WriteString(`\\`)
-			default:
-				b.WriteRune(r)
-			}
-		}
-
-		b.WriteString(`'`)
-
-		str := b.String()
-		if haveWhitespace || len(str) == 2 {
-			return str
-		}
-		return str[1 : len(str)-1]
+	// lib/pq no longer defaults to require.
+	sslMode := c.SSLMode
+	if sslMode == "" {
+		sslMode = "require"
 	}
 
 	{
@@ -205,15 +965,18 @@ func (c *Config) connStr() string {
 			quote(c.Database),
 			quote(c.Username),
 			quote("<redacted>"),
-			quote(c.SSLMode),
+			quote(sslMode),
 			c.ConnectTimeoutSec,
 		}
 		if c.featureSupported(featureFallbackApplicationName) {
 			logValues = append(logValues, quote(c.ApplicationName))
 		}
+		for _, param := range c.optionalParams() {
+			logValues = append(logValues, quote(param[1]))
+		}
 
 		logDSN := fmt.Sprintf(dsnFmt, logValues...)
-		log.Printf("[INFO] PostgreSQL DSN: `%s`", logDSN)
+		logEvent("INFO", "PostgreSQL DSN", logFields{"dsn": logDSN})
 	}
 
 	var connStr string
@@ -224,18 +987,78 @@ func (c *Config) connStr() string {
 			quote(c.Database),
 			quote(c.Username),
 			quote(c.Password),
-			quote(c.SSLMode),
+			quote(sslMode),
 			c.ConnectTimeoutSec,
 		}
 		if c.featureSupported(featureFallbackApplicationName) {
 			connValues = append(connValues, quote(c.ApplicationName))
 		}
+		for _, param := range c.optionalParams() {
+			connValues = append(connValues, quote(param[1]))
+		}
 		connStr = fmt.Sprintf(dsnFmt, connValues...)
 	}
 
 	return connStr
 }
 
+// optionalParams returns the name and value of the optional connection
+// settings which are set: the SSL certificate and key, and the run-time
+// parameters of the sessions.
+func (c *Config) optionalParams() [][2]string {
+	var params [][2]string
+	for _, param := range [][2]string{
+		{"sslrootcert", c.SSLRootCert},
+		{"sslcert", c.SSLCert},
+		{"sslkey", c.SSLKey},
+		{"ssl_min_protocol_version", sslVersions[c.SSLMinProtocolVersion]},
+		{"krbsrvname", c.KrbSrvname},
+		{"statement_timeout", durationMillis(c.StatementTimeout)},
+		{"lock_timeout", durationMillis(c.LockTimeout)},
+		{"role", c.SessionRole},
+		{"search_path", c.searchPath()},
+		{"binary_parameters", c.binaryParameters()},
+	} {
+		if param[1] != "" {
+			params = append(params, param)
+		}
+	}
+
+	return params
+}
+
+// binaryParameters returns lib/pq's binary_parameters setting, yes when pooled
+// so that queries with parameters are sent along with them, with an unnamed
+// statement, rather than prepared first.
+func (c *Config) binaryParameters() string {
+	if c.Pooled {
+		return "yes"
+	}
+
+	return ""
+}
+
+// searchPath returns the search_path setting for SearchPath, or an empty
+// string if it is not set.
+func (c *Config) searchPath() string {
+	schemas := make([]string, 0, len(c.SearchPath))
+	for _, schema := range c.SearchPath {
+		schemas = append(schemas, pq.QuoteIdentifier(schema))
+	}
+
+	return strings.Join(schemas, ", ")
+}
+
+// durationMillis formats a positive duration as a number of milliseconds, the
+// default unit of PostgreSQL's time settings.
+func durationMillis(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+
+	return strconv.FormatInt(int64(d/time.Millisecond), 10)
+}
+
 // DB returns a copy to an sql.Open()'ed database connection.  Callers must
 // return their database resources.  Use of QueryRow() or Exec() is encouraged.
 // Query() must have their rows.Close()'ed.
@@ -243,13 +1066,108 @@ func (c *Client) DB() *sql.DB {
 	return c.db
 }
 
+// DBFor returns the handle to use for the given database and session role,
+// opening it on first use.  An empty database name or role, or the provider's,
+// returns DB().  The same rules as for DB() apply to the returned handle.
+func (c *Client) DBFor(database, role string) (*sql.DB, error) {
+	if database == "" {
+		database = c.config.Database
+	}
+	if c.config.ignoreSessionRole {
+		role = ""
+	}
+	if role == "" {
+		role = c.config.SessionRole
+	}
+	if database == c.config.Database && role == c.config.SessionRole {
+		return c.db, nil
+	}
+	if c.config.Pooled && role != "" {
+		return nil, fmt.Errorf("session_role can not be used with connection_profile %s: it is session state, which poolers pin sessions to a server connection for", connProfilePooled)
+	}
+
+	c.dbsLock.Lock()
+	defer c.dbsLock.Unlock()
+
+	key := dbKey{database: database, role: role}
+	db, found := c.dbs[key]
+	if !found {
+		config := c.config
+		config.Database = database
+		config.SessionRole = role
+
+		var err error
+		db, err = config.open(config.connStr())
+		if err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("Error connecting to PostgreSQL database %q: {{err}}", database), err)
+		}
+		db.SetMaxOpenConns(c.config.MaxConns)
+		db.SetConnMaxLifetime(c.config.ConnMaxLifetime)
+		c.dbs[key] = db
+	}
+
+	// Move the database to the most recently used end of the pool and stop
+	// keeping idle connections to the ones falling off the other end.  The
+	// handles themselves are kept, as they may be in use by concurrent
+	// operations.
+	for i, k := range c.dbsLRU {
+		if k == key {
+			c.dbsLRU = append(c.dbsLRU[:i], c.dbsLRU[i+1:]...)
+			break
+		}
+	}
+	c.dbsLRU = append(c.dbsLRU, key)
+	db.SetMaxIdleConns(c.config.MaxIdleConns)
+	for len(c.dbsLRU) > c.config.DatabasePoolSize {
+		c.dbs[c.dbsLRU[0]].SetMaxIdleConns(0)
+		c.dbsLRU = c.dbsLRU[1:]
+	}
+
+	return db, nil
+}
+
+// releaseDB closes the idle connections of the handles to the database, which
+// would otherwise keep it from being dropped.
+func (c *Client) releaseDB(database string) {
+	c.dbsLock.Lock()
+	defer c.dbsLock.Unlock()
+
+	for key, db := range c.dbs {
+		if key.database != database {
+			continue
+		}
+		db.SetMaxIdleConns(0)
+		for i, k := range c.dbsLRU {
+			if k == key {
+				c.dbsLRU = append(c.dbsLRU[:i], c.dbsLRU[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
 // fingerprintCapabilities queries PostgreSQL to populate a local catalog of
-// capabilities.  This is only run once per Client.
-func fingerprintCapabilities(db *sql.DB) (*semver.Version, error) {
+// capabilities, and its flavor.  This is only run once per Client.
+func fingerprintCapabilities(db *sql.DB) (*semver.Version, serverFlavor, error) {
 	var pgVersion string
 	err := db.QueryRow(`SELECT VERSION()`).Scan(&pgVersion)
 	if err != nil {
-		return nil, errwrap.Wrapf("error PostgreSQL version: {{err}}", err)
+		return nil, flavorPostgreSQL, errwrap.Wrapf("error PostgreSQL version: {{err}}", err)
+	}
+
+	// CockroachDB CCL v23.1.11 (x86_64-pc-linux-gnu, built 2023/09/27 01:53:43, go1.19.10)
+	//
+	// CockroachDB's server_version is the version of PostgreSQL it is
+	// compatible with, e.g. 13.0.0, which features are checked against.
+	if strings.HasPrefix(pgVersion, "CockroachDB") {
+		if err := db.QueryRow(`SELECT current_setting('server_version')`).Scan(&pgVersion); err != nil {
+			return nil, flavorCockroachDB, errwrap.Wrapf("error CockroachDB server_version: {{err}}", err)
+		}
+		version, err := semver.ParseTolerant(pgVersion)
+		if err != nil {
+			return nil, flavorCockroachDB, errwrap.Wrapf("error parsing version: {{err}}", err)
+		}
+		return &version, flavorCockroachDB, nil
 	}
 
 	// PostgreSQL 9.2.21 on x86_64-apple-darwin16.5.0, compiled by Apple LLVM version 8.1.0 (clang-802.0.42), 64-bit
@@ -258,20 +1176,74 @@ func fingerprintCapabilities(db *sql.DB) (*semver.Version, error) {
 		return unicode.IsSpace(c) || c == ','
 	})
 	if len(fields) < 2 {
-		return nil, fmt.Errorf("error determining the server version: %q", pgVersion)
+		return nil, flavorPostgreSQL, fmt.Errorf("error determining the server version: %q", pgVersion)
+	}
+
+	// PostgreSQL 11.2-YB-2.18.0.0-b0 on x86_64-pc-linux-gnu, compiled by clang version 15.0.3, 64-bit
+	yugabyte := false
+	if i := strings.Index(fields[1], "-YB-"); i >= 0 {
+		fields[1] = fields[1][:i]
+		yugabyte = true
 	}
 
 	version, err := semver.ParseTolerant(fields[1])
 	if err != nil {
-		return nil, errwrap.Wrapf("error parsing version: {{err}}", err)
+		return nil, flavorPostgreSQL, errwrap.Wrapf("error parsing version: {{err}}", err)
+	}
+
+	if yugabyte {
+		return &version, flavorYugabyteDB, nil
+	}
+
+	// PostgreSQL 8.0.2 on i686-pc-linux-gnu, compiled by GCC gcc (GCC) 3.4.2 20041017 (Red Hat 3.4.2-6.fc3), Redshift 1.0.12103
+	if strings.Contains(pgVersion, "Redshift") {
+		return &version, flavorRedshift, nil
+	}
+
+	// PostgreSQL 9.4.26 (Greenplum Database 6.25.3 build commit:...) on x86_64-unknown-linux-gnu, compiled by gcc (GCC) 6.4.0, 64-bit compiled on Oct  4 2023 23:27:17
+	// PostgreSQL 14.4 (Cloudberry Database 1.5.4 build 1) on x86_64-pc-linux-gnu, compiled by gcc (GCC) 10.2.1, 64-bit compiled on Jul  1 2024 10:00:00
+	if strings.Contains(pgVersion, "Greenplum Database") || strings.Contains(pgVersion, "Cloudberry") {
+		return &version, flavorGreenplum, nil
 	}
 
-	return &version, nil
+	// Aurora and AlloyDB report the version of PostgreSQL they are
+	// compatible with, and have aurora_version() and the settings of the
+	// columnar engine besides.
+	var flavor string
+	if err := db.QueryRow(`SELECT CASE ` +
+		`WHEN EXISTS (SELECT 1 FROM pg_catalog.pg_proc WHERE proname = 'aurora_version') THEN 'aurora' ` +
+		`WHEN EXISTS (SELECT 1 FROM pg_catalog.pg_settings WHERE name = 'google_columnar_engine.enabled') THEN 'alloydb' ` +
+		`ELSE '' END`).Scan(&flavor); err != nil {
+		return nil, flavorPostgreSQL, errwrap.Wrapf("error detecting the server flavor: {{err}}", err)
+	}
+	switch flavor {
+	case "aurora":
+		return &version, flavorAurora, nil
+	case "alloydb":
+		return &version, flavorAlloyDB, nil
+	}
+
+	return &version, flavorPostgreSQL, nil
+}
+
+// detectSuperuser returns true if the connection user is a superuser.
+func detectSuperuser(db *sql.DB) (bool, error) {
+	var superuser bool
+	err := db.QueryRow(`SELECT rolsuper FROM pg_catalog.pg_roles WHERE rolname = CURRENT_USER`).Scan(&superuser)
+	switch {
+	case err == sql.ErrNoRows:
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+
+	return superuser, nil
 }
 
 // featureSupported returns true if a given feature is supported or not. This is
 // slightly different from Config's featureSupported in that here we're
-// evaluating against the fingerprinted version, not the expected version.
+// evaluating against the fingerprinted version, not the expected version, and
+// against CockroachDB's.
 func (c *Client) featureSupported(name featureName) bool {
 	fn, found := featureSupported[name]
 	if !found {
@@ -279,5 +1251,35 @@ func (c *Client) featureSupported(name featureName) bool {
 		panic(fmt.Sprintf("unknown feature flag %v", name))
 	}
 
+	if c.cockroach && cockroachUnsupported[name] {
+		return false
+	}
+	if c.yugabyte && yugabyteUnsupported[name] {
+		return false
+	}
+
 	return fn(c.version)
 }
+
+// parseFeatureVersions parses the version ranges of the feature flags.
+func parseFeatureVersions(versions map[featureName]string) map[featureName]semver.Range {
+	ranges := make(map[featureName]semver.Range, len(versions))
+	for name, versionRange := range versions {
+		ranges[name] = semver.MustParseRange(versionRange)
+	}
+
+	return ranges
+}
+
+// featureError returns the error of what, which requires the feature the
+// server does not support, with the version supporting it if any.
+func (c *Client) featureError(name featureName, what string) error {
+	switch {
+	case c.cockroach && cockroachUnsupported[name]:
+		return fmt.Errorf("%s is not supported on CockroachDB", what)
+	case c.yugabyte && yugabyteUnsupported[name]:
+		return fmt.Errorf("%s is not supported on YugabyteDB", what)
+	}
+
+	return fmt.Errorf("%s requires PostgreSQL %s, the server is %s", what, strings.TrimPrefix(featureVersions[name], ">="), c.version)
+}
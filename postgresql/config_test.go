@@ -0,0 +1,459 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/lib/pq"
+)
+
+func TestClientDBFor(t *testing.T) {
+	db, err := sql.Open("postgres", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	c := &Client{
+		config: Config{
+			Database:         "postgres",
+			SessionRole:      "owner",
+			MaxConns:         1,
+			DatabasePoolSize: 1,
+			ExpectedVersion:  semver.MustParse(defaultExpectedPostgreSQLVersion),
+		},
+		db:  db,
+		dbs: make(map[dbKey]*sql.DB),
+	}
+	defer func() {
+		for _, db := range c.dbs {
+			db.Close()
+		}
+	}()
+
+	for _, database := range []string{"", "postgres"} {
+		for _, role := range []string{"", "owner"} {
+			got, err := c.DBFor(database, role)
+			if err != nil {
+				t.Fatalf("DBFor(%q, %q): %v", database, role, err)
+			}
+			if got != db {
+				t.Errorf("DBFor(%q, %q) did not return the provider's database handle", database, role)
+			}
+		}
+	}
+
+	a, err := c.DBFor("a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == db {
+		t.Fatal(`DBFor("a") returned the provider's database handle`)
+	}
+	if again, _ := c.DBFor("a", "owner"); again != a {
+		t.Error(`DBFor("a") did not reuse its handle`)
+	}
+
+	b, err := c.DBFor("b", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.dbsLRU, []dbKey{{"b", "owner"}}) {
+		t.Errorf("expected only b to be pooled, got %v", c.dbsLRU)
+	}
+
+	if again, _ := c.DBFor("a", ""); again != a {
+		t.Error(`DBFor("a") did not reuse its handle after being evicted from the pool`)
+	}
+	if !reflect.DeepEqual(c.dbsLRU, []dbKey{{"a", "owner"}}) {
+		t.Errorf("expected only a to be pooled, got %v", c.dbsLRU)
+	}
+
+	for _, database := range []string{"", "postgres", "b"} {
+		got, err := c.DBFor(database, "other")
+		if err != nil {
+			t.Fatalf("DBFor(%q, \"other\"): %v", database, err)
+		}
+		if got == db || got == a || got == b {
+			t.Errorf("DBFor(%q, \"other\") did not open a handle for the role", database)
+		}
+	}
+	if len(c.dbs) != 4 {
+		t.Errorf("expected 4 database handles, got %d", len(c.dbs))
+	}
+
+	c.releaseDB("b")
+	if !reflect.DeepEqual(c.dbsLRU, []dbKey{}) {
+		t.Errorf("expected b to be released from the pool, got %v", c.dbsLRU)
+	}
+}
+
+func TestClientForRead(t *testing.T) {
+	config := Config{
+		Host:               "primary",
+		Port:               5432,
+		TargetSessionAttrs: "read-write",
+		ExpectedVersion:    semver.MustParse(defaultExpectedPostgreSQLVersion),
+	}
+
+	c, err := config.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.forRead() != c {
+		t.Error("expected the client to read from the server itself without read_host")
+	}
+
+	config.ReadHost = "replica"
+	config.ReadPort = 5433
+	c, err = config.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader := c.forRead()
+	if reader == c || reader.db == c.db {
+		t.Fatal("expected the client to read from the replica")
+	}
+	if reader.config.Host != "replica" || reader.config.Port != 5433 {
+		t.Errorf("expected the replica's client to connect to replica:5433, got %s:%d", reader.config.Host, reader.config.Port)
+	}
+	if reader.config.TargetSessionAttrs != "any" {
+		t.Errorf("expected the replica's client to accept read-only sessions, got %q", reader.config.TargetSessionAttrs)
+	}
+	if reader.forRead() != reader {
+		t.Error("expected the replica's client to have no replica")
+	}
+	if reader.config.Pooled {
+		t.Error("expected the replica's client not to be pooled")
+	}
+
+	// The pooled endpoint of a server with a direct one.
+	config.ReadPooled = true
+	config.SessionRole = "owner"
+	config.SearchPath = []string{"app"}
+	config.StatementTimeout = time.Minute
+	config.AdvisoryLocks = true
+	c, err = config.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader = c.forRead()
+	if !reader.config.Pooled || reader.config.ReadPooled || c.config.Pooled {
+		t.Error("expected only the pooled endpoint's client to be pooled")
+	}
+	if reader.config.SessionRole != "" || reader.config.SearchPath != nil || reader.config.StatementTimeout != 0 || reader.config.AdvisoryLocks {
+		t.Errorf("expected the pooled endpoint's client to keep no session state, got %+v", reader.config)
+	}
+	if c.config.SessionRole != "owner" || !c.config.AdvisoryLocks {
+		t.Errorf("expected the direct endpoint's client to keep the session state, got %+v", c.config)
+	}
+	if db, err := reader.DBFor("", "app_owner"); err != nil || db != reader.db {
+		t.Errorf("expected the pooled endpoint's client to refresh without session roles, got %v", err)
+	}
+}
+
+func TestConfigConnStrSSLFiles(t *testing.T) {
+	config := Config{
+		Host:            "localhost",
+		SSLMode:         "verify-full",
+		SSLRootCert:     "/etc/ssl/root ca.pem",
+		SSLKey:          "/etc/ssl/client.key",
+		ExpectedVersion: semver.MustParse(defaultExpectedPostgreSQLVersion),
+
+		SSLMinProtocolVersion: "TLSv1",
+	}
+
+	dsn := config.connStr()
+	for _, param := range []string{"sslmode=verify-full", "sslrootcert='/etc/ssl/root ca.pem'", "sslkey=/etc/ssl/client.key", "ssl_min_protocol_version=TLSv1.0"} {
+		if !strings.Contains(dsn, param) {
+			t.Errorf("expected %s in %q", param, dsn)
+		}
+	}
+	if strings.Contains(dsn, "sslcert=") {
+		t.Errorf("expected no sslcert in %q", dsn)
+	}
+
+	// lib/pq no longer defaults to require.
+	config.SSLMode = ""
+	if dsn := config.connStr(); !strings.Contains(dsn, "sslmode=require") {
+		t.Errorf("expected sslmode=require in %q", dsn)
+	}
+}
+
+func TestConfigConnStrTimeouts(t *testing.T) {
+	config := Config{
+		Host:            "localhost",
+		ExpectedVersion: semver.MustParse(defaultExpectedPostgreSQLVersion),
+	}
+	if dsn := config.connStr(); strings.Contains(dsn, "statement_timeout") || strings.Contains(dsn, "lock_timeout") {
+		t.Errorf("expected no session timeouts in %q", dsn)
+	}
+
+	config.StatementTimeout = time.Minute
+	config.LockTimeout = 1500 * time.Millisecond
+	dsn := config.connStr()
+	for _, param := range []string{"statement_timeout=60000", "lock_timeout=1500"} {
+		if !strings.Contains(dsn, param) {
+			t.Errorf("expected %s in %q", param, dsn)
+		}
+	}
+}
+
+func TestConfigConnStrSessionRole(t *testing.T) {
+	config := Config{
+		Host:            "localhost",
+		ExpectedVersion: semver.MustParse(defaultExpectedPostgreSQLVersion),
+	}
+	if dsn := config.connStr(); strings.Contains(dsn, "role=") {
+		t.Errorf("expected no session role in %q", dsn)
+	}
+
+	config.SessionRole = "app owner"
+	if dsn := config.connStr(); !strings.Contains(dsn, "role='app owner'") {
+		t.Errorf("expected role='app owner' in %q", dsn)
+	}
+}
+
+func TestConfigConnStrSearchPath(t *testing.T) {
+	config := Config{
+		Host:            "localhost",
+		ExpectedVersion: semver.MustParse(defaultExpectedPostgreSQLVersion),
+	}
+	if dsn := config.connStr(); strings.Contains(dsn, "search_path=") {
+		t.Errorf("expected no search_path in %q", dsn)
+	}
+
+	config.SearchPath = []string{"$user", "app", "public"}
+	if dsn := config.connStr(); !strings.Contains(dsn, `search_path='"$user", "app", "public"'`) {
+		t.Errorf(`expected search_path='"$user", "app", "public"' in %q`, dsn)
+	}
+}
+
+func TestConfigConnStrPooled(t *testing.T) {
+	config := Config{
+		Host:            "localhost",
+		ExpectedVersion: semver.MustParse(defaultExpectedPostgreSQLVersion),
+	}
+	if dsn := config.connStr(); strings.Contains(dsn, "binary_parameters=") {
+		t.Errorf("expected no binary_parameters in %q", dsn)
+	}
+
+	config.Pooled = true
+	if dsn := config.connStr(); !strings.Contains(dsn, "binary_parameters=yes") {
+		t.Errorf("expected binary_parameters=yes in %q", dsn)
+	}
+}
+
+func TestMajorVersion(t *testing.T) {
+	tests := map[string]string{
+		"9.0.0":  "9.0",
+		"9.6.10": "9.6",
+		"10.0.0": "10",
+		"12.4.0": "12",
+	}
+
+	for version, major := range tests {
+		if got := majorVersion(semver.MustParse(version)); got != major {
+			t.Errorf("%s: expected %q, got %q", version, major, got)
+		}
+	}
+}
+
+func TestRetryWithSSLMode(t *testing.T) {
+	tests := []struct {
+		err   error
+		retry bool
+	}{
+		{pq.ErrSSLNotSupported, true},
+		{&pq.Error{Code: "28000"}, true},
+		{&pq.Error{Code: "28P01"}, true},
+		{&pq.Error{Code: "3D000"}, false},
+		{fmt.Errorf("dial tcp: connection refused"), false},
+		{nil, false},
+	}
+
+	for _, test := range tests {
+		if retry := retryWithSSLMode(test.err); retry != test.retry {
+			t.Errorf("%v: expected %t, got %t", test.err, test.retry, retry)
+		}
+	}
+}
+
+// failingDialer counts its attempts to connect, which all fail with err.
+type failingDialer struct {
+	err       error
+	attempts  int
+	addresses []string
+}
+
+func (d *failingDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialTimeout(network, address, 0)
+}
+
+func (d *failingDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	d.attempts++
+	d.addresses = append(d.addresses, address)
+	return nil, d.err
+}
+
+func TestHookedDriverRetries(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("connection refused")}
+
+	tests := []struct {
+		err      error
+		attempts int
+	}{
+		{refused, 3},
+		{fmt.Errorf("no route to the moon"), 1},
+	}
+
+	for _, test := range tests {
+		dialer := &failingDialer{err: test.err}
+		config := Config{
+			Host:                   "localhost",
+			ExpectedVersion:        semver.MustParse(defaultExpectedPostgreSQLVersion),
+			MaxConnectRetries:      2,
+			ConnectRetryBackoff:    time.Millisecond,
+			ConnectRetryMaxBackoff: time.Millisecond,
+			dialer:                 dialer,
+		}
+
+		db, err := config.open(config.connStr())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Ping(); err == nil {
+			t.Errorf("%v: expected the connection to fail", test.err)
+		}
+		db.Close()
+
+		// database/sql itself does not retry these errors.
+		if dialer.attempts != test.attempts {
+			t.Errorf("%v: expected %d attempts, got %d", test.err, test.attempts, dialer.attempts)
+		}
+	}
+}
+
+func TestHookedDriverHosts(t *testing.T) {
+	dialer := &failingDialer{err: &net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("connection refused")}}
+	config := Config{
+		Host:               "db1, db2,db3",
+		Port:               5433,
+		TargetSessionAttrs: "read-write",
+		ExpectedVersion:    semver.MustParse(defaultExpectedPostgreSQLVersion),
+		dialer:             dialer,
+	}
+
+	db, err := config.open(config.connStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err == nil {
+		t.Error("expected the connection to fail")
+	}
+
+	expected := []string{"db1:5433", "db2:5433", "db3:5433"}
+	if !reflect.DeepEqual(dialer.addresses, expected) {
+		t.Errorf("expected to try %v, got %v", expected, dialer.addresses)
+	}
+}
+
+func TestRetryableConnError(t *testing.T) {
+	tests := []struct {
+		err   error
+		retry bool
+	}{
+		{&net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("connection refused")}, true},
+		{io.EOF, true},
+		{&pq.Error{Code: "57P03"}, true},
+		{&pq.Error{Code: "08006"}, true},
+		{&pq.Error{Code: "28P01"}, false},
+		{&pq.Error{Code: "XX000", Message: "Couldn't connect to compute node"}, true},
+		{errReadOnlyServer, true},
+		{fmt.Errorf("unknown"), false},
+	}
+
+	for _, test := range tests {
+		if retry := retryableConnError(test.err); retry != test.retry {
+			t.Errorf("%v: expected %t, got %t", test.err, test.retry, retry)
+		}
+	}
+}
+
+func TestClientFeatureSupportedCockroach(t *testing.T) {
+	// CockroachDB reports compatibility with PostgreSQL 13.
+	version := semver.MustParse("13.0.0")
+	postgres := &Client{version: version}
+	cockroach := &Client{version: version, cockroach: true}
+
+	for name := range featureSupported {
+		expected := postgres.featureSupported(name) && !cockroachUnsupported[name]
+		if supported := cockroach.featureSupported(name); supported != expected {
+			t.Errorf("feature %v: expected %t on CockroachDB, got %t", name, expected, supported)
+		}
+	}
+	if cockroach.featureSupported(featureReplicationSlots) || !cockroach.featureSupported(featureProKind) {
+		t.Error("expected CockroachDB to support pg_proc.prokind but not replication slots")
+	}
+}
+
+func TestClientFeatureSupportedYugabyte(t *testing.T) {
+	// YugabyteDB 2.18 is based on PostgreSQL 11.2.
+	version := semver.MustParse("11.2.0")
+	postgres := &Client{version: version}
+	yugabyte := &Client{version: version, yugabyte: true}
+
+	for name := range featureSupported {
+		expected := postgres.featureSupported(name) && !yugabyteUnsupported[name]
+		if supported := yugabyte.featureSupported(name); supported != expected {
+			t.Errorf("feature %v: expected %t on YugabyteDB, got %t", name, expected, supported)
+		}
+	}
+	if yugabyte.featureSupported(featureReplicationSlots) || !yugabyte.featureSupported(featureRLS) {
+		t.Error("expected YugabyteDB to support row-level security but not replication slots")
+	}
+}
+
+func TestClientFeatureError(t *testing.T) {
+	tests := []struct {
+		client   *Client
+		expected string
+	}{
+		{
+			client:   &Client{version: semver.MustParse("9.6.0")},
+			expected: "idle_session_timeout requires PostgreSQL 14.0.0, the server is 9.6.0",
+		},
+		{
+			client:   &Client{version: semver.MustParse("13.0.0"), cockroach: true},
+			expected: "idle_session_timeout requires PostgreSQL 14.0.0, the server is 13.0.0",
+		},
+	}
+
+	for _, test := range tests {
+		if err := test.client.featureError(featureIdleSessionTimeout, "idle_session_timeout"); err.Error() != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, err)
+		}
+	}
+
+	cockroach := &Client{version: semver.MustParse("13.0.0"), cockroach: true}
+	if err := cockroach.featureError(featureRLS, "Row-Level Security"); err.Error() != "Row-Level Security is not supported on CockroachDB" {
+		t.Errorf("unexpected error on CockroachDB: %q", err)
+	}
+}
+
+func TestFeatureVersions(t *testing.T) {
+	for name, versionRange := range featureVersions {
+		if !strings.HasPrefix(versionRange, ">=") {
+			t.Errorf("feature %v: expected a minimum version, got %q", name, versionRange)
+		}
+	}
+}
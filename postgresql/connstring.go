@@ -0,0 +1,161 @@
+package postgresql
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/errwrap"
+)
+
+// parseConnString parses a libpq connection string, either a
+// postgres:// or postgresql:// URI or keyword=value settings, into its
+// settings by libpq keyword.  Several hosts are joined with commas, and must
+// share their port.
+func parseConnString(s string) (map[string]string, error) {
+	if strings.HasPrefix(s, "postgres://") || strings.HasPrefix(s, "postgresql://") {
+		return parseConnURI(s)
+	}
+
+	return parseConnDSN(s)
+}
+
+func parseConnURI(s string) (map[string]string, error) {
+	settings := make(map[string]string)
+
+	rest := s[strings.Index(s, "://")+3:]
+	if i := strings.IndexByte(rest, '?'); i >= 0 {
+		query, err := url.ParseQuery(rest[i+1:])
+		if err != nil {
+			return nil, errwrap.Wrapf("invalid connection URI parameters: {{err}}", err)
+		}
+		for keyword, values := range query {
+			settings[keyword] = values[len(values)-1]
+		}
+		rest = rest[:i]
+	}
+
+	authority := rest
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		authority = rest[:i]
+		if dbName, err := url.PathUnescape(rest[i+1:]); err != nil {
+			return nil, errwrap.Wrapf("invalid connection URI database: {{err}}", err)
+		} else if dbName != "" {
+			settings["dbname"] = dbName
+		}
+	}
+
+	if i := strings.LastIndexByte(authority, '@'); i >= 0 {
+		userInfo, err := url.Parse("postgres://" + authority[:i+1])
+		if err != nil {
+			return nil, errwrap.Wrapf("invalid connection URI user: {{err}}", err)
+		}
+		if user := userInfo.User.Username(); user != "" {
+			settings["user"] = user
+		}
+		if password, ok := userInfo.User.Password(); ok {
+			settings["password"] = password
+		}
+		authority = authority[i+1:]
+	}
+
+	if authority == "" {
+		return settings, nil
+	}
+
+	var hosts []string
+	var port string
+	for i, addr := range strings.Split(authority, ",") {
+		host, hostPort := addr, ""
+		if strings.LastIndexByte(addr, ':') > strings.LastIndexByte(addr, ']') {
+			var err error
+			if host, hostPort, err = net.SplitHostPort(addr); err != nil {
+				return nil, errwrap.Wrapf("invalid connection URI host: {{err}}", err)
+			}
+		}
+		host, err := url.PathUnescape(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]"))
+		if err != nil {
+			return nil, errwrap.Wrapf("invalid connection URI host: {{err}}", err)
+		}
+		if host != "" {
+			hosts = append(hosts, host)
+		}
+
+		if _, err := strconv.Atoi(hostPort); hostPort != "" && err != nil {
+			return nil, fmt.Errorf("invalid connection URI port %q", hostPort)
+		}
+		if i != 0 && hostPort != port {
+			return nil, fmt.Errorf("the hosts of the connection URI must use the same port")
+		}
+		port = hostPort
+	}
+	if len(hosts) != 0 {
+		settings["host"] = strings.Join(hosts, ",")
+	}
+	if port != "" {
+		settings["port"] = port
+	}
+
+	return settings, nil
+}
+
+// parseConnDSN parses keyword=value settings separated by whitespace, with
+// values quoted and escaped as quoteConnValue() does.
+func parseConnDSN(s string) (map[string]string, error) {
+	settings := make(map[string]string)
+
+	r := []rune(s)
+	for i := 0; ; {
+		for i < len(r) && unicode.IsSpace(r[i]) {
+			i++
+		}
+		if i == len(r) {
+			return settings, nil
+		}
+
+		start := i
+		for i < len(r) && r[i] != '=' && !unicode.IsSpace(r[i]) {
+			i++
+		}
+		keyword := string(r[start:i])
+		for i < len(r) && unicode.IsSpace(r[i]) {
+			i++
+		}
+		if i == len(r) || r[i] != '=' {
+			return nil, fmt.Errorf("missing value for %q in connection string", keyword)
+		}
+		i++
+		for i < len(r) && unicode.IsSpace(r[i]) {
+			i++
+		}
+
+		var value []rune
+		if i < len(r) && r[i] == '\'' {
+			for i++; ; i++ {
+				if i == len(r) {
+					return nil, fmt.Errorf("unterminated quoted value for %q in connection string", keyword)
+				}
+				if r[i] == '\'' {
+					i++
+					break
+				}
+				if r[i] == '\\' && i+1 < len(r) {
+					i++
+				}
+				value = append(value, r[i])
+			}
+		} else {
+			for ; i < len(r) && !unicode.IsSpace(r[i]); i++ {
+				if r[i] == '\\' && i+1 < len(r) {
+					i++
+				}
+				value = append(value, r[i])
+			}
+		}
+
+		settings[keyword] = string(value)
+	}
+}
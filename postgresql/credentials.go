@@ -0,0 +1,147 @@
+package postgresql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+)
+
+// Run the credentials command again this long before the password it returned
+// expires.
+const commandPasswordExpiryDelta = time.Minute
+
+// commandPassword obtains the password from the output of a command, e.g.
+// `vault read -format=json database/static-creds/terraform`, which is run
+// again for new connections once the previous password expired.
+//
+// The output is either the password itself or a JSON object holding the
+// password, at the top level or in "data" as Vault returns it.  The validity
+// of the password is taken from Vault's "ttl" or "lease_duration", in seconds.
+// Without either, the command is run for every new connection.
+type commandPassword struct {
+	argv     []string
+	username string
+
+	lock     sync.Mutex
+	password string
+	expiry   time.Time
+}
+
+type commandPasswordOutput struct {
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	TTL           int    `json:"ttl"`
+	LeaseDuration int    `json:"lease_duration"`
+}
+
+func newCommandPassword(argv []string, username string) *commandPassword {
+	return &commandPassword{
+		argv:     argv,
+		username: username,
+	}
+}
+
+// Password returns the current password, running the command if needed.
+func (p *commandPassword) Password() (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.password != "" && time.Now().Add(commandPasswordExpiryDelta).Before(p.expiry) {
+		return p.password, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(p.argv[0], p.argv[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("Error running credentials command %q: %v: %s", strings.Join(p.argv, " "), err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	password, ttl, err := parseCommandPassword(stdout.Bytes(), p.username)
+	if err != nil {
+		return "", errwrap.Wrapf(fmt.Sprintf("Error reading the output of credentials command %q: {{err}}", strings.Join(p.argv, " ")), err)
+	}
+
+	p.password = password
+	p.expiry = time.Now().Add(ttl)
+
+	return p.password, nil
+}
+
+// parseCommandPassword returns the password in the output of a credentials
+// command and for how long it is valid.
+func parseCommandPassword(output []byte, username string) (string, time.Duration, error) {
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return "", 0, fmt.Errorf("no password")
+	}
+	if output[0] != '{' {
+		return string(output), 0, nil
+	}
+
+	var out struct {
+		commandPasswordOutput
+		Data *commandPasswordOutput `json:"data"`
+	}
+	if err := json.Unmarshal(output, &out); err != nil {
+		return "", 0, err
+	}
+
+	creds := out.commandPasswordOutput
+	if out.Data != nil {
+		creds.Username = out.Data.Username
+		creds.Password = out.Data.Password
+		if out.Data.TTL != 0 {
+			creds.TTL = out.Data.TTL
+		}
+	}
+
+	if creds.Password == "" {
+		return "", 0, fmt.Errorf("no password")
+	}
+	if creds.Username != "" && creds.Username != username {
+		return "", 0, fmt.Errorf("the credentials are for %q instead of %q, the username can not change", creds.Username, username)
+	}
+
+	ttl := creds.TTL
+	if ttl == 0 {
+		ttl = creds.LeaseDuration
+	}
+
+	return creds.Password, time.Duration(ttl) * time.Second, nil
+}
+
+// filePassword returns a function reading the password from a file, e.g. one
+// rendered by Vault Agent, for every new connection.
+func filePassword(path string) func() (string, error) {
+	return func() (string, error) {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", errwrap.Wrapf("Error reading password file: {{err}}", err)
+		}
+
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+}
+
+// envPassword returns a function reading the password from an environment
+// variable, e.g. one set by a CI secret store, for every new connection.
+func envPassword(name string) func() (string, error) {
+	return func() (string, error) {
+		password, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("Error reading password: environment variable %s is not set", name)
+		}
+
+		return password, nil
+	}
+}
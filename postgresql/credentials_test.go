@@ -0,0 +1,133 @@
+package postgresql
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseCommandPassword(t *testing.T) {
+	tests := []struct {
+		output   string
+		password string
+		ttl      time.Duration
+		valid    bool
+	}{
+		{"secret\n", "secret", 0, true},
+		{`{"password": "secret"}`, "secret", 0, true},
+		{`{"data": {"username": "terraform", "password": "secret", "ttl": 3600}}`, "secret", time.Hour, true},
+		{`{"lease_duration": 60, "data": {"password": "secret"}}`, "secret", time.Minute, true},
+		{`{"data": {"username": "v-token-terraform-abc", "password": "secret"}}`, "", 0, false},
+		{`{"data": {}}`, "", 0, false},
+		{"", "", 0, false},
+	}
+
+	for _, test := range tests {
+		password, ttl, err := parseCommandPassword([]byte(test.output), "terraform")
+		if !test.valid {
+			if err == nil {
+				t.Errorf("expected %q to be rejected", test.output)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.output, err)
+			continue
+		}
+		if password != test.password || ttl != test.ttl {
+			t.Errorf("%q: expected %q valid for %s, got %q valid for %s", test.output, test.password, test.ttl, password, ttl)
+		}
+	}
+}
+
+func TestCommandPassword(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-postgresql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Counts its runs in a file.
+	runs := filepath.Join(dir, "runs")
+	script := `echo >> "$1"; echo '{"data": {"password": "secret"}, "lease_duration": '"$2"'}'`
+
+	for _, test := range []struct {
+		leaseDuration string
+		runs          int
+	}{
+		{"3600", 1},
+		{"0", 2},
+	} {
+		os.Remove(runs)
+
+		p := newCommandPassword([]string{"sh", "-c", script, "sh", runs, test.leaseDuration}, "terraform")
+		for i := 0; i < 2; i++ {
+			password, err := p.Password()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if password != "secret" {
+				t.Errorf("expected the password of the command, got %q", password)
+			}
+		}
+
+		b, err := ioutil.ReadFile(runs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b) != test.runs {
+			t.Errorf("with a lease of %s seconds, expected %d runs, got %d", test.leaseDuration, test.runs, len(b))
+		}
+	}
+
+	if _, err := newCommandPassword([]string{"sh", "-c", "echo denied >&2; exit 2"}, "terraform").Password(); err == nil {
+		t.Error("expected a failing command to be an error")
+	}
+}
+
+func TestFilePassword(t *testing.T) {
+	f, err := ioutil.TempFile("", "tf-postgresql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.Close()
+
+	password := filePassword(f.Name())
+	for _, want := range []string{"first", "second"} {
+		if err := ioutil.WriteFile(f.Name(), []byte(want+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := password()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestEnvPassword(t *testing.T) {
+	const name = "TF_POSTGRESQL_TEST_PASSWORD"
+	defer os.Unsetenv(name)
+
+	password := envPassword(name)
+	os.Unsetenv(name)
+	if _, err := password(); err == nil {
+		t.Error("expected an unset variable to be an error")
+	}
+
+	for _, want := range []string{"first", "second", ""} {
+		os.Setenv(name, want)
+		got, err := password()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
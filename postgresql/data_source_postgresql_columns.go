@@ -0,0 +1,151 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	dataColumnsSchemaAttr      = "schema"
+	dataColumnsNamePatternAttr = "name_pattern"
+	dataColumnsTypePatternAttr = "type_pattern"
+	dataColumnsAttr            = "columns"
+
+	dataColumnSchemaAttr        = "schema"
+	dataColumnTableAttr         = "table"
+	dataColumnNameAttr          = "name"
+	dataColumnTypeAttr          = "type"
+	dataColumnNullableAttr      = "nullable"
+	dataColumnQualifiedNameAttr = "qualified_name"
+)
+
+func dataSourcePostgreSQLColumns() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLColumnsRead,
+
+		Schema: map[string]*schema.Schema{
+			dataColumnsSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only search the columns of this schema",
+			},
+			dataColumnsNamePatternAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return columns whose name matches this LIKE pattern",
+			},
+			dataColumnsTypePatternAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return columns whose type matches this LIKE pattern",
+			},
+			dataColumnsAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataColumnSchemaAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataColumnTableAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataColumnNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataColumnTypeAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataColumnNullableAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						dataColumnQualifiedNameAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The quoted schema.table.column name of the column",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLColumnsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	b := bytes.NewBufferString(`SELECT n.nspname, c.relname, a.attname, ` +
+		`pg_catalog.format_type(a.atttypid, a.atttypmod), NOT a.attnotnull, ` +
+		`pg_catalog.quote_ident(n.nspname) || '.' || pg_catalog.quote_ident(c.relname) || '.' || pg_catalog.quote_ident(a.attname) ` +
+		`FROM pg_catalog.pg_attribute a ` +
+		`JOIN pg_catalog.pg_class c ON c.oid = a.attrelid ` +
+		`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+		`JOIN pg_catalog.pg_type t ON t.oid = a.atttypid ` +
+		`WHERE c.relkind IN ('r', 'v', 'm', 'f', 'p') AND a.attnum > 0 AND NOT a.attisdropped ` +
+		`AND ` + userSchemasCond("n.nspname"))
+
+	args := []interface{}{}
+	schemaName := d.Get(dataColumnsSchemaAttr).(string)
+	if schemaName != "" {
+		args = append(args, schemaName)
+		fmt.Fprintf(b, " AND n.nspname = $%d", len(args))
+	}
+	namePattern := d.Get(dataColumnsNamePatternAttr).(string)
+	if namePattern != "" {
+		args = append(args, namePattern)
+		fmt.Fprintf(b, " AND a.attname LIKE $%d", len(args))
+	}
+	// format_type() schema-qualifies types which are not in the search_path,
+	// so the bare type name is matched as well.
+	typePattern := d.Get(dataColumnsTypePatternAttr).(string)
+	if typePattern != "" {
+		args = append(args, typePattern)
+		fmt.Fprintf(b, " AND (t.typname LIKE $%[1]d OR pg_catalog.format_type(a.atttypid, a.atttypmod) LIKE $%[1]d)", len(args))
+	}
+	fmt.Fprint(b, " ORDER BY n.nspname, c.relname, a.attnum")
+
+	rows, err := c.DB().Query(b.String(), args...)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading columns: {{err}}", err))
+	}
+	defer rows.Close()
+
+	columns := make([]interface{}, 0)
+	for rows.Next() {
+		var schemaName, table, name, colType, qualifiedName string
+		var nullable bool
+
+		if err := rows.Scan(&schemaName, &table, &name, &colType, &nullable, &qualifiedName); err != nil {
+			return diagnostics(errwrap.Wrapf("Error reading columns: {{err}}", err))
+		}
+
+		columns = append(columns, map[string]interface{}{
+			dataColumnSchemaAttr:        schemaName,
+			dataColumnTableAttr:         table,
+			dataColumnNameAttr:          name,
+			dataColumnTypeAttr:          colType,
+			dataColumnNullableAttr:      nullable,
+			dataColumnQualifiedNameAttr: qualifiedName,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading columns: {{err}}", err))
+	}
+
+	if err := d.Set(dataColumnsAttr, columns); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting columns: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("columns", schemaName, namePattern, typePattern))
+
+	return nil
+}
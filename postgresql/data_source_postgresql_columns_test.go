@@ -0,0 +1,56 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceColumns_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t, "DROP SCHEMA IF EXISTS ds_columns CASCADE")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE SCHEMA ds_columns",
+				"CREATE TABLE ds_columns.users (id INT, email TEXT NOT NULL, created_at TIMESTAMPTZ)",
+				`CREATE TABLE ds_columns."Contacts" (id INT, backup_email VARCHAR(255), updated_at TIMESTAMPTZ)`,
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceColumnsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_columns.emails", "columns.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_columns.emails", "columns.0.table", "Contacts"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_columns.emails", "columns.0.type", "character varying(255)"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_columns.emails", "columns.0.qualified_name", `ds_columns."Contacts".backup_email`),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_columns.emails", "columns.1.name", "email"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_columns.emails", "columns.1.nullable", "false"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_columns.timestamps", "columns.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceColumnsConfig = `
+data "postgresql_columns" "emails" {
+  schema       = "ds_columns"
+  name_pattern = "%email"
+}
+
+data "postgresql_columns" "timestamps" {
+  schema       = "ds_columns"
+  type_pattern = "timestamptz"
+}
+`
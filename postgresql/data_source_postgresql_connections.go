@@ -0,0 +1,141 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	dataConnectionsDatabaseAttr    = "database"
+	dataConnectionsExcludeSelfAttr = "exclude_self"
+	dataConnectionsTotalAttr       = "total"
+	dataConnectionsGroupsAttr      = "connections"
+
+	dataConnectionDatabaseAttr        = "database"
+	dataConnectionApplicationNameAttr = "application_name"
+	dataConnectionUsernameAttr        = "username"
+	dataConnectionStateAttr           = "state"
+	dataConnectionCountAttr           = "count"
+)
+
+func dataSourcePostgreSQLConnections() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLConnectionsRead,
+
+		Schema: map[string]*schema.Schema{
+			dataConnectionsDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only count connections to this database",
+			},
+			dataConnectionsExcludeSelfAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Do not count the connections opened by the provider itself",
+			},
+			dataConnectionsTotalAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The total number of matching connections",
+			},
+			dataConnectionsGroupsAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Connection counts grouped by database, application, user and state",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataConnectionDatabaseAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataConnectionApplicationNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataConnectionUsernameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataConnectionStateAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataConnectionCountAttr: {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLConnectionsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+
+	// Background processes are not associated with a database and are not
+	// counted.
+	b := bytes.NewBufferString(`SELECT datname, COALESCE(application_name, ''), COALESCE(usename, ''), ` +
+		`COALESCE(state, ''), COUNT(*) ` +
+		`FROM pg_catalog.pg_stat_activity WHERE datname IS NOT NULL`)
+
+	args := []interface{}{}
+	database := d.Get(dataConnectionsDatabaseAttr).(string)
+	if database != "" {
+		args = append(args, database)
+		fmt.Fprintf(b, " AND datname = $%d", len(args))
+	}
+
+	// The provider's own pool identifies itself with its application name, so
+	// excluding the current backend alone would miss its idle connections.
+	if d.Get(dataConnectionsExcludeSelfAttr).(bool) {
+		args = append(args, c.config.ApplicationName)
+		fmt.Fprintf(b, " AND pid <> pg_catalog.pg_backend_pid() AND COALESCE(application_name, '') <> $%d", len(args))
+	}
+	fmt.Fprint(b, " GROUP BY 1, 2, 3, 4 ORDER BY 1, 2, 3, 4")
+
+	rows, err := c.DB().Query(b.String(), args...)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading connections: {{err}}", err))
+	}
+	defer rows.Close()
+
+	var total int
+	groups := make([]interface{}, 0)
+	for rows.Next() {
+		var datname, applicationName, username, state string
+		var count int
+
+		if err := rows.Scan(&datname, &applicationName, &username, &state, &count); err != nil {
+			return diagnostics(errwrap.Wrapf("Error reading connections: {{err}}", err))
+		}
+
+		total += count
+		groups = append(groups, map[string]interface{}{
+			dataConnectionDatabaseAttr:        datname,
+			dataConnectionApplicationNameAttr: applicationName,
+			dataConnectionUsernameAttr:        username,
+			dataConnectionStateAttr:           state,
+			dataConnectionCountAttr:           count,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading connections: {{err}}", err))
+	}
+
+	d.Set(dataConnectionsTotalAttr, total)
+	if err := d.Set(dataConnectionsGroupsAttr, groups); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting connections: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("connections", database, fmt.Sprint(d.Get(dataConnectionsExcludeSelfAttr))))
+
+	return nil
+}
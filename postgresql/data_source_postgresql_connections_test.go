@@ -0,0 +1,45 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceConnections_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceConnectionsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_connections.unused", "total", "0"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_connections.unused", "connections.#", "0"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_connections.self", "connections.0.database", "postgres"),
+					resource.TestCheckResourceAttrSet(
+						"data.postgresql_connections.self", "total"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceConnectionsConfig = `
+resource "postgresql_database" "unused" {
+  name = "ds_connections_unused"
+}
+
+data "postgresql_connections" "unused" {
+  database = postgresql_database.unused.name
+}
+
+data "postgresql_connections" "self" {
+  database     = "postgres"
+  exclude_self = false
+}
+`
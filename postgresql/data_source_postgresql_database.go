@@ -0,0 +1,136 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const dataDatabaseSettingsAttr = "settings"
+
+func dataSourcePostgreSQLDatabase() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLDatabaseRead,
+
+		Schema: map[string]*schema.Schema{
+			dbNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the database",
+			},
+			dbOwnerAttr: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			dbEncodingAttr: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			dbCollationAttr: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			dbCTypeAttr: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			dbTablespaceAttr: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			dbConnLimitAttr: {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			dbAllowConnsAttr: {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			dbIsTemplateAttr: {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			dataDatabaseSizeAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Size of the database in bytes, or -1 if the connection user can not CONNECT to it",
+			},
+			dataDatabaseSettingsAttr: {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The configuration parameters set for the database with ALTER DATABASE ... SET",
+			},
+		},
+	}
+}
+
+// NOTE: role-independent database settings are stored with setrole = 0.
+const databaseSettingsQuery = `SELECT pg_catalog.split_part(s.setting, '=', 1), ` +
+	`pg_catalog.substr(s.setting, pg_catalog.strpos(s.setting, '=') + 1) ` +
+	`FROM (SELECT pg_catalog.unnest(rs.setconfig) AS setting FROM pg_catalog.pg_db_role_setting rs ` +
+	`JOIN pg_catalog.pg_database d ON d.oid = rs.setdatabase WHERE rs.setrole = 0 AND d.datname = $1) AS s`
+
+func dataSourcePostgreSQLDatabaseRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	dbName := d.Get(dbNameAttr).(string)
+
+	var owner, encoding, collation, ctype, tablespace string
+	var connLimit int
+	var allowConns, isTemplate bool
+	var size int64
+	err := c.DB().QueryRow(`SELECT pg_catalog.pg_get_userbyid(d.datdba), `+
+		`pg_catalog.pg_encoding_to_char(d.encoding), d.datcollate, d.datctype, ts.spcname, `+
+		`d.datconnlimit, d.datallowconn, d.datistemplate, `+
+		`CASE WHEN pg_catalog.has_database_privilege(d.datname, 'CONNECT') `+
+		`THEN pg_catalog.pg_database_size(d.datname) ELSE -1 END `+
+		`FROM pg_catalog.pg_database AS d, pg_catalog.pg_tablespace AS ts `+
+		`WHERE d.dattablespace = ts.oid AND d.datname = $1`, dbName).
+		Scan(&owner, &encoding, &collation, &ctype, &tablespace, &connLimit, &allowConns, &isTemplate, &size)
+	switch {
+	case err == sql.ErrNoRows:
+		return diagnostics(fmt.Errorf("PostgreSQL database (%s) not found", dbName))
+	case err != nil:
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading database %q: {{err}}", dbName), err))
+	}
+
+	rows, err := c.DB().Query(databaseSettingsQuery, dbName)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading settings of database %q: {{err}}", dbName), err))
+	}
+	defer rows.Close()
+
+	settings := make(map[string]interface{})
+	for rows.Next() {
+		var name, value string
+
+		if err := rows.Scan(&name, &value); err != nil {
+			return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading settings of database %q: {{err}}", dbName), err))
+		}
+
+		settings[name] = value
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading settings of database %q: {{err}}", dbName), err))
+	}
+
+	d.Set(dbOwnerAttr, owner)
+	d.Set(dbEncodingAttr, encoding)
+	d.Set(dbCollationAttr, collation)
+	d.Set(dbCTypeAttr, ctype)
+	d.Set(dbTablespaceAttr, tablespace)
+	d.Set(dbConnLimitAttr, connLimit)
+	d.Set(dbAllowConnsAttr, allowConns)
+	d.Set(dbIsTemplateAttr, isTemplate)
+	d.Set(dataDatabaseSizeAttr, int(size))
+	if err := d.Set(dataDatabaseSettingsAttr, settings); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting database settings: {{err}}", err))
+	}
+
+	d.SetId(dbName)
+
+	return nil
+}
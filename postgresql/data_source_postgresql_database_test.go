@@ -0,0 +1,55 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceDatabase_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP DATABASE IF EXISTS ds_database",
+		"DROP ROLE IF EXISTS ds_database_owner",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE ROLE ds_database_owner",
+				"CREATE DATABASE ds_database OWNER ds_database_owner TEMPLATE template0 ENCODING 'UTF8' CONNECTION LIMIT 10",
+				"ALTER DATABASE ds_database SET work_mem = '64MB'",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceDatabaseConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_database.db", "owner", "ds_database_owner"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_database.db", "encoding", "UTF8"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_database.db", "connection_limit", "10"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_database.db", "allow_connections", "true"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_database.db", "is_template", "false"),
+					resource.TestCheckResourceAttrSet(
+						"data.postgresql_database.db", "size"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_database.db", "settings.%", "1"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_database.db", "settings.work_mem", "64MB"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceDatabaseConfig = `
+data "postgresql_database" "db" {
+  name = "ds_database"
+}
+`
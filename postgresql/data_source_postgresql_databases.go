@@ -0,0 +1,151 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	dataDatabasesAttr                = "databases"
+	dataDatabasesNamePatternAttr     = "name_pattern"
+	dataDatabasesIncludeTemplateAttr = "include_templates"
+
+	dataDatabaseSizeAttr = "size"
+)
+
+func dataSourcePostgreSQLDatabases() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLDatabasesRead,
+
+		Schema: map[string]*schema.Schema{
+			dataDatabasesNamePatternAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return databases whose name matches this LIKE pattern",
+			},
+			dataDatabasesIncludeTemplateAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Also return template databases",
+			},
+			dataDatabasesAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dbNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dbOwnerAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dbEncodingAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dbCollationAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dbCTypeAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dbTablespaceAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dbConnLimitAttr: {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						dbAllowConnsAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						dbIsTemplateAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						dataDatabaseSizeAttr: {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Size of the database in bytes, or -1 if the connection user can not CONNECT to it",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLDatabasesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	b := bytes.NewBufferString(`SELECT d.datname, pg_catalog.pg_get_userbyid(d.datdba), ` +
+		`pg_catalog.pg_encoding_to_char(d.encoding), d.datcollate, d.datctype, ts.spcname, ` +
+		`d.datconnlimit, d.datallowconn, d.datistemplate, ` +
+		`CASE WHEN pg_catalog.has_database_privilege(d.datname, 'CONNECT') ` +
+		`THEN pg_catalog.pg_database_size(d.datname) ELSE -1 END ` +
+		`FROM pg_catalog.pg_database AS d, pg_catalog.pg_tablespace AS ts ` +
+		`WHERE d.dattablespace = ts.oid`)
+
+	args := []interface{}{}
+	if v, ok := d.GetOk(dataDatabasesNamePatternAttr); ok {
+		args = append(args, v.(string))
+		fmt.Fprintf(b, " AND d.datname LIKE $%d", len(args))
+	}
+	if !d.Get(dataDatabasesIncludeTemplateAttr).(bool) {
+		fmt.Fprint(b, " AND NOT d.datistemplate")
+	}
+	fmt.Fprint(b, " ORDER BY d.datname")
+
+	rows, err := c.DB().Query(b.String(), args...)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading databases: {{err}}", err))
+	}
+	defer rows.Close()
+
+	databases := make([]interface{}, 0)
+	for rows.Next() {
+		var name, owner, encoding, collation, ctype, tablespace string
+		var connLimit int
+		var allowConns, isTemplate bool
+		var size int64
+
+		if err := rows.Scan(&name, &owner, &encoding, &collation, &ctype, &tablespace, &connLimit, &allowConns, &isTemplate, &size); err != nil {
+			return diagnostics(errwrap.Wrapf("Error reading databases: {{err}}", err))
+		}
+
+		databases = append(databases, map[string]interface{}{
+			dbNameAttr:           name,
+			dbOwnerAttr:          owner,
+			dbEncodingAttr:       encoding,
+			dbCollationAttr:      collation,
+			dbCTypeAttr:          ctype,
+			dbTablespaceAttr:     tablespace,
+			dbConnLimitAttr:      connLimit,
+			dbAllowConnsAttr:     allowConns,
+			dbIsTemplateAttr:     isTemplate,
+			dataDatabaseSizeAttr: int(size),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading databases: {{err}}", err))
+	}
+
+	if err := d.Set(dataDatabasesAttr, databases); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting databases: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("databases", d.Get(dataDatabasesNamePatternAttr).(string), fmt.Sprint(d.Get(dataDatabasesIncludeTemplateAttr))))
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceDatabases_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceDatabasesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_databases.dbs", "databases.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_databases.dbs", "databases.0.name", "ds_databases_a"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_databases.dbs", "databases.0.owner", "ds_databases_owner"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_databases.dbs", "databases.0.encoding", "UTF8"),
+					resource.TestCheckResourceAttrSet(
+						"data.postgresql_databases.dbs", "databases.0.size"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_databases.dbs", "databases.1.name", "ds_databases_b"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceDatabasesConfig = `
+resource "postgresql_role" "owner" {
+  name = "ds_databases_owner"
+}
+
+resource "postgresql_database" "a" {
+  name  = "ds_databases_a"
+  owner = postgresql_role.owner.name
+}
+
+resource "postgresql_database" "b" {
+  name  = "ds_databases_b"
+  owner = postgresql_role.owner.name
+}
+
+data "postgresql_databases" "dbs" {
+  name_pattern = "ds_databases_%"
+
+  depends_on = [postgresql_database.a, postgresql_database.b]
+}
+`
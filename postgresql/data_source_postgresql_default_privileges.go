@@ -0,0 +1,174 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	dataDefaultPrivilegesOwnerAttr      = "owner"
+	dataDefaultPrivilegesSchemaAttr     = "schema"
+	dataDefaultPrivilegesObjectTypeAttr = "object_type"
+	dataDefaultPrivilegesAttr           = "default_privileges"
+
+	dataDefaultPrivilegeOwnerAttr      = "owner"
+	dataDefaultPrivilegeSchemaAttr     = "schema"
+	dataDefaultPrivilegeObjectTypeAttr = "object_type"
+	dataDefaultPrivilegeRoleAttr       = "role"
+	dataDefaultPrivilegePrivilegesAttr = "privileges"
+)
+
+// defaultACLObjectTypes maps the object types of ALTER DEFAULT PRIVILEGES to
+// their pg_default_acl.defaclobjtype code.
+var defaultACLObjectTypes = map[string]string{
+	"function": "f",
+	"schema":   "n",
+	"sequence": "S",
+	"table":    "r",
+	"type":     "T",
+}
+
+func validateDefaultACLObjectType(v interface{}, key string) (warnings []string, errors []error) {
+	if _, ok := defaultACLObjectTypes[v.(string)]; !ok {
+		types := make([]string, 0, len(defaultACLObjectTypes))
+		for t := range defaultACLObjectTypes {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		errors = append(errors, fmt.Errorf("%s must be one of %s, got %q", key, strings.Join(types, ", "), v.(string)))
+	}
+	return
+}
+
+func dataSourcePostgreSQLDefaultPrivileges() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLDefaultPrivilegesRead,
+
+		Schema: map[string]*schema.Schema{
+			dataDefaultPrivilegesOwnerAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return the default privileges of objects created by this role",
+			},
+			dataDefaultPrivilegesSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return the default privileges of objects created in this schema",
+			},
+			dataDefaultPrivilegesObjectTypeAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Only return the default privileges of this type of object",
+				ValidateFunc: validateDefaultACLObjectType,
+			},
+			dataDefaultPrivilegesAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataDefaultPrivilegeOwnerAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The role creating the objects",
+						},
+						dataDefaultPrivilegeSchemaAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The schema the objects are created in, empty for every schema",
+						},
+						dataDefaultPrivilegeObjectTypeAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataDefaultPrivilegeRoleAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The role the privileges are granted to (PUBLIC for everyone)",
+						},
+						dataDefaultPrivilegePrivilegesAttr: {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLDefaultPrivilegesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	// As in roleGrantsQuery, aclexplode() is called in the target list so
+	// that the query works on servers older than 9.3.
+	b := bytes.NewBufferString(`SELECT pg_catalog.pg_get_userbyid(acls.defaclrole), COALESCE(n.nspname, ''), ` +
+		`CASE acls.defaclobjtype WHEN 'r' THEN 'table' WHEN 'S' THEN 'sequence' WHEN 'f' THEN 'function' ` +
+		`WHEN 'T' THEN 'type' WHEN 'n' THEN 'schema' ELSE acls.defaclobjtype::TEXT END, ` +
+		`CASE WHEN (acls.a).grantee = 0 THEN 'PUBLIC' ELSE pg_catalog.pg_get_userbyid((acls.a).grantee) END, ` +
+		`pg_catalog.array_agg((acls.a).privilege_type::TEXT ORDER BY (acls.a).privilege_type) ` +
+		`FROM (SELECT da.defaclrole, da.defaclnamespace, da.defaclobjtype, pg_catalog.aclexplode(da.defaclacl) AS a ` +
+		`FROM pg_catalog.pg_default_acl da) AS acls ` +
+		`LEFT JOIN pg_catalog.pg_namespace n ON n.oid = acls.defaclnamespace ` +
+		`WHERE TRUE`)
+
+	args := []interface{}{}
+	owner := d.Get(dataDefaultPrivilegesOwnerAttr).(string)
+	if owner != "" {
+		args = append(args, owner)
+		fmt.Fprintf(b, " AND pg_catalog.pg_get_userbyid(acls.defaclrole) = $%d", len(args))
+	}
+	schemaName := d.Get(dataDefaultPrivilegesSchemaAttr).(string)
+	if schemaName != "" {
+		args = append(args, schemaName)
+		fmt.Fprintf(b, " AND n.nspname = $%d", len(args))
+	}
+	objectType := d.Get(dataDefaultPrivilegesObjectTypeAttr).(string)
+	if objectType != "" {
+		args = append(args, defaultACLObjectTypes[objectType])
+		fmt.Fprintf(b, " AND acls.defaclobjtype = $%d", len(args))
+	}
+	fmt.Fprint(b, " GROUP BY 1, 2, 3, 4 ORDER BY 1, 2, 3, 4")
+
+	rows, err := c.DB().Query(b.String(), args...)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading default privileges: {{err}}", err))
+	}
+	defer rows.Close()
+
+	defaultPrivileges := make([]interface{}, 0)
+	for rows.Next() {
+		var owner, schemaName, objectType, role string
+		var privileges []string
+
+		if err := rows.Scan(&owner, &schemaName, &objectType, &role, pq.Array(&privileges)); err != nil {
+			return diagnostics(errwrap.Wrapf("Error reading default privileges: {{err}}", err))
+		}
+
+		defaultPrivileges = append(defaultPrivileges, map[string]interface{}{
+			dataDefaultPrivilegeOwnerAttr:      owner,
+			dataDefaultPrivilegeSchemaAttr:     schemaName,
+			dataDefaultPrivilegeObjectTypeAttr: objectType,
+			dataDefaultPrivilegeRoleAttr:       role,
+			dataDefaultPrivilegePrivilegesAttr: privileges,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading default privileges: {{err}}", err))
+	}
+
+	if err := d.Set(dataDefaultPrivilegesAttr, defaultPrivileges); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting default privileges: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("default_privileges", owner, schemaName, objectType))
+
+	return nil
+}
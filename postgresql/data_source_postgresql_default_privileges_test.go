@@ -0,0 +1,79 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestValidateDefaultACLObjectType(t *testing.T) {
+	for _, v := range []string{"function", "schema", "sequence", "table", "type"} {
+		if _, errs := validateDefaultACLObjectType(v, "object_type"); len(errs) != 0 {
+			t.Errorf("expected %q to be valid, got: %v", v, errs)
+		}
+	}
+
+	for _, v := range []string{"", "tables", "view"} {
+		if _, errs := validateDefaultACLObjectType(v, "object_type"); len(errs) == 0 {
+			t.Errorf("expected %q to be invalid", v)
+		}
+	}
+}
+
+func TestAccPostgresqlDataSourceDefaultPrivileges_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP SCHEMA IF EXISTS ds_default_privs CASCADE",
+		"DROP OWNED BY ds_default_privs_owner",
+		"DROP ROLE IF EXISTS ds_default_privs_owner",
+		"DROP ROLE IF EXISTS ds_default_privs_reader",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE ROLE ds_default_privs_owner",
+				"CREATE ROLE ds_default_privs_reader",
+				"CREATE SCHEMA ds_default_privs",
+				"ALTER DEFAULT PRIVILEGES FOR ROLE ds_default_privs_owner IN SCHEMA ds_default_privs GRANT SELECT, UPDATE ON TABLES TO ds_default_privs_reader",
+				"ALTER DEFAULT PRIVILEGES FOR ROLE ds_default_privs_owner REVOKE EXECUTE ON FUNCTIONS FROM PUBLIC",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceDefaultPrivilegesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_default_privileges.tables", "default_privileges.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_default_privileges.tables", "default_privileges.0.schema", "ds_default_privs"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_default_privileges.tables", "default_privileges.0.role", "ds_default_privs_reader"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_default_privileges.tables", "default_privileges.0.privileges.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_default_privileges.tables", "default_privileges.0.privileges.0", "SELECT"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_default_privileges.functions", "default_privileges.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_default_privileges.functions", "default_privileges.0.schema", ""),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_default_privileges.functions", "default_privileges.0.role", "ds_default_privs_owner"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceDefaultPrivilegesConfig = `
+data "postgresql_default_privileges" "tables" {
+  owner       = "ds_default_privs_owner"
+  object_type = "table"
+}
+
+data "postgresql_default_privileges" "functions" {
+  owner       = "ds_default_privs_owner"
+  object_type = "function"
+}
+`
@@ -0,0 +1,158 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	dataExtensionsInstalledAttr = "installed"
+	dataExtensionsAvailableAttr = "available"
+
+	dataExtensionNameAttr             = "name"
+	dataExtensionSchemaAttr           = "schema"
+	dataExtensionVersionAttr          = "version"
+	dataExtensionDefaultVersionAttr   = "default_version"
+	dataExtensionInstalledVersionAttr = "installed_version"
+	dataExtensionCommentAttr          = "comment"
+)
+
+func dataSourcePostgreSQLExtensions() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLExtensionsRead,
+
+		Schema: map[string]*schema.Schema{
+			dataExtensionsInstalledAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Extensions installed in the database",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataExtensionNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataExtensionSchemaAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataExtensionVersionAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			dataExtensionsAvailableAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Extensions available for installation on the server",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataExtensionNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataExtensionDefaultVersionAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataExtensionInstalledVersionAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataExtensionCommentAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLExtensionsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	installed, err := installedExtensions(c)
+	if err != nil {
+		return diagnostics(err)
+	}
+	if err := d.Set(dataExtensionsInstalledAttr, installed); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting installed extensions: {{err}}", err))
+	}
+
+	available, err := availableExtensions(c)
+	if err != nil {
+		return diagnostics(err)
+	}
+	if err := d.Set(dataExtensionsAvailableAttr, available); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting available extensions: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("extensions", c.config.Database))
+
+	return nil
+}
+
+func installedExtensions(c *Client) ([]interface{}, error) {
+	query := `SELECT e.extname, n.nspname, e.extversion ` +
+		`FROM pg_catalog.pg_extension e, pg_catalog.pg_namespace n ` +
+		`WHERE n.oid = e.extnamespace ORDER BY e.extname`
+	rows, err := c.DB().Query(query)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error reading installed extensions: {{err}}", err)
+	}
+	defer rows.Close()
+
+	extensions := make([]interface{}, 0)
+	for rows.Next() {
+		var name, schemaName, version string
+		if err := rows.Scan(&name, &schemaName, &version); err != nil {
+			return nil, errwrap.Wrapf("Error reading installed extensions: {{err}}", err)
+		}
+
+		extensions = append(extensions, map[string]interface{}{
+			dataExtensionNameAttr:    name,
+			dataExtensionSchemaAttr:  schemaName,
+			dataExtensionVersionAttr: version,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errwrap.Wrapf("Error reading installed extensions: {{err}}", err)
+	}
+
+	return extensions, nil
+}
+
+func availableExtensions(c *Client) ([]interface{}, error) {
+	query := `SELECT name, COALESCE(default_version, ''), COALESCE(installed_version, ''), COALESCE(comment, '') ` +
+		`FROM pg_catalog.pg_available_extensions ORDER BY name`
+	rows, err := c.DB().Query(query)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error reading available extensions: {{err}}", err)
+	}
+	defer rows.Close()
+
+	extensions := make([]interface{}, 0)
+	for rows.Next() {
+		var name, defaultVersion, installedVersion, comment string
+		if err := rows.Scan(&name, &defaultVersion, &installedVersion, &comment); err != nil {
+			return nil, errwrap.Wrapf("Error reading available extensions: {{err}}", err)
+		}
+
+		extensions = append(extensions, map[string]interface{}{
+			dataExtensionNameAttr:             name,
+			dataExtensionDefaultVersionAttr:   defaultVersion,
+			dataExtensionInstalledVersionAttr: installedVersion,
+			dataExtensionCommentAttr:          comment,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errwrap.Wrapf("Error reading available extensions: {{err}}", err)
+	}
+
+	return extensions, nil
+}
@@ -0,0 +1,62 @@
+package postgresql
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccPostgresqlDataSourceExtensions_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceExtensionsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(
+						"data.postgresql_extensions.exts", "installed.#"),
+					resource.TestCheckResourceAttrSet(
+						"data.postgresql_extensions.exts", "available.#"),
+					testAccCheckPostgresqlDataSourceExtensionsInstalled("data.postgresql_extensions.exts", "pg_trgm"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckPostgresqlDataSourceExtensionsInstalled(n, extName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", n)
+		}
+
+		count, err := strconv.Atoi(rs.Primary.Attributes["installed.#"])
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < count; i++ {
+			if rs.Primary.Attributes[fmt.Sprintf("installed.%d.name", i)] == extName {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("Extension %q not reported as installed", extName)
+	}
+}
+
+var testAccPostgresqlDataSourceExtensionsConfig = `
+resource "postgresql_extension" "trgm" {
+  name = "pg_trgm"
+}
+
+data "postgresql_extensions" "exts" {
+  depends_on = [postgresql_extension.trgm]
+}
+`
@@ -0,0 +1,137 @@
+package postgresql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	dataForeignServersAttr = "foreign_servers"
+
+	dataForeignServerNameAttr    = "name"
+	dataForeignServerOwnerAttr   = "owner"
+	dataForeignServerFDWAttr     = "foreign_data_wrapper"
+	dataForeignServerTypeAttr    = "type"
+	dataForeignServerVersionAttr = "version"
+	dataForeignServerOptionsAttr = "options"
+)
+
+// sensitiveOptionMarkers are substrings of option names whose values are
+// never exposed by data sources.
+var sensitiveOptionMarkers = []string{"password", "secret", "token"}
+
+func dataSourcePostgreSQLForeignServers() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLForeignServersRead,
+
+		Schema: map[string]*schema.Schema{
+			dataForeignServersAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataForeignServerNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataForeignServerOwnerAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataForeignServerFDWAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataForeignServerTypeAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataForeignServerVersionAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataForeignServerOptionsAttr: {
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Description: "The server's options, with the values of sensitive options redacted",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLForeignServersRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	query := `SELECT s.srvname, pg_catalog.pg_get_userbyid(s.srvowner), w.fdwname, ` +
+		`COALESCE(s.srvtype, ''), COALESCE(s.srvversion, ''), COALESCE(s.srvoptions, '{}'::TEXT[]) ` +
+		`FROM pg_catalog.pg_foreign_server s ` +
+		`JOIN pg_catalog.pg_foreign_data_wrapper w ON w.oid = s.srvfdw ` +
+		`ORDER BY s.srvname`
+	rows, err := c.DB().Query(query)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading foreign servers: {{err}}", err))
+	}
+	defer rows.Close()
+
+	servers := make([]interface{}, 0)
+	for rows.Next() {
+		var name, owner, fdw, srvType, version string
+		var options []string
+
+		if err := rows.Scan(&name, &owner, &fdw, &srvType, &version, pq.Array(&options)); err != nil {
+			return diagnostics(errwrap.Wrapf("Error reading foreign servers: {{err}}", err))
+		}
+
+		servers = append(servers, map[string]interface{}{
+			dataForeignServerNameAttr:    name,
+			dataForeignServerOwnerAttr:   owner,
+			dataForeignServerFDWAttr:     fdw,
+			dataForeignServerTypeAttr:    srvType,
+			dataForeignServerVersionAttr: version,
+			dataForeignServerOptionsAttr: redactedOptions(options),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading foreign servers: {{err}}", err))
+	}
+
+	if err := d.Set(dataForeignServersAttr, servers); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting foreign servers: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("foreign_servers", c.config.Database))
+
+	return nil
+}
+
+// redactedOptions converts a list of key=value options, as stored in the
+// catalogs, into a map.  The values of options that look sensitive are
+// replaced.
+func redactedOptions(options []string) map[string]interface{} {
+	m := make(map[string]interface{}, len(options))
+	for _, option := range options {
+		parts := strings.SplitN(option, "=", 2)
+		key, value := parts[0], ""
+		if len(parts) == 2 {
+			value = parts[1]
+		}
+
+		lowerKey := strings.ToLower(key)
+		for _, marker := range sensitiveOptionMarkers {
+			if strings.Contains(lowerKey, marker) {
+				value = "<redacted>"
+				break
+			}
+		}
+
+		m[key] = value
+	}
+	return m
+}
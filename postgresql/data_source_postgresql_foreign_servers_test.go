@@ -0,0 +1,62 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceForeignServers_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t, "DROP EXTENSION IF EXISTS postgres_fdw CASCADE")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE EXTENSION postgres_fdw",
+				"CREATE SERVER ds_foreign_server FOREIGN DATA WRAPPER postgres_fdw OPTIONS (host 'remote', dbname 'app')",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceForeignServersConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_foreign_servers.all", "foreign_servers.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_foreign_servers.all", "foreign_servers.0.name", "ds_foreign_server"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_foreign_servers.all", "foreign_servers.0.foreign_data_wrapper", "postgres_fdw"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_foreign_servers.all", "foreign_servers.0.options.host", "remote"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_foreign_servers.all", "foreign_servers.0.options.dbname", "app"),
+				),
+			},
+		},
+	})
+}
+
+func TestRedactedOptions(t *testing.T) {
+	options := redactedOptions([]string{"host=db", "password=hunter2", "api_token=abc", "flag"})
+
+	expected := map[string]string{
+		"host":      "db",
+		"password":  "<redacted>",
+		"api_token": "<redacted>",
+		"flag":      "",
+	}
+	if len(options) != len(expected) {
+		t.Fatalf("expected %d options, got %d: %v", len(expected), len(options), options)
+	}
+	for k, v := range expected {
+		if options[k] != v {
+			t.Errorf("option %q: expected %q, got %q", k, v, options[k])
+		}
+	}
+}
+
+var testAccPostgresqlDataSourceForeignServersConfig = `
+data "postgresql_foreign_servers" "all" {}
+`
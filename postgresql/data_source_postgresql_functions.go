@@ -0,0 +1,153 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	dataFunctionsSchemaAttr      = "schema"
+	dataFunctionsNamePatternAttr = "name_pattern"
+	dataFunctionsAttr            = "functions"
+
+	dataFunctionNameAttr            = "name"
+	dataFunctionArgumentsAttr       = "arguments"
+	dataFunctionSignatureAttr       = "signature"
+	dataFunctionResultTypeAttr      = "result_type"
+	dataFunctionLanguageAttr        = "language"
+	dataFunctionKindAttr            = "kind"
+	dataFunctionOwnerAttr           = "owner"
+	dataFunctionSecurityDefinerAttr = "security_definer"
+)
+
+func dataSourcePostgreSQLFunctions() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLFunctionsRead,
+
+		Schema: map[string]*schema.Schema{
+			dataFunctionsSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "public",
+				Description: "The schema to list functions from",
+			},
+			dataFunctionsNamePatternAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return functions whose name matches this LIKE pattern",
+			},
+			dataFunctionsAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataFunctionNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataFunctionArgumentsAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The identity arguments of the function",
+						},
+						dataFunctionSignatureAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The schema-qualified identity of the function, usable in GRANT and ALTER statements",
+						},
+						dataFunctionResultTypeAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataFunctionLanguageAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataFunctionKindAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "One of function, procedure, aggregate or window",
+						},
+						dataFunctionOwnerAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataFunctionSecurityDefinerAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLFunctionsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	kindExpr := `CASE WHEN p.proisagg THEN 'aggregate' WHEN p.proiswindow THEN 'window' ELSE 'function' END`
+	if c.featureSupported(featureProKind) {
+		kindExpr = `CASE p.prokind WHEN 'a' THEN 'aggregate' WHEN 'w' THEN 'window' WHEN 'p' THEN 'procedure' ELSE 'function' END`
+	}
+
+	b := bytes.NewBufferString(`SELECT p.proname, pg_catalog.pg_get_function_identity_arguments(p.oid), ` +
+		`pg_catalog.quote_ident(n.nspname) || '.' || pg_catalog.quote_ident(p.proname) || ` +
+		`'(' || pg_catalog.pg_get_function_identity_arguments(p.oid) || ')', COALESCE(pg_catalog.pg_get_function_result(p.oid), ''), l.lanname, `)
+	fmt.Fprint(b, kindExpr)
+	fmt.Fprint(b, `, pg_catalog.pg_get_userbyid(p.proowner), p.prosecdef `+
+		`FROM pg_catalog.pg_proc p `+
+		`JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace `+
+		`JOIN pg_catalog.pg_language l ON l.oid = p.prolang `+
+		`WHERE n.nspname = $1`)
+
+	schemaName := d.Get(dataFunctionsSchemaAttr).(string)
+	args := []interface{}{schemaName}
+	if v, ok := d.GetOk(dataFunctionsNamePatternAttr); ok {
+		args = append(args, v.(string))
+		fmt.Fprintf(b, " AND p.proname LIKE $%d", len(args))
+	}
+	fmt.Fprint(b, " ORDER BY p.proname, 2")
+
+	rows, err := c.DB().Query(b.String(), args...)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading functions in schema %q: {{err}}", schemaName), err))
+	}
+	defer rows.Close()
+
+	functions := make([]interface{}, 0)
+	for rows.Next() {
+		var name, arguments, signature, resultType, language, kind, owner string
+		var securityDefiner bool
+
+		if err := rows.Scan(&name, &arguments, &signature, &resultType, &language, &kind, &owner, &securityDefiner); err != nil {
+			return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading functions in schema %q: {{err}}", schemaName), err))
+		}
+
+		functions = append(functions, map[string]interface{}{
+			dataFunctionNameAttr:            name,
+			dataFunctionArgumentsAttr:       arguments,
+			dataFunctionSignatureAttr:       signature,
+			dataFunctionResultTypeAttr:      resultType,
+			dataFunctionLanguageAttr:        language,
+			dataFunctionKindAttr:            kind,
+			dataFunctionOwnerAttr:           owner,
+			dataFunctionSecurityDefinerAttr: securityDefiner,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading functions in schema %q: {{err}}", schemaName), err))
+	}
+
+	if err := d.Set(dataFunctionsAttr, functions); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting functions: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("functions", schemaName, d.Get(dataFunctionsNamePatternAttr).(string)))
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceFunctions_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t, "DROP SCHEMA IF EXISTS ds_functions CASCADE")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE SCHEMA ds_functions",
+				"CREATE FUNCTION ds_functions.add(a integer, b integer) RETURNS integer LANGUAGE sql AS 'SELECT a + b'",
+				"CREATE FUNCTION ds_functions.secret() RETURNS text LANGUAGE sql SECURITY DEFINER AS 'SELECT ''s''::text'",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceFunctionsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_functions.fns", "functions.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_functions.fns", "functions.0.name", "add"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_functions.fns", "functions.0.arguments", "a integer, b integer"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_functions.fns", "functions.0.signature", "ds_functions.add(a integer, b integer)"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_functions.fns", "functions.0.result_type", "integer"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_functions.fns", "functions.0.language", "sql"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_functions.fns", "functions.0.kind", "function"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_functions.fns", "functions.1.security_definer", "true"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_functions.filtered", "functions.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceFunctionsConfig = `
+data "postgresql_functions" "fns" {
+  schema = "ds_functions"
+}
+
+data "postgresql_functions" "filtered" {
+  schema       = "ds_functions"
+  name_pattern = "sec%"
+}
+`
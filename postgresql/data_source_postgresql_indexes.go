@@ -0,0 +1,169 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	dataIndexesSchemaAttr = "schema"
+	dataIndexesTableAttr  = "table"
+	dataIndexesAttr       = "indexes"
+
+	dataIndexNameAttr       = "name"
+	dataIndexTableAttr      = "table"
+	dataIndexMethodAttr     = "method"
+	dataIndexDefinitionAttr = "definition"
+	dataIndexSizeAttr       = "size"
+	dataIndexUniqueAttr     = "unique"
+	dataIndexPrimaryAttr    = "primary"
+	dataIndexValidAttr      = "valid"
+	dataIndexOpClassesAttr  = "operator_classes"
+	dataIndexOptionsAttr    = "options"
+)
+
+func dataSourcePostgreSQLIndexes() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLIndexesRead,
+
+		Schema: map[string]*schema.Schema{
+			dataIndexesSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "public",
+				Description: "The schema to list indexes from",
+			},
+			dataIndexesTableAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return the indexes of this table",
+			},
+			dataIndexesAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataIndexNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataIndexTableAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataIndexMethodAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataIndexDefinitionAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataIndexSizeAttr: {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Size of the index in bytes",
+						},
+						dataIndexUniqueAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						dataIndexPrimaryAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						dataIndexValidAttr: {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "False if the index is invalid, e.g. after a failed CREATE INDEX CONCURRENTLY",
+						},
+						dataIndexOpClassesAttr: {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The operator classes of the indexed columns, e.g. vector_cosine_ops",
+						},
+						dataIndexOptionsAttr: {
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Description: "The storage parameters of the index, e.g. lists or m and ef_construction",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLIndexesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	b := bytes.NewBufferString(`SELECT ic.relname, tc.relname, am.amname, pg_catalog.pg_get_indexdef(i.indexrelid), ` +
+		`pg_catalog.pg_relation_size(i.indexrelid), i.indisunique, i.indisprimary, i.indisvalid, ` +
+		`ARRAY(SELECT opc.opcname FROM pg_catalog.generate_subscripts(i.indclass::OID[], 1) s ` +
+		`JOIN pg_catalog.pg_opclass opc ON opc.oid = (i.indclass::OID[])[s] ORDER BY s), ` +
+		`COALESCE(ic.reloptions, '{}'::TEXT[]) ` +
+		`FROM pg_catalog.pg_index i ` +
+		`JOIN pg_catalog.pg_class ic ON ic.oid = i.indexrelid ` +
+		`JOIN pg_catalog.pg_class tc ON tc.oid = i.indrelid ` +
+		`JOIN pg_catalog.pg_namespace n ON n.oid = tc.relnamespace ` +
+		`JOIN pg_catalog.pg_am am ON am.oid = ic.relam ` +
+		`WHERE n.nspname = $1`)
+
+	schemaName := d.Get(dataIndexesSchemaAttr).(string)
+	args := []interface{}{schemaName}
+	tableName := d.Get(dataIndexesTableAttr).(string)
+	if tableName != "" {
+		args = append(args, tableName)
+		fmt.Fprintf(b, " AND tc.relname = $%d", len(args))
+	}
+	fmt.Fprint(b, " ORDER BY tc.relname, ic.relname")
+
+	rows, err := c.DB().Query(b.String(), args...)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading indexes in schema %q: {{err}}", schemaName), err))
+	}
+	defer rows.Close()
+
+	indexes := make([]interface{}, 0)
+	for rows.Next() {
+		var name, table, method, definition string
+		var size int64
+		var unique, primary, valid bool
+		var opClasses, options []string
+
+		if err := rows.Scan(&name, &table, &method, &definition, &size, &unique, &primary, &valid,
+			pq.Array(&opClasses), pq.Array(&options)); err != nil {
+			return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading indexes in schema %q: {{err}}", schemaName), err))
+		}
+
+		indexes = append(indexes, map[string]interface{}{
+			dataIndexNameAttr:       name,
+			dataIndexTableAttr:      table,
+			dataIndexMethodAttr:     method,
+			dataIndexDefinitionAttr: definition,
+			dataIndexSizeAttr:       int(size),
+			dataIndexUniqueAttr:     unique,
+			dataIndexPrimaryAttr:    primary,
+			dataIndexValidAttr:      valid,
+			dataIndexOpClassesAttr:  opClasses,
+			dataIndexOptionsAttr:    redactedOptions(options),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading indexes in schema %q: {{err}}", schemaName), err))
+	}
+
+	if err := d.Set(dataIndexesAttr, indexes); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting indexes: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("indexes", schemaName, tableName))
+
+	return nil
+}
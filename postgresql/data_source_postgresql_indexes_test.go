@@ -0,0 +1,66 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceIndexes_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t, "DROP SCHEMA IF EXISTS ds_indexes CASCADE")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE SCHEMA ds_indexes",
+				"CREATE TABLE ds_indexes.items (id INT PRIMARY KEY, name TEXT)",
+				"CREATE INDEX items_name_idx ON ds_indexes.items (name text_pattern_ops) WITH (fillfactor = 70)",
+				"CREATE TABLE ds_indexes.other (id INT PRIMARY KEY)",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceIndexesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_indexes.schema", "indexes.#", "3"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_indexes.items", "indexes.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_indexes.items", "indexes.0.name", "items_name_idx"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_indexes.items", "indexes.0.method", "btree"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_indexes.items", "indexes.0.unique", "false"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_indexes.items", "indexes.0.valid", "true"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_indexes.items", "indexes.0.operator_classes.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_indexes.items", "indexes.0.operator_classes.0", "text_pattern_ops"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_indexes.items", "indexes.0.options.fillfactor", "70"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_indexes.items", "indexes.1.name", "items_pkey"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_indexes.items", "indexes.1.primary", "true"),
+					resource.TestCheckResourceAttrSet(
+						"data.postgresql_indexes.items", "indexes.1.definition"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceIndexesConfig = `
+data "postgresql_indexes" "schema" {
+  schema = "ds_indexes"
+}
+
+data "postgresql_indexes" "items" {
+  schema = "ds_indexes"
+  table  = "items"
+}
+`
@@ -0,0 +1,180 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	dataLocksSchemaAttr            = "schema"
+	dataLocksTableAttr             = "table"
+	dataLocksAttr                  = "locks"
+	dataLocksWaitingAttr           = "waiting"
+	dataLocksOldestTransactionAttr = "oldest_transaction_seconds"
+
+	dataLockPIDAttr                 = "pid"
+	dataLockRelationAttr            = "relation"
+	dataLockModeAttr                = "mode"
+	dataLockGrantedAttr             = "granted"
+	dataLockUsernameAttr            = "username"
+	dataLockApplicationNameAttr     = "application_name"
+	dataLockStateAttr               = "state"
+	dataLockTransactionDurationAttr = "transaction_seconds"
+)
+
+func dataSourcePostgreSQLLocks() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLLocksRead,
+
+		Schema: map[string]*schema.Schema{
+			dataLocksSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return the locks on relations of this schema",
+			},
+			dataLocksTableAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return the locks on this table",
+			},
+			dataLocksWaitingAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of lock requests waiting to be granted",
+			},
+			dataLocksOldestTransactionAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The age in seconds of the oldest transaction holding or waiting for a lock",
+			},
+			dataLocksAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataLockPIDAttr: {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						dataLockRelationAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The schema-qualified name of the locked relation",
+						},
+						dataLockModeAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataLockGrantedAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						dataLockUsernameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataLockApplicationNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataLockStateAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataLockTransactionDurationAttr: {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "How long the backend's current transaction has been running, in seconds",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLLocksRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+
+	// Relation names can only be resolved in the current database, so only
+	// the locks on its relations are returned.
+	b := bytes.NewBufferString(`SELECT l.pid, n.nspname || '.' || c.relname, l.mode, l.granted, ` +
+		`COALESCE(a.usename, ''), COALESCE(a.application_name, ''), COALESCE(a.state, ''), ` +
+		`COALESCE(EXTRACT(EPOCH FROM pg_catalog.now() - a.xact_start)::BIGINT, 0) ` +
+		`FROM pg_catalog.pg_locks l ` +
+		`JOIN pg_catalog.pg_class c ON c.oid = l.relation ` +
+		`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+		`LEFT JOIN pg_catalog.pg_stat_activity a ON a.pid = l.pid ` +
+		`WHERE l.locktype = 'relation' ` +
+		`AND l.database = (SELECT oid FROM pg_catalog.pg_database WHERE datname = pg_catalog.current_database()) ` +
+		`AND l.pid <> pg_catalog.pg_backend_pid() AND ` + userSchemasCond("n.nspname"))
+
+	args := []interface{}{}
+	schemaName := d.Get(dataLocksSchemaAttr).(string)
+	if schemaName != "" {
+		args = append(args, schemaName)
+		fmt.Fprintf(b, " AND n.nspname = $%d", len(args))
+	}
+	tableName := d.Get(dataLocksTableAttr).(string)
+	if tableName != "" {
+		args = append(args, tableName)
+		fmt.Fprintf(b, " AND c.relname = $%d", len(args))
+	}
+	fmt.Fprint(b, " ORDER BY 2, 1, 3")
+
+	rows, err := c.DB().Query(b.String(), args...)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading locks: {{err}}", err))
+	}
+	defer rows.Close()
+
+	var waiting int
+	var oldestTransaction int64
+	locks := make([]interface{}, 0)
+	for rows.Next() {
+		var pid int
+		var relation, mode, username, applicationName, state string
+		var granted bool
+		var transactionDuration int64
+
+		if err := rows.Scan(&pid, &relation, &mode, &granted, &username, &applicationName, &state, &transactionDuration); err != nil {
+			return diagnostics(errwrap.Wrapf("Error reading locks: {{err}}", err))
+		}
+
+		if !granted {
+			waiting++
+		}
+		if transactionDuration > oldestTransaction {
+			oldestTransaction = transactionDuration
+		}
+
+		locks = append(locks, map[string]interface{}{
+			dataLockPIDAttr:                 pid,
+			dataLockRelationAttr:            relation,
+			dataLockModeAttr:                mode,
+			dataLockGrantedAttr:             granted,
+			dataLockUsernameAttr:            username,
+			dataLockApplicationNameAttr:     applicationName,
+			dataLockStateAttr:               state,
+			dataLockTransactionDurationAttr: int(transactionDuration),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading locks: {{err}}", err))
+	}
+
+	d.Set(dataLocksWaitingAttr, waiting)
+	d.Set(dataLocksOldestTransactionAttr, int(oldestTransaction))
+	if err := d.Set(dataLocksAttr, locks); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting locks: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("locks", schemaName, tableName))
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceLocks_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t, "DROP SCHEMA IF EXISTS ds_locks CASCADE")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE SCHEMA ds_locks",
+				"CREATE TABLE ds_locks.items (id INT)",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceLocksConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_locks.items", "locks.#", "0"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_locks.items", "waiting", "0"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_locks.items", "oldest_transaction_seconds", "0"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceLocksConfig = `
+data "postgresql_locks" "items" {
+  schema = "ds_locks"
+  table  = "items"
+}
+`
@@ -0,0 +1,169 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	dataPoliciesSchemaAttr     = "schema"
+	dataPoliciesTableAttr      = "table"
+	dataPoliciesRLSEnabledAttr = "row_security_enabled"
+	dataPoliciesRLSForcedAttr  = "row_security_forced"
+	dataPoliciesAttr           = "policies"
+
+	dataPolicyNameAttr       = "name"
+	dataPolicyCommandAttr    = "command"
+	dataPolicyPermissiveAttr = "permissive"
+	dataPolicyRolesAttr      = "roles"
+	dataPolicyUsingAttr      = "using"
+	dataPolicyWithCheckAttr  = "with_check"
+)
+
+func dataSourcePostgreSQLPolicies() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLPoliciesRead,
+
+		Schema: map[string]*schema.Schema{
+			dataPoliciesSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "public",
+				Description: "The schema of the table",
+			},
+			dataPoliciesTableAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The table to list policies from",
+			},
+			dataPoliciesRLSEnabledAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether row-level security is enabled on the table",
+			},
+			dataPoliciesRLSForcedAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether row-level security also applies to the table owner",
+			},
+			dataPoliciesAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataPolicyNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataPolicyCommandAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ALL, SELECT, INSERT, UPDATE or DELETE",
+						},
+						dataPolicyPermissiveAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						dataPolicyRolesAttr: {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						dataPolicyUsingAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataPolicyWithCheckAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLPoliciesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	if !c.featureSupported(featureRLS) {
+		return diagnostics(c.featureError(featureRLS, "Row-level security policies"))
+	}
+
+	schemaName := d.Get(dataPoliciesSchemaAttr).(string)
+	tableName := d.Get(dataPoliciesTableAttr).(string)
+
+	var rlsEnabled, rlsForced bool
+	err := c.DB().QueryRow(`SELECT c.relrowsecurity, c.relforcerowsecurity `+
+		`FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace `+
+		`WHERE n.nspname = $1 AND c.relname = $2`, schemaName, tableName).Scan(&rlsEnabled, &rlsForced)
+	switch {
+	case err == sql.ErrNoRows:
+		return diagnostics(fmt.Errorf("PostgreSQL table (%s.%s) not found", schemaName, tableName))
+	case err != nil:
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading table %s.%s: {{err}}", schemaName, tableName), err))
+	}
+
+	permissiveExpr := "TRUE"
+	if c.featureSupported(featureRestrictivePolicies) {
+		permissiveExpr = "p.polpermissive"
+	}
+
+	query := `SELECT p.polname, ` +
+		`CASE p.polcmd WHEN 'r' THEN 'SELECT' WHEN 'a' THEN 'INSERT' WHEN 'w' THEN 'UPDATE' ` +
+		`WHEN 'd' THEN 'DELETE' ELSE 'ALL' END, ` + permissiveExpr + `, ` +
+		`ARRAY(SELECT CASE WHEN r = 0 THEN 'PUBLIC' ELSE pg_catalog.pg_get_userbyid(r) END ` +
+		`FROM pg_catalog.unnest(p.polroles) AS r ORDER BY 1), ` +
+		`COALESCE(pg_catalog.pg_get_expr(p.polqual, p.polrelid), ''), ` +
+		`COALESCE(pg_catalog.pg_get_expr(p.polwithcheck, p.polrelid), '') ` +
+		`FROM pg_catalog.pg_policy p ` +
+		`JOIN pg_catalog.pg_class c ON c.oid = p.polrelid ` +
+		`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+		`WHERE n.nspname = $1 AND c.relname = $2 ` +
+		`ORDER BY p.polname`
+
+	rows, err := c.DB().Query(query, schemaName, tableName)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading policies of table %s.%s: {{err}}", schemaName, tableName), err))
+	}
+	defer rows.Close()
+
+	policies := make([]interface{}, 0)
+	for rows.Next() {
+		var name, command, using, withCheck string
+		var permissive bool
+		var roles []string
+
+		if err := rows.Scan(&name, &command, &permissive, pq.Array(&roles), &using, &withCheck); err != nil {
+			return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading policies of table %s.%s: {{err}}", schemaName, tableName), err))
+		}
+
+		policies = append(policies, map[string]interface{}{
+			dataPolicyNameAttr:       name,
+			dataPolicyCommandAttr:    command,
+			dataPolicyPermissiveAttr: permissive,
+			dataPolicyRolesAttr:      roles,
+			dataPolicyUsingAttr:      using,
+			dataPolicyWithCheckAttr:  withCheck,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading policies of table %s.%s: {{err}}", schemaName, tableName), err))
+	}
+
+	d.Set(dataPoliciesRLSEnabledAttr, rlsEnabled)
+	d.Set(dataPoliciesRLSForcedAttr, rlsForced)
+	if err := d.Set(dataPoliciesAttr, policies); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting policies: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("policies", schemaName, tableName))
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourcePolicies_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP SCHEMA IF EXISTS ds_policies CASCADE",
+		"DROP ROLE IF EXISTS ds_policies_reader",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE ROLE ds_policies_reader",
+				"CREATE SCHEMA ds_policies",
+				"CREATE TABLE ds_policies.documents (id INT, owner TEXT)",
+				"ALTER TABLE ds_policies.documents ENABLE ROW LEVEL SECURITY",
+				"CREATE POLICY documents_owner ON ds_policies.documents USING (owner = current_user)",
+				"CREATE POLICY documents_read ON ds_policies.documents FOR SELECT TO ds_policies_reader USING (true)",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourcePoliciesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_policies.documents", "row_security_enabled", "true"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_policies.documents", "row_security_forced", "false"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_policies.documents", "policies.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_policies.documents", "policies.0.name", "documents_owner"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_policies.documents", "policies.0.command", "ALL"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_policies.documents", "policies.0.roles.0", "PUBLIC"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_policies.documents", "policies.0.using", "(owner = (CURRENT_USER)::text)"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_policies.documents", "policies.0.with_check", ""),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_policies.documents", "policies.1.command", "SELECT"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_policies.documents", "policies.1.roles.0", "ds_policies_reader"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourcePoliciesConfig = `
+data "postgresql_policies" "documents" {
+  schema = "ds_policies"
+  table  = "documents"
+}
+`
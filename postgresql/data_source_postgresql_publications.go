@@ -0,0 +1,159 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	dataPublicationsAttr = "publications"
+
+	dataPublicationNameAttr      = "name"
+	dataPublicationOwnerAttr     = "owner"
+	dataPublicationAllTablesAttr = "all_tables"
+	dataPublicationPublishAttr   = "publish"
+	dataPublicationTablesAttr    = "tables"
+)
+
+func dataSourcePostgreSQLPublications() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLPublicationsRead,
+
+		Schema: map[string]*schema.Schema{
+			dataPublicationsAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataPublicationNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataPublicationOwnerAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataPublicationAllTablesAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						dataPublicationPublishAttr: {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The operations replicated by the publication",
+						},
+						dataPublicationTablesAttr: {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The schema-qualified tables published",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLPublicationsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	if !c.featureSupported(featureLogicalReplication) {
+		return diagnostics(c.featureError(featureLogicalReplication, "Publications"))
+	}
+
+	truncateExpr := "FALSE"
+	if c.featureSupported(featurePublicationTruncate) {
+		truncateExpr = "p.pubtruncate"
+	}
+
+	query := `SELECT p.pubname, pg_catalog.pg_get_userbyid(p.pubowner), p.puballtables, ` +
+		`p.pubinsert, p.pubupdate, p.pubdelete, ` + truncateExpr + ` ` +
+		`FROM pg_catalog.pg_publication p ORDER BY p.pubname`
+	rows, err := c.DB().Query(query)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading publications: {{err}}", err))
+	}
+	defer rows.Close()
+
+	publications := make([]interface{}, 0)
+	for rows.Next() {
+		var name, owner string
+		var allTables, pubInsert, pubUpdate, pubDelete, pubTruncate bool
+
+		if err := rows.Scan(&name, &owner, &allTables, &pubInsert, &pubUpdate, &pubDelete, &pubTruncate); err != nil {
+			return diagnostics(errwrap.Wrapf("Error reading publications: {{err}}", err))
+		}
+
+		publish := make([]interface{}, 0, 4)
+		for _, op := range []struct {
+			name    string
+			enabled bool
+		}{
+			{"insert", pubInsert},
+			{"update", pubUpdate},
+			{"delete", pubDelete},
+			{"truncate", pubTruncate},
+		} {
+			if op.enabled {
+				publish = append(publish, op.name)
+			}
+		}
+
+		publications = append(publications, map[string]interface{}{
+			dataPublicationNameAttr:      name,
+			dataPublicationOwnerAttr:     owner,
+			dataPublicationAllTablesAttr: allTables,
+			dataPublicationPublishAttr:   publish,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading publications: {{err}}", err))
+	}
+	rows.Close()
+
+	for _, p := range publications {
+		publication := p.(map[string]interface{})
+		tables, err := publicationTables(c, publication[dataPublicationNameAttr].(string))
+		if err != nil {
+			return diagnostics(err)
+		}
+		publication[dataPublicationTablesAttr] = tables
+	}
+
+	if err := d.Set(dataPublicationsAttr, publications); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting publications: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("publications", c.config.Database))
+
+	return nil
+}
+
+func publicationTables(c *Client, pubName string) ([]interface{}, error) {
+	query := `SELECT pg_catalog.quote_ident(schemaname) || '.' || pg_catalog.quote_ident(tablename) ` +
+		`FROM pg_catalog.pg_publication_tables WHERE pubname = $1 ORDER BY schemaname, tablename`
+	rows, err := c.DB().Query(query, pubName)
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("Error reading tables of publication %q: {{err}}", pubName), err)
+	}
+	defer rows.Close()
+
+	tables := make([]interface{}, 0)
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("Error reading tables of publication %q: {{err}}", pubName), err)
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("Error reading tables of publication %q: {{err}}", pubName), err)
+	}
+
+	return tables, nil
+}
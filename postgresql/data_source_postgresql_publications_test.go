@@ -0,0 +1,54 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourcePublications_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP PUBLICATION IF EXISTS ds_publications_pub",
+		"DROP SCHEMA IF EXISTS ds_publications CASCADE",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE SCHEMA ds_publications",
+				"CREATE TABLE ds_publications.orders (id INT PRIMARY KEY)",
+				"CREATE TABLE ds_publications.items (id INT PRIMARY KEY)",
+				"CREATE PUBLICATION ds_publications_pub FOR TABLE ds_publications.orders, ds_publications.items WITH (publish = 'insert, delete')",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourcePublicationsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_publications.pubs", "publications.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_publications.pubs", "publications.0.name", "ds_publications_pub"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_publications.pubs", "publications.0.all_tables", "false"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_publications.pubs", "publications.0.publish.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_publications.pubs", "publications.0.publish.0", "insert"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_publications.pubs", "publications.0.publish.1", "delete"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_publications.pubs", "publications.0.tables.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_publications.pubs", "publications.0.tables.0", "ds_publications.items"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourcePublicationsConfig = `
+data "postgresql_publications" "pubs" {}
+`
@@ -0,0 +1,206 @@
+package postgresql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	dataQueryQueryAttr      = "query"
+	dataQueryParametersAttr = "parameters"
+	dataQueryColumnsAttr    = "columns"
+	dataQueryRowsAttr       = "rows"
+	dataQueryStringAttr     = "string_value"
+	dataQueryNumberAttr     = "number_value"
+	dataQueryBoolAttr       = "bool_value"
+)
+
+func dataSourcePostgreSQLQuery() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLQueryRead,
+
+		Schema: map[string]*schema.Schema{
+			dataQueryQueryAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The SELECT statement to run, using $1, $2, ... for parameters",
+			},
+			dataQueryParametersAttr: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Positional parameters for the query",
+			},
+			dataQueryColumnsAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The names of the columns returned by the query",
+			},
+			dataQueryRowsAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeMap},
+				Description: "The rows returned by the query, as maps of column name to value",
+			},
+			dataQueryStringAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The value returned by a single-row, single-column query",
+			},
+			dataQueryNumberAttr: {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "The value returned by a single-row, single-column query, if numeric",
+			},
+			dataQueryBoolAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "The value returned by a single-row, single-column query, if boolean",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLQueryRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+
+	query := d.Get(dataQueryQueryAttr).(string)
+	params := d.Get(dataQueryParametersAttr).([]interface{})
+
+	txn, err := c.DB().Begin()
+	if err != nil {
+		return diagnostics(err)
+	}
+	// The transaction is never committed: the query is only ever allowed to
+	// read.
+	defer txn.Rollback()
+
+	if _, err := txn.Exec("SET TRANSACTION READ ONLY"); err != nil {
+		return diagnostics(errwrap.Wrapf("Error starting read-only transaction: {{err}}", err))
+	}
+
+	// Preparing the query sends it with the extended protocol, which only
+	// takes a single statement: sent with the simple protocol, as lib/pq
+	// does for queries without parameters, e.g. COMMIT; DROP TABLE t would
+	// end the read-only transaction and run the DROP.
+	stmt, err := txn.Prepare(query)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf("Error running query: {{err}}", err))
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(params...)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf("Error running query: {{err}}", err))
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading query columns: {{err}}", err))
+	}
+
+	var rawValues []interface{}
+	results := make([]interface{}, 0)
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return diagnostics(errwrap.Wrapf("Error reading query results: {{err}}", err))
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			row[column] = queryValueToString(values[i])
+		}
+		results = append(results, row)
+		rawValues = values
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading query results: {{err}}", err))
+	}
+
+	if err := d.Set(dataQueryColumnsAttr, columns); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting query columns: {{err}}", err))
+	}
+	if err := d.Set(dataQueryRowsAttr, results); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting query rows: {{err}}", err))
+	}
+
+	if len(results) == 1 && len(columns) == 1 {
+		v := rawValues[0]
+		d.Set(dataQueryStringAttr, queryValueToString(v))
+		if n, ok := queryValueToNumber(v); ok {
+			d.Set(dataQueryNumberAttr, n)
+		}
+		if b, ok := v.(bool); ok {
+			d.Set(dataQueryBoolAttr, b)
+		}
+	}
+
+	sum := sha256.Sum256([]byte(query + "\x00" + strings.Join(queryParamStrings(params), "\x00")))
+	d.SetId(hex.EncodeToString(sum[:]))
+
+	return nil
+}
+
+func queryParamStrings(params []interface{}) []string {
+	strs := make([]string, len(params))
+	for i, p := range params {
+		strs[i] = p.(string)
+	}
+	return strs
+}
+
+// queryValueToString renders a value returned by lib/pq as a string.  NULLs
+// are rendered as empty strings.
+func queryValueToString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(t)
+	case string:
+		return t
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case float64:
+		return strconv.FormatFloat(t, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// queryValueToNumber returns v as a float64 if it is numeric.  NUMERIC
+// values are returned by lib/pq as text and are parsed here.
+func queryValueToNumber(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case int64:
+		return float64(t), true
+	case float64:
+		return t, true
+	case []byte:
+		n, err := strconv.ParseFloat(string(t), 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
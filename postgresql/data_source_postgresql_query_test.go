@@ -0,0 +1,104 @@
+package postgresql
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccPostgresqlDataSourceQuery_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceQueryConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_query.rows", "columns.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_query.rows", "columns.0", "n"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_query.rows", "rows.#", "3"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_query.rows", "rows.2.n", "3"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_query.rows", "rows.2.label", "row-3"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_query.number", "number_value", "42.5"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_query.number", "string_value", "42.5"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_query.bool", "bool_value", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDataSourceQuery_ReadOnly(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccPostgresqlDataSourceQueryWriteConfig,
+				ExpectError: regexp.MustCompile("read-only transaction"),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDataSourceQuery_MultipleStatements(t *testing.T) {
+	defer testAccPostgresqlExec(t, "DROP TABLE IF EXISTS ds_query_should_not_exist")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccPostgresqlDataSourceQueryCommitConfig,
+				ExpectError: regexp.MustCompile("cannot insert multiple commands into a prepared statement"),
+			},
+		},
+		CheckDestroy: func(*terraform.State) error {
+			var exists bool
+			err := testAccProvider.Meta().(*Client).DB().QueryRow("SELECT pg_catalog.to_regclass('ds_query_should_not_exist') IS NOT NULL").Scan(&exists)
+			if err == nil && exists {
+				err = errors.New("expected the statement following COMMIT not to run")
+			}
+			return err
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceQueryConfig = `
+data "postgresql_query" "rows" {
+  query      = "SELECT n, 'row-' || n AS label FROM generate_series(1, $1::INTEGER) AS n"
+  parameters = ["3"]
+}
+
+data "postgresql_query" "number" {
+  query = "SELECT 42.5::NUMERIC"
+}
+
+data "postgresql_query" "bool" {
+  query      = "SELECT $1::TEXT = 'yes'"
+  parameters = ["yes"]
+}
+`
+
+var testAccPostgresqlDataSourceQueryWriteConfig = `
+data "postgresql_query" "write" {
+  query = "CREATE TABLE ds_query_should_not_exist (id INT)"
+}
+`
+
+var testAccPostgresqlDataSourceQueryCommitConfig = `
+data "postgresql_query" "commit" {
+  query = "COMMIT; CREATE TABLE ds_query_should_not_exist (id INT)"
+}
+`
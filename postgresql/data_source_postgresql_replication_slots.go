@@ -0,0 +1,122 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	dataReplicationSlotsAttr = "replication_slots"
+
+	dataReplicationSlotNameAttr       = "slot_name"
+	dataReplicationSlotPluginAttr     = "plugin"
+	dataReplicationSlotTypeAttr       = "slot_type"
+	dataReplicationSlotDatabaseAttr   = "database"
+	dataReplicationSlotActiveAttr     = "active"
+	dataReplicationSlotRestartLSNAttr = "restart_lsn"
+	dataReplicationSlotLagBytesAttr   = "lag_bytes"
+)
+
+func dataSourcePostgreSQLReplicationSlots() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLReplicationSlotsRead,
+
+		Schema: map[string]*schema.Schema{
+			dataReplicationSlotsAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataReplicationSlotNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataReplicationSlotPluginAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataReplicationSlotTypeAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataReplicationSlotDatabaseAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataReplicationSlotActiveAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						dataReplicationSlotRestartLSNAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataReplicationSlotLagBytesAttr: {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Bytes of WAL retained for the slot, or -1 if unknown",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLReplicationSlotsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+
+	if !c.featureSupported(featureReplicationSlots) {
+		return diagnostics(c.featureError(featureReplicationSlots, "Replication slots"))
+	}
+
+	lagExpr := "pg_catalog.pg_xlog_location_diff(pg_catalog.pg_current_xlog_location(), s.restart_lsn)"
+	if c.featureSupported(featureWALFunctionNames) {
+		lagExpr = "pg_catalog.pg_wal_lsn_diff(pg_catalog.pg_current_wal_lsn(), s.restart_lsn)"
+	}
+
+	// The current WAL location can not be determined on a standby.
+	query := `SELECT s.slot_name, COALESCE(s.plugin, ''), s.slot_type, COALESCE(s.database, ''), s.active, ` +
+		`COALESCE(s.restart_lsn::TEXT, ''), ` +
+		`COALESCE(CASE WHEN pg_catalog.pg_is_in_recovery() THEN NULL ELSE ` + lagExpr + ` END, -1)::BIGINT ` +
+		`FROM pg_catalog.pg_replication_slots s ORDER BY s.slot_name`
+	rows, err := c.DB().Query(query)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading replication slots: {{err}}", err))
+	}
+	defer rows.Close()
+
+	slots := make([]interface{}, 0)
+	for rows.Next() {
+		var name, plugin, slotType, database, restartLSN string
+		var active bool
+		var lagBytes int64
+
+		if err := rows.Scan(&name, &plugin, &slotType, &database, &active, &restartLSN, &lagBytes); err != nil {
+			return diagnostics(errwrap.Wrapf("Error reading replication slots: {{err}}", err))
+		}
+
+		slots = append(slots, map[string]interface{}{
+			dataReplicationSlotNameAttr:       name,
+			dataReplicationSlotPluginAttr:     plugin,
+			dataReplicationSlotTypeAttr:       slotType,
+			dataReplicationSlotDatabaseAttr:   database,
+			dataReplicationSlotActiveAttr:     active,
+			dataReplicationSlotRestartLSNAttr: restartLSN,
+			dataReplicationSlotLagBytesAttr:   int(lagBytes),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading replication slots: {{err}}", err))
+	}
+
+	if err := d.Set(dataReplicationSlotsAttr, slots); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting replication slots: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("replication_slots", c.config.Host))
+
+	return nil
+}
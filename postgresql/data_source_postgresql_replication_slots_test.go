@@ -0,0 +1,27 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceReplicationSlots_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceReplicationSlotsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(
+						"data.postgresql_replication_slots.slots", "replication_slots.#"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceReplicationSlotsConfig = `
+data "postgresql_replication_slots" "slots" {}
+`
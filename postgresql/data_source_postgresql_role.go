@@ -0,0 +1,190 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	dataRoleRolesAttr    = "roles"
+	dataRoleSettingsAttr = "settings"
+
+	dataRoleSettingDatabaseAttr = "database"
+	dataRoleSettingNameAttr     = "name"
+	dataRoleSettingValueAttr    = "value"
+)
+
+func dataSourcePostgreSQLRole() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLRoleRead,
+
+		Schema: map[string]*schema.Schema{
+			roleNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the role",
+			},
+			roleSuperuserAttr: {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			roleCreateDBAttr: {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			roleCreateRoleAttr: {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			roleInheritAttr: {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			roleLoginAttr: {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			roleReplicationAttr: {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			roleBypassRLSAttr: {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			roleConnLimitAttr: {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			roleValidUntilAttr: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			dataRoleRolesAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The roles this role is a member of",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			dataRoleSettingsAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The configuration parameters set for this role with ALTER ROLE ... SET",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataRoleSettingDatabaseAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The database the setting applies to, empty for all databases",
+						},
+						dataRoleSettingNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataRoleSettingValueAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// NOTE: the settings are stored as name=value strings, and values may
+// themselves contain an equals sign.
+const roleSettingsQuery = `SELECT COALESCE(d.datname, ''), ` +
+	`pg_catalog.split_part(s.setting, '=', 1), pg_catalog.substr(s.setting, pg_catalog.strpos(s.setting, '=') + 1) ` +
+	`FROM (SELECT rs.setdatabase, pg_catalog.unnest(rs.setconfig) AS setting ` +
+	`FROM pg_catalog.pg_db_role_setting rs WHERE rs.setrole = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $1)) AS s ` +
+	`LEFT JOIN pg_catalog.pg_database d ON d.oid = s.setdatabase ` +
+	`ORDER BY 1, 2`
+
+func dataSourcePostgreSQLRoleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	roleName := d.Get(roleNameAttr).(string)
+
+	bypassRLSExpr := "FALSE"
+	if c.featureSupported(featureRLS) {
+		bypassRLSExpr = "r.rolbypassrls"
+	}
+
+	var superuser, inherit, createRole, createDB, canLogin, replication, bypassRLS bool
+	var connLimit int
+	var validUntil string
+	var roles []string
+	err := c.DB().QueryRow(`SELECT r.rolsuper, r.rolinherit, r.rolcreaterole, r.rolcreatedb, `+
+		`r.rolcanlogin, r.rolreplication, `+bypassRLSExpr+`, r.rolconnlimit, `+
+		`COALESCE(r.rolvaliduntil::TEXT, 'infinity'), `+
+		`ARRAY(SELECT g.rolname FROM pg_catalog.pg_auth_members m `+
+		`JOIN pg_catalog.pg_roles g ON g.oid = m.roleid WHERE m.member = r.oid ORDER BY g.rolname) `+
+		`FROM pg_catalog.pg_roles r WHERE r.rolname = $1`, roleName).Scan(
+		&superuser,
+		&inherit,
+		&createRole,
+		&createDB,
+		&canLogin,
+		&replication,
+		&bypassRLS,
+		&connLimit,
+		&validUntil,
+		pq.Array(&roles),
+	)
+	switch {
+	case err == sql.ErrNoRows:
+		return diagnostics(fmt.Errorf("PostgreSQL role (%s) not found", roleName))
+	case err != nil:
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading role %q: {{err}}", roleName), err))
+	}
+
+	rows, err := c.DB().Query(roleSettingsQuery, roleName)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading settings of role %q: {{err}}", roleName), err))
+	}
+	defer rows.Close()
+
+	settings := make([]interface{}, 0)
+	for rows.Next() {
+		var database, name, value string
+
+		if err := rows.Scan(&database, &name, &value); err != nil {
+			return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading settings of role %q: {{err}}", roleName), err))
+		}
+
+		settings = append(settings, map[string]interface{}{
+			dataRoleSettingDatabaseAttr: database,
+			dataRoleSettingNameAttr:     name,
+			dataRoleSettingValueAttr:    value,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading settings of role %q: {{err}}", roleName), err))
+	}
+
+	d.Set(roleSuperuserAttr, superuser)
+	d.Set(roleInheritAttr, inherit)
+	d.Set(roleCreateRoleAttr, createRole)
+	d.Set(roleCreateDBAttr, createDB)
+	d.Set(roleLoginAttr, canLogin)
+	d.Set(roleReplicationAttr, replication)
+	d.Set(roleBypassRLSAttr, bypassRLS)
+	d.Set(roleConnLimitAttr, connLimit)
+	d.Set(roleValidUntilAttr, validUntil)
+	if err := d.Set(dataRoleRolesAttr, roles); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting role memberships: {{err}}", err))
+	}
+	if err := d.Set(dataRoleSettingsAttr, settings); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting role settings: {{err}}", err))
+	}
+
+	d.SetId(roleName)
+
+	return nil
+}
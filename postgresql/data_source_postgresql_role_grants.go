@@ -0,0 +1,183 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	dataRoleGrantsRoleAttr   = "role"
+	dataRoleGrantsGrantsAttr = "grants"
+
+	dataRoleGrantObjectTypeAttr = "object_type"
+	dataRoleGrantSchemaAttr     = "schema"
+	dataRoleGrantObjectAttr     = "object_name"
+	dataRoleGrantColumnAttr     = "column_name"
+	dataRoleGrantPrivilegeAttr  = "privilege"
+	dataRoleGrantGrantableAttr  = "with_grant_option"
+	dataRoleGrantGrantorAttr    = "grantor"
+)
+
+func dataSourcePostgreSQLRoleGrants() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLRoleGrantsRead,
+
+		Schema: map[string]*schema.Schema{
+			dataRoleGrantsRoleAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The role whose privileges are listed (PUBLIC for privileges granted to everyone)",
+			},
+			dataRoleGrantsGrantsAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataRoleGrantObjectTypeAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataRoleGrantSchemaAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataRoleGrantObjectAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataRoleGrantColumnAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The column of the table the privilege is granted on, for column privileges",
+						},
+						dataRoleGrantPrivilegeAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataRoleGrantGrantableAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						dataRoleGrantGrantorAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// roleGrantsQuery returns the query exploding the ACLs of every database,
+// foreign data wrapper, foreign server and large object, and of the schemas,
+// relations, columns, routines and types of the current database, for the
+// role of OID $1.  Objects without an ACL have the default privileges of
+// their kind, e.g. EXECUTE on functions for PUBLIC, while columns have none.
+// The set returning aclexplode() is called in the target list rather than
+// laterally so that the query works on servers older than 9.3.
+func roleGrantsQuery(c *Client) string {
+	acls := []string{
+		`SELECT 'database'::TEXT AS object_type, ''::TEXT AS schema_name, d.datname::TEXT AS object_name, ''::TEXT AS column_name, ` +
+			`pg_catalog.aclexplode(COALESCE(d.datacl, pg_catalog.acldefault('d', d.datdba))) AS a ` +
+			`FROM pg_catalog.pg_database d`,
+		`SELECT 'schema', '', n.nspname, '', pg_catalog.aclexplode(COALESCE(n.nspacl, pg_catalog.acldefault('n', n.nspowner))) ` +
+			`FROM pg_catalog.pg_namespace n WHERE ` + userSchemasCond("n.nspname"),
+		`SELECT CASE c.relkind WHEN 'S' THEN 'sequence' ELSE 'table' END, n.nspname, c.relname, '', ` +
+			`pg_catalog.aclexplode(COALESCE(c.relacl, pg_catalog.acldefault(CASE c.relkind WHEN 'S' THEN 's' ELSE 'r' END::"char", c.relowner))) ` +
+			`FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+			`WHERE c.relkind IN ('r', 'v', 'm', 'f', 'p', 'S') AND ` + userSchemasCond("n.nspname"),
+		`SELECT 'column', n.nspname, c.relname, a.attname, pg_catalog.aclexplode(a.attacl) ` +
+			`FROM pg_catalog.pg_attribute a JOIN pg_catalog.pg_class c ON c.oid = a.attrelid ` +
+			`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+			`WHERE c.relkind IN ('r', 'v', 'm', 'f', 'p') AND a.attnum > 0 AND NOT a.attisdropped AND a.attacl IS NOT NULL AND ` + userSchemasCond("n.nspname"),
+		`SELECT 'function', n.nspname, p.proname || '(' || pg_catalog.pg_get_function_identity_arguments(p.oid) || ')', '', ` +
+			`pg_catalog.aclexplode(COALESCE(p.proacl, pg_catalog.acldefault('f', p.proowner))) ` +
+			`FROM pg_catalog.pg_proc p JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace ` +
+			`WHERE ` + userSchemasCond("n.nspname"),
+		`SELECT 'foreign_data_wrapper', '', w.fdwname, '', pg_catalog.aclexplode(COALESCE(w.fdwacl, pg_catalog.acldefault('F', w.fdwowner))) ` +
+			`FROM pg_catalog.pg_foreign_data_wrapper w`,
+		`SELECT 'foreign_server', '', s.srvname, '', pg_catalog.aclexplode(COALESCE(s.srvacl, pg_catalog.acldefault('S', s.srvowner))) ` +
+			`FROM pg_catalog.pg_foreign_server s`,
+	}
+	// Array types and the row types of relations have no privileges of
+	// their own.
+	if c.featureSupported(featureTypePrivileges) {
+		acls = append(acls, `SELECT CASE t.typtype WHEN 'd' THEN 'domain' ELSE 'type' END, n.nspname, t.typname, '', `+
+			`pg_catalog.aclexplode(COALESCE(t.typacl, pg_catalog.acldefault('T', t.typowner))) `+
+			`FROM pg_catalog.pg_type t JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace `+
+			`WHERE t.typcategory <> 'A' AND (t.typrelid = 0 OR `+
+			`(SELECT c.relkind FROM pg_catalog.pg_class c WHERE c.oid = t.typrelid) = 'c') AND `+userSchemasCond("n.nspname"))
+	}
+	if c.featureSupported(featureLargeObjectPrivileges) {
+		acls = append(acls, `SELECT 'large_object', '', l.oid::TEXT, '', `+
+			`pg_catalog.aclexplode(COALESCE(l.lomacl, pg_catalog.acldefault('L', l.lomowner))) `+
+			`FROM pg_catalog.pg_largeobject_metadata l`)
+	}
+
+	return `SELECT object_type, schema_name, object_name, column_name, (a).privilege_type, (a).is_grantable, ` +
+		`pg_catalog.pg_get_userbyid((a).grantor) ` +
+		`FROM (` + strings.Join(acls, " UNION ALL ") + `) AS acls ` +
+		`WHERE (a).grantee = $1 ` +
+		`ORDER BY 1, 2, 3, 4, 5`
+}
+
+func dataSourcePostgreSQLRoleGrantsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	roleName := d.Get(dataRoleGrantsRoleAttr).(string)
+
+	// PUBLIC is represented by the grantee OID 0 in ACLs.
+	var roleOID int64
+	if strings.ToUpper(roleName) != "PUBLIC" {
+		err := c.DB().QueryRow("SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $1", roleName).Scan(&roleOID)
+		switch {
+		case err == sql.ErrNoRows:
+			return diagnostics(fmt.Errorf("PostgreSQL role (%s) not found", roleName))
+		case err != nil:
+			return diagnostics(errwrap.Wrapf("Error reading role: {{err}}", err))
+		}
+	}
+
+	rows, err := c.DB().Query(roleGrantsQuery(c), roleOID)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading grants of role %q: {{err}}", roleName), err))
+	}
+	defer rows.Close()
+
+	grants := make([]interface{}, 0)
+	for rows.Next() {
+		var objectType, schemaName, objectName, columnName, privilege, grantor string
+		var grantable bool
+
+		if err := rows.Scan(&objectType, &schemaName, &objectName, &columnName, &privilege, &grantable, &grantor); err != nil {
+			return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading grants of role %q: {{err}}", roleName), err))
+		}
+
+		grants = append(grants, map[string]interface{}{
+			dataRoleGrantObjectTypeAttr: objectType,
+			dataRoleGrantSchemaAttr:     schemaName,
+			dataRoleGrantObjectAttr:     objectName,
+			dataRoleGrantColumnAttr:     columnName,
+			dataRoleGrantPrivilegeAttr:  privilege,
+			dataRoleGrantGrantableAttr:  grantable,
+			dataRoleGrantGrantorAttr:    grantor,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading grants of role %q: {{err}}", roleName), err))
+	}
+
+	if err := d.Set(dataRoleGrantsGrantsAttr, grants); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting grants: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("role_grants", roleName))
+
+	return nil
+}
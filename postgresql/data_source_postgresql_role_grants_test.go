@@ -0,0 +1,92 @@
+package postgresql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestRoleGrantsQuery(t *testing.T) {
+	version := semver.MustParse("13.0.0")
+	tests := []struct {
+		client   *Client
+		included []string
+		excluded []string
+	}{
+		{
+			client:   &Client{version: version},
+			included: []string{"acldefault('d', d.datdba)", "a.attacl", "w.fdwacl", "s.srvacl", "t.typacl", "l.lomacl"},
+		},
+		{
+			client:   &Client{version: version, cockroach: true},
+			included: []string{"t.typacl"},
+			excluded: []string{"l.lomacl"},
+		},
+		{
+			client:   &Client{version: semver.MustParse("9.1.0")},
+			excluded: []string{"t.typacl"},
+		},
+	}
+
+	for _, test := range tests {
+		query := roleGrantsQuery(test.client)
+		for _, s := range test.included {
+			if !strings.Contains(query, s) {
+				t.Errorf("%v: expected the query to read %s", test.client.version, s)
+			}
+		}
+		for _, s := range test.excluded {
+			if strings.Contains(query, s) {
+				t.Errorf("%v: expected the query not to read %s", test.client.version, s)
+			}
+		}
+	}
+}
+
+func TestAccPostgresqlDataSourceRoleGrants_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceRoleGrantsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role_grants.grants", "grants.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role_grants.grants", "grants.0.object_type", "schema"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role_grants.grants", "grants.0.object_name", "ds_role_grants"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role_grants.grants", "grants.0.privilege", "USAGE"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role_grants.grants", "grants.0.with_grant_option", "false"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceRoleGrantsConfig = `
+resource "postgresql_role" "grantee" {
+  name = "ds_role_grants_grantee"
+}
+
+resource "postgresql_schema" "schema" {
+  name = "ds_role_grants"
+
+  policy {
+    usage = true
+    role  = postgresql_role.grantee.name
+  }
+}
+
+data "postgresql_role_grants" "grants" {
+  role = postgresql_role.grantee.name
+
+  depends_on = [postgresql_schema.schema]
+}
+`
@@ -0,0 +1,64 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceRole_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP ROLE IF EXISTS ds_role_app",
+		"DROP ROLE IF EXISTS ds_role_group",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE ROLE ds_role_group",
+				"CREATE ROLE ds_role_app LOGIN CONNECTION LIMIT 5 IN ROLE ds_role_group",
+				"ALTER ROLE ds_role_app SET statement_timeout = '5s'",
+				"ALTER ROLE ds_role_app IN DATABASE postgres SET search_path = app, public",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceRoleConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role.app", "login", "true"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role.app", "superuser", "false"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role.app", "connection_limit", "5"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role.app", "valid_until", "infinity"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role.app", "roles.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role.app", "roles.0", "ds_role_group"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role.app", "settings.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role.app", "settings.0.database", ""),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role.app", "settings.0.name", "statement_timeout"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role.app", "settings.0.value", "5s"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role.app", "settings.1.database", "postgres"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_role.app", "settings.1.value", "app, public"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceRoleConfig = `
+data "postgresql_role" "app" {
+  name = "ds_role_app"
+}
+`
@@ -0,0 +1,99 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	dataSequenceValueSchemaAttr    = "schema"
+	dataSequenceValueNameAttr      = "name"
+	dataSequenceValueLastAttr      = "last_value"
+	dataSequenceValueIsCalledAttr  = "is_called"
+	dataSequenceValueNextAttr      = "next_value"
+	dataSequenceValueIncrementAttr = "increment"
+)
+
+func dataSourcePostgreSQLSequenceValue() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLSequenceValueRead,
+
+		Schema: map[string]*schema.Schema{
+			dataSequenceValueSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "public",
+				Description: "The schema of the sequence",
+			},
+			dataSequenceValueNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the sequence",
+			},
+			dataSequenceValueLastAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The last value returned by nextval(), or the start value if is_called is false",
+			},
+			dataSequenceValueIsCalledAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether nextval() has been called since the sequence was created or last reset",
+			},
+			dataSequenceValueNextAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The value the next call to nextval() will return",
+			},
+			dataSequenceValueIncrementAttr: {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLSequenceValueRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	schemaName := d.Get(dataSequenceValueSchemaAttr).(string)
+	seqName := d.Get(dataSequenceValueNameAttr).(string)
+
+	var increment int64
+	err := c.DB().QueryRow(`SELECT s.increment::BIGINT FROM information_schema.sequences s `+
+		`WHERE s.sequence_schema = $1 AND s.sequence_name = $2`, schemaName, seqName).Scan(&increment)
+	switch {
+	case err == sql.ErrNoRows:
+		return diagnostics(fmt.Errorf("PostgreSQL sequence (%s.%s) not found", schemaName, seqName))
+	case err != nil:
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading sequence %s.%s: {{err}}", schemaName, seqName), err))
+	}
+
+	// Reading the sequence relation itself, unlike nextval(), does not
+	// consume a value.
+	var lastValue int64
+	var isCalled bool
+	query := fmt.Sprintf("SELECT last_value, is_called FROM %s.%s", pq.QuoteIdentifier(schemaName), pq.QuoteIdentifier(seqName))
+	if err := c.DB().QueryRow(query).Scan(&lastValue, &isCalled); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading value of sequence %s.%s: {{err}}", schemaName, seqName), err))
+	}
+
+	nextValue := lastValue
+	if isCalled {
+		nextValue += increment
+	}
+
+	d.Set(dataSequenceValueLastAttr, int(lastValue))
+	d.Set(dataSequenceValueIsCalledAttr, isCalled)
+	d.Set(dataSequenceValueNextAttr, int(nextValue))
+	d.Set(dataSequenceValueIncrementAttr, int(increment))
+
+	d.SetId(dataSourceID("sequence_value", schemaName, seqName))
+
+	return nil
+}
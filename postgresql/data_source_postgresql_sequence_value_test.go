@@ -0,0 +1,55 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceSequenceValue_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t, "DROP SCHEMA IF EXISTS ds_sequence_value CASCADE")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE SCHEMA ds_sequence_value",
+				"CREATE SEQUENCE ds_sequence_value.fresh START 100",
+				"CREATE SEQUENCE ds_sequence_value.used INCREMENT 10",
+				"SELECT setval('ds_sequence_value.used', 500)",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceSequenceValueConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_sequence_value.fresh", "last_value", "100"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_sequence_value.fresh", "is_called", "false"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_sequence_value.fresh", "next_value", "100"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_sequence_value.used", "last_value", "500"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_sequence_value.used", "is_called", "true"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_sequence_value.used", "next_value", "510"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceSequenceValueConfig = `
+data "postgresql_sequence_value" "fresh" {
+  schema = "ds_sequence_value"
+  name   = "fresh"
+}
+
+data "postgresql_sequence_value" "used" {
+  schema = "ds_sequence_value"
+  name   = "used"
+}
+`
@@ -0,0 +1,165 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	dataSequencesSchemaAttr = "schema"
+	dataSequencesAttr       = "sequences"
+
+	dataSequenceNameAttr          = "name"
+	dataSequenceOwnerAttr         = "owner"
+	dataSequenceDataTypeAttr      = "data_type"
+	dataSequenceStartAttr         = "start_value"
+	dataSequenceMinAttr           = "min_value"
+	dataSequenceMaxAttr           = "max_value"
+	dataSequenceIncrementAttr     = "increment"
+	dataSequenceCycleAttr         = "cycle"
+	dataSequenceOwnedByTableAttr  = "owned_by_table"
+	dataSequenceOwnedByColumnAttr = "owned_by_column"
+)
+
+func dataSourcePostgreSQLSequences() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLSequencesRead,
+
+		Schema: map[string]*schema.Schema{
+			dataSequencesSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "public",
+				Description: "The schema to list sequences from",
+			},
+			dataSequencesAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataSequenceNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataSequenceOwnerAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataSequenceDataTypeAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataSequenceStartAttr: {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						dataSequenceMinAttr: {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						dataSequenceMaxAttr: {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						dataSequenceIncrementAttr: {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						dataSequenceCycleAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						dataSequenceOwnedByTableAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The table owning the sequence, if any",
+						},
+						dataSequenceOwnedByColumnAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The column owning the sequence, if any",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// sequencesListQuery returns the query selecting the sequences of the schema
+// $1, with their parameters from pg_sequence, or from
+// information_schema.sequences before PostgreSQL 10, which only lists the
+// sequences the user has a privilege on.
+//
+// NOTE: a sequence and the table owning it always live in the same schema, so
+// only the table and column names are reported.
+func sequencesListQuery(c *Client) string {
+	params := `pg_catalog.format_type(s.seqtypid, NULL), s.seqstart, s.seqmin, s.seqmax, s.seqincrement, s.seqcycle`
+	join := `JOIN pg_catalog.pg_sequence s ON s.seqrelid = c.oid `
+	if !c.featureSupported(featureSequenceCatalog) {
+		params = `s.data_type, s.start_value::BIGINT, s.minimum_value::BIGINT, s.maximum_value::BIGINT, s.increment::BIGINT, s.cycle_option = 'YES'`
+		join = `JOIN information_schema.sequences s ON s.sequence_schema = n.nspname AND s.sequence_name = c.relname `
+	}
+
+	return `SELECT c.relname, pg_catalog.pg_get_userbyid(c.relowner), ` + params + `, ` +
+		`COALESCE(tc.relname, ''), COALESCE(a.attname, '') ` +
+		`FROM pg_catalog.pg_class c ` +
+		`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+		join +
+		`LEFT JOIN pg_catalog.pg_depend dep ON dep.classid = 'pg_catalog.pg_class'::regclass ` +
+		`AND dep.objid = c.oid AND dep.refclassid = 'pg_catalog.pg_class'::regclass AND dep.deptype IN ('a', 'i') ` +
+		`LEFT JOIN pg_catalog.pg_class tc ON tc.oid = dep.refobjid ` +
+		`LEFT JOIN pg_catalog.pg_attribute a ON a.attrelid = dep.refobjid AND a.attnum = dep.refobjsubid ` +
+		`WHERE c.relkind = 'S' AND n.nspname = $1 ` +
+		`ORDER BY c.relname`
+}
+
+func dataSourcePostgreSQLSequencesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	schemaName := d.Get(dataSequencesSchemaAttr).(string)
+
+	rows, err := c.DB().Query(sequencesListQuery(c), schemaName)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading sequences in schema %q: {{err}}", schemaName), err))
+	}
+	defer rows.Close()
+
+	sequences := make([]interface{}, 0)
+	for rows.Next() {
+		var name, owner, dataType, ownedByTable, ownedByColumn string
+		var start, min, max, increment int64
+		var cycle bool
+
+		if err := rows.Scan(&name, &owner, &dataType, &start, &min, &max, &increment, &cycle, &ownedByTable, &ownedByColumn); err != nil {
+			return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading sequences in schema %q: {{err}}", schemaName), err))
+		}
+
+		sequences = append(sequences, map[string]interface{}{
+			dataSequenceNameAttr:          name,
+			dataSequenceOwnerAttr:         owner,
+			dataSequenceDataTypeAttr:      dataType,
+			dataSequenceStartAttr:         int(start),
+			dataSequenceMinAttr:           int(min),
+			dataSequenceMaxAttr:           int(max),
+			dataSequenceIncrementAttr:     int(increment),
+			dataSequenceCycleAttr:         cycle,
+			dataSequenceOwnedByTableAttr:  ownedByTable,
+			dataSequenceOwnedByColumnAttr: ownedByColumn,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading sequences in schema %q: {{err}}", schemaName), err))
+	}
+
+	if err := d.Set(dataSequencesAttr, sequences); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting sequences: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("sequences", schemaName))
+
+	return nil
+}
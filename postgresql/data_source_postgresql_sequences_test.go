@@ -0,0 +1,65 @@
+package postgresql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestSequencesListQuery(t *testing.T) {
+	if query := sequencesListQuery(&Client{version: semver.MustParse("10.0.0")}); !strings.Contains(query, "pg_catalog.pg_sequence") {
+		t.Errorf("expected pg_sequence to be read on PostgreSQL 10, got %q", query)
+	}
+	if query := sequencesListQuery(&Client{version: semver.MustParse("9.6.0")}); !strings.Contains(query, "information_schema.sequences") {
+		t.Errorf("expected information_schema.sequences to be read before PostgreSQL 10, got %q", query)
+	}
+}
+
+func TestAccPostgresqlDataSourceSequences_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t, "DROP SCHEMA IF EXISTS ds_sequences CASCADE")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE SCHEMA ds_sequences",
+				"CREATE TABLE ds_sequences.items (id SERIAL PRIMARY KEY)",
+				"CREATE SEQUENCE ds_sequences.standalone INCREMENT BY 5 START WITH 10 CYCLE",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceSequencesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_sequences.seqs", "sequences.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_sequences.seqs", "sequences.0.name", "items_id_seq"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_sequences.seqs", "sequences.0.owned_by_table", "items"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_sequences.seqs", "sequences.0.owned_by_column", "id"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_sequences.seqs", "sequences.1.name", "standalone"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_sequences.seqs", "sequences.1.start_value", "10"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_sequences.seqs", "sequences.1.increment", "5"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_sequences.seqs", "sequences.1.cycle", "true"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_sequences.seqs", "sequences.1.owned_by_table", ""),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceSequencesConfig = `
+data "postgresql_sequences" "seqs" {
+  schema = "ds_sequences"
+}
+`
@@ -0,0 +1,97 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	dataServerVersionAttr      = "server_version"
+	dataServerVersionNumAttr   = "server_version_num"
+	dataServerVersionMajorAttr = "major_version"
+	dataServerVersionFullAttr  = "version"
+)
+
+// serverCapabilities maps the capability attributes exported by the
+// postgresql_server_version data source to the feature they are derived from.
+var serverCapabilities = map[string]featureName{
+	"supports_generated_columns":   featureGeneratedColumns,
+	"supports_identity_columns":    featureIdentityColumns,
+	"supports_logical_replication": featureLogicalReplication,
+	"supports_partitioning":        featureDeclarativePartitioning,
+	"supports_procedures":          featureProKind,
+	"supports_row_level_security":  featureRLS,
+	"supports_scram":               featureSCRAM,
+}
+
+func dataSourcePostgreSQLServerVersion() *schema.Resource {
+	s := map[string]*schema.Schema{
+		dataServerVersionAttr: {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The server version as reported by SHOW server_version",
+		},
+		dataServerVersionNumAttr: {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "The server version as an integer, e.g. 100004",
+		},
+		dataServerVersionMajorAttr: {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The major version of the server, e.g. 9.6 or 10",
+		},
+		dataServerVersionFullAttr: {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The full version string as reported by SELECT VERSION()",
+		},
+	}
+	for attr := range serverCapabilities {
+		s[attr] = &schema.Schema{
+			Type:     schema.TypeBool,
+			Computed: true,
+		}
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLServerVersionRead,
+		Schema:      s,
+	}
+}
+
+func dataSourcePostgreSQLServerVersionRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+
+	var serverVersion, fullVersion string
+	var serverVersionNum int
+	err := c.DB().QueryRow(`SELECT pg_catalog.current_setting('server_version'), `+
+		`pg_catalog.current_setting('server_version_num')::INTEGER, VERSION()`).
+		Scan(&serverVersion, &serverVersionNum, &fullVersion)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading server version: {{err}}", err))
+	}
+
+	// Before PostgreSQL 10 the major version was made of the first two
+	// components: 90603 is 9.6.3, 100004 is 10.4.
+	major := fmt.Sprintf("%d", serverVersionNum/10000)
+	if serverVersionNum < 100000 {
+		major = fmt.Sprintf("%d.%d", serverVersionNum/10000, serverVersionNum/100%100)
+	}
+
+	d.Set(dataServerVersionAttr, serverVersion)
+	d.Set(dataServerVersionNumAttr, serverVersionNum)
+	d.Set(dataServerVersionMajorAttr, major)
+	d.Set(dataServerVersionFullAttr, fullVersion)
+	for attr, feature := range serverCapabilities {
+		d.Set(attr, c.featureSupported(feature))
+	}
+
+	d.SetId(serverVersion)
+
+	return nil
+}
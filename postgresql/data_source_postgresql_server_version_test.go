@@ -0,0 +1,33 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceServerVersion_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceServerVersionConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(
+						"data.postgresql_server_version.current", "server_version"),
+					resource.TestCheckResourceAttrSet(
+						"data.postgresql_server_version.current", "server_version_num"),
+					resource.TestCheckResourceAttrSet(
+						"data.postgresql_server_version.current", "major_version"),
+					resource.TestCheckResourceAttrSet(
+						"data.postgresql_server_version.current", "supports_row_level_security"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceServerVersionConfig = `
+data "postgresql_server_version" "current" {}
+`
@@ -0,0 +1,150 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	dataSettingsNamePatternAttr = "name_pattern"
+	dataSettingsAttr            = "settings"
+
+	dataSettingNameAttr           = "name"
+	dataSettingValueAttr          = "setting"
+	dataSettingUnitAttr           = "unit"
+	dataSettingCategoryAttr       = "category"
+	dataSettingContextAttr        = "context"
+	dataSettingTypeAttr           = "vartype"
+	dataSettingSourceAttr         = "source"
+	dataSettingBootValueAttr      = "boot_val"
+	dataSettingResetValueAttr     = "reset_val"
+	dataSettingPendingRestartAttr = "pending_restart"
+)
+
+func dataSourcePostgreSQLSettings() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLSettingsRead,
+
+		Schema: map[string]*schema.Schema{
+			dataSettingsNamePatternAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return settings whose name matches this LIKE pattern",
+			},
+			dataSettingsAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataSettingNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataSettingValueAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataSettingUnitAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataSettingCategoryAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataSettingContextAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataSettingTypeAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataSettingSourceAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataSettingBootValueAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataSettingResetValueAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataSettingPendingRestartAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLSettingsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+
+	pendingRestartExpr := "FALSE"
+	if c.featureSupported(featureSettingPendingRestart) {
+		pendingRestartExpr = "pending_restart"
+	}
+
+	b := bytes.NewBufferString(`SELECT name, COALESCE(setting, ''), COALESCE(unit, ''), category, context, vartype, ` +
+		`source, COALESCE(boot_val, ''), COALESCE(reset_val, ''), `)
+	fmt.Fprint(b, pendingRestartExpr, " FROM pg_catalog.pg_settings")
+
+	args := []interface{}{}
+	namePattern := d.Get(dataSettingsNamePatternAttr).(string)
+	if namePattern != "" {
+		args = append(args, namePattern)
+		fmt.Fprintf(b, " WHERE name LIKE $%d", len(args))
+	}
+	fmt.Fprint(b, " ORDER BY name")
+
+	rows, err := c.DB().Query(b.String(), args...)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading settings: {{err}}", err))
+	}
+	defer rows.Close()
+
+	settings := make([]interface{}, 0)
+	for rows.Next() {
+		var name, setting, unit, category, context, vartype, source, bootVal, resetVal string
+		var pendingRestart bool
+
+		if err := rows.Scan(&name, &setting, &unit, &category, &context, &vartype, &source, &bootVal, &resetVal, &pendingRestart); err != nil {
+			return diagnostics(errwrap.Wrapf("Error reading settings: {{err}}", err))
+		}
+
+		settings = append(settings, map[string]interface{}{
+			dataSettingNameAttr:           name,
+			dataSettingValueAttr:          setting,
+			dataSettingUnitAttr:           unit,
+			dataSettingCategoryAttr:       category,
+			dataSettingContextAttr:        context,
+			dataSettingTypeAttr:           vartype,
+			dataSettingSourceAttr:         source,
+			dataSettingBootValueAttr:      bootVal,
+			dataSettingResetValueAttr:     resetVal,
+			dataSettingPendingRestartAttr: pendingRestart,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading settings: {{err}}", err))
+	}
+
+	if err := d.Set(dataSettingsAttr, settings); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting settings: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("settings", namePattern))
+
+	return nil
+}
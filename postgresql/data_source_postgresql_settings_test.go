@@ -0,0 +1,39 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceSettings_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceSettingsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_settings.max_conns", "settings.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_settings.max_conns", "settings.0.name", "max_connections"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_settings.max_conns", "settings.0.context", "postmaster"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_settings.max_conns", "settings.0.vartype", "integer"),
+					resource.TestCheckResourceAttrSet(
+						"data.postgresql_settings.max_conns", "settings.0.setting"),
+					resource.TestCheckResourceAttrSet(
+						"data.postgresql_settings.max_conns", "settings.0.boot_val"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceSettingsConfig = `
+data "postgresql_settings" "max_conns" {
+  name_pattern = "max\\_connections"
+}
+`
@@ -0,0 +1,152 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	dataTableConstraintsSchemaAttr = "schema"
+	dataTableConstraintsTableAttr  = "table"
+	dataTableConstraintsAttr       = "constraints"
+
+	dataConstraintNameAttr              = "name"
+	dataConstraintTypeAttr              = "type"
+	dataConstraintDefinitionAttr        = "definition"
+	dataConstraintColumnsAttr           = "columns"
+	dataConstraintReferencedTableAttr   = "referenced_table"
+	dataConstraintValidatedAttr         = "validated"
+	dataConstraintDeferrableAttr        = "deferrable"
+	dataConstraintInitiallyDeferredAttr = "initially_deferred"
+)
+
+func dataSourcePostgreSQLTableConstraints() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLTableConstraintsRead,
+
+		Schema: map[string]*schema.Schema{
+			dataTableConstraintsSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "public",
+				Description: "The schema of the table",
+			},
+			dataTableConstraintsTableAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The table to list constraints from",
+			},
+			dataTableConstraintsAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataConstraintNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataConstraintTypeAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "One of check, exclusion, foreign_key, primary_key, trigger or unique",
+						},
+						dataConstraintDefinitionAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataConstraintColumnsAttr: {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						dataConstraintReferencedTableAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The schema-qualified table referenced by a foreign key",
+						},
+						dataConstraintValidatedAttr: {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "False if the constraint was added NOT VALID and not validated since",
+						},
+						dataConstraintDeferrableAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						dataConstraintInitiallyDeferredAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// NOTE: generate_subscripts() is used to keep the columns in their constraint
+// order because WITH ORDINALITY is not available before 9.4.
+const tableConstraintsQuery = `SELECT co.conname, ` +
+	`CASE co.contype WHEN 'c' THEN 'check' WHEN 'f' THEN 'foreign_key' WHEN 'p' THEN 'primary_key' ` +
+	`WHEN 'u' THEN 'unique' WHEN 't' THEN 'trigger' WHEN 'x' THEN 'exclusion' ELSE co.contype::TEXT END, ` +
+	`pg_catalog.pg_get_constraintdef(co.oid), ` +
+	`ARRAY(SELECT a.attname FROM pg_catalog.generate_subscripts(co.conkey, 1) AS i ` +
+	`JOIN pg_catalog.pg_attribute a ON a.attrelid = co.conrelid AND a.attnum = co.conkey[i] ORDER BY i), ` +
+	`COALESCE(fn.nspname || '.' || fc.relname, ''), co.convalidated, co.condeferrable, co.condeferred ` +
+	`FROM pg_catalog.pg_constraint co ` +
+	`JOIN pg_catalog.pg_class c ON c.oid = co.conrelid ` +
+	`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+	`LEFT JOIN pg_catalog.pg_class fc ON fc.oid = co.confrelid ` +
+	`LEFT JOIN pg_catalog.pg_namespace fn ON fn.oid = fc.relnamespace ` +
+	`WHERE n.nspname = $1 AND c.relname = $2 ` +
+	`ORDER BY co.conname`
+
+func dataSourcePostgreSQLTableConstraintsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	schemaName := d.Get(dataTableConstraintsSchemaAttr).(string)
+	tableName := d.Get(dataTableConstraintsTableAttr).(string)
+
+	rows, err := c.DB().Query(tableConstraintsQuery, schemaName, tableName)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading constraints of table %s.%s: {{err}}", schemaName, tableName), err))
+	}
+	defer rows.Close()
+
+	constraints := make([]interface{}, 0)
+	for rows.Next() {
+		var name, conType, definition, referencedTable string
+		var columns []string
+		var validated, deferrable, deferred bool
+
+		if err := rows.Scan(&name, &conType, &definition, pq.Array(&columns), &referencedTable, &validated, &deferrable, &deferred); err != nil {
+			return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading constraints of table %s.%s: {{err}}", schemaName, tableName), err))
+		}
+
+		constraints = append(constraints, map[string]interface{}{
+			dataConstraintNameAttr:              name,
+			dataConstraintTypeAttr:              conType,
+			dataConstraintDefinitionAttr:        definition,
+			dataConstraintColumnsAttr:           columns,
+			dataConstraintReferencedTableAttr:   referencedTable,
+			dataConstraintValidatedAttr:         validated,
+			dataConstraintDeferrableAttr:        deferrable,
+			dataConstraintInitiallyDeferredAttr: deferred,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading constraints of table %s.%s: {{err}}", schemaName, tableName), err))
+	}
+
+	if err := d.Set(dataTableConstraintsAttr, constraints); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting constraints: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("table_constraints", schemaName, tableName))
+
+	return nil
+}
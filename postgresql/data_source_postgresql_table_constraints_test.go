@@ -0,0 +1,59 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceTableConstraints_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t, "DROP SCHEMA IF EXISTS ds_constraints CASCADE")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE SCHEMA ds_constraints",
+				"CREATE TABLE ds_constraints.accounts (id INT PRIMARY KEY)",
+				"CREATE TABLE ds_constraints.orders (id INT, account_id INT, amount INT, PRIMARY KEY (account_id, id))",
+				"ALTER TABLE ds_constraints.orders ADD CONSTRAINT orders_account_fk FOREIGN KEY (account_id) REFERENCES ds_constraints.accounts DEFERRABLE INITIALLY DEFERRED",
+				"ALTER TABLE ds_constraints.orders ADD CONSTRAINT orders_positive CHECK (amount > 0) NOT VALID",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceTableConstraintsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_table_constraints.orders", "constraints.#", "3"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_table_constraints.orders", "constraints.0.name", "orders_account_fk"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_table_constraints.orders", "constraints.0.type", "foreign_key"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_table_constraints.orders", "constraints.0.referenced_table", "ds_constraints.accounts"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_table_constraints.orders", "constraints.0.initially_deferred", "true"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_table_constraints.orders", "constraints.1.type", "primary_key"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_table_constraints.orders", "constraints.1.columns.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_table_constraints.orders", "constraints.1.columns.0", "account_id"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_table_constraints.orders", "constraints.2.name", "orders_positive"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_table_constraints.orders", "constraints.2.validated", "false"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceTableConstraintsConfig = `
+data "postgresql_table_constraints" "orders" {
+  schema = "ds_constraints"
+  table  = "orders"
+}
+`
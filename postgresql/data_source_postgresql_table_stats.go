@@ -0,0 +1,175 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	dataTableStatsSchemaAttr = "schema"
+	dataTableStatsAttr       = "tables"
+
+	dataTableStatNameAttr            = "name"
+	dataTableStatSchemaAttr          = "schema"
+	dataTableStatTotalSizeAttr       = "total_size"
+	dataTableStatTableSizeAttr       = "table_size"
+	dataTableStatRowEstimateAttr     = "row_estimate"
+	dataTableStatLiveTuplesAttr      = "live_tuples"
+	dataTableStatDeadTuplesAttr      = "dead_tuples"
+	dataTableStatDeadTupleRatioAttr  = "dead_tuple_ratio"
+	dataTableStatLastVacuumAttr      = "last_vacuum"
+	dataTableStatLastAutovacuumAttr  = "last_autovacuum"
+	dataTableStatLastAnalyzeAttr     = "last_analyze"
+	dataTableStatLastAutoanalyzeAttr = "last_autoanalyze"
+)
+
+func dataSourcePostgreSQLTableStats() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLTableStatsRead,
+
+		Schema: map[string]*schema.Schema{
+			dataTableStatsSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The schema to report tables from (default: all non-system schemas)",
+			},
+			dataTableStatsAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataTableStatNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataTableStatSchemaAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataTableStatTotalSizeAttr: {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Size of the table including indexes and TOAST, in bytes",
+						},
+						dataTableStatTableSizeAttr: {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Size of the table's main fork, in bytes",
+						},
+						dataTableStatRowEstimateAttr: {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The planner's estimate of the number of rows",
+						},
+						dataTableStatLiveTuplesAttr: {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						dataTableStatDeadTuplesAttr: {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						dataTableStatDeadTupleRatioAttr: {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: "Fraction of dead tuples, an approximation of the table's bloat",
+						},
+						dataTableStatLastVacuumAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataTableStatLastAutovacuumAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataTableStatLastAnalyzeAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataTableStatLastAutoanalyzeAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLTableStatsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+
+	b := bytes.NewBufferString(`SELECT s.relname, s.schemaname, ` +
+		`pg_catalog.pg_total_relation_size(s.relid), pg_catalog.pg_relation_size(s.relid), ` +
+		`c.reltuples::BIGINT, s.n_live_tup, s.n_dead_tup, ` +
+		`s.last_vacuum, s.last_autovacuum, s.last_analyze, s.last_autoanalyze ` +
+		`FROM pg_catalog.pg_stat_user_tables s ` +
+		`JOIN pg_catalog.pg_class c ON c.oid = s.relid `)
+
+	args := []interface{}{}
+	schemaName := d.Get(dataTableStatsSchemaAttr).(string)
+	if schemaName != "" {
+		args = append(args, schemaName)
+		fmt.Fprintf(b, "WHERE s.schemaname = $%d", len(args))
+	} else {
+		fmt.Fprint(b, "WHERE ", userSchemasCond("s.schemaname"))
+	}
+	fmt.Fprint(b, " ORDER BY s.schemaname, s.relname")
+
+	rows, err := c.DB().Query(b.String(), args...)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading table statistics: {{err}}", err))
+	}
+	defer rows.Close()
+
+	tables := make([]interface{}, 0)
+	for rows.Next() {
+		var name, nspName string
+		var totalSize, tableSize, rowEstimate, liveTuples, deadTuples int64
+		var lastVacuum, lastAutovacuum, lastAnalyze, lastAutoanalyze pq.NullTime
+
+		err := rows.Scan(&name, &nspName, &totalSize, &tableSize, &rowEstimate, &liveTuples, &deadTuples,
+			&lastVacuum, &lastAutovacuum, &lastAnalyze, &lastAutoanalyze)
+		if err != nil {
+			return diagnostics(errwrap.Wrapf("Error reading table statistics: {{err}}", err))
+		}
+
+		var deadRatio float64
+		if liveTuples+deadTuples > 0 {
+			deadRatio = float64(deadTuples) / float64(liveTuples+deadTuples)
+		}
+
+		tables = append(tables, map[string]interface{}{
+			dataTableStatNameAttr:            name,
+			dataTableStatSchemaAttr:          nspName,
+			dataTableStatTotalSizeAttr:       int(totalSize),
+			dataTableStatTableSizeAttr:       int(tableSize),
+			dataTableStatRowEstimateAttr:     int(rowEstimate),
+			dataTableStatLiveTuplesAttr:      int(liveTuples),
+			dataTableStatDeadTuplesAttr:      int(deadTuples),
+			dataTableStatDeadTupleRatioAttr:  deadRatio,
+			dataTableStatLastVacuumAttr:      nullTimeToString(lastVacuum),
+			dataTableStatLastAutovacuumAttr:  nullTimeToString(lastAutovacuum),
+			dataTableStatLastAnalyzeAttr:     nullTimeToString(lastAnalyze),
+			dataTableStatLastAutoanalyzeAttr: nullTimeToString(lastAutoanalyze),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading table statistics: {{err}}", err))
+	}
+
+	if err := d.Set(dataTableStatsAttr, tables); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting table statistics: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("table_stats", schemaName))
+
+	return nil
+}
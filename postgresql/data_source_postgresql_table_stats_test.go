@@ -0,0 +1,48 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceTableStats_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t, "DROP SCHEMA IF EXISTS ds_table_stats CASCADE")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE SCHEMA ds_table_stats",
+				"CREATE TABLE ds_table_stats.items AS SELECT n AS id FROM generate_series(1, 1000) AS n",
+				"ANALYZE ds_table_stats.items",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceTableStatsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_table_stats.stats", "tables.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_table_stats.stats", "tables.0.name", "items"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_table_stats.stats", "tables.0.row_estimate", "1000"),
+					resource.TestCheckResourceAttrSet(
+						"data.postgresql_table_stats.stats", "tables.0.total_size"),
+					resource.TestCheckResourceAttrSet(
+						"data.postgresql_table_stats.stats", "tables.0.last_analyze"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_table_stats.stats", "tables.0.last_vacuum", ""),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceTableStatsConfig = `
+data "postgresql_table_stats" "stats" {
+  schema = "ds_table_stats"
+}
+`
@@ -0,0 +1,103 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	dataTablespacesAttr = "tablespaces"
+
+	dataTablespaceNameAttr     = "name"
+	dataTablespaceOwnerAttr    = "owner"
+	dataTablespaceLocationAttr = "location"
+	dataTablespaceSizeAttr     = "size"
+	dataTablespaceOptionsAttr  = "options"
+)
+
+func dataSourcePostgreSQLTablespaces() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLTablespacesRead,
+
+		Schema: map[string]*schema.Schema{
+			dataTablespacesAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataTablespaceNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataTablespaceOwnerAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataTablespaceLocationAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The directory of the tablespace, empty for the built-in tablespaces",
+						},
+						dataTablespaceSizeAttr: {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Size of the tablespace in bytes, or -1 if the connection user lacks the CREATE privilege on it",
+						},
+						dataTablespaceOptionsAttr: {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLTablespacesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	query := `SELECT t.spcname, pg_catalog.pg_get_userbyid(t.spcowner), pg_catalog.pg_tablespace_location(t.oid), ` +
+		`CASE WHEN pg_catalog.has_tablespace_privilege(t.oid, 'CREATE') ` +
+		`THEN pg_catalog.pg_tablespace_size(t.oid) ELSE -1 END, ` +
+		`COALESCE(t.spcoptions, '{}'::TEXT[]) ` +
+		`FROM pg_catalog.pg_tablespace t ORDER BY t.spcname`
+	rows, err := c.DB().Query(query)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading tablespaces: {{err}}", err))
+	}
+	defer rows.Close()
+
+	tablespaces := make([]interface{}, 0)
+	for rows.Next() {
+		var name, owner, location string
+		var size int64
+		var options []string
+
+		if err := rows.Scan(&name, &owner, &location, &size, pq.Array(&options)); err != nil {
+			return diagnostics(errwrap.Wrapf("Error reading tablespaces: {{err}}", err))
+		}
+
+		tablespaces = append(tablespaces, map[string]interface{}{
+			dataTablespaceNameAttr:     name,
+			dataTablespaceOwnerAttr:    owner,
+			dataTablespaceLocationAttr: location,
+			dataTablespaceSizeAttr:     int(size),
+			dataTablespaceOptionsAttr:  options,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading tablespaces: {{err}}", err))
+	}
+
+	if err := d.Set(dataTablespacesAttr, tablespaces); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting tablespaces: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("tablespaces", c.config.Host))
+
+	return nil
+}
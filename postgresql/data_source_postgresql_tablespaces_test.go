@@ -0,0 +1,31 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceTablespaces_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceTablespacesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_tablespaces.all", "tablespaces.0.name", "pg_default"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_tablespaces.all", "tablespaces.0.location", ""),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_tablespaces.all", "tablespaces.1.name", "pg_global"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceTablespacesConfig = `
+data "postgresql_tablespaces" "all" {}
+`
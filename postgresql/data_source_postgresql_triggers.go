@@ -0,0 +1,158 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	dataTriggersSchemaAttr = "schema"
+	dataTriggersTableAttr  = "table"
+	dataTriggersAttr       = "triggers"
+
+	dataTriggerNameAttr     = "name"
+	dataTriggerTableAttr    = "table"
+	dataTriggerTimingAttr   = "timing"
+	dataTriggerEventsAttr   = "events"
+	dataTriggerLevelAttr    = "level"
+	dataTriggerFunctionAttr = "function"
+	dataTriggerEnabledAttr  = "enabled"
+	dataTriggerDisabledAttr = "disabled"
+)
+
+func dataSourcePostgreSQLTriggers() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLTriggersRead,
+
+		Schema: map[string]*schema.Schema{
+			dataTriggersSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "public",
+				Description: "The schema to list triggers from",
+			},
+			dataTriggersTableAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return the triggers of this table",
+			},
+			dataTriggersAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataTriggerNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataTriggerTableAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataTriggerTimingAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "BEFORE, AFTER or INSTEAD OF",
+						},
+						dataTriggerEventsAttr: {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						dataTriggerLevelAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ROW or STATEMENT",
+						},
+						dataTriggerFunctionAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The schema-qualified name of the trigger function",
+						},
+						dataTriggerEnabledAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "When the trigger fires: origin, replica, always or disabled",
+						},
+						dataTriggerDisabledAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// NOTE: the timing, level and events of a trigger are packed in the tgtype
+// bitmask, see TRIGGER_TYPE_* in src/include/catalog/pg_trigger.h.
+func dataSourcePostgreSQLTriggersRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	b := bytes.NewBufferString(`SELECT t.tgname, c.relname, ` +
+		`CASE WHEN t.tgtype & 2 <> 0 THEN 'BEFORE' WHEN t.tgtype & 64 <> 0 THEN 'INSTEAD OF' ELSE 'AFTER' END, ` +
+		`ARRAY(SELECT e.name FROM (VALUES (4, 'INSERT', 1), (16, 'UPDATE', 2), (8, 'DELETE', 3), (32, 'TRUNCATE', 4)) ` +
+		`AS e(bit, name, pos) WHERE t.tgtype & e.bit <> 0 ORDER BY e.pos), ` +
+		`CASE WHEN t.tgtype & 1 <> 0 THEN 'ROW' ELSE 'STATEMENT' END, ` +
+		`pn.nspname || '.' || p.proname, ` +
+		`CASE t.tgenabled WHEN 'O' THEN 'origin' WHEN 'R' THEN 'replica' WHEN 'A' THEN 'always' ELSE 'disabled' END ` +
+		`FROM pg_catalog.pg_trigger t ` +
+		`JOIN pg_catalog.pg_class c ON c.oid = t.tgrelid ` +
+		`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+		`JOIN pg_catalog.pg_proc p ON p.oid = t.tgfoid ` +
+		`JOIN pg_catalog.pg_namespace pn ON pn.oid = p.pronamespace ` +
+		`WHERE NOT t.tgisinternal AND n.nspname = $1`)
+
+	schemaName := d.Get(dataTriggersSchemaAttr).(string)
+	args := []interface{}{schemaName}
+	tableName := d.Get(dataTriggersTableAttr).(string)
+	if tableName != "" {
+		args = append(args, tableName)
+		fmt.Fprintf(b, " AND c.relname = $%d", len(args))
+	}
+	fmt.Fprint(b, " ORDER BY c.relname, t.tgname")
+
+	rows, err := c.DB().Query(b.String(), args...)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading triggers in schema %q: {{err}}", schemaName), err))
+	}
+	defer rows.Close()
+
+	triggers := make([]interface{}, 0)
+	for rows.Next() {
+		var name, table, timing, level, function, enabled string
+		var events []string
+
+		if err := rows.Scan(&name, &table, &timing, pq.Array(&events), &level, &function, &enabled); err != nil {
+			return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading triggers in schema %q: {{err}}", schemaName), err))
+		}
+
+		triggers = append(triggers, map[string]interface{}{
+			dataTriggerNameAttr:     name,
+			dataTriggerTableAttr:    table,
+			dataTriggerTimingAttr:   timing,
+			dataTriggerEventsAttr:   events,
+			dataTriggerLevelAttr:    level,
+			dataTriggerFunctionAttr: function,
+			dataTriggerEnabledAttr:  enabled,
+			dataTriggerDisabledAttr: enabled == "disabled",
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading triggers in schema %q: {{err}}", schemaName), err))
+	}
+
+	if err := d.Set(dataTriggersAttr, triggers); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting triggers: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("triggers", schemaName, tableName))
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceTriggers_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t, "DROP SCHEMA IF EXISTS ds_triggers CASCADE")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE SCHEMA ds_triggers",
+				"CREATE TABLE ds_triggers.items (id INT)",
+				"CREATE FUNCTION ds_triggers.audit() RETURNS TRIGGER LANGUAGE plpgsql AS 'BEGIN RETURN NULL; END'",
+				"CREATE TRIGGER items_audit AFTER INSERT OR UPDATE ON ds_triggers.items FOR EACH ROW EXECUTE PROCEDURE ds_triggers.audit()",
+				"CREATE TRIGGER items_truncate BEFORE TRUNCATE ON ds_triggers.items EXECUTE PROCEDURE ds_triggers.audit()",
+				"ALTER TABLE ds_triggers.items DISABLE TRIGGER items_truncate",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceTriggersConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_triggers.items", "triggers.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_triggers.items", "triggers.0.name", "items_audit"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_triggers.items", "triggers.0.timing", "AFTER"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_triggers.items", "triggers.0.events.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_triggers.items", "triggers.0.events.0", "INSERT"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_triggers.items", "triggers.0.events.1", "UPDATE"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_triggers.items", "triggers.0.level", "ROW"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_triggers.items", "triggers.0.function", "ds_triggers.audit"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_triggers.items", "triggers.0.disabled", "false"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_triggers.items", "triggers.1.timing", "BEFORE"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_triggers.items", "triggers.1.level", "STATEMENT"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_triggers.items", "triggers.1.disabled", "true"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceTriggersConfig = `
+data "postgresql_triggers" "items" {
+  schema = "ds_triggers"
+  table  = "items"
+}
+`
@@ -0,0 +1,305 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	dataTypesSchemaAttr     = "schema"
+	dataTypesEnumsAttr      = "enums"
+	dataTypesDomainsAttr    = "domains"
+	dataTypesCompositesAttr = "composites"
+
+	dataTypeNameAttr  = "name"
+	dataTypeOwnerAttr = "owner"
+
+	dataEnumLabelsAttr = "labels"
+
+	dataDomainBaseTypeAttr  = "base_type"
+	dataDomainNotNullAttr   = "not_null"
+	dataDomainDefaultAttr   = "default"
+	dataDomainChecksAttr    = "check_constraints"
+	dataDomainCheckNameAttr = "name"
+	dataDomainCheckDefAttr  = "definition"
+
+	dataCompositeAttrsAttr = "attributes"
+	dataCompositeAttrName  = "name"
+	dataCompositeAttrType  = "type"
+)
+
+func dataSourcePostgreSQLTypes() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLTypesRead,
+
+		Schema: map[string]*schema.Schema{
+			dataTypesSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "public",
+				Description: "The schema to list types from",
+			},
+			dataTypesEnumsAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataTypeNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataTypeOwnerAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataEnumLabelsAttr: {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The labels of the enum, in sort order",
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			dataTypesDomainsAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataTypeNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataTypeOwnerAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataDomainBaseTypeAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataDomainNotNullAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						dataDomainDefaultAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataDomainChecksAttr: {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									dataDomainCheckNameAttr: {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									dataDomainCheckDefAttr: {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			dataTypesCompositesAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataTypeNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataTypeOwnerAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataCompositeAttrsAttr: {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The attributes of the composite type, in order",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									dataCompositeAttrName: {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									dataCompositeAttrType: {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+const typesEnumsQuery = `SELECT t.typname, pg_catalog.pg_get_userbyid(t.typowner), ` +
+	`ARRAY(SELECT e.enumlabel FROM pg_catalog.pg_enum e WHERE e.enumtypid = t.oid ORDER BY e.enumsortorder) ` +
+	`FROM pg_catalog.pg_type t JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace ` +
+	`WHERE t.typtype = 'e' AND n.nspname = $1 ` +
+	`ORDER BY t.typname`
+
+const typesDomainsQuery = `SELECT t.typname, pg_catalog.pg_get_userbyid(t.typowner), ` +
+	`pg_catalog.format_type(t.typbasetype, t.typtypmod), t.typnotnull, COALESCE(t.typdefault, ''), ` +
+	`ARRAY(SELECT co.conname FROM pg_catalog.pg_constraint co WHERE co.contypid = t.oid AND co.contype = 'c' ORDER BY co.conname), ` +
+	`ARRAY(SELECT pg_catalog.pg_get_constraintdef(co.oid) FROM pg_catalog.pg_constraint co WHERE co.contypid = t.oid AND co.contype = 'c' ORDER BY co.conname) ` +
+	`FROM pg_catalog.pg_type t JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace ` +
+	`WHERE t.typtype = 'd' AND n.nspname = $1 ` +
+	`ORDER BY t.typname`
+
+// NOTE: every table also has a composite row type. Only standalone composite
+// types (CREATE TYPE ... AS) are backed by a relation of kind 'c'.
+const typesCompositesQuery = `SELECT t.typname, pg_catalog.pg_get_userbyid(t.typowner), ` +
+	`ARRAY(SELECT a.attname FROM pg_catalog.pg_attribute a WHERE a.attrelid = t.typrelid ` +
+	`AND a.attnum > 0 AND NOT a.attisdropped ORDER BY a.attnum), ` +
+	`ARRAY(SELECT pg_catalog.format_type(a.atttypid, a.atttypmod) FROM pg_catalog.pg_attribute a ` +
+	`WHERE a.attrelid = t.typrelid AND a.attnum > 0 AND NOT a.attisdropped ORDER BY a.attnum) ` +
+	`FROM pg_catalog.pg_type t JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace ` +
+	`JOIN pg_catalog.pg_class c ON c.oid = t.typrelid ` +
+	`WHERE t.typtype = 'c' AND c.relkind = 'c' AND n.nspname = $1 ` +
+	`ORDER BY t.typname`
+
+func dataSourcePostgreSQLTypesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	schemaName := d.Get(dataTypesSchemaAttr).(string)
+
+	enums, err := readEnumTypes(c, schemaName)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading enum types in schema %q: {{err}}", schemaName), err))
+	}
+	if err := d.Set(dataTypesEnumsAttr, enums); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting enum types: {{err}}", err))
+	}
+
+	domains, err := readDomainTypes(c, schemaName)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading domains in schema %q: {{err}}", schemaName), err))
+	}
+	if err := d.Set(dataTypesDomainsAttr, domains); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting domains: {{err}}", err))
+	}
+
+	composites, err := readCompositeTypes(c, schemaName)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error reading composite types in schema %q: {{err}}", schemaName), err))
+	}
+	if err := d.Set(dataTypesCompositesAttr, composites); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting composite types: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("types", schemaName))
+
+	return nil
+}
+
+func readEnumTypes(c *Client, schemaName string) ([]interface{}, error) {
+	rows, err := c.DB().Query(typesEnumsQuery, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	enums := make([]interface{}, 0)
+	for rows.Next() {
+		var name, owner string
+		var labels []string
+
+		if err := rows.Scan(&name, &owner, pq.Array(&labels)); err != nil {
+			return nil, err
+		}
+
+		enums = append(enums, map[string]interface{}{
+			dataTypeNameAttr:   name,
+			dataTypeOwnerAttr:  owner,
+			dataEnumLabelsAttr: labels,
+		})
+	}
+
+	return enums, rows.Err()
+}
+
+func readDomainTypes(c *Client, schemaName string) ([]interface{}, error) {
+	rows, err := c.DB().Query(typesDomainsQuery, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	domains := make([]interface{}, 0)
+	for rows.Next() {
+		var name, owner, baseType, defaultValue string
+		var notNull bool
+		var checkNames, checkDefs []string
+
+		if err := rows.Scan(&name, &owner, &baseType, &notNull, &defaultValue, pq.Array(&checkNames), pq.Array(&checkDefs)); err != nil {
+			return nil, err
+		}
+
+		checks := make([]interface{}, len(checkNames))
+		for i := range checkNames {
+			checks[i] = map[string]interface{}{
+				dataDomainCheckNameAttr: checkNames[i],
+				dataDomainCheckDefAttr:  checkDefs[i],
+			}
+		}
+
+		domains = append(domains, map[string]interface{}{
+			dataTypeNameAttr:       name,
+			dataTypeOwnerAttr:      owner,
+			dataDomainBaseTypeAttr: baseType,
+			dataDomainNotNullAttr:  notNull,
+			dataDomainDefaultAttr:  defaultValue,
+			dataDomainChecksAttr:   checks,
+		})
+	}
+
+	return domains, rows.Err()
+}
+
+func readCompositeTypes(c *Client, schemaName string) ([]interface{}, error) {
+	rows, err := c.DB().Query(typesCompositesQuery, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	composites := make([]interface{}, 0)
+	for rows.Next() {
+		var name, owner string
+		var attrNames, attrTypes []string
+
+		if err := rows.Scan(&name, &owner, pq.Array(&attrNames), pq.Array(&attrTypes)); err != nil {
+			return nil, err
+		}
+
+		attrs := make([]interface{}, len(attrNames))
+		for i := range attrNames {
+			attrs[i] = map[string]interface{}{
+				dataCompositeAttrName: attrNames[i],
+				dataCompositeAttrType: attrTypes[i],
+			}
+		}
+
+		composites = append(composites, map[string]interface{}{
+			dataTypeNameAttr:       name,
+			dataTypeOwnerAttr:      owner,
+			dataCompositeAttrsAttr: attrs,
+		})
+	}
+
+	return composites, rows.Err()
+}
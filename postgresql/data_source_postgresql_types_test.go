@@ -0,0 +1,66 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceTypes_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t, "DROP SCHEMA IF EXISTS ds_types CASCADE")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE SCHEMA ds_types",
+				"CREATE TYPE ds_types.status AS ENUM ('draft', 'published', 'archived')",
+				"CREATE DOMAIN ds_types.positive AS INTEGER NOT NULL DEFAULT 1 CONSTRAINT positive_check CHECK (VALUE > 0)",
+				"CREATE TYPE ds_types.money_amount AS (amount NUMERIC(12, 2), currency CHAR(3))",
+				"CREATE TABLE ds_types.not_a_type (id INT)",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceTypesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_types.types", "enums.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_types.types", "enums.0.name", "status"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_types.types", "enums.0.labels.#", "3"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_types.types", "enums.0.labels.2", "archived"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_types.types", "domains.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_types.types", "domains.0.base_type", "integer"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_types.types", "domains.0.not_null", "true"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_types.types", "domains.0.default", "1"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_types.types", "domains.0.check_constraints.0.name", "positive_check"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_types.types", "domains.0.check_constraints.0.definition", "CHECK ((VALUE > 0))"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_types.types", "composites.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_types.types", "composites.0.attributes.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_types.types", "composites.0.attributes.0.type", "numeric(12,2)"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_types.types", "composites.0.attributes.1.name", "currency"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceTypesConfig = `
+data "postgresql_types" "types" {
+  schema = "ds_types"
+}
+`
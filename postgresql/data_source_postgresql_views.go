@@ -0,0 +1,134 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	dataViewsSchemaAttr              = "schema"
+	dataViewsIncludeMaterializedAttr = "include_materialized"
+	dataViewsAttr                    = "views"
+
+	dataViewNameAttr           = "name"
+	dataViewSchemaAttr         = "schema"
+	dataViewOwnerAttr          = "owner"
+	dataViewMaterializedAttr   = "materialized"
+	dataViewDefinitionHashAttr = "definition_hash"
+)
+
+func dataSourcePostgreSQLViews() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePostgreSQLViewsRead,
+
+		Schema: map[string]*schema.Schema{
+			dataViewsSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The schema to list views from (default: all non-system schemas)",
+			},
+			dataViewsIncludeMaterializedAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Also return materialized views",
+			},
+			dataViewsAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dataViewNameAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataViewSchemaAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataViewOwnerAttr: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						dataViewMaterializedAttr: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						dataViewDefinitionHashAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "SHA-256 of the view's definition as returned by pg_get_viewdef()",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLViewsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	b := bytes.NewBufferString(`SELECT c.relname, n.nspname, pg_catalog.pg_get_userbyid(c.relowner), ` +
+		`c.relkind = 'm', COALESCE(pg_catalog.pg_get_viewdef(c.oid), '') ` +
+		`FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+		`WHERE `)
+
+	if d.Get(dataViewsIncludeMaterializedAttr).(bool) {
+		fmt.Fprint(b, "c.relkind IN ('v', 'm')")
+	} else {
+		fmt.Fprint(b, "c.relkind = 'v'")
+	}
+
+	args := []interface{}{}
+	schemaName := d.Get(dataViewsSchemaAttr).(string)
+	if schemaName != "" {
+		args = append(args, schemaName)
+		fmt.Fprintf(b, " AND n.nspname = $%d", len(args))
+	} else {
+		fmt.Fprint(b, " AND ", userSchemasCond("n.nspname"))
+	}
+	fmt.Fprint(b, " ORDER BY n.nspname, c.relname")
+
+	rows, err := c.DB().Query(b.String(), args...)
+	if err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading views: {{err}}", err))
+	}
+	defer rows.Close()
+
+	views := make([]interface{}, 0)
+	for rows.Next() {
+		var name, nspName, owner, definition string
+		var materialized bool
+
+		if err := rows.Scan(&name, &nspName, &owner, &materialized, &definition); err != nil {
+			return diagnostics(errwrap.Wrapf("Error reading views: {{err}}", err))
+		}
+
+		sum := sha256.Sum256([]byte(definition))
+		views = append(views, map[string]interface{}{
+			dataViewNameAttr:           name,
+			dataViewSchemaAttr:         nspName,
+			dataViewOwnerAttr:          owner,
+			dataViewMaterializedAttr:   materialized,
+			dataViewDefinitionHashAttr: hex.EncodeToString(sum[:]),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return diagnostics(errwrap.Wrapf("Error reading views: {{err}}", err))
+	}
+
+	if err := d.Set(dataViewsAttr, views); err != nil {
+		return diagnostics(errwrap.Wrapf("Error setting views: {{err}}", err))
+	}
+
+	d.SetId(dataSourceID("views", schemaName, fmt.Sprint(d.Get(dataViewsIncludeMaterializedAttr))))
+
+	return nil
+}
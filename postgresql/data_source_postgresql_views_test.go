@@ -0,0 +1,56 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceViews_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t, "DROP SCHEMA IF EXISTS ds_views CASCADE")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE SCHEMA ds_views",
+				"CREATE TABLE ds_views.items (id INT)",
+				"CREATE VIEW ds_views.plain AS SELECT id FROM ds_views.items",
+				"CREATE MATERIALIZED VIEW ds_views.snapshot AS SELECT id FROM ds_views.items",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceViewsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.postgresql_views.all", "views.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_views.all", "views.0.name", "plain"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_views.all", "views.0.materialized", "false"),
+					resource.TestCheckResourceAttrSet(
+						"data.postgresql_views.all", "views.0.definition_hash"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_views.all", "views.1.name", "snapshot"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_views.all", "views.1.materialized", "true"),
+					resource.TestCheckResourceAttr(
+						"data.postgresql_views.plain", "views.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceViewsConfig = `
+data "postgresql_views" "all" {
+  schema = "ds_views"
+}
+
+data "postgresql_views" "plain" {
+  schema               = "ds_views"
+  include_materialized = false
+}
+`
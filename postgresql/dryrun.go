@@ -0,0 +1,180 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dryRun collects the statements changing the server which connections do not
+// run in dry_run mode.
+type dryRun struct {
+	// run is held by the operations changing resources, one at a time, so
+	// that the statements collected are theirs.
+	run sync.Mutex
+
+	lock       sync.Mutex
+	statements []string
+}
+
+// passwordLiteral matches the passwords of CREATE and ALTER ROLE statements.
+var passwordLiteral = regexp.MustCompile(`(?i)(\bPASSWORD\s+)'(?:[^']|'')*'`)
+
+// redactPasswords returns the statement with the passwords it sets replaced.
+func redactPasswords(statement string) string {
+	return passwordLiteral.ReplaceAllString(statement, "$1'<redacted>'")
+}
+
+// record collects the statement instead of running it, with its arguments.
+func (r *dryRun) record(query string, args []driver.Value) {
+	statement := redactPasswords(strings.TrimSpace(query))
+	for i, arg := range args {
+		statement += fmt.Sprintf("\n  -- $%d = %v", i+1, arg)
+	}
+
+	r.lock.Lock()
+	r.statements = append(r.statements, statement)
+	r.lock.Unlock()
+}
+
+// noRows is the result of the queries changing the server dry_run mode does
+// not run.
+type noRows struct{}
+
+func (noRows) Columns() []string              { return nil }
+func (noRows) Close() error                   { return nil }
+func (noRows) Next(dest []driver.Value) error { return io.EOF }
+
+// take returns the statements collected since the last call.
+func (r *dryRun) take() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	statements := r.statements
+	r.statements = nil
+
+	return statements
+}
+
+// changingFunctions are the functions changing the server which statements
+// call with SELECT, e.g. those of the extensions whose objects resources
+// manage.
+var changingFunctions = []string{
+	"nextval",
+	"setval",
+	"pg_cancel_backend",
+	"pg_terminate_backend",
+
+	// TimescaleDB, see postgresql_hypertable.
+	"create_hypertable",
+	"set_chunk_time_interval",
+	"add_retention_policy",
+	"remove_retention_policy",
+	"add_compression_policy",
+	"remove_compression_policy",
+
+	// Citus, see postgresql_distributed_table.
+	"create_distributed_table",
+	"create_reference_table",
+	"alter_distributed_table",
+	"undistribute_table",
+
+	// AlloyDB's columnar engine, see the columnar of postgresql_table.
+	"google_columnar_engine_add",
+	"google_columnar_engine_drop",
+}
+
+// changingFunctionCall matches the calls of changingFunctions, qualified with
+// their schema or not.
+var changingFunctionCall = regexp.MustCompile(`(?i)\b(?:` + strings.Join(changingFunctions, "|") + `)\s*\(`)
+
+// readStatement returns true if the statement only reads from the server, or
+// only changes the session or transaction running it, so that dry_run mode
+// runs it.  SELECTs calling changingFunctions change the server.
+func readStatement(query string) bool {
+	words := strings.Fields(strings.ToUpper(query))
+	if len(words) == 0 {
+		return true
+	}
+
+	switch words[0] {
+	case "SELECT":
+		return !changingFunctionCall.MatchString(query)
+	case "SHOW", "SET", "RESET":
+		return true
+	}
+
+	return false
+}
+
+// dryRunOperation runs f, the op of the resource with the ID id, and in
+// dry_run mode returns the statements it would have run as the error.  The
+// statements it reads with still run, against a server the previous
+// statements did not change.
+func (c *Client) dryRunOperation(op, id string, f func() error) error {
+	dryRun := c.config.dryRun
+	if dryRun == nil {
+		return f()
+	}
+
+	dryRun.run.Lock()
+	defer dryRun.run.Unlock()
+
+	dryRun.take()
+	err := f()
+	statements := dryRun.take()
+
+	report := fmt.Sprintf("dry_run: the %s would run:\n\n", op)
+	if len(statements) == 0 {
+		report = fmt.Sprintf("dry_run: the %s would run no statements\n", op)
+	}
+	for _, statement := range statements {
+		report += statement + ";\n"
+	}
+	if err != nil {
+		report += fmt.Sprintf("\nand then failed, which may be due to the statements not having run: %s\n", err)
+	}
+	logEvent("INFO", "Dry run", logFields{"operation": op, "id": id, "statements": statements})
+
+	return errors.New(report)
+}
+
+// dryRunChanges makes the changes to the resource fail, with the statements
+// they would have run as the error, in dry_run mode.
+func dryRunChanges(r *schema.Resource) {
+	wrap := func(op string, f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+		if f == nil {
+			return nil
+		}
+		return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			c := meta.(*Client)
+			if c.config.dryRun == nil {
+				return f(ctx, d, meta)
+			}
+
+			err := c.dryRunOperation(op, d.Id(), func() error {
+				return diagsError(f(ctx, d, meta))
+			})
+
+			// Terraform must not record the change as made.
+			if op == "create" {
+				d.SetId("")
+			}
+			d.Partial(true)
+
+			return diag.Errorf("%s", err)
+		}
+	}
+
+	r.CreateContext = wrap("create", r.CreateContext)
+	r.UpdateContext = wrap("update", r.UpdateContext)
+	r.DeleteContext = wrap("delete", r.DeleteContext)
+}
@@ -0,0 +1,226 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDryRunConn(t *testing.T) {
+	dryRun := &dryRun{}
+	conn := &liveConn{Conn: lostConn{}, dryRun: dryRun}
+
+	for _, query := range []string{
+		"SELECT rolname FROM pg_catalog.pg_roles",
+		"SET TRANSACTION READ ONLY",
+		`CREATE ROLE "app" LOGIN PASSWORD 'it''s secret'`,
+		"ALTER DATABASE app CONNECTION LIMIT $1",
+		"SELECT pg_catalog.setval('app_id_seq', 1)",
+	} {
+		var args []driver.Value
+		if strings.Contains(query, "$1") {
+			args = []driver.Value{int64(10)}
+		}
+		if _, err := conn.Exec(query, args); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{
+		`CREATE ROLE "app" LOGIN PASSWORD '<redacted>'`,
+		"ALTER DATABASE app CONNECTION LIMIT $1\n  -- $1 = 10",
+		"SELECT pg_catalog.setval('app_id_seq', 1)",
+	}
+	if statements := dryRun.take(); !reflect.DeepEqual(statements, expected) {
+		t.Errorf("expected the statements %q to be collected, got %q", expected, statements)
+	}
+	if statements := dryRun.take(); len(statements) != 0 {
+		t.Errorf("expected the statements to be taken once, got %q", statements)
+	}
+}
+
+func TestDryRunChanges(t *testing.T) {
+	client := &Client{config: Config{dryRun: &dryRun{}}}
+	r := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {Type: schema.TypeString, Required: true},
+		},
+		CreateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			client.config.dryRun.record(`CREATE SCHEMA "app"`, nil)
+			d.SetId("app")
+			return nil
+		},
+	}
+	dryRunChanges(r)
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"name": "app"})
+	err := diagsError(r.CreateContext(context.Background(), d, client))
+	if err == nil || !strings.Contains(err.Error(), `CREATE SCHEMA "app";`) {
+		t.Errorf("expected the create to fail with its statements, got %v", err)
+	}
+	if d.Id() != "" {
+		t.Errorf("expected the resource not to be created, got ID %q", d.Id())
+	}
+}
+
+// recordingConn is a driver connection recording the statements run on it.
+// Queries select no rows but the row of the first key of rows they contain.
+type recordingConn struct {
+	lostConn
+	statements *[]string
+	rows       map[string][]driver.Value
+}
+
+func (c recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	*c.statements = append(*c.statements, query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	*c.statements = append(*c.statements, query)
+	for key, row := range c.rows {
+		if strings.Contains(query, key) {
+			return &oneRow{row: row}, nil
+		}
+	}
+	return noRows{}, nil
+}
+
+// oneRow is the rows of a query selecting row.
+type oneRow struct {
+	row  []driver.Value
+	read bool
+}
+
+func (r *oneRow) Columns() []string { return make([]string, len(r.row)) }
+func (r *oneRow) Close() error      { return nil }
+
+func (r *oneRow) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	copy(dest, r.row)
+	return nil
+}
+
+// connectorFunc is a driver.Connector opening connections with a function.
+type connectorFunc func() driver.Conn
+
+func (f connectorFunc) Connect(ctx context.Context) (driver.Conn, error) { return f(), nil }
+func (f connectorFunc) Driver() driver.Driver                            { return hookedDriver{} }
+
+func TestDryRunResources(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource func() *schema.Resource
+		alloydb  bool
+		config   map[string]interface{}
+		rows     map[string][]driver.Value
+		changes  []string
+	}{
+		{
+			name:     "postgresql_index",
+			resource: resourcePostgreSQLIndex,
+			config: map[string]interface{}{
+				"table":  "documents",
+				"name":   "documents_embedding_idx",
+				"method": "hnsw",
+				"column": []interface{}{map[string]interface{}{"name": "embedding"}},
+			},
+			changes: []string{"CREATE INDEX", "DROP INDEX"},
+		},
+		{
+			name:     "postgresql_hypertable",
+			resource: resourcePostgreSQLHypertable,
+			config: map[string]interface{}{
+				"table":               "metrics",
+				"time_column":         "time",
+				"chunk_time_interval": "1 day",
+				"retention_period":    "90 days",
+				"compress_after":      "7 days",
+			},
+			changes: []string{
+				"create_hypertable",
+				"set_chunk_time_interval",
+				"add_retention_policy",
+				"remove_retention_policy",
+				"add_compression_policy",
+				"remove_compression_policy",
+				"timescaledb.compress",
+			},
+		},
+		{
+			name:     "postgresql_distributed_table",
+			resource: resourcePostgreSQLDistributedTable,
+			config: map[string]interface{}{
+				"table":               "events",
+				"distribution_column": "tenant_id",
+				"shard_count":         8,
+			},
+			changes: []string{"create_distributed_table", "alter_distributed_table", "undistribute_table"},
+		},
+		{
+			name:     "postgresql_distributed_table reference",
+			resource: resourcePostgreSQLDistributedTable,
+			config: map[string]interface{}{
+				"table":     "tenants",
+				"reference": true,
+			},
+			changes: []string{"create_reference_table", "undistribute_table"},
+		},
+		{
+			name:     "postgresql_table columnar",
+			resource: resourcePostgreSQLTable,
+			alloydb:  true,
+			config: map[string]interface{}{
+				"name":     "events",
+				"columnar": true,
+			},
+			rows:    map[string][]driver.Value{"google_columnar_engine.enabled": {true}},
+			changes: []string{"CREATE TABLE", "google_columnar_engine_add"},
+		},
+	}
+
+	for _, test := range tests {
+		var statements []string
+		client := &Client{alloydb: test.alloydb, config: Config{dryRun: &dryRun{}}}
+		client.db = sql.OpenDB(connectorFunc(func() driver.Conn {
+			return &liveConn{Conn: recordingConn{statements: &statements, rows: test.rows}, dryRun: client.config.dryRun, used: time.Now()}
+		}))
+
+		r := test.resource()
+		dryRunChanges(r)
+		var reports string
+		for _, op := range []func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics{r.CreateContext, r.UpdateContext, r.DeleteContext} {
+			if op == nil {
+				continue
+			}
+			d := schema.TestResourceDataRaw(t, r.Schema, test.config)
+			d.SetId("dry-run")
+			if err := diagsError(op(context.Background(), d, client)); err != nil {
+				reports += err.Error()
+			}
+		}
+
+		for _, change := range test.changes {
+			if !strings.Contains(reports, change) {
+				t.Errorf("%s: expected %q to be reported, got %q", test.name, change, reports)
+			}
+			for _, statement := range statements {
+				if strings.Contains(statement, change) {
+					t.Errorf("%s: expected %q not to run, got %q", test.name, change, statement)
+				}
+			}
+		}
+		client.db.Close()
+	}
+}
@@ -0,0 +1,277 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	providerschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ProviderServer returns the server of the provider, which serves the
+// resources ported to terraform-plugin-framework next to the ones of
+// Provider, until they all are.
+func ProviderServer(ctx context.Context) (func() tfprotov5.ProviderServer, error) {
+	p := Provider()
+	mux, err := tf5muxserver.NewMuxServer(ctx,
+		// The provider is configured first, so that the resources of the
+		// framework share its client.
+		p.GRPCProvider,
+		providerserver.NewProtocol5(&frameworkProvider{sdk: p}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return mux.ProviderServer, nil
+}
+
+// frameworkProvider is the provider of the resources ported to
+// terraform-plugin-framework, configured by sdk.
+type frameworkProvider struct {
+	sdk *schema.Provider
+}
+
+func (p *frameworkProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "postgresql"
+}
+
+// Schema returns the schema of sdk, which both providers must have for
+// Terraform to configure them alike.
+func (p *frameworkProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	s, err := frameworkProviderSchema(ctx, p.sdk)
+	if err != nil {
+		resp.Diagnostics.AddError("Error converting the provider schema", err.Error())
+		return
+	}
+
+	resp.Schema = s
+}
+
+// frameworkProviderSchema returns the schema of the provider p in
+// terraform-plugin-framework's terms.  The attributes are converted from the
+// schema p serves, whose required attributes with defaults are optional.
+// Their values are validated by p alone.
+func frameworkProviderSchema(ctx context.Context, p *schema.Provider) (providerschema.Schema, error) {
+	resp, err := p.GRPCProvider().GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		return providerschema.Schema{}, err
+	}
+	for _, d := range resp.Diagnostics {
+		if d.Severity == tfprotov5.DiagnosticSeverityError {
+			return providerschema.Schema{}, fmt.Errorf("%s: %s", d.Summary, d.Detail)
+		}
+	}
+
+	attrs := make(map[string]providerschema.Attribute, len(resp.Provider.Block.Attributes))
+	for _, a := range resp.Provider.Block.Attributes {
+		switch {
+		case a.Type.Is(tftypes.String):
+			attrs[a.Name] = providerschema.StringAttribute{
+				Required:           a.Required,
+				Optional:           a.Optional,
+				Sensitive:          a.Sensitive,
+				Description:        a.Description,
+				DeprecationMessage: a.DeprecationMessage,
+			}
+		case a.Type.Is(tftypes.Number):
+			attrs[a.Name] = providerschema.Int64Attribute{
+				Required:           a.Required,
+				Optional:           a.Optional,
+				Sensitive:          a.Sensitive,
+				Description:        a.Description,
+				DeprecationMessage: a.DeprecationMessage,
+			}
+		case a.Type.Is(tftypes.Bool):
+			attrs[a.Name] = providerschema.BoolAttribute{
+				Required:           a.Required,
+				Optional:           a.Optional,
+				Sensitive:          a.Sensitive,
+				Description:        a.Description,
+				DeprecationMessage: a.DeprecationMessage,
+			}
+		case a.Type.Is(tftypes.List{ElementType: tftypes.String}):
+			attrs[a.Name] = providerschema.ListAttribute{
+				ElementType:        types.StringType,
+				Required:           a.Required,
+				Optional:           a.Optional,
+				Sensitive:          a.Sensitive,
+				Description:        a.Description,
+				DeprecationMessage: a.DeprecationMessage,
+			}
+		default:
+			return providerschema.Schema{}, fmt.Errorf("unsupported type %s of the provider attribute %s", a.Type, a.Name)
+		}
+	}
+
+	return providerschema.Schema{Attributes: attrs}, nil
+}
+
+// Configure hands the client sdk was configured with to the resources.
+func (p *frameworkProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	resp.ResourceData = p.sdk.Meta()
+}
+
+func (p *frameworkProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+func (p *frameworkProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		newGrantRoleResource,
+	}
+}
+
+// configuredClient returns the client of the provider data of a resource, or
+// an error diagnostic if the provider is not configured.
+func configuredClient(data interface{}) (*Client, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	c, ok := data.(*Client)
+	if !ok {
+		diags.AddError("Provider not configured", fmt.Sprintf("Expected the provider's client, got %T", data))
+	}
+
+	return c, diags
+}
+
+// operation runs f, the op of the resource of the type name with the ID id,
+// as the resources of Provider run theirs: connected first, failing with the
+// statements of changes in dry_run mode, reporting the apply statistics after
+// changes, and logged.
+func (c *Client) operation(name, op, id string, f func() error) error {
+	start := time.Now()
+	run := func() error {
+		if err := c.connect(); err != nil {
+			return err
+		}
+		return f()
+	}
+
+	var err error
+	if op == "read" {
+		err = run()
+	} else {
+		err = c.dryRunOperation(op, id, run)
+		pushStats(c)
+	}
+	logOperation(name, op, id, start, err)
+
+	return err
+}
+
+// frameworkDiagnostics returns the diagnostics of err, with the path of the
+// attribute at fault for an attrError.
+func frameworkDiagnostics(err error) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if err == nil {
+		return diags
+	}
+
+	var attrErr *attrError
+	if errors.As(err, &attrErr) {
+		diags.AddAttributeError(path.Root(attrErr.attr), err.Error(), "")
+	} else {
+		diags.AddError(err.Error(), "")
+	}
+
+	return diags
+}
+
+// modifyPlanFeatures checks the plan of a resource of the framework against
+// the features of the server with check, as planFeatures does the plans of the
+// resources of Provider.
+func modifyPlanFeatures(ctx context.Context, c *Client, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, check func(*Client, attrChanges) error) {
+	// Nothing is checked before destroying, nor before the provider is
+	// configured.
+	if req.Plan.Raw.IsNull() || c == nil || !c.connectForPlan() {
+		return
+	}
+
+	resp.Diagnostics.Append(frameworkDiagnostics(check(c, planChanges{ctx: ctx, plan: req.Plan, state: req.State}))...)
+}
+
+// attrGetter is implemented by both tfsdk.Plan and tfsdk.State.
+type attrGetter interface {
+	GetAttribute(ctx context.Context, path path.Path, target interface{}) diag.Diagnostics
+}
+
+// planChanges are the attributes of the plan of a resource of the framework,
+// changed from its state, null when creating it, for the checks reading
+// attrChanges.  The values not known yet are not set.
+type planChanges struct {
+	ctx   context.Context
+	plan  tfsdk.Plan
+	state tfsdk.State
+}
+
+func (p planChanges) value(data attrGetter, null bool, name string) attr.Value {
+	if null {
+		return nil
+	}
+
+	var v attr.Value
+	if diags := data.GetAttribute(p.ctx, path.Root(name), &v); diags.HasError() {
+		return nil
+	}
+
+	return v
+}
+
+func (p planChanges) Id() string {
+	id, _ := goValue(p.value(p.state, p.state.Raw.IsNull(), "id"))
+	s, _ := id.(string)
+
+	return s
+}
+
+func (p planChanges) GetOk(name string) (interface{}, bool) {
+	return goValue(p.value(p.plan, p.plan.Raw.IsNull(), name))
+}
+
+func (p planChanges) Get(name string) interface{} {
+	v, _ := p.GetOk(name)
+	return v
+}
+
+func (p planChanges) HasChange(name string) bool {
+	old := p.value(p.state, p.state.Raw.IsNull(), name)
+	if old == nil {
+		return true
+	}
+
+	return !old.Equal(p.value(p.plan, p.plan.Raw.IsNull(), name))
+}
+
+// goValue returns the Go value of v, and whether it is known, not null and not
+// the zero value, as for schema.ResourceData.GetOk.
+func goValue(v attr.Value) (interface{}, bool) {
+	if v == nil {
+		return nil, false
+	}
+	known := !v.IsNull() && !v.IsUnknown()
+
+	switch v := v.(type) {
+	case types.String:
+		return v.ValueString(), known && v.ValueString() != ""
+	case types.Bool:
+		return v.ValueBool(), known && v.ValueBool()
+	case types.Int64:
+		return int(v.ValueInt64()), known && v.ValueInt64() != 0
+	}
+
+	return nil, false
+}
@@ -0,0 +1,92 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestProviderServerSchema(t *testing.T) {
+	ctx := context.Background()
+	server, err := ProviderServer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The schemas of both providers must be the same for the mux to serve
+	// them.
+	resp, err := server().GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		t.Errorf("%s: %s", d.Summary, d.Detail)
+	}
+	for _, name := range []string{"postgresql_role", grantRoleName} {
+		if _, ok := resp.ResourceSchemas[name]; !ok {
+			t.Errorf("expected the resource %s to be served", name)
+		}
+	}
+}
+
+func TestPlanChanges(t *testing.T) {
+	ctx := context.Background()
+	r := newGrantRoleResource()
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	s := schemaResp.Schema
+	objType := s.Type().TerraformType(ctx)
+
+	value := func(grantRole interface{}) tftypes.Value {
+		return tftypes.NewValue(objType, map[string]tftypes.Value{
+			"id":                 tftypes.NewValue(tftypes.String, "member/pg_monitor"),
+			grantRoleMemberAttr:  tftypes.NewValue(tftypes.String, "member"),
+			grantRoleGrantedAttr: tftypes.NewValue(tftypes.String, grantRole),
+			grantRoleAdminAttr:   tftypes.NewValue(tftypes.Bool, false),
+			grantRoleGrantorAttr: tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		})
+	}
+	null := tftypes.NewValue(objType, nil)
+
+	tests := []struct {
+		name    string
+		plan    tftypes.Value
+		state   tftypes.Value
+		id      string
+		value   interface{}
+		ok      bool
+		changed bool
+	}{
+		{"create", value("pg_monitor"), null, "", "pg_monitor", true, true},
+		{"unchanged", value("pg_monitor"), value("pg_monitor"), "member/pg_monitor", "pg_monitor", true, false},
+		{"changed", value("pg_maintain"), value("pg_monitor"), "member/pg_monitor", "pg_maintain", true, true},
+		{"unknown", value(tftypes.UnknownValue), null, "", "", false, true},
+	}
+
+	for _, test := range tests {
+		d := planChanges{
+			ctx:   ctx,
+			plan:  tfsdk.Plan{Schema: s, Raw: test.plan},
+			state: tfsdk.State{Schema: s, Raw: test.state},
+		}
+		if id := d.Id(); id != test.id {
+			t.Errorf("%s: expected the ID %q, got %q", test.name, test.id, id)
+		}
+		if v, ok := d.GetOk(grantRoleGrantedAttr); v != test.value || ok != test.ok {
+			t.Errorf("%s: expected %v, %v, got %v, %v", test.name, test.value, test.ok, v, ok)
+		}
+		if changed := d.HasChange(grantRoleGrantedAttr); changed != test.changed {
+			t.Errorf("%s: expected a change %v, got %v", test.name, test.changed, changed)
+		}
+	}
+
+	// The admin option is false, the zero value.
+	d := planChanges{ctx: ctx, plan: tfsdk.Plan{Schema: s, Raw: value("pg_monitor")}, state: tfsdk.State{Schema: s, Raw: null}}
+	if v, ok := d.GetOk(grantRoleAdminAttr); v != false || ok {
+		t.Errorf("expected false, false for %s, got %v, %v", grantRoleAdminAttr, v, ok)
+	}
+}
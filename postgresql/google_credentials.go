@@ -0,0 +1,263 @@
+package postgresql
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+)
+
+const (
+	googleTokenURL    = "https://oauth2.googleapis.com/token"
+	googleMetadataURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+	// Refresh access tokens this long before they expire, so that a token
+	// handed out is good for at least the time it takes to use it.
+	googleTokenExpiryDelta = time.Minute
+)
+
+// googleCredentials is the subset of the application default credentials
+// files (service account keys and `gcloud auth application-default login`
+// credentials) needed to request access tokens.
+type googleCredentials struct {
+	Type string `json:"type"`
+
+	// service_account
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+
+	// authorized_user
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type googleTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// googleTokenSource hands out OAuth2 access tokens for the Google Cloud APIs,
+// obtained from the application default credentials.  In order of precedence
+// these are the GOOGLE_OAUTH_ACCESS_TOKEN environment variable, the
+// credentials file named by GOOGLE_APPLICATION_CREDENTIALS, the file written
+// by `gcloud auth application-default login` and the metadata server of the
+// Google Cloud instance the provider runs on.
+type googleTokenSource struct {
+	client *http.Client
+	scopes []string
+
+	lock   sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func newGoogleTokenSource(client *http.Client, scopes ...string) *googleTokenSource {
+	return &googleTokenSource{
+		client: client,
+		scopes: scopes,
+	}
+}
+
+// Token returns a valid access token, requesting a new one if needed.
+func (s *googleTokenSource) Token() (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.token != "" && time.Now().Add(googleTokenExpiryDelta).Before(s.expiry) {
+		return s.token, nil
+	}
+
+	creds, err := readGoogleCredentials()
+	if err != nil {
+		return "", err
+	}
+
+	var resp *googleTokenResponse
+	switch {
+	case creds == nil:
+		resp, err = s.metadataToken()
+	case creds.Type == "service_account":
+		resp, err = s.serviceAccountToken(creds)
+	case creds.Type == "authorized_user":
+		resp, err = s.refreshToken(creds)
+	default:
+		return "", fmt.Errorf("unsupported Google credentials type %q", creds.Type)
+	}
+	if err != nil {
+		return "", errwrap.Wrapf("Error requesting Google access token: {{err}}", err)
+	}
+
+	s.token = resp.AccessToken
+	s.expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+
+	return s.token, nil
+}
+
+// readGoogleCredentials returns the application default credentials file, or
+// nil if there is none.
+func readGoogleCredentials() (*googleCredentials, error) {
+	path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if path == "" {
+		path = filepath.Join(os.Getenv("HOME"), ".config", "gcloud", "application_default_credentials.json")
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, nil
+		}
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error reading Google credentials: {{err}}", err)
+	}
+
+	var creds googleCredentials
+	if err := json.Unmarshal(b, &creds); err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("Error parsing Google credentials %q: {{err}}", path), err)
+	}
+
+	return &creds, nil
+}
+
+// serviceAccountToken exchanges a JWT signed with the service account's key
+// for an access token.
+func (s *googleTokenSource) serviceAccountToken(creds *googleCredentials) (*googleTokenResponse, error) {
+	tokenURL := creds.TokenURI
+	if tokenURL == "" {
+		tokenURL = googleTokenURL
+	}
+
+	block, _ := pem.Decode([]byte(creds.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("invalid private key for %s", creds.ClientEmail)
+	}
+	key, err := parseRSAPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("invalid private key for %s: {{err}}", creds.ClientEmail), err)
+	}
+
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": creds.PrivateKeyID,
+	})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   creds.ClientEmail,
+		"scope": strings.Join(s.scopes, " "),
+		"aud":   tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+
+	jwt := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(jwt))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return nil, err
+	}
+	jwt += "." + base64.RawURLEncoding.EncodeToString(sig)
+
+	return s.postToken(tokenURL, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {jwt},
+	})
+}
+
+// refreshToken uses the refresh token of a user's credentials to get an
+// access token.
+func (s *googleTokenSource) refreshToken(creds *googleCredentials) (*googleTokenResponse, error) {
+	return s.postToken(googleTokenURL, url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {creds.ClientID},
+		"client_secret": {creds.ClientSecret},
+		"refresh_token": {creds.RefreshToken},
+	})
+}
+
+// metadataToken gets an access token for the service account of the Google
+// Cloud instance the provider runs on.
+func (s *googleTokenSource) metadataToken() (*googleTokenResponse, error) {
+	req, err := http.NewRequest("GET", googleMetadataURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := s.doToken(req)
+	if err != nil {
+		return nil, errwrap.Wrapf("no Google credentials found and the metadata server is not available: {{err}}", err)
+	}
+
+	return resp, nil
+}
+
+func (s *googleTokenSource) postToken(tokenURL string, form url.Values) (*googleTokenResponse, error) {
+	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return s.doToken(req)
+}
+
+func (s *googleTokenSource) doToken(req *http.Request) (*googleTokenResponse, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(b))
+	}
+
+	var token googleTokenResponse
+	if err := json.Unmarshal(b, &token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("no access token in response")
+	}
+
+	return &token, nil
+}
+
+// parseRSAPrivateKey parses a PKCS#8 or, failing that, a PKCS#1 RSA key.
+func parseRSAPrivateKey(der []byte) (*rsa.PrivateKey, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("not an RSA key")
+		}
+		return rsaKey, nil
+	}
+
+	return x509.ParsePKCS1PrivateKey(der)
+}
@@ -0,0 +1,144 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+// greenplumDistribution returns the DISTRIBUTED BY or DISTRIBUTED RANDOMLY
+// clause of the distribution policy of the table on Greenplum, which every
+// table must have.
+func greenplumDistribution(d *schema.ResourceData) (string, error) {
+	distributedBy := interfaceStrings(d.Get(tableDistributedByAttr).([]interface{}))
+	randomly := d.Get(tableDistributedRandomlyAttr).(bool)
+
+	switch {
+	case len(distributedBy) > 0 && randomly:
+		return "", fmt.Errorf("%s and %s can not both be set", tableDistributedByAttr, tableDistributedRandomlyAttr)
+	case len(distributedBy) > 0:
+		return fmt.Sprintf("DISTRIBUTED BY (%s)", quoteIdentifiers(distributedBy)), nil
+	case randomly:
+		return "DISTRIBUTED RANDOMLY", nil
+	}
+
+	return "", fmt.Errorf("Greenplum tables need either %s or %s", tableDistributedByAttr, tableDistributedRandomlyAttr)
+}
+
+// parseGreenplumDistribution parses the distribution policy as
+// pg_get_table_distributedby() prints it, e.g. DISTRIBUTED BY (id, "Region")
+// or DISTRIBUTED RANDOMLY.  The operator classes of the columns, printed when
+// not the default ones, are left out.  Replicated tables have neither
+// distribution columns nor a random distribution.
+func parseGreenplumDistribution(policy string) (distributedBy []string, randomly bool) {
+	policy = strings.TrimSpace(policy)
+	if policy == "DISTRIBUTED RANDOMLY" {
+		return nil, true
+	}
+	if !strings.HasPrefix(policy, "DISTRIBUTED BY (") || !strings.HasSuffix(policy, ")") {
+		return nil, false
+	}
+
+	for _, key := range splitQuoted(policy[len("DISTRIBUTED BY ("):len(policy)-1], ',') {
+		key = strings.TrimSpace(key)
+		column := key
+		if strings.HasPrefix(key, `"`) {
+			for i := 1; i < len(key); i++ {
+				if key[i] != '"' {
+					continue
+				}
+				if i+1 < len(key) && key[i+1] == '"' {
+					i++
+					continue
+				}
+				column = key[:i+1]
+				break
+			}
+		} else if i := strings.IndexByte(key, ' '); i >= 0 {
+			column = key[:i]
+		}
+		distributedBy = append(distributedBy, unquoteIdentifier(column))
+	}
+
+	return distributedBy, false
+}
+
+// resourcePostgreSQLTableCreateGreenplum creates the table with its columns and
+// its distribution policy: the distribution columns must be created with the
+// table.
+func resourcePostgreSQLTableCreateGreenplum(d *schema.ResourceData, meta interface{}, db *sql.DB) error {
+	tableName := d.Get(tableNameAttr).(string)
+
+	distribution, err := greenplumDistribution(d)
+	if err != nil {
+		return err
+	}
+
+	var definitions []string
+	for _, column := range d.Get(columnAttr).([]interface{}) {
+		definitions = append(definitions, columnDefinition(column.(map[string]interface{})))
+	}
+
+	sql := fmt.Sprintf("CREATE TABLE %s (%s) %s", pq.QuoteIdentifier(tableName), strings.Join(definitions, ", "), distribution)
+	if _, err := db.Exec(sql); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error creating table %s: {{err}}", tableName), err)
+	}
+
+	d.SetId(tableName)
+
+	return resourcePostgreSQLTableReadImpl(d, meta)
+}
+
+// checkGreenplumTableAttrs returns an error if the attributes only Greenplum
+// supports are set.
+func checkGreenplumTableAttrs(d attrChanges) error {
+	for _, attr := range []string{tableDistributedByAttr, tableDistributedRandomlyAttr} {
+		if _, ok := d.GetOk(attr); ok {
+			return &attrError{attr: attr, err: fmt.Errorf("%s is only supported on Greenplum and Cloudberry", attr)}
+		}
+	}
+
+	return nil
+}
+
+// readGreenplumDistribution reads the distribution policy of the table on
+// Greenplum.
+func readGreenplumDistribution(d *schema.ResourceData, db *sql.DB, tableName string) error {
+	var policy string
+	err := db.QueryRow(`SELECT pg_catalog.pg_get_table_distributedby(c.oid) `+
+		`FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace `+
+		`WHERE n.nspname = 'public' AND c.relname = $1`, tableName).Scan(&policy)
+	if err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error reading the distribution policy of TABLE (%s): {{err}}", tableName), err)
+	}
+
+	distributedBy, randomly := parseGreenplumDistribution(policy)
+	d.Set(tableDistributedByAttr, distributedBy)
+	d.Set(tableDistributedRandomlyAttr, randomly)
+
+	return nil
+}
+
+// alterGreenplumDistributionIfNeeded changes the distribution policy of the
+// table on Greenplum, which redistributes its rows.
+func alterGreenplumDistributionIfNeeded(d *schema.ResourceData, db *sql.DB) error {
+	if !d.HasChange(tableDistributedByAttr) && !d.HasChange(tableDistributedRandomlyAttr) {
+		return nil
+	}
+
+	distribution, err := greenplumDistribution(d)
+	if err != nil {
+		return err
+	}
+
+	sql := fmt.Sprintf("ALTER TABLE %s SET %s", pq.QuoteIdentifier(databaseObjectName(d.Id())), distribution)
+	if _, err := db.Exec(sql); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error altering the distribution policy of TABLE (%s): {{err}}", d.Id()), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package postgresql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestParseGreenplumDistribution(t *testing.T) {
+	tests := []struct {
+		policy        string
+		distributedBy []string
+		randomly      bool
+	}{
+		{policy: "DISTRIBUTED RANDOMLY", randomly: true},
+		{policy: "DISTRIBUTED REPLICATED"},
+		{policy: "DISTRIBUTED BY (id)", distributedBy: []string{"id"}},
+		{policy: `DISTRIBUTED BY (id, "Region")`, distributedBy: []string{"id", "Region"}},
+		{policy: `DISTRIBUTED BY ("a, ""b""" int4_ops, c)`, distributedBy: []string{`a, "b"`, "c"}},
+		{policy: "DISTRIBUTED BY (id int4_ops)", distributedBy: []string{"id"}},
+	}
+
+	for _, test := range tests {
+		distributedBy, randomly := parseGreenplumDistribution(test.policy)
+		if !reflect.DeepEqual(distributedBy, test.distributedBy) || randomly != test.randomly {
+			t.Errorf("%q: expected %q and %t, got %q and %t", test.policy, test.distributedBy, test.randomly, distributedBy, randomly)
+		}
+	}
+}
+
+func TestGreenplumDistribution(t *testing.T) {
+	tests := []struct {
+		config       map[string]interface{}
+		distribution string
+		err          bool
+	}{
+		{
+			config:       map[string]interface{}{"distributed_by": []interface{}{"id", "Region"}},
+			distribution: `DISTRIBUTED BY ("id", "Region")`,
+		},
+		{
+			config:       map[string]interface{}{"distributed_randomly": true},
+			distribution: "DISTRIBUTED RANDOMLY",
+		},
+		{
+			config: map[string]interface{}{},
+			err:    true,
+		},
+	}
+
+	for _, test := range tests {
+		test.config["name"] = "events"
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLTable().Schema, test.config)
+		distribution, err := greenplumDistribution(d)
+		if (err != nil) != test.err {
+			t.Errorf("%v: expected error %t, got %v", test.config, test.err, err)
+			continue
+		}
+		if distribution != test.distribution {
+			t.Errorf("%v: expected %q, got %q", test.config, test.distribution, distribution)
+		}
+	}
+}
@@ -0,0 +1,489 @@
+package postgresql
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/jcmturner/gofork/encoding/asn1"
+	"github.com/jcmturner/gokrb5/v8/asn1tools"
+	"github.com/jcmturner/gokrb5/v8/crypto"
+	"github.com/jcmturner/gokrb5/v8/crypto/etype"
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/iana/chksumtype"
+	"github.com/jcmturner/gokrb5/v8/iana/etypeID"
+	"github.com/jcmturner/gokrb5/v8/iana/flags"
+	"github.com/jcmturner/gokrb5/v8/iana/keyusage"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/jcmturner/gokrb5/v8/types"
+	"github.com/lib/pq"
+)
+
+const (
+	// gssEncRequestCode is the code of the message asking a PostgreSQL
+	// server to encrypt the connection with GSSAPI.
+	gssEncRequestCode = 80877104
+
+	// gssMaxPacket is the largest packet PostgreSQL accepts over a
+	// GSS-encrypted connection, with its length.
+	gssMaxPacket = 16384
+
+	// gssMaxData is the most data sealed in one packet: a packet holds the
+	// wrap token header, the confounder, the data, the copy of the header
+	// and a checksum of at most 24 bytes (RFC 4121, RFC 8009).
+	gssMaxData = gssMaxPacket - 4 - 16 - 16 - 16 - 24
+
+	// Flags of the wrap tokens (RFC 4121 section 4.2.2).
+	gssSentByAcceptor = 0x01
+	gssSealed         = 0x02
+	gssAcceptorSubkey = 0x04
+)
+
+// gssEncDialer is a pq.Dialer encrypting the connections it establishes with
+// GSSAPI and the Kerberos tickets of the credential cache, as libpq does with
+// gssencmode, which lib/pq does not support.  If the server does not encrypt
+// the connection, SSL is negotiated by ssl, if set, instead.  lib/pq must then
+// be configured with sslmode=disable, as servers refuse to negotiate SSL over
+// GSS-encrypted connections.
+type gssEncDialer struct {
+	// mode is libpq's gssencmode: prefer or require.
+	mode string
+
+	// service is the Kerberos service name of the server, krbsrvname.
+	service string
+
+	// gss returns the Kerberos client of the connection.
+	gss func() (*kerberosGSS, error)
+
+	forward pq.Dialer
+	ssl     *sslDialer
+}
+
+// Dial implements pq.Dialer.
+func (d *gssEncDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialTimeout(network, address, 0)
+}
+
+// DialTimeout implements pq.Dialer.  With gssencmode prefer, like libpq, GSS
+// encryption is only attempted with Kerberos credentials, and the connection
+// is established again without it if it fails.
+func (d *gssEncDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	conn, err := d.forward.DialTimeout(network, address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if network == "unix" {
+		if d.mode == "require" {
+			conn.Close()
+			return nil, fmt.Errorf("gssencmode require can not be used over Unix-domain sockets")
+		}
+		return conn, nil
+	}
+
+	gss, err := d.gss()
+	if err != nil {
+		if d.mode == "require" {
+			conn.Close()
+			return nil, err
+		}
+		logEvent("DEBUG", "No Kerberos credentials, connecting without GSS encryption", logFields{"error": err})
+		return d.fallback(conn, network, address, timeout)
+	}
+
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+	gssConn, err := d.negotiate(conn, address, gss)
+	switch {
+	case err != nil && d.mode == "require":
+		conn.Close()
+		return nil, err
+	case err != nil:
+		conn.Close()
+		logEvent("DEBUG", "Error encrypting the connection with GSSAPI, connecting without GSS encryption", logFields{"error": err})
+		if conn, err = d.forward.DialTimeout(network, address, timeout); err != nil {
+			return nil, err
+		}
+		return d.fallback(conn, network, address, timeout)
+	case gssConn == nil && d.mode == "require":
+		conn.Close()
+		return nil, fmt.Errorf("gssencmode require, but the server does not support GSS encryption")
+	case gssConn == nil:
+		return d.fallback(conn, network, address, timeout)
+	}
+	gssConn.SetDeadline(time.Time{})
+
+	return gssConn, nil
+}
+
+// fallback returns conn, not encrypted with GSSAPI, with SSL if ssl is set.
+func (d *gssEncDialer) fallback(conn net.Conn, network, address string, timeout time.Duration) (net.Conn, error) {
+	if d.ssl == nil {
+		conn.SetDeadline(time.Time{})
+		return conn, nil
+	}
+
+	return d.ssl.upgrade(conn, network, address, timeout)
+}
+
+// negotiate asks the server to encrypt conn with GSSAPI and establishes the
+// security context, or returns nil if the server does not support it.
+func (d *gssEncDialer) negotiate(conn net.Conn, address string, gss *kerberosGSS) (*gssConn, error) {
+	req := make([]byte, 8)
+	binary.BigEndian.PutUint32(req[0:4], 8)
+	binary.BigEndian.PutUint32(req[4:8], gssEncRequestCode)
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	resp := make([]byte, 1)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+	switch resp[0] {
+	case 'G':
+	case 'N':
+		return nil, nil
+	default:
+		// Servers before PostgreSQL 12 answer with an error, and close
+		// the connection.
+		return nil, fmt.Errorf("unexpected response %q to GSS encryption request", resp[0])
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	sec, token, err := gss.initSecContext(host, d.service)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeGSSPacket(conn, token); err != nil {
+		return nil, err
+	}
+
+	// The server answers with the AP-REP of the mutual authentication.
+	token, err = readGSSPacket(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return sec.complete(conn, token)
+}
+
+// krb5SecContext is the Kerberos security context of a GSS-encrypted
+// connection being established by the initiator (RFC 4121).
+type krb5SecContext struct {
+	sessionKey    types.EncryptionKey
+	authenticator types.Authenticator
+}
+
+// initSecContext returns the security context for the service on host, and
+// the initial context token to send to the server, asking for mutual
+// authentication and confidentiality as libpq does.
+func (g *kerberosGSS) initSecContext(host, service string) (*krb5SecContext, []byte, error) {
+	tkt, key, err := g.client.GetServiceTicket(g.spn(host, service))
+	if err != nil {
+		return nil, nil, errwrap.Wrapf("Error getting a Kerberos service ticket: {{err}}", err)
+	}
+
+	auth, err := types.NewAuthenticator(g.client.Credentials.Domain(), g.client.Credentials.CName())
+	if err != nil {
+		return nil, nil, err
+	}
+	// The checksum of the GSSAPI flags (RFC 4121 section 4.1.1), without
+	// channel bindings.
+	checksum := make([]byte, 24)
+	binary.LittleEndian.PutUint32(checksum[0:4], 16)
+	binary.LittleEndian.PutUint32(checksum[20:24], uint32(gssapi.ContextFlagMutual|gssapi.ContextFlagReplay|
+		gssapi.ContextFlagSequence|gssapi.ContextFlagConf|gssapi.ContextFlagInteg))
+	auth.Cksum = types.Checksum{CksumType: chksumtype.GSSAPI, Checksum: checksum}
+
+	apReq, err := messages.NewAPReq(tkt, key, auth)
+	if err != nil {
+		return nil, nil, err
+	}
+	types.SetFlag(&apReq.APOptions, flags.APOptionMutualRequired)
+	b, err := apReq.Marshal()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token, _ := asn1.Marshal(gssapi.OIDKRB5.OID())
+	token = append(token, 0x01, 0x00)
+	token = asn1tools.AddASNAppTag(append(token, b...), 0)
+
+	return &krb5SecContext{sessionKey: key, authenticator: auth}, token, nil
+}
+
+// complete processes the server's context token, the AP-REP, and returns conn
+// encrypted with the established context.
+func (sec *krb5SecContext) complete(conn net.Conn, token []byte) (*gssConn, error) {
+	var krb5Token spnego.KRB5Token
+	if err := krb5Token.Unmarshal(token); err != nil {
+		return nil, err
+	}
+	if krb5Token.IsKRBError() {
+		return nil, fmt.Errorf("the server rejected the Kerberos authentication: %s", krb5Token.KRBError.Error())
+	}
+	if !krb5Token.IsAPRep() {
+		return nil, fmt.Errorf("unexpected Kerberos context token")
+	}
+
+	b, err := crypto.DecryptEncPart(krb5Token.APRep.EncPart, sec.sessionKey, keyusage.AP_REP_ENCPART)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error decrypting the Kerberos AP-REP: {{err}}", err)
+	}
+	var encPart messages.EncAPRepPart
+	if err := encPart.Unmarshal(b); err != nil {
+		return nil, err
+	}
+	if !encPart.CTime.Equal(sec.authenticator.CTime.Truncate(time.Second)) || encPart.Cusec != sec.authenticator.Cusec {
+		return nil, fmt.Errorf("the Kerberos AP-REP does not match the authentication")
+	}
+
+	c := &gssConn{
+		Conn:    conn,
+		key:     sec.sessionKey,
+		sendSeq: uint64(sec.authenticator.SeqNumber),
+		recvSeq: uint64(encPart.SequenceNumber),
+	}
+	if len(encPart.Subkey.KeyValue) != 0 {
+		c.key = encPart.Subkey
+		c.acceptorSubkey = true
+	}
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// gssConn is a connection encrypted with the wrap tokens of a Kerberos
+// security context (RFC 4121), in the packets of PostgreSQL's GSS encryption:
+// each token preceded by its length.
+type gssConn struct {
+	net.Conn
+
+	// acceptor is set on the server side of the connection, in tests.
+	acceptor bool
+
+	key            types.EncryptionKey
+	acceptorSubkey bool
+	etype          etype.EType
+
+	sendSeq uint64
+	recvSeq uint64
+
+	// raw is what was read of the next packet, and pending what is left to
+	// be read of the data of the last one.
+	raw     []byte
+	pending []byte
+}
+
+// init checks that the key can seal wrap tokens: only the encryption types of
+// RFC 4121 can, which excludes RC4 and DES3.
+func (c *gssConn) init() error {
+	switch c.key.KeyType {
+	case etypeID.AES128_CTS_HMAC_SHA1_96, etypeID.AES256_CTS_HMAC_SHA1_96, etypeID.AES128_CTS_HMAC_SHA256_128, etypeID.AES256_CTS_HMAC_SHA384_192:
+	default:
+		return fmt.Errorf("GSS encryption requires an AES Kerberos session key, got encryption type %d", c.key.KeyType)
+	}
+
+	var err error
+	c.etype, err = crypto.GetEtype(c.key.KeyType)
+
+	return err
+}
+
+func (c *gssConn) Write(b []byte) (int, error) {
+	for written := 0; written < len(b); {
+		n := len(b) - written
+		if n > gssMaxData {
+			n = gssMaxData
+		}
+
+		token, err := c.wrap(b[written : written+n])
+		if err != nil {
+			return written, err
+		}
+		if err := writeGSSPacket(c.Conn, token); err != nil {
+			return written, err
+		}
+		written += n
+	}
+
+	return len(b), nil
+}
+
+func (c *gssConn) Read(b []byte) (int, error) {
+	for len(c.pending) == 0 {
+		if err := c.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(b, c.pending)
+	c.pending = c.pending[n:]
+
+	return n, nil
+}
+
+// next reads the next packet, keeping what was read if the read fails, e.g.
+// on a deadline, and unwraps its data.
+func (c *gssConn) next() error {
+	for {
+		if len(c.raw) >= 4 {
+			n := int(binary.BigEndian.Uint32(c.raw[0:4]))
+			if n > gssMaxPacket {
+				return fmt.Errorf("invalid GSS packet length %d", n)
+			}
+			if len(c.raw) >= 4+n {
+				token := c.raw[4 : 4+n]
+				c.raw = c.raw[4+n:]
+
+				var err error
+				c.pending, err = c.unwrap(token)
+				return err
+			}
+		}
+
+		buf := make([]byte, gssMaxPacket)
+		n, err := c.Conn.Read(buf)
+		c.raw = append(c.raw, buf[:n]...)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// wrap returns the wrap token sealing data.
+func (c *gssConn) wrap(data []byte) ([]byte, error) {
+	header := make([]byte, 16)
+	header[0], header[1] = 0x05, 0x04
+	header[2] = gssSealed
+	if c.acceptor {
+		header[2] |= gssSentByAcceptor
+	}
+	if c.acceptorSubkey {
+		header[2] |= gssAcceptorSubkey
+	}
+	header[3] = 0xff
+	binary.BigEndian.PutUint64(header[8:16], c.sendSeq)
+
+	usage := uint32(keyusage.GSSAPI_INITIATOR_SEAL)
+	if c.acceptor {
+		usage = keyusage.GSSAPI_ACCEPTOR_SEAL
+	}
+	plaintext := append(append([]byte{}, data...), header...)
+	_, ciphertext, err := c.etype.EncryptMessage(c.key.KeyValue, plaintext, usage)
+	if err != nil {
+		return nil, err
+	}
+	c.sendSeq++
+
+	return append(header, ciphertext...), nil
+}
+
+// unwrap returns the data sealed in a wrap token of the peer.
+func (c *gssConn) unwrap(token []byte) ([]byte, error) {
+	if len(token) < 16 || token[0] != 0x05 || token[1] != 0x04 || token[3] != 0xff {
+		return nil, fmt.Errorf("invalid GSS wrap token")
+	}
+	header := token[:16]
+	flags := header[2]
+	if flags&gssSealed == 0 || (flags&gssSentByAcceptor != 0) == c.acceptor || (flags&gssAcceptorSubkey != 0) != c.acceptorSubkey {
+		return nil, fmt.Errorf("unexpected GSS wrap token flags %#x", flags)
+	}
+	if seq := binary.BigEndian.Uint64(header[8:16]); seq != c.recvSeq {
+		return nil, fmt.Errorf("unexpected GSS wrap token sequence number %d, expected %d", seq, c.recvSeq)
+	}
+	ec := int(binary.BigEndian.Uint16(header[4:6]))
+	rrc := int(binary.BigEndian.Uint16(header[6:8]))
+
+	// Undo the rotation of the ciphertext by RRC bytes to the right.
+	ciphertext := token[16:]
+	if len(ciphertext) != 0 {
+		rrc %= len(ciphertext)
+		ciphertext = append(append([]byte{}, ciphertext[rrc:]...), ciphertext[:rrc]...)
+	}
+
+	usage := uint32(keyusage.GSSAPI_ACCEPTOR_SEAL)
+	if c.acceptor {
+		usage = keyusage.GSSAPI_INITIATOR_SEAL
+	}
+	plaintext, err := c.etype.DecryptMessage(c.key.KeyValue, ciphertext, usage)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error decrypting GSS wrap token: {{err}}", err)
+	}
+	if len(plaintext) < 16+ec {
+		return nil, fmt.Errorf("invalid GSS wrap token")
+	}
+
+	// The sealed copy of the header has an RRC of zero.
+	sealedHeader := append([]byte{}, header...)
+	sealedHeader[6], sealedHeader[7] = 0, 0
+	if !bytes.Equal(plaintext[len(plaintext)-16:], sealedHeader) {
+		return nil, fmt.Errorf("invalid GSS wrap token header")
+	}
+	c.recvSeq++
+
+	return plaintext[:len(plaintext)-16-ec], nil
+}
+
+// writeGSSPacket writes token preceded by its length.
+func writeGSSPacket(w io.Writer, token []byte) error {
+	if len(token) > gssMaxPacket-4 {
+		return fmt.Errorf("GSS token of %d bytes too large", len(token))
+	}
+
+	packet := make([]byte, 4, 4+len(token))
+	binary.BigEndian.PutUint32(packet, uint32(len(token)))
+	_, err := w.Write(append(packet, token...))
+
+	return err
+}
+
+// readGSSPacket reads a context token preceded by its length, or reports the
+// error the server sends instead.
+func readGSSPacket(r io.Reader) ([]byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if header[0] == 'E' {
+		_, body, err := readMessage(io.MultiReader(bytes.NewReader(header), r))
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("GSS encryption failed: %s", errorResponseMessage(body))
+	}
+
+	n := binary.BigEndian.Uint32(header)
+	if n > gssMaxPacket {
+		return nil, fmt.Errorf("invalid GSS packet length %d", n)
+	}
+	token := make([]byte, n)
+	if _, err := io.ReadFull(r, token); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// errorResponseMessage returns the message of the body of an ErrorResponse.
+func errorResponseMessage(body []byte) string {
+	for _, field := range strings.Split(string(body), "\x00") {
+		if strings.HasPrefix(field, "M") {
+			return field[1:]
+		}
+	}
+
+	return "unknown error"
+}
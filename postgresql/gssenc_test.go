@@ -0,0 +1,251 @@
+package postgresql
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jcmturner/gofork/encoding/asn1"
+	"github.com/jcmturner/gokrb5/v8/asn1tools"
+	"github.com/jcmturner/gokrb5/v8/crypto"
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/iana/asnAppTag"
+	"github.com/jcmturner/gokrb5/v8/iana/etypeID"
+	"github.com/jcmturner/gokrb5/v8/iana/keyusage"
+	"github.com/jcmturner/gokrb5/v8/iana/msgtype"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/types"
+)
+
+func testKerberosKey(t *testing.T, keyType int32, size int) types.EncryptionKey {
+	key := types.EncryptionKey{KeyType: keyType, KeyValue: make([]byte, size)}
+	if _, err := rand.Read(key.KeyValue); err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+// testAPRep returns the context token of the AP-REP answering auth,
+// encrypted with sessionKey.
+func testAPRep(t *testing.T, sessionKey types.EncryptionKey, auth types.Authenticator, subkey types.EncryptionKey, seq int64) []byte {
+	b, err := asn1.Marshal(messages.EncAPRepPart{
+		CTime:          auth.CTime,
+		Cusec:          auth.Cusec,
+		Subkey:         subkey,
+		SequenceNumber: seq,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encPart, err := crypto.GetEncryptedData(asn1tools.AddASNAppTag(b, asnAppTag.EncAPRepPart), sessionKey, keyusage.AP_REP_ENCPART, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = asn1.Marshal(messages.APRep{PVNO: 5, MsgType: msgtype.KRB_AP_REP, EncPart: encPart})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _ := asn1.Marshal(gssapi.OIDKRB5.OID())
+	token = append(append(token, 0x02, 0x00), asn1tools.AddASNAppTag(b, asnAppTag.APREP)...)
+
+	return asn1tools.AddASNAppTag(token, 0)
+}
+
+func TestKrb5SecContextComplete(t *testing.T) {
+	sessionKey := testKerberosKey(t, etypeID.AES256_CTS_HMAC_SHA1_96, 32)
+	subkey := testKerberosKey(t, etypeID.AES128_CTS_HMAC_SHA256_128, 16)
+	auth, err := types.NewAuthenticator("EXAMPLE.COM", types.NewPrincipalName(1, "terraform"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec := &krb5SecContext{sessionKey: sessionKey, authenticator: auth}
+
+	c, err := sec.complete(nil, testAPRep(t, sessionKey, auth, subkey, 42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.acceptorSubkey || c.key.KeyType != subkey.KeyType || !bytes.Equal(c.key.KeyValue, subkey.KeyValue) {
+		t.Errorf("expected the acceptor subkey to be used, got %+v", c.key)
+	}
+	if c.sendSeq != uint64(auth.SeqNumber) || c.recvSeq != 42 {
+		t.Errorf("expected the sequence numbers %d and 42, got %d and %d", auth.SeqNumber, c.sendSeq, c.recvSeq)
+	}
+
+	c, err = sec.complete(nil, testAPRep(t, sessionKey, auth, types.EncryptionKey{}, 42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.acceptorSubkey || !bytes.Equal(c.key.KeyValue, sessionKey.KeyValue) {
+		t.Errorf("expected the session key to be used without acceptor subkey, got %+v", c.key)
+	}
+
+	other := auth
+	other.CTime = auth.CTime.Add(-time.Minute)
+	if _, err := sec.complete(nil, testAPRep(t, sessionKey, other, subkey, 42)); err == nil {
+		t.Error("expected an AP-REP not answering the authenticator to be rejected")
+	}
+	if _, err := sec.complete(nil, testAPRep(t, testKerberosKey(t, etypeID.AES256_CTS_HMAC_SHA1_96, 32), auth, subkey, 42)); err == nil {
+		t.Error("expected an AP-REP encrypted with another key to be rejected")
+	}
+
+	rc4 := testKerberosKey(t, etypeID.RC4_HMAC, 16)
+	if _, err := sec.complete(nil, testAPRep(t, sessionKey, auth, rc4, 42)); err == nil || !strings.Contains(err.Error(), "AES") {
+		t.Errorf("expected RC4 to be rejected, got %v", err)
+	}
+}
+
+// testGSSConns returns both ends of a GSS-encrypted connection.
+func testGSSConns(t *testing.T, key types.EncryptionKey) (client, server *gssConn) {
+	clientConn, serverConn := net.Pipe()
+	client = &gssConn{Conn: clientConn, key: key, acceptorSubkey: true, sendSeq: 7, recvSeq: 1000}
+	server = &gssConn{Conn: serverConn, acceptor: true, key: key, acceptorSubkey: true, sendSeq: 1000, recvSeq: 7}
+	for _, c := range []*gssConn{client, server} {
+		if err := c.init(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return client, server
+}
+
+func TestGSSConn(t *testing.T) {
+	for _, keyType := range []int32{etypeID.AES128_CTS_HMAC_SHA1_96, etypeID.AES256_CTS_HMAC_SHA384_192} {
+		size := 16
+		if keyType == etypeID.AES256_CTS_HMAC_SHA384_192 {
+			size = 32
+		}
+		client, server := testGSSConns(t, testKerberosKey(t, keyType, size))
+
+		// Messages larger than a packet are split.
+		data := make([]byte, 3*gssMaxData+1)
+		rand.Read(data)
+		errs := make(chan error, 1)
+		go func() {
+			_, err := client.Write(data)
+			errs <- err
+		}()
+		received := make([]byte, len(data))
+		if _, err := io.ReadFull(server, received); err != nil {
+			t.Fatal(err)
+		}
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(received, data) {
+			t.Errorf("%d: expected the data to be received as sent", keyType)
+		}
+
+		go func() {
+			_, err := server.Write([]byte("SELECT 1"))
+			errs <- err
+		}()
+		received = make([]byte, len("SELECT 1"))
+		if _, err := io.ReadFull(client, received); err != nil || string(received) != "SELECT 1" {
+			t.Errorf("%d: expected the server's data, got %q: %v", keyType, received, err)
+		}
+		<-errs
+
+		client.Close()
+		server.Close()
+	}
+}
+
+func TestGSSConnUnwrap(t *testing.T) {
+	client, server := testGSSConns(t, testKerberosKey(t, etypeID.AES256_CTS_HMAC_SHA1_96, 32))
+	defer client.Close()
+	defer server.Close()
+
+	// Like Windows, rotate the ciphertext by 28 bytes to the right.
+	token, err := server.wrap([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := token[16:]
+	rotated := append(append([]byte{}, token[:16]...), ciphertext[len(ciphertext)-28:]...)
+	rotated = append(rotated, ciphertext[:len(ciphertext)-28]...)
+	binary.BigEndian.PutUint16(rotated[6:8], 28)
+	if data, err := client.unwrap(rotated); err != nil || string(data) != "data" {
+		t.Errorf("expected the rotated token to be unwrapped, got %q: %v", data, err)
+	}
+
+	token, _ = server.wrap([]byte("data"))
+	tampered := append([]byte{}, token...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := client.unwrap(tampered); err == nil {
+		t.Error("expected a tampered token to be rejected")
+	}
+
+	// The token whose unwrapping failed is still expected.
+	if _, err := client.unwrap(token); err != nil {
+		t.Error(err)
+	}
+	if _, err := client.unwrap(token); err == nil || !strings.Contains(err.Error(), "sequence number") {
+		t.Errorf("expected a replayed token to be rejected, got %v", err)
+	}
+
+	own, _ := client.wrap([]byte("data"))
+	if _, err := client.unwrap(own); err == nil {
+		t.Error("expected a token of the initiator to be rejected by the initiator")
+	}
+}
+
+func TestGSSEncDialer(t *testing.T) {
+	// A server answering GSS encryption requests with response, then
+	// echoing.
+	var response byte
+	server := listen(t, func(conn net.Conn) {
+		req := make([]byte, 8)
+		if _, err := io.ReadFull(conn, req); err != nil || binary.BigEndian.Uint32(req[4:8]) != gssEncRequestCode {
+			return
+		}
+		conn.Write([]byte{response})
+		if response == 'N' {
+			io.Copy(conn, conn)
+		}
+	})
+	defer server.Close()
+
+	credentials := func() (*kerberosGSS, error) { return &kerberosGSS{}, nil }
+	noCredentials := func() (*kerberosGSS, error) { return nil, fmt.Errorf("no credential cache") }
+
+	tests := []struct {
+		mode     string
+		gss      func() (*kerberosGSS, error)
+		response byte
+		valid    bool
+	}{
+		{"prefer", credentials, 'N', true},
+		{"require", credentials, 'N', false},
+		{"prefer", credentials, 'E', true},
+		{"require", credentials, 'E', false},
+		{"prefer", noCredentials, 'N', true},
+		{"require", noCredentials, 'N', false},
+	}
+
+	for _, test := range tests {
+		response = test.response
+		d := &gssEncDialer{mode: test.mode, service: "postgres", gss: test.gss, forward: netDialer{}}
+		conn, err := d.DialTimeout("tcp", server.Addr().String(), 5*time.Second)
+		if !test.valid {
+			if err == nil {
+				conn.Close()
+				t.Errorf("%s with %q: expected an error", test.mode, test.response)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s with %q: %v", test.mode, test.response, err)
+			continue
+		}
+		conn.Close()
+	}
+
+}
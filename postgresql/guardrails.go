@@ -0,0 +1,117 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/lib/pq"
+)
+
+// escalationRoles are the predefined roles letting their members write the
+// files of the server or run programs as its operating system user, hence
+// become superusers.  Together with CREATEROLE, which lets a role grant
+// memberships to others, they hand out superuser-equivalent privileges.
+var escalationRoles = []string{
+	"pg_execute_server_program",
+	"pg_write_server_files",
+}
+
+// isSystemSchema returns whether the schema is one of the system's:
+// information_schema, or the ones named pg_*, which are reserved, e.g.
+// pg_catalog and pg_toast.
+func isSystemSchema(name string) bool {
+	return name == "information_schema" || strings.HasPrefix(name, "pg_")
+}
+
+// validateGrantSchema warns, when planning, about the privileges granted on the
+// objects of system schemas, which are refused when applied unless the
+// provider's allow_unsafe_grants is set.
+func validateGrantSchema(v interface{}, key string) (warnings []string, errors []error) {
+	if isSystemSchema(v.(string)) {
+		warnings = append(warnings, fmt.Sprintf("%s %q is a system schema: privileges on its objects are only granted with the provider's allow_unsafe_grants", key, v.(string)))
+	}
+	return
+}
+
+// checkUnsafeGrant returns the error refusing the unsafe grant described, or
+// logs it as a warning and returns nil with allow_unsafe_grants.
+func (c *Client) checkUnsafeGrant(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if c.config.AllowUnsafeGrants {
+		logEvent("WARN", "Unsafe grant allowed by allow_unsafe_grants", logFields{"grant": msg})
+		return nil
+	}
+
+	return fmt.Errorf("Refusing to apply an unsafe grant, set the provider's allow_unsafe_grants to apply it anyway: %s", msg)
+}
+
+// checkSystemSchemaGrant refuses the grants on the objects of system schemas,
+// or on the system schemas themselves.
+func checkSystemSchemaGrant(c *Client, g *grant) error {
+	if g.schema != "" && isSystemSchema(g.schema) {
+		return c.checkUnsafeGrant("privileges on the %s objects of system schema %s to role %s", g.objectType, g.schema, g.role)
+	}
+	if g.objectType == "schema" {
+		for _, object := range g.objects {
+			if isSystemSchema(object) {
+				return c.checkUnsafeGrant("privileges on system schema %s to role %s", object, g.role)
+			}
+		}
+	}
+
+	return nil
+}
+
+// escalationMemberships returns the escalationRoles the role is a member of,
+// directly or not, or is itself.  The ones the server does not have are left
+// out.
+func escalationMemberships(q queryer, role string) ([]string, error) {
+	rows, err := q.Query(`SELECT r.rolname FROM pg_catalog.pg_roles r `+
+		`WHERE r.rolname = ANY($2) AND CASE WHEN EXISTS (SELECT 1 FROM pg_catalog.pg_roles m WHERE m.rolname = $1) `+
+		`THEN pg_catalog.pg_has_role($1, r.oid, 'MEMBER') ELSE false END ORDER BY r.rolname`, role, pq.Array(escalationRoles))
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("Error reading the memberships of role %s: {{err}}", role), err)
+	}
+	defer rows.Close()
+
+	var roles []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("Error reading the memberships of role %s: {{err}}", role), err)
+		}
+		roles = append(roles, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("Error reading the memberships of role %s: {{err}}", role), err)
+	}
+
+	return roles, nil
+}
+
+// checkCreateRoleEscalation refuses to give a role both CREATEROLE and
+// membership in the escalationRoles: CREATEROLE, with granted empty, or
+// membership in granted, if the role has CREATEROLE.
+func checkCreateRoleEscalation(c *Client, q queryer, role, granted string) error {
+	if granted == "" {
+		roles, err := escalationMemberships(q, role)
+		if err != nil || len(roles) == 0 {
+			return err
+		}
+		return c.checkUnsafeGrant("CREATEROLE to role %s, a member of %s", role, strings.Join(roles, ", "))
+	}
+
+	var createRole bool
+	if err := q.QueryRow("SELECT COALESCE((SELECT rolcreaterole FROM pg_catalog.pg_roles WHERE rolname = $1), false)", role).Scan(&createRole); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error reading role %s: {{err}}", role), err)
+	}
+	if !createRole {
+		return nil
+	}
+	roles, err := escalationMemberships(q, granted)
+	if err != nil || len(roles) == 0 {
+		return err
+	}
+	return c.checkUnsafeGrant("membership in role %s, giving %s, to role %s with CREATEROLE", granted, strings.Join(roles, ", "), role)
+}
@@ -0,0 +1,72 @@
+package postgresql
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestCheckSystemSchemaGrant(t *testing.T) {
+	tests := []struct {
+		g      grant
+		unsafe bool
+	}{
+		{g: grant{role: "app", schema: "public", objectType: "table"}},
+		{g: grant{role: "app", schema: "pg_catalog", objectType: "table"}, unsafe: true},
+		{g: grant{role: "app", schema: "information_schema", objectType: "table"}, unsafe: true},
+		{g: grant{role: "app", schema: "pg_toast", objectType: "table", objects: []string{"pg_toast_2619"}}, unsafe: true},
+		{g: grant{role: "app", objectType: "schema", objects: []string{"app", "pg_catalog"}}, unsafe: true},
+		{g: grant{role: "app", objectType: "schema", objects: []string{"app", "pgbench"}}},
+		{g: grant{role: "app", objectType: "database", objects: []string{"pg_app"}}},
+	}
+
+	for _, test := range tests {
+		err := checkSystemSchemaGrant(&Client{}, &test.g)
+		if (err != nil) != test.unsafe {
+			t.Errorf("%+v: expected unsafe %t, got %v", test.g, test.unsafe, err)
+		}
+
+		// allow_unsafe_grants only warns.
+		if err := checkSystemSchemaGrant(&Client{config: Config{AllowUnsafeGrants: true}}, &test.g); err != nil {
+			t.Errorf("%+v: expected no error with allow_unsafe_grants, got %v", test.g, err)
+		}
+
+		warnings, errors := validateGrantSchema(test.g.schema, grantSchemaAttr)
+		if len(errors) > 0 || (len(warnings) > 0) != (test.g.schema != "" && test.unsafe) {
+			t.Errorf("%+v: unexpected warnings %q or errors %v", test.g, warnings, errors)
+		}
+	}
+}
+
+func TestAccPostgresqlGrantRole_CreateRoleEscalation(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP ROLE IF EXISTS escalation_files",
+		"DROP ROLE IF EXISTS escalation_admin",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE ROLE escalation_files",
+				"GRANT pg_write_server_files TO escalation_files",
+				"CREATE ROLE escalation_admin CREATEROLE",
+			)
+		},
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccPostgresqlGrantRoleEscalationConfig,
+				ExpectError: regexp.MustCompile("allow_unsafe_grants"),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlGrantRoleEscalationConfig = `
+resource "postgresql_grant_role" "escalation" {
+  role       = "escalation_admin"
+  grant_role = "escalation_files"
+}
+`
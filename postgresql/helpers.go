@@ -1,8 +1,20 @@
 package postgresql
 
 import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
 )
 
 // pqQuoteLiteral returns a string literal safe for inclusion in a PostgreSQL
@@ -15,6 +27,63 @@ func pqQuoteLiteral(in string) string {
 	return in
 }
 
+// passwordChars are the characters of the passwords generatePassword
+// generates, which need no quoting in connection strings and URIs.
+const passwordChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// generatePassword returns a random password of 32 passwordChars, drawn from
+// the system's cryptographically secure random number generator.
+func generatePassword() (string, error) {
+	b := make([]byte, 32)
+	for i := range b {
+		// Drop the values making some characters likelier than others.
+		for {
+			var r [1]byte
+			if _, err := rand.Read(r[:]); err != nil {
+				return "", err
+			}
+			if int(r[0]) < 256-256%len(passwordChars) {
+				b[i] = passwordChars[int(r[0])%len(passwordChars)]
+				break
+			}
+		}
+	}
+
+	return string(b), nil
+}
+
+// setToSortedStrings returns the strings of the set, sorted.
+func setToSortedStrings(set *schema.Set) []string {
+	strs := make([]string, 0, set.Len())
+	for _, v := range set.List() {
+		strs = append(strs, v.(string))
+	}
+	sort.Strings(strs)
+
+	return strs
+}
+
+// stringInSlice returns true if the string is one of the slice's.
+func stringInSlice(s string, slice []string) bool {
+	for _, v := range slice {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// quoteIdentifiers returns the identifiers, quoted, separated by commas.
+func quoteIdentifiers(identifiers []string) string {
+	quoted := make([]string, len(identifiers))
+	for i, identifier := range identifiers {
+		quoted[i] = pq.QuoteIdentifier(identifier)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
 func validateConnLimit(v interface{}, key string) (warnings []string, errors []error) {
 	value := v.(int)
 	if value < -1 {
@@ -22,3 +91,285 @@ func validateConnLimit(v interface{}, key string) (warnings []string, errors []e
 	}
 	return
 }
+
+// dataSourceID returns a stable ID for a data source derived from the data
+// source's kind and the filters it was read with.
+func dataSourceID(kind string, filters ...string) string {
+	return strings.Join(append([]string{kind}, filters...), ".")
+}
+
+// importID returns the ID of a resource made of parts, which its importer
+// splits back with splitImportID.
+func importID(parts ...string) string {
+	return strings.Join(parts, "/")
+}
+
+// splitImportID splits the ID of a resource being imported into at least min
+// and at most max parts, or returns an error describing the expected format.
+// The last part may itself contain slashes.
+func splitImportID(id string, min, max int, format string) ([]string, error) {
+	parts := strings.SplitN(id, "/", max)
+	if len(parts) < min {
+		return nil, fmt.Errorf("invalid ID %q, expected %s", id, format)
+	}
+
+	return parts, nil
+}
+
+// databaseObjectImportFormat is the format of the IDs of the resources of
+// objects in a database, e.g. schemas.  Objects of the provider's database can
+// be imported with their name alone.
+const databaseObjectImportFormat = "[database/]name"
+
+// databaseObjectID returns the ID of the resource of the object named name in
+// the database, or the provider's if empty, in databaseObjectImportFormat.
+func (c *Client) databaseObjectID(database, name string) string {
+	return importID(c.databaseName(database), name)
+}
+
+// databaseObjectName returns the name of the object in the ID of its resource,
+// which is the whole ID for the resources created before their IDs had the
+// database.
+func databaseObjectName(id string) string {
+	if i := strings.Index(id, "/"); i >= 0 {
+		return id[i+1:]
+	}
+
+	return id
+}
+
+// importDatabaseObject returns the importer of the resources of objects in a
+// database, setting the database and the name attributes from the ID.
+func importDatabaseObject(databaseAttr, nameAttr string) schema.StateContextFunc {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+		parts, err := splitImportID(d.Id(), 1, 2, databaseObjectImportFormat)
+		if err != nil {
+			return nil, err
+		}
+
+		database, name := "", parts[0]
+		if len(parts) == 2 {
+			database, name = parts[0], parts[1]
+		}
+		c := meta.(*Client)
+		d.Set(databaseAttr, c.databaseName(database))
+		d.Set(nameAttr, name)
+		d.SetId(c.databaseObjectID(database, name))
+
+		return []*schema.ResourceData{d}, nil
+	}
+}
+
+// userSchemasCond returns a SQL condition that filters out the system schemas
+// (pg_catalog, information_schema, TOAST and temporary schemas) from the
+// namespace name column nspCol.
+func userSchemasCond(nspCol string) string {
+	return fmt.Sprintf(`%[1]s NOT IN ('pg_catalog', 'information_schema') AND %[1]s NOT LIKE 'pg\_toast%%' AND %[1]s NOT LIKE 'pg\_temp\_%%'`, nspCol)
+}
+
+// nullTimeToString formats t in RFC 3339 format, or returns an empty string
+// if t is NULL.
+func nullTimeToString(t pq.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.Format(time.RFC3339Nano)
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx.
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// quoteGrantee returns the role as it is written in GRANT and REVOKE, where
+// PUBLIC is a keyword, as is GROUP before the groups of Redshift, e.g. "GROUP
+// analysts".
+func quoteGrantee(role string) string {
+	if strings.ToUpper(role) == "PUBLIC" {
+		return "PUBLIC"
+	}
+	if len(role) > 6 && strings.ToUpper(role[:6]) == "GROUP " {
+		return "GROUP " + pq.QuoteIdentifier(role[6:])
+	}
+
+	return pq.QuoteIdentifier(role)
+}
+
+// grantRoleMembership makes the connection user a member of role, which a
+// non-superuser needs in order to create objects owned by role, transfer
+// objects to it, or reassign its objects.  It returns true if the membership
+// was granted and must be revoked with revokeRoleMembership once done.
+// Nothing is granted to superusers or to users which already are members of
+// role.
+// Callers hold c.lockRoleMembership(role) until the membership is revoked.
+func grantRoleMembership(c *Client, q queryer, role string) (bool, error) {
+	if role == "" || role == c.config.Username || c.superuser {
+		return false, nil
+	}
+	if err := checkAuroraOwner(c, role); err != nil {
+		return false, err
+	}
+
+	var isMember bool
+	if err := q.QueryRow("SELECT pg_catalog.pg_has_role($1, $2, 'MEMBER')", c.config.Username, role).Scan(&isMember); err != nil {
+		return false, errwrap.Wrapf(fmt.Sprintf("Error checking membership of connection user (%q) in ROLE %q: {{err}}", c.config.Username, role), err)
+	}
+	if isMember {
+		return false, nil
+	}
+
+	sql := fmt.Sprintf("GRANT %s TO %s", pq.QuoteIdentifier(role), pq.QuoteIdentifier(c.config.Username))
+	if _, err := q.Exec(sql); err != nil {
+		return false, errwrap.Wrapf(fmt.Sprintf("Error adding connection user (%q) to ROLE %q: {{err}}", c.config.Username, role), err)
+	}
+
+	return true, nil
+}
+
+// revokeRoleMembership undoes grantRoleMembership.
+func revokeRoleMembership(c *Client, q queryer, role string) error {
+	sql := fmt.Sprintf("REVOKE %s FROM %s", pq.QuoteIdentifier(role), pq.QuoteIdentifier(c.config.Username))
+	if _, err := q.Exec(sql); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error removing connection user (%q) from ROLE %q: {{err}}", c.config.Username, role), err)
+	}
+
+	return nil
+}
+
+// attrChanges are the attributes of a resource the feature checks read: the
+// ones applied, or the ones planned.
+type attrChanges interface {
+	Id() string
+	Get(string) interface{}
+	GetOk(string) (interface{}, bool)
+	HasChange(string) bool
+}
+
+// valueKnown returns whether the value of attr is known, which it always is
+// when applied.  Values computed from other resources are only known at plan
+// time once these are applied.
+func valueKnown(d attrChanges, attr string) bool {
+	if diff, ok := d.(*schema.ResourceDiff); ok {
+		return diff.NewValueKnown(attr)
+	}
+
+	return true
+}
+
+// checkAttrFeatures returns an error if any of the attributes set or changed
+// requires a feature the server does not support, so that resources fail
+// before running any statement rather than with a syntax error halfway.
+func checkAttrFeatures(c *Client, d attrChanges, features map[string]featureName) error {
+	attrs := make([]string, 0, len(features))
+	for attr := range features {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+
+	for _, attr := range attrs {
+		if _, ok := d.GetOk(attr); !ok || !d.HasChange(attr) {
+			continue
+		}
+		if !c.featureSupported(features[attr]) {
+			return &attrError{attr: attr, err: c.featureError(features[attr], attr)}
+		}
+	}
+
+	return nil
+}
+
+// checkValueFeatures returns an error if the attribute attr is set or changed
+// to one of the values of features, whose feature the server does not
+// support.
+func checkValueFeatures(c *Client, d attrChanges, attr string, features map[string]featureName) error {
+	value, ok := d.GetOk(attr)
+	if !ok || !d.HasChange(attr) {
+		return nil
+	}
+	if feature, ok := features[value.(string)]; ok && !c.featureSupported(feature) {
+		return &attrError{attr: attr, err: c.featureError(feature, fmt.Sprintf("%s %q", attr, value))}
+	}
+
+	return nil
+}
+
+// planFeatures returns the CustomizeDiff of resources checking their plans
+// against the features of the server with check, so that the attributes the
+// server does not support fail the plan rather than the apply.  Resources
+// still run check before any statement: plans are only checked once the
+// provider can connect, with a configuration known at plan time.
+func planFeatures(check func(*Client, attrChanges) error) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		c := meta.(*Client)
+		if !c.connectForPlan() {
+			return nil
+		}
+
+		return check(c, d)
+	}
+}
+
+// connectForPlan connects to the server to check a plan against its features,
+// and returns false if the plan is not to be checked: when the provider's
+// configuration is only known at apply, or the server cannot be reached yet.
+func (c *Client) connectForPlan() bool {
+	if c.config.partial {
+		return false
+	}
+	if err := c.connect(); err != nil {
+		logEvent("WARN", "Not checking the plan against the server's features", logFields{"error": err})
+		return false
+	}
+
+	return true
+}
+
+// attrError is the error of a value of the attribute attr, which Terraform
+// shows against the attribute in the configuration.
+type attrError struct {
+	attr string
+	err  error
+}
+
+func (e *attrError) Error() string {
+	return e.err.Error()
+}
+
+// diagnostics returns the diagnostics of err, with the path of the attribute
+// at fault for an attrError.
+func diagnostics(err error) diag.Diagnostics {
+	if err == nil {
+		return nil
+	}
+
+	diags := diag.FromErr(err)
+	var attrErr *attrError
+	if errors.As(err, &attrErr) {
+		diags[0].AttributePath = cty.GetAttrPath(attrErr.attr)
+	}
+
+	return diags
+}
+
+// diagsError returns the error of the error diagnostics of diags, for logs and
+// reports that only have room for a message.
+func diagsError(diags diag.Diagnostics) error {
+	var msgs []string
+	for _, d := range diags {
+		if d.Severity != diag.Error {
+			continue
+		}
+		msg := d.Summary
+		if d.Detail != "" {
+			msg += ": " + d.Detail
+		}
+		msgs = append(msgs, msg)
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	return errors.New(strings.Join(msgs, "; "))
+}
@@ -0,0 +1,148 @@
+package postgresql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestGeneratePassword(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		password, err := generatePassword()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(password) != 32 {
+			t.Errorf("expected a password of 32 characters, got %q", password)
+		}
+		for _, c := range password {
+			if !strings.ContainsRune(passwordChars, c) {
+				t.Errorf("unexpected character %q in password %q", c, password)
+			}
+		}
+		if seen[password] {
+			t.Errorf("password %q generated twice", password)
+		}
+		seen[password] = true
+	}
+}
+
+func TestCheckAttrFeatures(t *testing.T) {
+	c := &Client{version: semver.MustParse("9.6.0")}
+	tests := []struct {
+		raw      map[string]interface{}
+		expected string
+	}{
+		{
+			raw: map[string]interface{}{roleNameAttr: "app"},
+		},
+		{
+			raw: map[string]interface{}{roleNameAttr: "app", roleIdleInTxnTimeoutAttr: "1min"},
+		},
+		{
+			raw:      map[string]interface{}{roleNameAttr: "app", roleIdleSessionTimeoutAttr: "1h"},
+			expected: "idle_session_timeout requires PostgreSQL 14.0.0, the server is 9.6.0",
+		},
+	}
+
+	for _, test := range tests {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, test.raw)
+		err := checkAttrFeatures(c, d, roleAttrFeatures)
+		switch {
+		case test.expected == "" && err != nil:
+			t.Errorf("%v: unexpected error: %v", test.raw, err)
+		case test.expected != "" && (err == nil || err.Error() != test.expected):
+			t.Errorf("%v: expected %q, got %v", test.raw, test.expected, err)
+		case err != nil:
+			diags := diagnostics(err)
+			if path := cty.GetAttrPath(roleIdleSessionTimeoutAttr); len(diags) != 1 || !diags[0].AttributePath.Equals(path) {
+				t.Errorf("%v: expected the diagnostic to point at %s, got %#v", test.raw, roleIdleSessionTimeoutAttr, diags)
+			}
+		}
+	}
+}
+
+func TestImportDatabaseObject(t *testing.T) {
+	c := &Client{config: Config{Database: "postgres"}}
+	r := resourcePostgreSQLSchema()
+	tests := []struct {
+		id       string
+		database string
+		name     string
+	}{
+		{"app/reporting", "app", "reporting"},
+		{"reporting", "postgres", "reporting"},
+		{"app/a/b", "app", "a/b"},
+	}
+
+	for _, test := range tests {
+		d := r.TestResourceData()
+		d.SetId(test.id)
+		states, err := r.Importer.StateContext(context.Background(), d, c)
+		if err != nil {
+			t.Fatalf("%q: %v", test.id, err)
+		}
+		d = states[0]
+		if database := d.Get(schemaDatabaseAttr).(string); database != test.database {
+			t.Errorf("%q: expected database %q, got %q", test.id, test.database, database)
+		}
+		if name := d.Get(schemaNameAttr).(string); name != test.name {
+			t.Errorf("%q: expected name %q, got %q", test.id, test.name, name)
+		}
+		if id := d.Id(); id != test.database+"/"+test.name {
+			t.Errorf("%q: expected the ID to name the database, got %q", test.id, id)
+		}
+		if name := databaseObjectName(d.Id()); name != test.name {
+			t.Errorf("%q: expected the name %q in the ID, got %q", test.id, test.name, name)
+		}
+	}
+
+	if name := databaseObjectName("reporting"); name != "reporting" {
+		t.Errorf("expected the IDs without a database to be the name, got %q", name)
+	}
+}
+
+func TestPlanFeatures(t *testing.T) {
+	tests := []struct {
+		raw      map[string]interface{}
+		partial  bool
+		expected string
+	}{
+		{
+			raw: map[string]interface{}{dbNameAttr: "app"},
+		},
+		{
+			raw:      map[string]interface{}{dbNameAttr: "app", dbIsTemplateAttr: true},
+			expected: "is_template requires PostgreSQL 9.5.0, the server is 9.4.0",
+		},
+		{
+			raw:      map[string]interface{}{dbNameAttr: "app", dbAllowConnsAttr: false},
+			expected: "allow_connections requires PostgreSQL 9.5.0, the server is 9.4.0",
+		},
+		{
+			raw:     map[string]interface{}{dbNameAttr: "app", dbIsTemplateAttr: true},
+			partial: true,
+		},
+	}
+
+	r := resourcePostgreSQLDatabase()
+	for _, test := range tests {
+		c := &Client{version: semver.MustParse("9.4.0"), config: Config{partial: test.partial}}
+		// The client is connected.
+		c.connectOnce.Do(func() {})
+
+		_, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(test.raw), c)
+		switch {
+		case test.expected == "" && err != nil:
+			t.Errorf("%v: unexpected error: %v", test.raw, err)
+		case test.expected != "" && (err == nil || !strings.Contains(err.Error(), test.expected)):
+			t.Errorf("%v: expected %q, got %v", test.raw, test.expected, err)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// connCheckTimeout is how long checking whether a pooled connection is still
+// open waits for the server to have closed it.
+const connCheckTimeout = time.Millisecond
+
+// connCheckIdle is how long a pooled connection is idle before it is checked
+// to be still open when reused.  Connections reused sooner are not: the
+// statements lost with them are retried, see liveConn.connError.
+const connCheckIdle = time.Minute
+
+// lastConnDialer is a pq.Dialer remembering the last connection it
+// established.
+type lastConnDialer struct {
+	forward pq.Dialer
+	conn    net.Conn
+}
+
+// Dial implements pq.Dialer.
+func (d *lastConnDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialTimeout(network, address, 0)
+}
+
+// DialTimeout implements pq.Dialer.
+func (d *lastConnDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	conn, err := d.forward.DialTimeout(network, address, timeout)
+	if err == nil {
+		d.conn = conn
+	}
+
+	return conn, err
+}
+
+// liveConn is a lib/pq connection which, before being reused from the pool
+// after connCheckIdle, checks that the server, or a firewall or VPN in
+// between, did not close its network connection in the meantime.  database/sql then replaces it with a
+// new connection, instead of lib/pq failing the next statement with an
+// unexpected EOF.
+//
+// When the connection is lost while running a statement, e.g. because the
+// server restarted, the statement is retried on a new connection if running
+// it twice is harmless.  Statements run in transactions are not, since the
+// transaction was rolled back with the connection.
+type liveConn struct {
+	driver.Conn
+	netConn net.Conn
+
+	inTx bool
+	lost bool
+
+	// used is when the connection last ran a statement.
+	used time.Time
+
+	// dryRun, if set, collects the statements changing the server instead
+	// of running them.
+	dryRun *dryRun
+
+	// audit, if set, records the statements changing the server, run by
+	// user, acting as role, in database.
+	audit    *auditLog
+	database string
+	user     string
+	role     string
+
+	stats *applyStats
+
+	// stop, if set, is the provider's stop context, which cancels the
+	// statements running when Terraform is interrupted, see
+	// statementContext.
+	stop context.Context
+}
+
+// Begin implements driver.Conn.
+func (c *liveConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx implements driver.ConnBeginTx.
+func (c *liveConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if c.lost {
+		return nil, driver.ErrBadConn
+	}
+
+	tx, err := c.Conn.(driver.ConnBeginTx).BeginTx(c.statementContext(ctx), opts)
+	if err != nil {
+		return nil, c.connError("BEGIN", err)
+	}
+	c.inTx = true
+
+	return &liveTx{Tx: tx, conn: c}, nil
+}
+
+// Exec implements driver.Execer.
+func (c *liveConn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	return c.ExecContext(context.Background(), query, namedValues(args))
+}
+
+// ExecContext implements driver.ExecerContext, which lib/pq's connections
+// implement to run statements without preparing them.
+func (c *liveConn) ExecContext(ctx context.Context, query string, named []driver.NamedValue) (driver.Result, error) {
+	if c.lost {
+		return nil, driver.ErrBadConn
+	}
+	args := values(named)
+	if c.dryRun != nil && !readStatement(query) {
+		c.dryRun.record(query, args)
+		return driver.RowsAffected(0), nil
+	}
+
+	start := time.Now()
+	result, err := c.Conn.(driver.ExecerContext).ExecContext(c.statementContext(ctx), query, named)
+	c.used = time.Now()
+	c.logStatement(start, query, err)
+	c.stats.statement(readStatement(query), time.Since(start), err)
+	if auditErr := c.auditStatement(start, query, args, result, err); auditErr != nil && err == nil {
+		return result, auditErr
+	}
+
+	return result, c.connError(query, err)
+}
+
+// Query implements driver.Queryer.
+func (c *liveConn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	return c.QueryContext(context.Background(), query, namedValues(args))
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *liveConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.lost {
+		return nil, driver.ErrBadConn
+	}
+	if c.dryRun != nil && !readStatement(query) {
+		c.dryRun.record(query, values(args))
+		return noRows{}, nil
+	}
+
+	start := time.Now()
+	rows, err := c.Conn.(driver.QueryerContext).QueryContext(c.statementContext(ctx), query, args)
+	c.used = time.Now()
+	c.logStatement(start, query, err)
+	c.stats.statement(readStatement(query), time.Since(start), err)
+	if auditErr := c.auditStatement(start, query, values(args), nil, err); auditErr != nil && err == nil {
+		rows.Close()
+		return nil, auditErr
+	}
+
+	return rows, c.connError(query, err)
+}
+
+// auditStatement records the statement run since start in the audit log, if
+// any, if it changes the server, with its result or err.
+func (c *liveConn) auditStatement(start time.Time, query string, args []driver.Value, result driver.Result, err error) error {
+	if c.audit == nil || readStatement(query) || query == c.audit.insertQuery() {
+		return nil
+	}
+
+	entry := newAuditEntry(start, query, args, result, err)
+	entry.Database, entry.User, entry.Role = c.database, c.user, c.role
+
+	return c.audit.record(entry)
+}
+
+// logStatement logs the statement run since start, failing with err if not
+// nil.
+func (c *liveConn) logStatement(start time.Time, query string, err error) {
+	fields := logFields{
+		"statement":   redactPasswords(strings.TrimSpace(query)),
+		"database":    c.database,
+		"user":        c.user,
+		"duration_ms": milliseconds(start),
+	}
+	if err != nil {
+		fields["error"] = err
+	}
+	logEvent("DEBUG", "Statement run", fields)
+}
+
+// ResetSession implements driver.SessionResetter.  The network connection is
+// only read from, bypassing lib/pq, after the connection was idle for
+// connCheckIdle, which is when firewalls and VPNs drop connections.
+func (c *liveConn) ResetSession(ctx context.Context) error {
+	if c.lost {
+		return driver.ErrBadConn
+	}
+	if time.Since(c.used) > connCheckIdle && !connOpen(c.netConn) {
+		return driver.ErrBadConn
+	}
+
+	return nil
+}
+
+// connError returns the error to report for running the query, which is
+// driver.ErrBadConn, making database/sql retry on another connection, if the
+// connection was lost and the query can be retried.  lib/pq itself returns
+// driver.ErrBadConn for some of the ways connections are lost, whatever the
+// query.
+func (c *liveConn) connError(query string, err error) error {
+	if err == nil || (err != driver.ErrBadConn && !connLostError(err)) {
+		return err
+	}
+	c.lost = true
+
+	switch {
+	case c.inTx:
+		return err
+	case retryableStatement(query):
+		logEvent("WARN", "Lost the connection to the PostgreSQL server, retrying on a new connection", logFields{"error": err})
+		c.stats.retry()
+		return driver.ErrBadConn
+	case err == driver.ErrBadConn:
+		return errors.New("lost the connection to the PostgreSQL server while running a statement which can not be retried safely")
+	}
+
+	return err
+}
+
+// liveTx is a transaction of a liveConn.
+type liveTx struct {
+	driver.Tx
+	conn *liveConn
+}
+
+// Commit implements driver.Tx.
+func (tx *liveTx) Commit() error {
+	tx.conn.inTx = false
+	tx.conn.used = time.Now()
+	err := tx.Tx.Commit()
+	if err == driver.ErrBadConn || connLostError(err) {
+		tx.conn.lost = true
+	}
+
+	return err
+}
+
+// Rollback implements driver.Tx.
+func (tx *liveTx) Rollback() error {
+	tx.conn.inTx = false
+	tx.conn.used = time.Now()
+	err := tx.Tx.Rollback()
+	if err == driver.ErrBadConn || connLostError(err) {
+		tx.conn.lost = true
+	}
+
+	return err
+}
+
+// connLostError returns true if the error is lib/pq's for a connection lost
+// while running a statement.
+func connLostError(err error) bool {
+	switch err := err.(type) {
+	case *pq.Error:
+		// admin_shutdown and crash_shutdown, sent to the sessions of a
+		// server shutting down, connection_exception, and
+		// read_only_sql_transaction, raised once the server was demoted to
+		// a replica, as the writer of an Aurora cluster is during a
+		// failover: the connection is of no use anymore.
+		return err.Code == "57P01" || err.Code == "57P02" || err.Code.Class() == "08" || err.Code == "25006"
+	case net.Error:
+		return true
+	}
+
+	return err == io.EOF || err == io.ErrUnexpectedEOF
+}
+
+// retryableStatement returns true if running the statement again after it
+// may have been run already has the same effect as running it once: reads,
+// privileges, comments, and creating or dropping objects if they do not
+// exist or exist yet.
+func retryableStatement(query string) bool {
+	words := strings.Fields(strings.ToUpper(query))
+	if len(words) == 0 {
+		return false
+	}
+	statement := strings.Join(words, " ") + " "
+
+	switch words[0] {
+	case "BEGIN", "SELECT", "SHOW", "GRANT", "REVOKE", "COMMENT":
+		return true
+	case "CREATE":
+		return strings.Contains(statement, " IF NOT EXISTS ")
+	case "DROP":
+		return strings.Contains(statement, " IF EXISTS ")
+	}
+
+	return false
+}
+
+// connOpen returns true if nothing was received on the idle connection, which
+// would be the server closing it or telling why it is about to.
+func connOpen(conn net.Conn) bool {
+	if err := conn.SetReadDeadline(time.Now().Add(connCheckTimeout)); err != nil {
+		return false
+	}
+	defer conn.SetReadDeadline(time.Time{})
+
+	n, err := conn.Read(make([]byte, 1))
+	if n != 0 {
+		return false
+	}
+	netErr, ok := err.(net.Error)
+
+	return ok && netErr.Timeout()
+}
@@ -0,0 +1,159 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestNetDialerKeepAlive(t *testing.T) {
+	accepted := make(chan struct{})
+	l := listen(t, func(conn net.Conn) { <-accepted })
+	defer l.Close()
+	defer close(accepted)
+
+	dialer := netDialer{
+		keepAlive: &net.KeepAliveConfig{
+			Enable:   true,
+			Idle:     30 * time.Second,
+			Interval: 5 * time.Second,
+			Count:    3,
+		},
+	}
+	conn, err := dialer.DialTimeout("tcp", l.Addr().String(), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	dialer.keepAlive.Enable = false
+	if conn, err = dialer.Dial("tcp", l.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
+
+func TestLiveConn(t *testing.T) {
+	client, server := net.Pipe()
+	conn := &liveConn{netConn: client}
+
+	if err := conn.ResetSession(context.Background()); err != nil {
+		t.Errorf("expected an idle connection to be reused, got %v", err)
+	}
+
+	// The server reporting why it terminates the connection.
+	go server.Write([]byte{'E'})
+	if err := conn.ResetSession(context.Background()); err != driver.ErrBadConn {
+		t.Errorf("expected a connection the server wrote to to be discarded, got %v", err)
+	}
+
+	server.Close()
+	if err := conn.ResetSession(context.Background()); err != driver.ErrBadConn {
+		t.Errorf("expected a closed connection to be discarded, got %v", err)
+	}
+
+	// Connections reused soon after running a statement are not checked.
+	conn = &liveConn{netConn: client, used: time.Now()}
+	if err := conn.ResetSession(context.Background()); err != nil {
+		t.Errorf("expected a recently used connection to be reused unchecked, got %v", err)
+	}
+	conn.lost = true
+	if err := conn.ResetSession(context.Background()); err != driver.ErrBadConn {
+		t.Errorf("expected a lost connection to be discarded, got %v", err)
+	}
+}
+
+// lostConn is a driver connection losing the connection to the server with
+// err on every statement.
+type lostConn struct {
+	err error
+}
+
+func (c lostConn) Prepare(query string) (driver.Stmt, error) { return nil, c.err }
+func (c lostConn) Close() error                              { return nil }
+func (c lostConn) Begin() (driver.Tx, error)                 { return lostConn{}, nil }
+func (c lostConn) Commit() error                             { return nil }
+func (c lostConn) Rollback() error                           { return nil }
+
+func (c lostConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return lostConn{}, nil
+}
+
+func (c lostConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return nil, c.err
+}
+
+func (c lostConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return nil, c.err
+}
+
+func TestLiveConnLost(t *testing.T) {
+	tests := []struct {
+		err   error
+		query string
+		inTx  bool
+
+		expected string
+		lost     bool
+	}{
+		{io.ErrUnexpectedEOF, "SELECT 1", false, driver.ErrBadConn.Error(), true},
+		{io.ErrUnexpectedEOF, "SELECT 1", true, io.ErrUnexpectedEOF.Error(), true},
+		{io.ErrUnexpectedEOF, "CREATE ROLE app", false, io.ErrUnexpectedEOF.Error(), true},
+		{driver.ErrBadConn, "DROP SCHEMA IF EXISTS app", false, driver.ErrBadConn.Error(), true},
+		{driver.ErrBadConn, "CREATE ROLE app", false, "can not be retried safely", true},
+		{&pq.Error{Code: "57P01"}, "GRANT app TO admin", false, driver.ErrBadConn.Error(), true},
+		{&pq.Error{Code: "42P07"}, "SELECT 1", false, "pq: ", false},
+	}
+
+	for _, test := range tests {
+		client, server := net.Pipe()
+		defer server.Close()
+		conn := &liveConn{Conn: lostConn{test.err}, netConn: client}
+		if test.inTx {
+			if _, err := conn.Begin(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		_, err := conn.Exec(test.query, nil)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%q failing with %v: expected an error containing %q, got %v", test.query, test.err, test.expected, err)
+		}
+
+		if err := conn.ResetSession(context.Background()); test.lost != (err == driver.ErrBadConn) {
+			t.Errorf("%q failing with %v: expected the connection to be discarded: %t, got %v", test.query, test.err, test.lost, err)
+		}
+	}
+}
+
+func TestRetryableStatement(t *testing.T) {
+	for _, query := range []string{
+		"SELECT 1",
+		"  select rolname FROM pg_catalog.pg_roles",
+		"GRANT USAGE ON SCHEMA app TO reader",
+		"CREATE EXTENSION IF NOT EXISTS hstore",
+		"DROP MATERIALIZED VIEW IF EXISTS app.totals",
+	} {
+		if !retryableStatement(query) {
+			t.Errorf("expected %q to be retryable", query)
+		}
+	}
+
+	for _, query := range []string{
+		"",
+		"CREATE ROLE app",
+		"DROP SCHEMA app CASCADE",
+		"ALTER ROLE app RENAME TO web",
+		"INSERT INTO t VALUES (1)",
+	} {
+		if retryableStatement(query) {
+			t.Errorf("expected %q not to be retryable", query)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package postgresql
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/lib/pq"
+)
+
+// kerberosGSS is lib/pq's GSS provider, authenticating with the Kerberos
+// tickets of the credential cache, e.g. obtained with kinit, as libpq does.
+type kerberosGSS struct {
+	client *client.Client
+}
+
+// newKerberosGSS returns a GSS provider with the Kerberos configuration of
+// KRB5_CONFIG, or /etc/krb5.conf, and the credential cache of KRB5CCNAME, or
+// the default one.  lib/pq calls it for every connection the server asks to
+// authenticate with GSSAPI, so renewed tickets are picked up.
+func newKerberosGSS() (pq.GSS, error) {
+	return loadKerberosGSS()
+}
+
+// loadKerberosGSS returns the Kerberos client newKerberosGSS returns, for
+// gssEncDialer too.
+func loadKerberosGSS() (*kerberosGSS, error) {
+	configPath := os.Getenv("KRB5_CONFIG")
+	if configPath == "" {
+		configPath = "/etc/krb5.conf"
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error loading the Kerberos configuration: {{err}}", err)
+	}
+
+	ccachePath, err := kerberosCCachePath(os.Getenv("KRB5CCNAME"))
+	if err != nil {
+		return nil, err
+	}
+	ccache, err := credentials.LoadCCache(ccachePath)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error loading the Kerberos credential cache: {{err}}", err)
+	}
+
+	cl, err := client.NewFromCCache(ccache, cfg, client.DisablePAFXFAST(true))
+	if err != nil {
+		return nil, err
+	}
+
+	return &kerberosGSS{client: cl}, nil
+}
+
+// kerberosCCachePath returns the path of the credential cache KRB5CCNAME
+// names, or of MIT Kerberos' default one if empty.  Only file caches can be
+// read.
+func kerberosCCachePath(name string) (string, error) {
+	switch {
+	case name == "":
+		return fmt.Sprintf("/tmp/krb5cc_%d", os.Getuid()), nil
+	case strings.HasPrefix(name, "FILE:"):
+		return strings.TrimPrefix(name, "FILE:"), nil
+	case !strings.Contains(name, ":"):
+		return name, nil
+	}
+
+	return "", fmt.Errorf("unsupported Kerberos credential cache %q: only FILE caches can be read", name)
+}
+
+// GetInitToken implements pq.GSS, for the service principal of the server,
+// e.g. postgres/db.example.com.
+func (g *kerberosGSS) GetInitToken(host, service string) ([]byte, error) {
+	return g.GetInitTokenFromSpn(g.spn(host, service))
+}
+
+// spn returns the name of the service principal of the server on host.
+func (g *kerberosGSS) spn(host, service string) string {
+	if g.client.Config.LibDefaults.DNSCanonicalizeHostname {
+		if cname, err := net.LookupCNAME(host); err == nil {
+			host = strings.TrimSuffix(cname, ".")
+		}
+	}
+
+	return service + "/" + host
+}
+
+// GetInitTokenFromSpn implements pq.GSS.
+func (g *kerberosGSS) GetInitTokenFromSpn(spn string) ([]byte, error) {
+	token, err := spnego.SPNEGOClient(g.client, spn).InitSecContext()
+	if err != nil {
+		return nil, err
+	}
+
+	return token.Marshal()
+}
+
+// Continue implements pq.GSS.  The server's response completes the
+// authentication, as the client does not ask for mutual authentication.
+func (g *kerberosGSS) Continue(inToken []byte) (bool, []byte, error) {
+	var token spnego.SPNEGOToken
+	if err := token.Unmarshal(inToken); err != nil {
+		return true, nil, err
+	}
+	if state := token.NegTokenResp.State(); state != spnego.NegStateAcceptCompleted {
+		return true, nil, fmt.Errorf("the server did not accept the Kerberos authentication: state %d", state)
+	}
+
+	return true, nil, nil
+}
+
+func init() {
+	pq.RegisterGSSProvider(newKerberosGSS)
+}
@@ -0,0 +1,36 @@
+package postgresql
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestKerberosCCachePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		valid    bool
+	}{
+		{"", fmt.Sprintf("/tmp/krb5cc_%d", os.Getuid()), true},
+		{"FILE:/run/user/1000/krb5cc", "/run/user/1000/krb5cc", true},
+		{"/home/terraform/.krb5cc", "/home/terraform/.krb5cc", true},
+		{"KEYRING:persistent:1000", "", false},
+		{"KCM:", "", false},
+	}
+
+	for _, test := range tests {
+		path, err := kerberosCCachePath(test.name)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %q", test.name, path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.name, err)
+		} else if path != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.name, test.expected, path)
+		}
+	}
+}
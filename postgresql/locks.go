@@ -0,0 +1,148 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"hash/crc32"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+)
+
+// advisoryLockPollInterval is how often a catalog lock held by another
+// Terraform run is tried again.
+const advisoryLockPollInterval = 500 * time.Millisecond
+
+// advisoryLockNamespace is the first key of the advisory locks the provider
+// takes, telling them apart from the ones of applications.
+var advisoryLockNamespace = int32(crc32.ChecksumIEEE([]byte("terraform-provider-postgresql")))
+
+// catalogLocks holds the locks of the classes of catalog objects, by class
+// and database.  Changes to objects of different classes, or in different
+// databases, do not conflict, so that they run in parallel, while changes to
+// the objects of a class are serialized, as PostgreSQL fails concurrent
+// changes to some catalogs, e.g. with "tuple concurrently updated".
+type catalogLocks struct {
+	lock  sync.Mutex
+	locks map[string]*sync.RWMutex
+}
+
+// get returns the lock of the class of objects in the database.
+func (l *catalogLocks) get(class, database string) *sync.RWMutex {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	key := database + "/" + class
+	if l.locks == nil {
+		l.locks = make(map[string]*sync.RWMutex)
+	}
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &sync.RWMutex{}
+		l.locks[key] = lock
+	}
+
+	return lock
+}
+
+// lockCatalog takes the catalog lock for changing the objects of the class,
+// e.g. "schema", in the database or, if database is empty, shared by all the
+// databases of the cluster, such as roles.  With advisory_locks, it is also
+// taken on the server, so that other Terraform runs changing the same
+// cluster wait for it.  It returns the function releasing the lock.
+func (c *Client) lockCatalog(class, database string) (func(), error) {
+	lock := c.catalogLocks.get(class, database)
+	lock.Lock()
+	// CockroachDB has no advisory locks, and YugabyteDB only has them when
+	// enabled on every server: only the changes of the run wait for each
+	// other there.
+	if !c.config.AdvisoryLocks || c.cockroach || c.yugabyte {
+		return lock.Unlock, nil
+	}
+
+	unlock, err := c.advisoryLock(class, database)
+	if err != nil {
+		lock.Unlock()
+		return nil, err
+	}
+
+	return func() {
+		unlock()
+		lock.Unlock()
+	}, nil
+}
+
+// rlockCatalog waits for the changes to the objects of the class in the
+// database to be done, and keeps new ones from starting until the function
+// returned is called.  Resources read their objects with it, so as not to see
+// them half changed by the provider.
+func (c *Client) rlockCatalog(class, database string) func() {
+	lock := c.catalogLocks.get(class, database)
+	lock.RLock()
+
+	return lock.RUnlock
+}
+
+// lockRoleMembership takes the lock of the membership of the connection user
+// in the role, which operations granting it temporarily, see
+// grantRoleMembership, hold until they revoke it, so that they do not revoke
+// it from under each other.
+func (c *Client) lockRoleMembership(role string) (func(), error) {
+	return c.lockCatalog("membership of "+role, "")
+}
+
+// advisoryLock takes the advisory lock of the class of objects in the
+// database, waiting up to config.AdvisoryLockTimeout, if not zero, for other
+// sessions to release it.  The lock is held by a session of its own, which
+// the function returned releases it with.  The key of the lock is the CRC-32
+// of database/class, which two classes can share: their changes then wait for
+// each other, as if they were of the same class.
+func (c *Client) advisoryLock(class, database string) (func(), error) {
+	ctx := context.Background()
+	conn, err := c.lockDB.Conn(ctx)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error connecting to take the catalog lock: {{err}}", err)
+	}
+
+	key := int32(crc32.ChecksumIEEE([]byte(database + "/" + class)))
+	start := time.Now()
+	for {
+		var locked bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_catalog.pg_try_advisory_lock($1, $2)", advisoryLockNamespace, key).Scan(&locked); err != nil {
+			conn.Close()
+			return nil, errwrap.Wrapf("Error taking the catalog lock: {{err}}", err)
+		}
+		if locked {
+			break
+		}
+
+		if c.config.AdvisoryLockTimeout != 0 && time.Since(start) > c.config.AdvisoryLockTimeout {
+			conn.Close()
+			return nil, fmt.Errorf("timed out after %s waiting for another Terraform run changing %s objects to release the catalog lock", c.config.AdvisoryLockTimeout, class)
+		}
+		time.Sleep(advisoryLockPollInterval)
+	}
+
+	return func() {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_catalog.pg_advisory_unlock($1, $2)", advisoryLockNamespace, key); err != nil {
+			// The lock is released with the session instead, which is
+			// closed rather than returned to the pool, where the next
+			// holder would take the lock again without waiting.
+			logEvent("WARN", "Error releasing the catalog lock", logFields{"class": class, "database": database, "error": err})
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}, nil
+}
+
+// databaseName returns the name of the database resources with the database
+// attribute connect to: the provider's if empty.
+func (c *Client) databaseName(database string) string {
+	if database == "" {
+		return c.config.Database
+	}
+
+	return database
+}
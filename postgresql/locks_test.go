@@ -0,0 +1,49 @@
+package postgresql
+
+import "testing"
+
+func TestLockCatalog(t *testing.T) {
+	c := &Client{config: Config{Database: "postgres"}}
+
+	unlock, err := c.lockCatalog("schema", c.databaseName(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock := c.catalogLocks.get("schema", "postgres")
+	if lock.TryRLock() {
+		t.Fatal("expected the catalog lock to be held")
+	}
+
+	// Other classes and databases are not locked.
+	for _, key := range [][2]string{{"table", "postgres"}, {"schema", "app"}, {"role", ""}} {
+		other := c.catalogLocks.get(key[0], key[1])
+		if !other.TryLock() {
+			t.Errorf("expected the lock of %s in %q not to be held", key[0], key[1])
+			continue
+		}
+		other.Unlock()
+	}
+
+	unlock()
+	if !lock.TryLock() {
+		t.Fatal("expected the catalog lock to be released")
+	}
+	lock.Unlock()
+
+	runlock := c.rlockCatalog("schema", "postgres")
+	if lock.TryLock() {
+		t.Fatal("expected the catalog lock to be held for reading")
+	}
+	if !lock.TryRLock() {
+		t.Fatal("expected reads not to wait for each other")
+	}
+	lock.RUnlock()
+	runlock()
+
+	if name := c.databaseName("app"); name != "app" {
+		t.Errorf("expected the database of the resource, got %q", name)
+	}
+	if name := c.databaseName(""); name != "postgres" {
+		t.Errorf("expected the provider's database, got %q", name)
+	}
+}
@@ -0,0 +1,101 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+// logFields are the fields of a structured log entry.
+type logFields map[string]interface{}
+
+// logEvent writes a structured log entry, a JSON object with the message and
+// the fields, at the level, which Terraform shows depending on TF_LOG.
+func logEvent(level, message string, fields logFields) {
+	entry := make(logFields, len(fields)+1)
+	for k, v := range fields {
+		if err, ok := v.(error); ok {
+			if code := sqlState(err); code != "" {
+				entry["sqlstate"] = code
+			}
+			v = err.Error()
+		}
+		entry[k] = v
+	}
+	entry["message"] = message
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("[%s] %s: %v", level, message, fields)
+		return
+	}
+	log.Printf("[%s] postgresql: %s", level, line)
+}
+
+// sqlState returns the SQLSTATE of the PostgreSQL error the error is or
+// wraps, if any.
+func sqlState(err error) string {
+	var code string
+	errwrap.Walk(err, func(err error) {
+		if err, ok := err.(*pq.Error); ok {
+			code = string(err.Code)
+		}
+	})
+
+	return code
+}
+
+// milliseconds returns the time elapsed since start in milliseconds.
+func milliseconds(start time.Time) float64 {
+	return float64(time.Since(start)) / float64(time.Millisecond)
+}
+
+// logOperation logs the operation op of the resource or data source of the
+// type name with the ID id, started at start, with its duration and error.
+func logOperation(name, op, id string, start time.Time, err error) {
+	fields := logFields{
+		"resource":    name,
+		"id":          id,
+		"operation":   op,
+		"duration_ms": milliseconds(start),
+	}
+	if err != nil {
+		fields["error"] = err
+		logEvent("ERROR", "Operation failed", fields)
+	} else {
+		logEvent("DEBUG", "Operation completed", fields)
+	}
+}
+
+// logOperations logs the operations of the resource or data source of the type
+// name, with their duration and error.  Terraform does not tell providers the
+// address of the resource, so the ID stands in for its name.
+func logOperations(name string, r *schema.Resource) {
+	wrap := func(op string, f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+		if f == nil {
+			return nil
+		}
+		return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			start := time.Now()
+			id := d.Id()
+			diags := f(ctx, d, meta)
+			if id == "" {
+				id = d.Id()
+			}
+			logOperation(name, op, id, start, diagsError(diags))
+
+			return diags
+		}
+	}
+
+	r.CreateContext = wrap("create", r.CreateContext)
+	r.ReadContext = wrap("read", r.ReadContext)
+	r.UpdateContext = wrap("update", r.UpdateContext)
+	r.DeleteContext = wrap("delete", r.DeleteContext)
+}
@@ -0,0 +1,46 @@
+package postgresql
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/lib/pq"
+)
+
+func TestLogEvent(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	flags := log.Flags()
+	log.SetFlags(0)
+	defer log.SetFlags(flags)
+
+	err := errwrap.Wrapf("Error creating role: {{err}}", &pq.Error{Code: "42710", Message: "role \"app\" already exists"})
+	logEvent("ERROR", "Operation failed", logFields{"resource": "postgresql_role", "error": err})
+
+	line := strings.TrimSpace(buf.String())
+	if !strings.HasPrefix(line, "[ERROR] postgresql: ") {
+		t.Fatalf("expected the entry to be logged at the level, got %q", line)
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "[ERROR] postgresql: ")), &entry); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"message":  "Operation failed",
+		"resource": "postgresql_role",
+		"error":    err.Error(),
+		"sqlstate": "42710",
+	}
+	for k, v := range expected {
+		if entry[k] != v {
+			t.Errorf("expected %s to be %q, got %q", k, v, entry[k])
+		}
+	}
+}
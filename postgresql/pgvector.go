@@ -0,0 +1,49 @@
+package postgresql
+
+import (
+	"database/sql"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/lib/pq"
+)
+
+// pgvectorTypes are the types of pgvector, whose typmod, the number of
+// dimensions, e.g. vector(1536), information_schema does not report.
+var pgvectorTypes = []string{
+	"halfvec",
+	"sparsevec",
+	"vector",
+}
+
+// pgvectorTypesQuery selects the pgvector columns of the table $1 and their
+// types with their typmods.
+const pgvectorTypesQuery = `SELECT a.attname, pg_catalog.format_type(a.atttypid, a.atttypmod) ` +
+	`FROM pg_catalog.pg_attribute a ` +
+	`JOIN pg_catalog.pg_class c ON c.oid = a.attrelid ` +
+	`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+	`WHERE n.nspname = 'public' AND c.relname = $1 AND a.attnum > 0 AND NOT a.attisdropped ` +
+	`AND pg_catalog.format_type(a.atttypid, NULL) = ANY($2)`
+
+// pgvectorColumnTypes returns the types, with their typmods, of the pgvector
+// columns of the table, by column.
+func pgvectorColumnTypes(db *sql.DB, tableName string) (map[string]string, error) {
+	rows, err := db.Query(pgvectorTypesQuery, tableName, pq.Array(pgvectorTypes))
+	if err != nil {
+		return nil, errwrap.Wrapf("Error reading the pgvector columns: {{err}}", err)
+	}
+	defer rows.Close()
+
+	types := make(map[string]string)
+	for rows.Next() {
+		var column, columnType string
+		if err := rows.Scan(&column, &columnType); err != nil {
+			return nil, errwrap.Wrapf("Error reading the pgvector columns: {{err}}", err)
+		}
+		types[column] = columnType
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errwrap.Wrapf("Error reading the pgvector columns: {{err}}", err)
+	}
+
+	return types, nil
+}
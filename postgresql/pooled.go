@@ -0,0 +1,59 @@
+package postgresql
+
+import (
+	"fmt"
+)
+
+// Connection profiles: direct connections to the server, or connections
+// through a pooler sharing server connections between clients, e.g. RDS Proxy
+// or PgBouncer in transaction mode.
+const (
+	connProfileDirect = "direct"
+	connProfilePooled = "pooled"
+)
+
+func validateConnectionProfile(v interface{}, key string) (warnings []string, errors []error) {
+	switch v.(string) {
+	case connProfileDirect, connProfilePooled:
+	default:
+		errors = append(errors, fmt.Errorf("%s must be one of %s or %s, got %q", key, connProfileDirect, connProfilePooled, v.(string)))
+	}
+	return
+}
+
+// checkPooledConfig returns an error if the configuration uses session state,
+// which poolers either pin sessions to a server connection for, defeating the
+// pool, or lose between transactions: run-time parameters set when
+// connecting, which apply to the whole session, and session-level advisory
+// locks.
+func checkPooledConfig(c *Config) error {
+	for _, setting := range []struct {
+		name string
+		set  bool
+	}{
+		{"session_role", c.SessionRole != ""},
+		{"search_path", len(c.SearchPath) != 0},
+		{"statement_timeout", c.StatementTimeout > 0},
+		{"lock_timeout", c.LockTimeout > 0},
+		{"advisory_locks", c.AdvisoryLocks},
+	} {
+		if setting.set {
+			return fmt.Errorf("%s can not be used with connection_profile %s: it is session state, which poolers pin sessions to a server connection for", setting.name, connProfilePooled)
+		}
+	}
+
+	return nil
+}
+
+// withoutSessionState clears the session state checkPooledConfig rejects from
+// the configuration of the pooled endpoint of a server with a direct one.
+// Only refreshes go through it, which read the catalogs the same without it,
+// while the changes made through the direct endpoint keep it.
+func withoutSessionState(c *Config) {
+	c.SessionRole = ""
+	c.SearchPath = nil
+	c.StatementTimeout = 0
+	c.LockTimeout = 0
+	c.AdvisoryLocks = false
+	c.ignoreSessionRole = true
+}
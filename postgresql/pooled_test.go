@@ -0,0 +1,42 @@
+package postgresql
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckPooledConfig(t *testing.T) {
+	tests := []struct {
+		config  Config
+		setting string
+	}{
+		{config: Config{ApplicationName: "terraform", MaxConns: 5}},
+		{config: Config{SessionRole: "owner"}, setting: "session_role"},
+		{config: Config{SearchPath: []string{"app"}}, setting: "search_path"},
+		{config: Config{StatementTimeout: time.Minute}, setting: "statement_timeout"},
+		{config: Config{LockTimeout: time.Second}, setting: "lock_timeout"},
+		{config: Config{AdvisoryLocks: true}, setting: "advisory_locks"},
+	}
+
+	for _, test := range tests {
+		err := checkPooledConfig(&test.config)
+		switch {
+		case test.setting == "" && err != nil:
+			t.Errorf("unexpected error: %v", err)
+		case test.setting != "" && (err == nil || !strings.HasPrefix(err.Error(), test.setting+" can not be used")):
+			t.Errorf("expected an error about %s, got %v", test.setting, err)
+		}
+	}
+}
+
+func TestValidateConnectionProfile(t *testing.T) {
+	for _, profile := range []string{"direct", "pooled"} {
+		if _, errs := validateConnectionProfile(profile, "connection_profile"); len(errs) != 0 {
+			t.Errorf("%s: unexpected errors %v", profile, errs)
+		}
+	}
+	if _, errs := validateConnectionProfile("transaction", "connection_profile"); len(errs) == 0 {
+		t.Error("expected an error for transaction")
+	}
+}
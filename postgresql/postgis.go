@@ -0,0 +1,120 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// postgisTypeModsQuery selects the column, type, geometry type, SRID and number
+// of dimensions of the geometry and geography columns of the table $1, which
+// information_schema reports without their typmods.
+const postgisTypeModsQuery = `SELECT f_geometry_column, 'geometry', UPPER(type), srid, coord_dimension FROM geometry_columns ` +
+	`WHERE f_table_schema = 'public' AND f_table_name = $1 ` +
+	`UNION ALL ` +
+	`SELECT f_geography_column, 'geography', UPPER(type), srid, coord_dimension FROM geography_columns ` +
+	`WHERE f_table_schema = 'public' AND f_table_name = $1`
+
+// postgisGeometryTypes maps the geometry types, as geometry_columns has them, to
+// the way PostGIS prints them in typmods.
+var postgisGeometryTypes = map[string]string{
+	"GEOMETRY":           "Geometry",
+	"POINT":              "Point",
+	"LINESTRING":         "LineString",
+	"POLYGON":            "Polygon",
+	"MULTIPOINT":         "MultiPoint",
+	"MULTILINESTRING":    "MultiLineString",
+	"MULTIPOLYGON":       "MultiPolygon",
+	"GEOMETRYCOLLECTION": "GeometryCollection",
+	"CIRCULARSTRING":     "CircularString",
+	"COMPOUNDCURVE":      "CompoundCurve",
+	"CURVEPOLYGON":       "CurvePolygon",
+	"MULTICURVE":         "MultiCurve",
+	"MULTISURFACE":       "MultiSurface",
+	"POLYHEDRALSURFACE":  "PolyhedralSurface",
+	"TRIANGLE":           "Triangle",
+	"TIN":                "Tin",
+}
+
+// isPostGISType returns whether the type is a PostGIS type with typmods.
+func isPostGISType(columnType string) bool {
+	base := strings.ToLower(columnType)
+	if i := strings.IndexByte(base, '('); i >= 0 {
+		base = base[:i]
+	}
+	base = strings.TrimSpace(base)
+
+	return base == "geometry" || base == "geography"
+}
+
+// postgisType returns the type of a geometry or geography column with its
+// typmods, e.g. geometry(PointZ,4326), or the bare type if unconstrained.  The
+// geometry types measured (M) are named so in geometry_columns, while the ones
+// with a Z coordinate only have one more dimension.
+func postgisType(base, geometryType string, srid, dims int) string {
+	suffix := ""
+	if strings.HasSuffix(geometryType, "M") && postgisGeometryTypes[strings.TrimSuffix(geometryType, "M")] != "" {
+		geometryType = strings.TrimSuffix(geometryType, "M")
+		suffix = "M"
+		if dims == 4 {
+			suffix = "ZM"
+		}
+	} else if dims == 3 {
+		suffix = "Z"
+	} else if dims == 4 {
+		suffix = "ZM"
+	}
+
+	name, ok := postgisGeometryTypes[geometryType]
+	if !ok {
+		name = geometryType
+	}
+	name += suffix
+
+	switch {
+	case name == "Geometry" && srid == 0:
+		return base
+	case srid == 0:
+		return fmt.Sprintf("%s(%s)", base, name)
+	}
+
+	return fmt.Sprintf("%s(%s,%d)", base, name, srid)
+}
+
+// postgisColumnTypes returns the types, with their typmods, of the geometry
+// and geography columns of the table, by column.
+func postgisColumnTypes(db *sql.DB, tableName string) (map[string]string, error) {
+	rows, err := db.Query(postgisTypeModsQuery, tableName)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error reading the PostGIS columns: {{err}}", err)
+	}
+	defer rows.Close()
+
+	types := make(map[string]string)
+	for rows.Next() {
+		var column, base, geometryType string
+		var srid, dims int
+		if err := rows.Scan(&column, &base, &geometryType, &srid, &dims); err != nil {
+			return nil, errwrap.Wrapf("Error reading the PostGIS columns: {{err}}", err)
+		}
+		types[column] = postgisType(base, geometryType, srid, dims)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errwrap.Wrapf("Error reading the PostGIS columns: {{err}}", err)
+	}
+
+	return types, nil
+}
+
+// suppressPostGISTypeDiff suppresses the differences between PostGIS types
+// spelled differently, e.g. geometry(POINT, 4326) and geometry(Point,4326).
+func suppressPostGISTypeDiff(k, old, new string, d *schema.ResourceData) bool {
+	if !isPostGISType(old) || !isPostGISType(new) {
+		return false
+	}
+
+	return strings.EqualFold(strings.Replace(old, " ", "", -1), strings.Replace(new, " ", "", -1))
+}
@@ -0,0 +1,46 @@
+package postgresql
+
+import "testing"
+
+func TestPostGISType(t *testing.T) {
+	tests := []struct {
+		base         string
+		geometryType string
+		srid, dims   int
+		expected     string
+	}{
+		{"geometry", "GEOMETRY", 0, 2, "geometry"},
+		{"geometry", "POINT", 4326, 2, "geometry(Point,4326)"},
+		{"geometry", "POINT", 4326, 3, "geometry(PointZ,4326)"},
+		{"geometry", "POINTM", 4326, 3, "geometry(PointM,4326)"},
+		{"geometry", "POINT", 4326, 4, "geometry(PointZM,4326)"},
+		{"geometry", "MULTIPOLYGON", 0, 2, "geometry(MultiPolygon)"},
+		{"geometry", "GEOMETRY", 3857, 2, "geometry(Geometry,3857)"},
+		{"geography", "LINESTRING", 4326, 2, "geography(LineString,4326)"},
+	}
+
+	for _, test := range tests {
+		if actual := postgisType(test.base, test.geometryType, test.srid, test.dims); actual != test.expected {
+			t.Errorf("%s %s %d %d: expected %q, got %q", test.base, test.geometryType, test.srid, test.dims, test.expected, actual)
+		}
+	}
+}
+
+func TestSuppressPostGISTypeDiff(t *testing.T) {
+	tests := []struct {
+		old, new string
+		suppress bool
+	}{
+		{"geometry(Point,4326)", "geometry(POINT, 4326)", true},
+		{"geometry(Point,4326)", "geometry(Point,3857)", false},
+		{"geometry(Point,4326)", "geography(Point,4326)", false},
+		{"geometry", "GEOMETRY", true},
+		{"varchar", "VARCHAR", false},
+	}
+
+	for _, test := range tests {
+		if suppress := suppressPostGISTypeDiff("column.0.type", test.old, test.new, nil); suppress != test.suppress {
+			t.Errorf("%q -> %q: expected suppress %t, got %t", test.old, test.new, test.suppress, suppress)
+		}
+	}
+}
@@ -1,64 +1,247 @@
 package postgresql
 
 import (
+	"context"
+	"crypto/tls"
+	"database/sql"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/hashicorp/errwrap"
-	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
 )
 
 const (
 	defaultProviderMaxOpenConnections = uint(4)
-	defaultExpectedPostgreSQLVersion  = "9.0.0"
+	defaultProviderMaxIdleConnections = uint(1)
+	defaultProviderDatabasePoolSize   = uint(4)
+
+	defaultProviderConnectRetryBackoff       = 1
+	defaultProviderConnectRetryMaxBackoff    = 30
+	defaultProviderSerializationRetryBackoff = 100
+	defaultProviderAdvisoryLockTimeout       = 300
+	defaultExpectedPostgreSQLVersion         = "9.0.0"
 )
 
-// Provider returns a terraform.ResourceProvider.
-func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+// Provider returns the schema.Provider of PostgreSQL.
+func Provider() *schema.Provider {
+	p := &schema.Provider{
 		Schema: map[string]*schema.Schema{
+			"connection_string": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "postgres:// URI or keyword=value connection string to connect with, overridden by the other connection attributes",
+				Sensitive:   true,
+			},
 			"host": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("PGHOST", nil),
-				Description: "Name of PostgreSQL server address to connect to",
+				Description: "Name of PostgreSQL server address to connect to, or comma-separated names to try in turn",
+			},
+			"read_host": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Address of a read-only replica to refresh resources from, instead of host",
+			},
+			"read_port": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "The port of read_host, if not port",
+			},
+			"direct_host": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "Address of the direct endpoint of a server whose host is a pooled endpoint, to make changes through",
+				ConflictsWith: []string{"read_host"},
+			},
+			"direct_port": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "The port of direct_host, if not port",
+			},
+			"target_session_attrs": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Set to read-write to only connect to a host accepting read-write sessions, i.e. the primary",
+				ValidateFunc: validateTargetSessionAttrs,
 			},
 			"port": {
 				Type:        schema.TypeInt,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("PGPORT", 5432),
 				Description: "The PostgreSQL port number to connect to at the server host, or socket file name extension for Unix-domain connections",
 			},
 			"database": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Description: "The name of the database to connect to in order to connect to (defaults to `postgres`).",
-				DefaultFunc: schema.EnvDefaultFunc("PGDATABASE", "postgres"),
 			},
 			"username": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("PGUSER", "postgres"),
 				Description: "PostgreSQL user name to connect as",
 			},
 			"password": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("PGPASSWORD", nil),
 				Description: "Password to be used if the PostgreSQL server demands password authentication",
 				Sensitive:   true,
 			},
-			"sslmode": {
+			"cloudsql_instance": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Connection name (project:region:instance) of a Google Cloud SQL instance to connect to through its proxy instead of host",
+				ValidateFunc: validateCloudSQLInstance,
+			},
+			"cloudsql_iam_auth": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Authenticate with the Google credentials the provider runs with (IAM database authentication) instead of password",
+			},
+			"alloydb_iam_auth": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Authenticate with the Google credentials the provider runs with (AlloyDB IAM database authentication) instead of password",
+			},
+			"password_command": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Description:   "Command, and its arguments, printing the password, run again for new connections once it expired",
+				ConflictsWith: []string{"password_file", "password_env"},
+			},
+			"password_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "File holding the password, read again for every new connection",
+				ConflictsWith: []string{"password_env"},
+			},
+			"password_env": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Environment variable holding the password, read again for every new connection",
+			},
+			"krbsrvname": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Kerberos service name of the server when authenticating with GSSAPI, postgres by default",
+			},
+			"gssencmode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Whether to encrypt the connections with GSSAPI and the Kerberos credential cache: disable, prefer or require",
+				ValidateFunc: validateGSSEncMode,
+			},
+			"azure_ad_auth": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Authenticate with an Azure AD access token, refreshed as needed, instead of password",
+			},
+			"azure_tenant_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{"ARM_TENANT_ID", "AZURE_TENANT_ID"}, nil),
+				Description: "The Azure AD tenant of the service principal to authenticate as",
+			},
+			"azure_client_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{"ARM_CLIENT_ID", "AZURE_CLIENT_ID"}, nil),
+				Description: "The client ID of the service principal, or of the user-assigned managed identity, to authenticate as",
+			},
+			"azure_client_secret": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("PGSSLMODE", nil),
-				Description: "This option determines whether or with what priority a secure SSL TCP/IP connection will be negotiated with the PostgreSQL server",
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{"ARM_CLIENT_SECRET", "AZURE_CLIENT_SECRET"}, nil),
+				Description: "The client secret of the service principal to authenticate as. The managed identity is used if not set",
+				Sensitive:   true,
+			},
+			"proxy_url": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.MultiEnvDefaultFunc([]string{"ALL_PROXY", "all_proxy"}, nil),
+				Description:  "URL of a SOCKS5 (socks5:// or socks5h://) or HTTP CONNECT (http://) proxy to connect through",
+				ValidateFunc: validateProxyURL,
+			},
+			"superuser": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Whether the connection user is a superuser (true or false). Detected from pg_roles if not set.",
+				ValidateFunc: validateSuperuser,
+			},
+			"sslmode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "This option determines whether or with what priority a secure SSL TCP/IP connection will be negotiated with the PostgreSQL server",
+				ValidateFunc: validateSSLMode,
 			},
 			"ssl_mode": {
-				Type:       schema.TypeString,
-				Optional:   true,
-				Deprecated: "Rename PostgreSQL provider `ssl_mode` attribute to `sslmode`",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Deprecated:   "Rename PostgreSQL provider `ssl_mode` attribute to `sslmode`",
+				ValidateFunc: validateSSLMode,
+			},
+			"sslrootcert": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path of the file holding the SSL certificate authorities the server certificate is verified with",
+			},
+			"sslcert": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path of the file holding the SSL client certificate",
+			},
+			"sslkey": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path of the file holding the private key of the SSL client certificate",
+			},
+			"sslrootcert_pem": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "The PEM encoded SSL certificate authorities the server certificate is verified with",
+				ConflictsWith: []string{"sslrootcert"},
+			},
+			"sslcert_pem": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "The PEM encoded SSL client certificate",
+				ConflictsWith: []string{"sslcert"},
+			},
+			"sslkey_pem": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "The PEM encoded private key of the SSL client certificate",
+				Sensitive:     true,
+				ConflictsWith: []string{"sslkey"},
+			},
+			"ssl_min_version": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("PGSSLMINPROTOCOLVERSION", "TLSv1.2"),
+				Description:  "Minimum TLS version to negotiate: TLSv1, TLSv1.1, TLSv1.2 or TLSv1.3",
+				ValidateFunc: validateSSLMinVersion,
+			},
+			"ssl_cipher_suites": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "TLS 1.2 and earlier cipher suites to negotiate, instead of Go's defaults",
+			},
+			"channel_binding": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Whether to bind SCRAM authentication to the SSL connection with SCRAM-SHA-256-PLUS: disable, prefer or require",
+				ValidateFunc: validateChannelBinding,
 			},
 			"connect_timeout": {
 				Type:         schema.TypeInt,
@@ -67,34 +250,387 @@ func Provider() terraform.ResourceProvider {
 				Description:  "Maximum wait for connection, in seconds. Zero or not specified means wait indefinitely.",
 				ValidateFunc: validateConnTimeout,
 			},
+			"statement_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Abort statements running for longer than this, in milliseconds. Zero means no limit.",
+				ValidateFunc: validateConnTimeout,
+			},
+			"lock_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Abort statements waiting for a lock for longer than this, in milliseconds. Zero means no limit.",
+				ValidateFunc: validateConnTimeout,
+			},
+			"session_role": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Role to act as after connecting, as if by SET ROLE, e.g. to create objects owned by it",
+			},
+			"search_path": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Schemas to look up unqualified object names in, instead of the role's or database's search_path",
+			},
+			"max_connect_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Number of times to retry connecting to the server if it is not reachable or not ready yet",
+				ValidateFunc: validateMaxConnectRetries,
+			},
+			"connect_retry_backoff": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultProviderConnectRetryBackoff,
+				Description:  "Wait before the first connection retry, in seconds, doubled for every retry",
+				ValidateFunc: validateConnTimeout,
+			},
+			"connect_retry_max_backoff": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultProviderConnectRetryMaxBackoff,
+				Description:  "Maximum wait between connection retries, in seconds",
+				ValidateFunc: validateConnTimeout,
+			},
+			"dry_run": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("POSTGRESQL_DRY_RUN", false),
+				Description: "Fail changes to resources with the statements they would run instead of running them",
+			},
+			"audit_log_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "File to append the statements changing the server to, as JSON lines",
+			},
+			"audit_log_table": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Table, possibly schema-qualified, of the provider's database to insert the statements changing the server into",
+			},
+			"metrics_statsd_address": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "host:port of a StatsD server to send the statement, change and retry counts of applies to",
+			},
+			"metrics_pushgateway_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "URL of a Prometheus Pushgateway to push the statement, change and retry counts of applies to",
+			},
+			"advisory_locks": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Take the catalog lock changes hold as advisory locks on the server too, so that concurrent Terraform runs wait for each other",
+			},
+			"advisory_lock_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultProviderAdvisoryLockTimeout,
+				Description:  "Maximum wait for other Terraform runs to release an advisory lock, in seconds. Zero means wait indefinitely.",
+				ValidateFunc: validateConnTimeout,
+			},
+			"max_serialization_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Number of times to retry changes failing with a serialization failure or a deadlock, as happens with concurrent applies",
+				ValidateFunc: validateMaxConnectRetries,
+			},
+			"serialization_retry_backoff": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultProviderSerializationRetryBackoff,
+				Description:  "Wait before the first retry of a change failing with a serialization failure or a deadlock, in milliseconds, doubled for every retry",
+				ValidateFunc: validateConnTimeout,
+			},
+			"keepalives": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Send TCP keepalives, so that connections idle through long plans are not dropped by firewalls and VPNs",
+			},
+			"keepalives_idle": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Idle time before sending TCP keepalives, in seconds. Zero means the default of 15 seconds.",
+				ValidateFunc: validateConnTimeout,
+			},
+			"keepalives_interval": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Time between unanswered TCP keepalives, in seconds. Zero means the default of 15 seconds.",
+				ValidateFunc: validateConnTimeout,
+			},
+			"keepalives_count": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Number of unanswered TCP keepalives after which the connection is considered dead. Zero means the default of 9.",
+				ValidateFunc: validateConnTimeout,
+			},
 			"max_connections": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Deprecated:    "Rename PostgreSQL provider `max_connections` attribute to `max_open_connections`",
+				ValidateFunc:  validateMaxConnections,
+				ConflictsWith: []string{"max_open_connections"},
+			},
+			"max_open_connections": {
 				Type:         schema.TypeInt,
 				Optional:     true,
 				Default:      defaultProviderMaxOpenConnections,
 				Description:  "Maximum number of connections to establish to the database. Zero means unlimited.",
-				ValidateFunc: validateMaxConnections,
+				ValidateFunc: validateConnTimeout,
+			},
+			"max_idle_connections": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultProviderMaxIdleConnections,
+				Description:  "Maximum number of idle connections to keep to the database. Zero means none.",
+				ValidateFunc: validateConnTimeout,
+			},
+			"connection_max_lifetime": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Maximum time, in seconds, connections are reused for. Zero means forever.",
+				ValidateFunc: validateConnTimeout,
+			},
+			"database_pool_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultProviderDatabasePoolSize,
+				Description:  "Maximum number of databases, other than `database`, to keep idle connections to when resources set their own `database` or `session_role`",
+				ValidateFunc: validateDatabasePoolSize,
+			},
+			"connection_profile": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      connProfileDirect,
+				Description:  "direct, or pooled to connect through RDS Proxy, PgBouncer in transaction mode or similar poolers without session state pinning sessions",
+				ValidateFunc: validateConnectionProfile,
 			},
 			"expected_version": {
 				Type:         schema.TypeString,
 				Optional:     true,
-				Default:      defaultExpectedPostgreSQLVersion,
-				Description:  "Specify the expected version of PostgreSQL.",
+				Description:  "Specify the expected version of PostgreSQL. When set, connecting to a server of another major version fails.",
 				ValidateFunc: validateExpectedVersion,
 			},
+			"allow_unsafe_grants": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Only warn about grants on system schemas and superuser-equivalent combinations of privileges instead of refusing them",
+			},
+			"expected_system_identifier": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Specify the expected system identifier of the PostgreSQL cluster, as returned by pg_control_system(). When set, connecting to another cluster fails.",
+				ValidateFunc: validateSystemIdentifier,
+			},
+		},
+
+		DataSourcesMap: map[string]*schema.Resource{
+			"postgresql_columns":            dataSourcePostgreSQLColumns(),
+			"postgresql_connections":        dataSourcePostgreSQLConnections(),
+			"postgresql_database":           dataSourcePostgreSQLDatabase(),
+			"postgresql_databases":          dataSourcePostgreSQLDatabases(),
+			"postgresql_default_privileges": dataSourcePostgreSQLDefaultPrivileges(),
+			"postgresql_extensions":         dataSourcePostgreSQLExtensions(),
+			"postgresql_foreign_servers":    dataSourcePostgreSQLForeignServers(),
+			"postgresql_functions":          dataSourcePostgreSQLFunctions(),
+			"postgresql_indexes":            dataSourcePostgreSQLIndexes(),
+			"postgresql_locks":              dataSourcePostgreSQLLocks(),
+			"postgresql_policies":           dataSourcePostgreSQLPolicies(),
+			"postgresql_publications":       dataSourcePostgreSQLPublications(),
+			"postgresql_query":              dataSourcePostgreSQLQuery(),
+			"postgresql_replication_slots":  dataSourcePostgreSQLReplicationSlots(),
+			"postgresql_role":               dataSourcePostgreSQLRole(),
+			"postgresql_role_grants":        dataSourcePostgreSQLRoleGrants(),
+			"postgresql_sequence_value":     dataSourcePostgreSQLSequenceValue(),
+			"postgresql_sequences":          dataSourcePostgreSQLSequences(),
+			"postgresql_server_version":     dataSourcePostgreSQLServerVersion(),
+			"postgresql_settings":           dataSourcePostgreSQLSettings(),
+			"postgresql_table_constraints":  dataSourcePostgreSQLTableConstraints(),
+			"postgresql_table_stats":        dataSourcePostgreSQLTableStats(),
+			"postgresql_tablespaces":        dataSourcePostgreSQLTablespaces(),
+			"postgresql_triggers":           dataSourcePostgreSQLTriggers(),
+			"postgresql_types":              dataSourcePostgreSQLTypes(),
+			"postgresql_views":              dataSourcePostgreSQLViews(),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"postgresql_database":  resourcePostgreSQLDatabase(),
-			"postgresql_extension": resourcePostgreSQLExtension(),
-			"postgresql_schema":    resourcePostgreSQLSchema(),
-			"postgresql_role":      resourcePostgreSQLRole(),
-			"postgresql_table":     resourcePostgreSQLTable(),
+			"postgresql_database":           resourcePostgreSQLDatabase(),
+			"postgresql_default_privileges": resourcePostgreSQLDefaultPrivileges(),
+			"postgresql_distributed_table":  resourcePostgreSQLDistributedTable(),
+			"postgresql_extension":          resourcePostgreSQLExtension(),
+			"postgresql_grant":              resourcePostgreSQLGrant(),
+			"postgresql_hypertable":         resourcePostgreSQLHypertable(),
+			"postgresql_index":              resourcePostgreSQLIndex(),
+			"postgresql_pgaudit":            resourcePostgreSQLPgAudit(),
+			"postgresql_schema":             resourcePostgreSQLSchema(),
+			"postgresql_schema_ownership":   resourcePostgreSQLSchemaOwnership(),
+			"postgresql_role":               resourcePostgreSQLRole(),
+			"postgresql_role_setting":       resourcePostgreSQLRoleSetting(),
+			"postgresql_table":              resourcePostgreSQLTable(),
 		},
+	}
+	p.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+		// ctx only lasts as long as the configuration request: the
+		// statements are cancelled with the stop context instead.
+		stop, ok := schema.StopContext(ctx)
+		if !ok {
+			stop = context.Background()
+		}
+
+		client, err := providerConfigure(stop, d, p.TerraformVersion)
+		if err != nil {
+			return nil, diagnostics(err)
+		}
+
+		return client, nil
+	}
+
+	for name, r := range p.DataSourcesMap {
+		connectFirst(r)
+		logOperations(name, r)
+	}
+	for name, r := range p.ResourcesMap {
+		connectFirst(r)
+		refreshFromReader(r)
+		dryRunChanges(r)
+		reportStats(r)
+		logOperations(name, r)
+	}
+
+	return p
+}
+
+// connectFirst makes the operations of the resource connect the client to the
+// server first, so that the provider only connects when it has work to do.
+func connectFirst(r *schema.Resource) {
+	wrap := func(f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+		if f == nil {
+			return nil
+		}
+		return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			if err := meta.(*Client).connect(); err != nil {
+				return diagnostics(err)
+			}
+			return f(ctx, d, meta)
+		}
+	}
+
+	r.CreateContext = wrap(r.CreateContext)
+	r.ReadContext = wrap(r.ReadContext)
+	r.UpdateContext = wrap(r.UpdateContext)
+	r.DeleteContext = wrap(r.DeleteContext)
+
+	if exists := r.Exists; exists != nil {
+		r.Exists = func(d *schema.ResourceData, meta interface{}) (bool, error) {
+			if err := meta.(*Client).connect(); err != nil {
+				return false, err
+			}
+			return exists(d, meta)
+		}
+	}
+}
 
-		ConfigureFunc: providerConfigure,
+// refreshFromReader makes Terraform's refreshes of the resource read from the
+// replica, if any.  The resource reads from the server itself after changing
+// it, so that replication lag does not matter.
+func refreshFromReader(r *schema.Resource) {
+	if read := r.ReadContext; read != nil {
+		r.ReadContext = func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			return read(ctx, d, meta.(*Client).forRead())
+		}
+	}
+
+	if exists := r.Exists; exists != nil {
+		r.Exists = func(d *schema.ResourceData, meta interface{}) (bool, error) {
+			return exists(d, meta.(*Client).forRead())
+		}
 	}
 }
 
+// inTxn runs f in a transaction of db, which it commits if f succeeds.  When
+// the transaction fails with a serialization failure or a deadlock, which roll
+// it back, as happens when several applies change the same objects at the
+// same time, f runs again in a new transaction.  On Aurora, it also runs again
+// when the transaction fails because of a failover, once connected to the new
+// writer.  Only the transaction is retried, not the statements run before it,
+// so f must make every change it retries.  what names the change in the error
+// committing it.
+func (c *Client) inTxn(db *sql.DB, what string, f func(*sql.Tx) error) error {
+	config := c.config
+	backoff := config.SerializationRetryBackoff
+	for retry := 0; ; retry++ {
+		err := runTxn(db, what, f)
+		if err == nil || retry == config.MaxSerializationRetries {
+			return err
+		}
+		msg := "Serialization failure, retrying the transaction"
+		switch {
+		case serializationFailure(err):
+		case c.aurora && failoverError(err):
+			msg = "Aurora failover, retrying the transaction"
+		default:
+			return err
+		}
+
+		logEvent("WARN", msg, logFields{
+			"backoff":     backoff.String(),
+			"retry":       retry + 1,
+			"max_retries": config.MaxSerializationRetries,
+			"error":       err,
+		})
+		config.stats.retry()
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// runTxn runs f in a transaction of db and commits it, or rolls it back if f
+// fails.
+func runTxn(db *sql.DB, what string, f func(*sql.Tx) error) error {
+	txn, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	if err := f(txn); err != nil {
+		return err
+	}
+
+	if err := txn.Commit(); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error committing %s: {{err}}", what), err)
+	}
+
+	return nil
+}
+
+// serializationFailure returns true if the error is, or wraps, a
+// serialization_failure or deadlock_detected error.
+func serializationFailure(err error) bool {
+	var found bool
+	errwrap.Walk(err, func(err error) {
+		if err, ok := err.(*pq.Error); ok && (err.Code == "40001" || err.Code == "40P01") {
+			found = true
+		}
+	})
+
+	return found
+}
+
 func validateConnTimeout(v interface{}, key string) (warnings []string, errors []error) {
 	value := v.(int)
 	if value < 0 {
@@ -103,6 +639,49 @@ func validateConnTimeout(v interface{}, key string) (warnings []string, errors [
 	return
 }
 
+func validateSSLMode(v interface{}, key string) (warnings []string, errors []error) {
+	switch v.(string) {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		errors = append(errors, fmt.Errorf("%s must be one of disable, allow, prefer, require, verify-ca or verify-full, got %q", key, v.(string)))
+	}
+	return
+}
+
+func validateSSLMinVersion(v interface{}, key string) (warnings []string, errors []error) {
+	if _, ok := sslVersions[v.(string)]; !ok {
+		errors = append(errors, fmt.Errorf("%s must be one of TLSv1, TLSv1.1, TLSv1.2 or TLSv1.3, got %q", key, v.(string)))
+	}
+	return
+}
+
+func validateChannelBinding(v interface{}, key string) (warnings []string, errors []error) {
+	switch v.(string) {
+	case "disable", "prefer", "require":
+	default:
+		errors = append(errors, fmt.Errorf("%s must be one of disable, prefer or require, got %q", key, v.(string)))
+	}
+	return
+}
+
+func validateGSSEncMode(v interface{}, key string) (warnings []string, errors []error) {
+	switch v.(string) {
+	case "disable", "prefer", "require":
+	default:
+		errors = append(errors, fmt.Errorf("%s must be one of disable, prefer or require, got %q", key, v.(string)))
+	}
+	return
+}
+
+func validateTargetSessionAttrs(v interface{}, key string) (warnings []string, errors []error) {
+	switch v.(string) {
+	case "any", "read-write":
+	default:
+		errors = append(errors, fmt.Errorf("%s must be one of any or read-write, got %q", key, v.(string)))
+	}
+	return
+}
+
 func validateExpectedVersion(v interface{}, key string) (warnings []string, errors []error) {
 	if _, err := semver.Parse(v.(string)); err != nil {
 		errors = append(errors, fmt.Errorf("invalid version (%q): %v", v.(string), err))
@@ -110,6 +689,52 @@ func validateExpectedVersion(v interface{}, key string) (warnings []string, erro
 	return
 }
 
+func validateSystemIdentifier(v interface{}, key string) (warnings []string, errors []error) {
+	if _, err := strconv.ParseUint(v.(string), 10, 64); err != nil {
+		errors = append(errors, fmt.Errorf("%s must be the decimal system identifier of a cluster, got %q", key, v.(string)))
+	}
+	return
+}
+
+func validateDatabasePoolSize(v interface{}, key string) (warnings []string, errors []error) {
+	value := v.(int)
+	if value < 0 {
+		errors = append(errors, fmt.Errorf("%s can not be less than 0", key))
+	}
+	return
+}
+
+func validateCloudSQLInstance(v interface{}, key string) (warnings []string, errors []error) {
+	if _, _, _, err := parseCloudSQLConnectionName(v.(string)); err != nil {
+		errors = append(errors, err)
+	}
+	return
+}
+
+func validateProxyURL(v interface{}, key string) (warnings []string, errors []error) {
+	if _, err := newProxyDialer(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%s: %v", key, err))
+	}
+	return
+}
+
+// validateSuperuser accepts any boolean.  The superuser attribute is a string
+// so that leaving it unset, to auto-detect, can be told apart from false.
+func validateSuperuser(v interface{}, key string) (warnings []string, errors []error) {
+	if _, err := strconv.ParseBool(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%s must be true or false, got %q", key, v.(string)))
+	}
+	return
+}
+
+func validateMaxConnectRetries(v interface{}, key string) (warnings []string, errors []error) {
+	value := v.(int)
+	if value < 0 {
+		errors = append(errors, fmt.Errorf("%s can not be less than 0", key))
+	}
+	return
+}
+
 func validateMaxConnections(v interface{}, key string) (warnings []string, errors []error) {
 	value := v.(int)
 	if value < 1 {
@@ -118,30 +743,419 @@ func validateMaxConnections(v interface{}, key string) (warnings []string, error
 	return
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
-	var sslMode string
-	if sslModeRaw, ok := d.GetOk("sslmode"); ok {
-		sslMode = sslModeRaw.(string)
-	} else {
-		sslModeDeprecated := d.Get("ssl_mode").(string)
-		if sslModeDeprecated != "" {
-			sslMode = sslModeDeprecated
+// connStringAttributes maps the libpq keywords connection_string may set to
+// the provider attributes overriding them.
+var connStringAttributes = map[string]string{
+	"host":                 "host",
+	"port":                 "port",
+	"dbname":               "database",
+	"user":                 "username",
+	"password":             "password",
+	"sslmode":              "sslmode",
+	"sslrootcert":          "sslrootcert",
+	"sslcert":              "sslcert",
+	"sslkey":               "sslkey",
+	"target_session_attrs": "target_session_attrs",
+	"channel_binding":      "channel_binding",
+	"krbsrvname":           "krbsrvname",
+	"gssencmode":           "gssencmode",
+}
+
+// connSetting returns the connection setting of the libpq keyword from, in
+// order, its provider attribute, the connection_string settings, the
+// environment variable and the default, as libpq does.
+func connSetting(d *schema.ResourceData, settings map[string]string, keyword, env, def string) string {
+	if v, ok := d.GetOk(connStringAttributes[keyword]); ok {
+		return fmt.Sprint(v)
+	}
+	if v, ok := settings[keyword]; ok {
+		return v
+	}
+	if v := os.Getenv(env); v != "" {
+		return v
+	}
+
+	return def
+}
+
+func providerConfigure(stop context.Context, d *schema.ResourceData, terraformVersion string) (interface{}, error) {
+	var settings map[string]string
+	if v, ok := d.GetOk("connection_string"); ok {
+		var err error
+		if settings, err = parseConnString(v.(string)); err != nil {
+			return nil, errwrap.Wrapf("invalid connection_string: {{err}}", err)
+		}
+		for keyword := range settings {
+			if _, ok := connStringAttributes[keyword]; !ok {
+				return nil, fmt.Errorf("unsupported connection_string setting %q", keyword)
+			}
 		}
 	}
+
+	sslMode := d.Get("ssl_mode").(string)
+	if _, ok := d.GetOk("sslmode"); ok || sslMode == "" {
+		sslMode = connSetting(d, settings, "sslmode", "PGSSLMODE", "")
+	}
+	port, err := strconv.Atoi(connSetting(d, settings, "port", "PGPORT", "5432"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid port: %v", err)
+	}
+	targetSessionAttrs := connSetting(d, settings, "target_session_attrs", "PGTARGETSESSIONATTRS", "any")
+	// lib/pq refuses to connect while PGCHANNELBINDING is set, so it is not
+	// read.
+	channelBinding := connSetting(d, settings, "channel_binding", "", "prefer")
+	// Nor while PGGSSENCMODE is.
+	gssEncMode := connSetting(d, settings, "gssencmode", "", "prefer")
+
+	// Settings which do not come from attributes were not validated yet.
+	var errs []error
+	if sslMode != "" {
+		_, errs = validateSSLMode(sslMode, "sslmode")
+	}
+	_, targetSessionAttrsErrs := validateTargetSessionAttrs(targetSessionAttrs, "target_session_attrs")
+	_, channelBindingErrs := validateChannelBinding(channelBinding, "channel_binding")
+	_, gssEncModeErrs := validateGSSEncMode(gssEncMode, "gssencmode")
+	if errs = append(append(append(errs, targetSessionAttrsErrs...), channelBindingErrs...), gssEncModeErrs...); len(errs) != 0 {
+		return nil, errs[0]
+	}
+
 	versionStr := d.Get("expected_version").(string)
+	if versionStr == "" {
+		versionStr = defaultExpectedPostgreSQLVersion
+	}
 	version, _ := semver.Parse(versionStr)
 
 	config := Config{
-		Host:              d.Get("host").(string),
-		Port:              d.Get("port").(int),
-		Database:          d.Get("database").(string),
-		Username:          d.Get("username").(string),
-		Password:          d.Get("password").(string),
+		Host:              connSetting(d, settings, "host", "PGHOST", ""),
+		Port:              port,
+		Database:          connSetting(d, settings, "dbname", "PGDATABASE", "postgres"),
+		Username:          connSetting(d, settings, "user", "PGUSER", "postgres"),
+		Password:          connSetting(d, settings, "password", "PGPASSWORD", ""),
 		SSLMode:           sslMode,
-		ApplicationName:   tfAppName(),
+		SSLRootCert:       connSetting(d, settings, "sslrootcert", "PGSSLROOTCERT", ""),
+		SSLCert:           connSetting(d, settings, "sslcert", "PGSSLCERT", ""),
+		SSLKey:            connSetting(d, settings, "sslkey", "PGSSLKEY", ""),
+		ApplicationName:   tfAppName(terraformVersion),
 		ConnectTimeoutSec: d.Get("connect_timeout").(int),
-		MaxConns:          d.Get("max_connections").(int),
+		MaxConns:          d.Get("max_open_connections").(int),
+		MaxIdleConns:      d.Get("max_idle_connections").(int),
+		ConnMaxLifetime:   time.Duration(d.Get("connection_max_lifetime").(int)) * time.Second,
+		StatementTimeout:  time.Duration(d.Get("statement_timeout").(int)) * time.Millisecond,
+		LockTimeout:       time.Duration(d.Get("lock_timeout").(int)) * time.Millisecond,
+		SessionRole:       d.Get("session_role").(string),
+		DatabasePoolSize:  d.Get("database_pool_size").(int),
+		Pooled:            d.Get("connection_profile").(string) == connProfilePooled,
 		ExpectedVersion:   version,
+		CheckVersion:      d.Get("expected_version").(string) != "",
+
+		ExpectedSystemIdentifier: d.Get("expected_system_identifier").(string),
+		SSLMinProtocolVersion:    d.Get("ssl_min_version").(string),
+		KrbSrvname:               connSetting(d, settings, "krbsrvname", "PGKRBSRVNAME", ""),
+
+		ReadHost:               d.Get("read_host").(string),
+		ReadPort:               d.Get("read_port").(int),
+		TargetSessionAttrs:     targetSessionAttrs,
+		MaxConnectRetries:      d.Get("max_connect_retries").(int),
+		ConnectRetryBackoff:    time.Duration(d.Get("connect_retry_backoff").(int)) * time.Second,
+		ConnectRetryMaxBackoff: time.Duration(d.Get("connect_retry_max_backoff").(int)) * time.Second,
+
+		AllowUnsafeGrants:         d.Get("allow_unsafe_grants").(bool),
+		AdvisoryLocks:             d.Get("advisory_locks").(bool),
+		AdvisoryLockTimeout:       time.Duration(d.Get("advisory_lock_timeout").(int)) * time.Second,
+		MaxSerializationRetries:   d.Get("max_serialization_retries").(int),
+		SerializationRetryBackoff: time.Duration(d.Get("serialization_retry_backoff").(int)) * time.Millisecond,
+	}
+
+	if v, ok := d.GetOk("max_connections"); ok {
+		config.MaxConns = v.(int)
+	}
+
+	// With a direct endpoint, changes, which are DDL, are made through it,
+	// and resources refreshed through the pooled one, host.
+	if v, ok := d.GetOk("direct_host"); ok {
+		if config.Pooled {
+			return nil, fmt.Errorf("direct_host can not be used with connection_profile %s: host is then the pooled endpoint, and direct_host the one changes are made through", connProfilePooled)
+		}
+		config.ReadHost, config.ReadPort = config.Host, config.Port
+		config.Host = v.(string)
+		if port, ok := d.GetOk("direct_port"); ok {
+			config.Port = port.(int)
+		}
+		config.ReadPooled = true
+	}
+
+	if _, ok := d.GetOk("max_connect_retries"); !ok && isNeonHost(config.Host) {
+		config.MaxConnectRetries = neonConnectRetries
+	}
+
+	if v, ok := d.GetOk("superuser"); ok {
+		superuser, _ := strconv.ParseBool(v.(string))
+		config.Superuser = &superuser
+	}
+
+	// Connections are established with the keepalive settings, whichever
+	// dialer they go through.
+	var forward pq.Dialer = netDialer{
+		keepAlive: &net.KeepAliveConfig{
+			Enable:   d.Get("keepalives").(bool),
+			Idle:     time.Duration(d.Get("keepalives_idle").(int)) * time.Second,
+			Interval: time.Duration(d.Get("keepalives_interval").(int)) * time.Second,
+			Count:    d.Get("keepalives_count").(int),
+		},
+	}
+	config.dialer = forward
+
+	if v, ok := d.GetOk("proxy_url"); ok {
+		proxy, err := newProxyDialer(v.(string))
+		if err != nil {
+			return nil, err
+		}
+		proxy.forward = forward
+		forward = proxy
+		config.dialer = proxy
+	}
+
+	_, cloudSQL := d.GetOk("cloudsql_instance")
+	if cloudSQL && config.ReadHost != "" {
+		return nil, fmt.Errorf("read_host and direct_host can not be used with cloudsql_instance")
+	}
+
+	// lib/pq authenticates with SCRAM-SHA-256 without channel binding, so
+	// the connections binding it are authenticated by the provider, which
+	// must then see the authentication requests through the SSL connection
+	// it negotiates itself.  So must it for SSL to be negotiated only when
+	// GSS encryption, which lib/pq does not support either, is not.  The
+	// Cloud SQL dialer encrypts the connections already.
+	if cloudSQL && gssEncMode == "require" {
+		return nil, fmt.Errorf("gssencmode require can not be used with cloudsql_instance")
+	}
+	providerSSL := false
+	if (channelBinding != "disable" || gssEncMode != "disable") && !cloudSQL {
+		switch config.SSLMode {
+		case "disable", "allow":
+			if channelBinding == "require" {
+				return nil, fmt.Errorf("channel_binding require can not be used with sslmode %s", config.SSLMode)
+			}
+		default:
+			providerSSL = true
+		}
+	}
+
+	pems := [][]byte{
+		[]byte(d.Get("sslrootcert_pem").(string)),
+		[]byte(d.Get("sslcert_pem").(string)),
+		[]byte(d.Get("sslkey_pem").(string)),
+	}
+	cipherSuites, ok := d.GetOk("ssl_cipher_suites")
+	if len(pems[0]) != 0 || len(pems[1]) != 0 || len(pems[2]) != 0 || ok || providerSSL {
+		switch {
+		case cloudSQL:
+			return nil, fmt.Errorf("sslrootcert_pem, sslcert_pem, sslkey_pem and ssl_cipher_suites can not be used with cloudsql_instance")
+		case config.SSLMode == "disable":
+			return nil, fmt.Errorf("sslmode disable can not be used with in-memory SSL certificates or ssl_cipher_suites")
+		}
+
+		// lib/pq only reads certificates from files and does not configure
+		// cipher suites, so SSL connections are established with a TLS
+		// configuration registered with it, or by the provider for channel
+		// binding, which holds the certificates given as files too.
+		files := []string{config.SSLRootCert, config.SSLCert, config.SSLKey}
+		if providerSSL {
+			// Like lib/pq, fall back to the files of libpq's directory,
+			// the key only for the certificate found there.
+			if files[0] == "" && len(pems[0]) == 0 {
+				files[0] = defaultSSLFile("root.crt")
+			}
+			if files[1] == "" && len(pems[1]) == 0 {
+				files[1] = defaultSSLFile("postgresql.crt")
+				if files[1] != "" && files[2] == "" && len(pems[2]) == 0 {
+					files[2] = defaultSSLFile("postgresql.key")
+				}
+			}
+		}
+		if files[0] == "system" {
+			// The system's certificate authorities, which lib/pq only
+			// trusts to verify the host name too.
+			if config.SSLMode == "" {
+				config.SSLMode = "verify-full"
+			}
+			if config.SSLMode != "verify-full" {
+				return nil, fmt.Errorf("sslrootcert system requires sslmode verify-full, got %q", config.SSLMode)
+			}
+			files[0] = ""
+		}
+		for i, path := range files {
+			if len(pems[i]) != 0 || path == "" {
+				continue
+			}
+			var err error
+			if pems[i], err = ioutil.ReadFile(path); err != nil {
+				return nil, errwrap.Wrapf("Error reading SSL certificate: {{err}}", err)
+			}
+		}
+
+		tlsConfig, err := newTLSConfig(config.SSLMode, pems[0], pems[1], pems[2])
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			names := make([]string, 0, len(cipherSuites.([]interface{})))
+			for _, name := range cipherSuites.([]interface{}) {
+				names = append(names, name.(string))
+			}
+			if tlsConfig.CipherSuites, err = cipherSuiteIDs(names); err != nil {
+				return nil, err
+			}
+		}
+
+		config.SSLRootCert = ""
+		config.SSLCert = ""
+		config.SSLKey = ""
+		if providerSSL {
+			mode := config.SSLMode
+			if mode == "" {
+				// lib/pq's default.
+				mode = "require"
+			}
+			tlsConfig.MinVersion = tlsVersions[config.SSLMinProtocolVersion]
+			tlsConfig.Renegotiation = tls.RenegotiateFreelyAsClient
+
+			config.dialer = &sslDialer{mode: mode, tlsConfig: tlsConfig, forward: config.dialer}
+			config.SSLMode = "disable"
+		} else {
+			config.tlsConfigMode = registerTLSConfig(tlsConfig)
+		}
+	}
+
+	if gssEncMode != "disable" && !cloudSQL {
+		service := config.KrbSrvname
+		if service == "" {
+			service = "postgres"
+		}
+		gssEnc := &gssEncDialer{mode: gssEncMode, service: service, gss: loadKerberosGSS, forward: config.dialer}
+		if ssl, ok := config.dialer.(*sslDialer); ok {
+			gssEnc.forward = ssl.forward
+			gssEnc.ssl = ssl
+		}
+		config.dialer = gssEnc
+	}
+
+	if v, ok := d.GetOk("cloudsql_instance"); ok {
+		iamAuth := d.Get("cloudsql_iam_auth").(bool)
+		dialer, err := newCloudSQLDialer(v.(string), iamAuth)
+		if err != nil {
+			return nil, err
+		}
+		dialer.forward = forward
+
+		// The host only tells connections to different instances apart,
+		// and TLS is handled by the dialer.
+		config.Host = v.(string)
+		config.Port = cloudSQLPort
+		config.SSLMode = "disable"
+		if iamAuth {
+			config.Password = ""
+		}
+		config.dialer = dialer
+	} else if d.Get("cloudsql_iam_auth").(bool) {
+		return nil, fmt.Errorf("cloudsql_iam_auth requires cloudsql_instance to be set")
+	}
+
+	stats, err := newApplyStats(d.Get("metrics_statsd_address").(string), d.Get("metrics_pushgateway_url").(string))
+	if err != nil {
+		return nil, errwrap.Wrapf("Error connecting to the StatsD server: {{err}}", err)
+	}
+	config.stats = stats
+
+	config.stop = stop
+	config.partial = !d.GetRawConfig().IsWhollyKnown()
+
+	if d.Get("dry_run").(bool) {
+		config.dryRun = &dryRun{}
+	}
+
+	auditFile, auditFileSet := d.GetOk("audit_log_file")
+	auditTable, auditTableSet := d.GetOk("audit_log_table")
+	if auditFileSet || auditTableSet {
+		config.audit = &auditLog{table: auditTable.(string)}
+		if auditFileSet {
+			f, err := os.OpenFile(auditFile.(string), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+			if err != nil {
+				return nil, errwrap.Wrapf("Error opening the audit log: {{err}}", err)
+			}
+			config.audit.file = f
+		}
+	}
+
+	for _, name := range d.Get("search_path").([]interface{}) {
+		config.SearchPath = append(config.SearchPath, name.(string))
+	}
+
+	if config.Pooled {
+		if err := checkPooledConfig(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	if v, ok := d.GetOk("password_command"); ok {
+		argv := make([]string, 0, len(v.([]interface{})))
+		for _, arg := range v.([]interface{}) {
+			argv = append(argv, arg.(string))
+		}
+
+		config.Password = ""
+		config.passwordFunc = newCommandPassword(argv, config.Username).Password
+	} else if v, ok := d.GetOk("password_file"); ok {
+		config.Password = ""
+		config.passwordFunc = filePassword(v.(string))
+	} else if v, ok := d.GetOk("password_env"); ok {
+		config.Password = ""
+		config.passwordFunc = envPassword(v.(string))
+	}
+
+	if d.Get("alloydb_iam_auth").(bool) {
+		if config.passwordFunc != nil {
+			return nil, fmt.Errorf("alloydb_iam_auth can not be combined with password_command, password_file or password_env")
+		}
+
+		config.Password = ""
+		config.passwordFunc = newAlloyDBTokenSource().Token
+	}
+
+	if d.Get("azure_ad_auth").(bool) {
+		if config.passwordFunc != nil {
+			return nil, fmt.Errorf("azure_ad_auth can not be combined with password_command, password_file, password_env or alloydb_iam_auth")
+		}
+
+		tokens := newAzureTokenSource(
+			d.Get("azure_tenant_id").(string),
+			d.Get("azure_client_id").(string),
+			d.Get("azure_client_secret").(string),
+		)
+
+		config.Password = ""
+		config.passwordFunc = tokens.Token
+	}
+
+	if channelBinding != "disable" && (providerSSL || cloudSQL) {
+		// The provider needs the password to authenticate, and lets lib/pq
+		// authenticate without one, e.g. with the password of ~/.pgpass.
+		if config.Password != "" || config.passwordFunc != nil {
+			config.ChannelBinding = channelBinding
+		} else if channelBinding == "require" {
+			return nil, fmt.Errorf("channel_binding require requires password, password_command, password_file, password_env or a token authentication")
+		}
+	}
+
+	if config.audit != nil && config.audit.table != "" {
+		// The entries are inserted through connections of their own, which
+		// are not waiting for the ones of the statements they record.
+		db, err := config.open(config.connStr())
+		if err != nil {
+			return nil, errwrap.Wrapf("Error connecting to PostgreSQL server: {{err}}", err)
+		}
+		db.SetMaxOpenConns(1)
+		config.audit.db = db
 	}
 
 	client, err := config.NewClient()
@@ -152,6 +1166,12 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	return client, nil
 }
 
-func tfAppName() string {
-	return fmt.Sprintf("Terraform v%s", terraform.VersionString())
+// tfAppName returns the application name of the connections, with the version
+// of Terraform if known.
+func tfAppName(terraformVersion string) string {
+	if terraformVersion == "" {
+		return "Terraform"
+	}
+
+	return fmt.Sprintf("Terraform v%s", terraformVersion)
 }
@@ -0,0 +1,309 @@
+package postgresql
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/lib/pq"
+)
+
+// netDialer is lib/pq's default dialer, with the TCP keepalive settings of
+// keepAlive, if set, instead of Go's.
+type netDialer struct {
+	keepAlive *net.KeepAliveConfig
+}
+
+func (d netDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialTimeout(network, address, 0)
+}
+
+func (d netDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: timeout}
+	if d.keepAlive != nil {
+		dialer.KeepAliveConfig = *d.keepAlive
+		if !d.keepAlive.Enable {
+			dialer.KeepAlive = -1
+		}
+	}
+
+	return dialer.Dial(network, address)
+}
+
+// proxyDialer is a pq.Dialer connecting through a SOCKS5 (socks5:// or, to
+// resolve host names on the proxy, socks5h://) or an HTTP CONNECT (http://)
+// proxy.  Unix domain sockets are connected to directly.
+type proxyDialer struct {
+	proxy   *url.URL
+	forward pq.Dialer
+}
+
+func newProxyDialer(proxyURL string) (*proxyDialer, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, errwrap.Wrapf("invalid proxy URL: {{err}}", err)
+	}
+
+	switch u.Scheme {
+	case "socks5", "socks5h", "http":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, expected socks5, socks5h or http", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: no host", proxyURL)
+	}
+
+	return &proxyDialer{
+		proxy:   u,
+		forward: netDialer{},
+	}, nil
+}
+
+// Dial implements pq.Dialer.
+func (d *proxyDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialTimeout(network, address, 0)
+}
+
+// DialTimeout implements pq.Dialer.
+func (d *proxyDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	if network == "unix" {
+		return d.forward.DialTimeout(network, address, timeout)
+	}
+
+	proxyAddr := d.proxy.Host
+	if d.proxy.Port() == "" {
+		port := "1080"
+		if d.proxy.Scheme == "http" {
+			port = "80"
+		}
+		proxyAddr = net.JoinHostPort(proxyAddr, port)
+	}
+
+	conn, err := d.forward.DialTimeout("tcp", proxyAddr, timeout)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error connecting to proxy: {{err}}", err)
+	}
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	if d.proxy.Scheme == "http" {
+		conn, err = d.connectHTTP(conn, address)
+	} else {
+		err = d.connectSOCKS5(conn, address)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, errwrap.Wrapf(fmt.Sprintf("Error connecting to %s through proxy: {{err}}", address), err)
+	}
+
+	conn.SetDeadline(time.Time{})
+
+	return conn, nil
+}
+
+// connectHTTP asks an HTTP proxy to tunnel conn to address.
+func (d *proxyDialer) connectHTTP(conn net.Conn, address string) (net.Conn, error) {
+	req := &http.Request{
+		Method: "CONNECT",
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if user := d.proxy.User; user != nil {
+		password, _ := user.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+password)))
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("proxy responded %s", resp.Status)
+	}
+
+	// Don't lose what the proxy already relayed from the server.
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+
+	return conn, nil
+}
+
+// connectSOCKS5 asks a SOCKS5 proxy (RFC 1928) to connect conn to address,
+// authenticating with the user name and password of the proxy URL (RFC 1929)
+// if any.
+func (d *proxyDialer) connectSOCKS5(conn net.Conn, address string) error {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return fmt.Errorf("invalid port %q", portStr)
+	}
+
+	const (
+		socks5Version      = 5
+		socks5NoAuth       = 0
+		socks5PasswordAuth = 2
+		socks5Connect      = 1
+		socks5IPv4         = 1
+		socks5DomainName   = 3
+		socks5IPv6         = 4
+	)
+
+	// Method negotiation
+	methods := []byte{socks5NoAuth}
+	if d.proxy.User != nil {
+		methods = append(methods, socks5PasswordAuth)
+	}
+	if _, err := conn.Write(append([]byte{socks5Version, byte(len(methods))}, methods...)); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 2)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return err
+	}
+	if buf[0] != socks5Version {
+		return fmt.Errorf("unexpected SOCKS version %d", buf[0])
+	}
+
+	switch buf[1] {
+	case socks5NoAuth:
+	case socks5PasswordAuth:
+		if d.proxy.User == nil {
+			return fmt.Errorf("proxy requires authentication")
+		}
+		user := d.proxy.User.Username()
+		password, _ := d.proxy.User.Password()
+		if len(user) > 255 || len(password) > 255 {
+			return fmt.Errorf("proxy user name or password too long")
+		}
+
+		req := []byte{1, byte(len(user))}
+		req = append(req, user...)
+		req = append(req, byte(len(password)))
+		req = append(req, password...)
+		if _, err := conn.Write(req); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return err
+		}
+		if buf[1] != 0 {
+			return fmt.Errorf("proxy authentication failed")
+		}
+	default:
+		return fmt.Errorf("no acceptable proxy authentication method")
+	}
+
+	// Connect request
+	req := []byte{socks5Version, socks5Connect, 0}
+	ip := net.ParseIP(host)
+	if ip == nil && d.proxy.Scheme == "socks5" {
+		ips, err := net.LookupIP(host)
+		if err != nil {
+			return err
+		}
+		ip = ips[0]
+	}
+	switch {
+	case ip == nil:
+		if len(host) > 255 {
+			return fmt.Errorf("host name too long")
+		}
+		req = append(req, socks5DomainName, byte(len(host)))
+		req = append(req, host...)
+	case ip.To4() != nil:
+		req = append(req, socks5IPv4)
+		req = append(req, ip.To4()...)
+	default:
+		req = append(req, socks5IPv6)
+		req = append(req, ip.To16()...)
+	}
+	req = append(req, 0, 0)
+	binary.BigEndian.PutUint16(req[len(req)-2:], uint16(port))
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	// Reply: version, status, reserved, bound address type, bound address and
+	// port.
+	reply := make([]byte, 4)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[1] != 0 {
+		return fmt.Errorf("proxy refused the connection: %s", socks5ReplyText(reply[1]))
+	}
+
+	var addrLen int
+	switch reply[3] {
+	case socks5IPv4:
+		addrLen = net.IPv4len
+	case socks5IPv6:
+		addrLen = net.IPv6len
+	case socks5DomainName:
+		if _, err := io.ReadFull(conn, buf[:1]); err != nil {
+			return err
+		}
+		addrLen = int(buf[0])
+	default:
+		return fmt.Errorf("unexpected address type %d in proxy reply", reply[3])
+	}
+	if _, err := io.ReadFull(conn, make([]byte, addrLen+2)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func socks5ReplyText(code byte) string {
+	switch code {
+	case 1:
+		return "general failure"
+	case 2:
+		return "connection not allowed by ruleset"
+	case 3:
+		return "network unreachable"
+	case 4:
+		return "host unreachable"
+	case 5:
+		return "connection refused"
+	case 6:
+		return "TTL expired"
+	case 7:
+		return "command not supported"
+	case 8:
+		return "address type not supported"
+	default:
+		return fmt.Sprintf("unknown error %d", code)
+	}
+}
+
+// bufferedConn is a net.Conn whose reads start with what was buffered while
+// setting it up.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
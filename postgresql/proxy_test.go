@@ -0,0 +1,145 @@
+package postgresql
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// listen serves every connection accepted on a local port with handle.
+func listen(t *testing.T, handle func(net.Conn)) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+
+	return l
+}
+
+func TestProxyDialer(t *testing.T) {
+	echo := listen(t, func(conn net.Conn) { io.Copy(conn, conn) })
+	defer echo.Close()
+
+	socksTargets := make(chan string, 1)
+	socks := listen(t, func(conn net.Conn) {
+		buf := make([]byte, 262)
+
+		// Require user name and password authentication.
+		io.ReadFull(conn, buf[:2])
+		io.ReadFull(conn, buf[:buf[1]])
+		conn.Write([]byte{5, 2})
+		io.ReadFull(conn, buf[:2])
+		n := int(buf[1])
+		io.ReadFull(conn, buf[:n])
+		user := string(buf[:n])
+		io.ReadFull(conn, buf[:1])
+		n = int(buf[0])
+		io.ReadFull(conn, buf[:n])
+		if user != "user" || string(buf[:n]) != "secret" {
+			conn.Write([]byte{1, 1})
+			return
+		}
+		conn.Write([]byte{1, 0})
+
+		// Connect to the host name requested.
+		io.ReadFull(conn, buf[:4])
+		if buf[3] != 3 {
+			conn.Write([]byte{5, 8, 0, 1, 0, 0, 0, 0, 0, 0})
+			return
+		}
+		io.ReadFull(conn, buf[:1])
+		n = int(buf[0])
+		io.ReadFull(conn, buf[:n+2])
+		socksTargets <- string(buf[:n])
+
+		target, err := net.Dial("tcp", echo.Addr().String())
+		if err != nil {
+			conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+			return
+		}
+		defer target.Close()
+		conn.Write([]byte{5, 0, 0, 1, 127, 0, 0, 1, 0, 0})
+		go io.Copy(target, conn)
+		io.Copy(conn, target)
+	})
+	defer socks.Close()
+
+	connectTargets := make(chan string, 1)
+	httpProxy := listen(t, func(conn net.Conn) {
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil {
+			return
+		}
+		connectTargets <- req.Host
+		if req.Method != "CONNECT" {
+			conn.Write([]byte("HTTP/1.1 405 Method Not Allowed\r\n\r\n"))
+			return
+		}
+
+		target, err := net.Dial("tcp", echo.Addr().String())
+		if err != nil {
+			conn.Write([]byte("HTTP/1.1 502 Bad Gateway\r\n\r\n"))
+			return
+		}
+		defer target.Close()
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		go io.Copy(target, conn)
+		io.Copy(conn, target)
+	})
+	defer httpProxy.Close()
+
+	for _, proxyURL := range []string{
+		"socks5h://user:secret@" + socks.Addr().String(),
+		"http://" + httpProxy.Addr().String(),
+	} {
+		d, err := newProxyDialer(proxyURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conn, err := d.DialTimeout("tcp", "db.example.com:5432", 5*time.Second)
+		if err != nil {
+			t.Errorf("%s: %v", proxyURL, err)
+			continue
+		}
+
+		conn.Write([]byte("ping"))
+		buf := make([]byte, 4)
+		if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "ping" {
+			t.Errorf("%s: expected the connection to be relayed, got %q: %v", proxyURL, buf, err)
+		}
+		conn.Close()
+	}
+
+	if socksTarget := <-socksTargets; socksTarget != "db.example.com" {
+		t.Errorf("expected the SOCKS5 proxy to resolve the host name, got %q", socksTarget)
+	}
+	if connectTarget := <-connectTargets; connectTarget != "db.example.com:5432" {
+		t.Errorf("expected the HTTP proxy to connect to the address, got %q", connectTarget)
+	}
+
+	d, _ := newProxyDialer("socks5h://user:wrong@" + socks.Addr().String())
+	if _, err := d.DialTimeout("tcp", "db.example.com:5432", 5*time.Second); err == nil {
+		t.Error("expected the SOCKS5 authentication to fail")
+	}
+
+	if _, err := newProxyDialer("https://proxy.example.com"); err == nil {
+		t.Error("expected https proxies to be rejected")
+	}
+}
@@ -0,0 +1,152 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+// redshiftACLQueries are the queries selecting the names and the ACLs, as
+// text, of the objects $2 in the schema $1 of the object types whose
+// privileges can be granted on Redshift, which has no aclexplode().
+var redshiftACLQueries = map[string]string{
+	"database": `SELECT d.datname, COALESCE(pg_catalog.array_to_string(d.datacl, ','), '') ` +
+		`FROM pg_catalog.pg_database d WHERE $1::TEXT = '' AND d.datname = ANY($2)`,
+	"schema": `SELECT n.nspname, COALESCE(pg_catalog.array_to_string(n.nspacl, ','), '') ` +
+		`FROM pg_catalog.pg_namespace n WHERE $1::TEXT = '' AND n.nspname = ANY($2)`,
+	"table": `SELECT c.relname, COALESCE(pg_catalog.array_to_string(c.relacl, ','), '') ` +
+		`FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+		`WHERE n.nspname = $1 AND c.relkind IN ('r', 'v') AND ($2::TEXT[] IS NULL OR c.relname = ANY($2))`,
+}
+
+// redshiftPrivileges maps the letters of the privileges in Redshift's aclitems
+// to the privileges.  Unlike PostgreSQL's, D is DROP.
+var redshiftPrivileges = map[byte]string{
+	'a': "INSERT",
+	'r': "SELECT",
+	'w': "UPDATE",
+	'd': "DELETE",
+	'D': "DROP",
+	'x': "REFERENCES",
+	'R': "RULE",
+	't': "TRIGGER",
+	'X': "EXECUTE",
+	'U': "USAGE",
+	'C': "CREATE",
+	'T': "TEMPORARY",
+	'A': "ALTER",
+}
+
+// redshiftACLPrivileges returns the privileges granted to the grantee, as
+// quoteGrantee writes it, in the aclitems of the ACL, followed by a * when they
+// are granted with grant option, as aclPrivileges does.  Groups are written
+// "group name" in aclitems, and PUBLIC with an empty name.
+func redshiftACLPrivileges(acl, grantee string) []string {
+	var privileges []string
+	for _, item := range splitQuoted(acl, ',') {
+		parts := splitQuoted(item, '=')
+		if len(parts) != 2 {
+			continue
+		}
+
+		name := parts[0]
+		switch {
+		case name == "":
+			name = "PUBLIC"
+		case strings.HasPrefix(name, "group "):
+			name = "GROUP " + pq.QuoteIdentifier(unquoteIdentifier(strings.TrimPrefix(name, "group ")))
+		default:
+			name = pq.QuoteIdentifier(unquoteIdentifier(name))
+		}
+		if name != grantee {
+			continue
+		}
+
+		letters := parts[1]
+		if i := strings.IndexByte(letters, '/'); i >= 0 {
+			letters = letters[:i]
+		}
+		for i := 0; i < len(letters); i++ {
+			privilege, ok := redshiftPrivileges[letters[i]]
+			if !ok {
+				continue
+			}
+			if i+1 < len(letters) && letters[i+1] == '*' {
+				privilege += "*"
+				i++
+			}
+			privileges = append(privileges, privilege)
+		}
+	}
+
+	return privileges
+}
+
+// splitQuoted splits s on the separators outside of double quotes.
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if s != "" {
+		parts = append(parts, s[start:])
+	}
+
+	return parts
+}
+
+// unquoteIdentifier removes the double quotes around an identifier, if any.
+func unquoteIdentifier(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+
+	return strings.Replace(s[1:len(s)-1], `""`, `"`, -1)
+}
+
+// redshiftTableOptions returns the DISTSTYLE, DISTKEY and SORTKEY options of
+// CREATE TABLE on Redshift, if any.
+func redshiftTableOptions(d *schema.ResourceData) (string, error) {
+	distStyle := strings.ToUpper(d.Get(tableDistStyleAttr).(string))
+	distKey := d.Get(tableDistKeyAttr).(string)
+	sortKeys := d.Get(tableSortKeysAttr).([]interface{})
+
+	var options []string
+	switch {
+	case distKey != "" && distStyle != "" && distStyle != "KEY":
+		return "", fmt.Errorf("%s can only be set with a %s of KEY", tableDistKeyAttr, tableDistStyleAttr)
+	case distKey == "" && distStyle == "KEY":
+		return "", fmt.Errorf("%s is required with a %s of KEY", tableDistKeyAttr, tableDistStyleAttr)
+	case distKey != "":
+		options = append(options, fmt.Sprintf("DISTSTYLE KEY DISTKEY (%s)", pq.QuoteIdentifier(distKey)))
+	case distStyle != "":
+		options = append(options, "DISTSTYLE "+distStyle)
+	}
+	if len(sortKeys) > 0 {
+		options = append(options, fmt.Sprintf("SORTKEY (%s)", quoteIdentifiers(interfaceStrings(sortKeys))))
+	}
+
+	return strings.Join(options, " "), nil
+}
+
+// interfaceStrings returns the strings of a list attribute.
+func interfaceStrings(list []interface{}) []string {
+	strs := make([]string, len(list))
+	for i, v := range list {
+		strs[i] = v.(string)
+	}
+
+	return strs
+}
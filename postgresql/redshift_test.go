@@ -0,0 +1,76 @@
+package postgresql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestRedshiftACLPrivileges(t *testing.T) {
+	acl := `owner=arwdRxtDA/owner,app=r*w/owner,"Mixed, ""Case"""=a/owner,group analysts=r/owner,group "BI team"=rx/owner,=r/owner`
+
+	tests := []struct {
+		role       string
+		privileges []string
+	}{
+		{"app", []string{"SELECT*", "UPDATE"}},
+		{`Mixed, "Case"`, []string{"INSERT"}},
+		{"GROUP analysts", []string{"SELECT"}},
+		{"GROUP BI team", []string{"SELECT", "REFERENCES"}},
+		{"public", []string{"SELECT"}},
+		{"owner", []string{"INSERT", "SELECT", "UPDATE", "DELETE", "RULE", "REFERENCES", "TRIGGER", "DROP", "ALTER"}},
+		{"analysts", nil},
+	}
+
+	for _, test := range tests {
+		if privileges := redshiftACLPrivileges(acl, quoteGrantee(test.role)); !reflect.DeepEqual(privileges, test.privileges) {
+			t.Errorf("%s: expected %q, got %q", test.role, test.privileges, privileges)
+		}
+	}
+}
+
+func TestRedshiftTableOptions(t *testing.T) {
+	tests := []struct {
+		config  map[string]interface{}
+		options string
+		err     bool
+	}{
+		{
+			config: map[string]interface{}{},
+		},
+		{
+			config:  map[string]interface{}{"dist_style": "even", "sort_keys": []interface{}{"day", "id"}},
+			options: `DISTSTYLE EVEN SORTKEY ("day", "id")`,
+		},
+		{
+			config:  map[string]interface{}{"dist_key": "user_id"},
+			options: `DISTSTYLE KEY DISTKEY ("user_id")`,
+		},
+		{
+			config:  map[string]interface{}{"dist_style": "KEY", "dist_key": "user_id"},
+			options: `DISTSTYLE KEY DISTKEY ("user_id")`,
+		},
+		{
+			config: map[string]interface{}{"dist_style": "ALL", "dist_key": "user_id"},
+			err:    true,
+		},
+		{
+			config: map[string]interface{}{"dist_style": "KEY"},
+			err:    true,
+		},
+	}
+
+	for _, test := range tests {
+		test.config["name"] = "events"
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLTable().Schema, test.config)
+		options, err := redshiftTableOptions(d)
+		if (err != nil) != test.err {
+			t.Errorf("%v: expected error %t, got %v", test.config, test.err, err)
+			continue
+		}
+		if options != test.options {
+			t.Errorf("%v: expected %q, got %q", test.config, test.options, options)
+		}
+	}
+}
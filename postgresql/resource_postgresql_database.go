@@ -2,39 +2,62 @@ package postgresql
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
 	"strings"
 
 	"github.com/hashicorp/errwrap"
-	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/lib/pq"
 )
 
 const (
-	dbAllowConnsAttr = "allow_connections"
-	dbCTypeAttr      = "lc_ctype"
-	dbCollationAttr  = "lc_collate"
-	dbConnLimitAttr  = "connection_limit"
-	dbEncodingAttr   = "encoding"
-	dbIsTemplateAttr = "is_template"
-	dbNameAttr       = "name"
-	dbOwnerAttr      = "owner"
-	dbTablespaceAttr = "tablespace_name"
-	dbTemplateAttr   = "template"
+	dbAllowConnsAttr   = "allow_connections"
+	dbCTypeAttr        = "lc_ctype"
+	dbCollationAttr    = "lc_collate"
+	dbConnLimitAttr    = "connection_limit"
+	dbEncodingAttr     = "encoding"
+	dbIsTemplateAttr   = "is_template"
+	dbNameAttr         = "name"
+	dbOwnerAttr        = "owner"
+	dbRevokePublicAttr = "revoke_public_privileges"
+	dbTablespaceAttr   = "tablespace_name"
+	dbTemplateAttr     = "template"
 )
 
+// dbAttrFeatures are the features the attributes of databases require, if any.
+var dbAttrFeatures = map[string]featureName{
+	dbIsTemplateAttr: featureDBIsTemplate,
+}
+
+// checkDBFeatures returns an error if the database has attributes the server
+// does not support.  allow_connections is only one when false: servers
+// without ALLOW_CONNECTIONS allow connections.
+func checkDBFeatures(c *Client, d attrChanges) error {
+	if err := checkAttrFeatures(c, d, dbAttrFeatures); err != nil {
+		return err
+	}
+
+	if (d.Id() == "" || d.HasChange(dbAllowConnsAttr)) && valueKnown(d, dbAllowConnsAttr) && !d.Get(dbAllowConnsAttr).(bool) && !c.featureSupported(featureDBAllowConnections) {
+		return &attrError{attr: dbAllowConnsAttr, err: c.featureError(featureDBAllowConnections, dbAllowConnsAttr)}
+	}
+
+	return nil
+}
+
 func resourcePostgreSQLDatabase() *schema.Resource {
 	return &schema.Resource{
-		Create: resourcePostgreSQLDatabaseCreate,
-		Read:   resourcePostgreSQLDatabaseRead,
-		Update: resourcePostgreSQLDatabaseUpdate,
-		Delete: resourcePostgreSQLDatabaseDelete,
-		Exists: resourcePostgreSQLDatabaseExists,
+		CreateContext: resourcePostgreSQLDatabaseCreate,
+		ReadContext:   resourcePostgreSQLDatabaseRead,
+		UpdateContext: resourcePostgreSQLDatabaseUpdate,
+		DeleteContext: resourcePostgreSQLDatabaseDelete,
+		Exists:        resourcePostgreSQLDatabaseExists,
+		CustomizeDiff: planFeatures(checkDBFeatures),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -102,15 +125,31 @@ func resourcePostgreSQLDatabase() *schema.Resource {
 				Computed:    true,
 				Description: "If true, then this database can be cloned by any user with CREATEDB privileges",
 			},
+			dbRevokePublicAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, then CONNECT and TEMPORARY on the database, and CREATE on its public schema, are kept revoked from PUBLIC",
+			},
 		},
 	}
 }
 
-func resourcePostgreSQLDatabaseCreate(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLDatabaseCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return diagnostics(createDatabase(d, meta))
+}
+
+func createDatabase(d *schema.ResourceData, meta interface{}) (err error) {
 	c := meta.(*Client)
+	if err := checkDBFeatures(c, d); err != nil {
+		return err
+	}
 
-	c.catalogLock.Lock()
-	defer c.catalogLock.Unlock()
+	unlock, err := c.lockCatalog("database", "")
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	dbName := d.Get(dbNameAttr).(string)
 	b := bytes.NewBufferString("CREATE DATABASE ")
@@ -118,17 +157,24 @@ func resourcePostgreSQLDatabaseCreate(d *schema.ResourceData, meta interface{})
 
 	// Needed in order to set the owner of the db if the connection user is not a
 	// superuser
-	err := grantRoleMembership(c.DB(), d.Get(dbOwnerAttr).(string), c.config.Username)
+	owner := d.Get(dbOwnerAttr).(string)
+	unlockMembership, err := c.lockRoleMembership(owner)
 	if err != nil {
-		return errwrap.Wrapf(fmt.Sprintf("Error adding connection user (%q) to ROLE %q: {{err}}", c.config.Username, d.Get(dbOwnerAttr).(string)), err)
+		return err
+	}
+	defer unlockMembership()
+
+	granted, err := grantRoleMembership(c, c.DB(), owner)
+	if err != nil {
+		return err
+	}
+	if granted {
+		defer func() {
+			if revokeErr := revokeRoleMembership(c, c.DB(), owner); revokeErr != nil && err == nil {
+				err = revokeErr
+			}
+		}()
 	}
-	defer func() {
-		//undo the grant if the connection user is not a superuser
-		err = revokeRoleMembership(c.DB(), d.Get(dbOwnerAttr).(string), c.config.Username)
-		if err != nil {
-			err = errwrap.Wrapf(fmt.Sprintf("Error removing connection user (%q) from ROLE %q: {{err}}", c.config.Username, d.Get(dbOwnerAttr).(string)), err)
-		}
-	}()
 
 	// Handle each option individually and stream results into the query
 	// buffer.
@@ -207,32 +253,52 @@ func resourcePostgreSQLDatabaseCreate(d *schema.ResourceData, meta interface{})
 
 	d.SetId(dbName)
 
+	if d.Get(dbRevokePublicAttr).(bool) {
+		if err := revokeDBPublicPrivileges(c, dbName); err != nil {
+			return err
+		}
+	}
+
 	// Set err outside of the return so that the deferred revoke can override err
 	// if necessary.
 	err = resourcePostgreSQLDatabaseReadImpl(d, meta)
 	return err
 }
 
-func resourcePostgreSQLDatabaseDelete(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLDatabaseDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return diagnostics(dropDatabase(d, meta))
+}
+
+func dropDatabase(d *schema.ResourceData, meta interface{}) (err error) {
 	c := meta.(*Client)
-	c.catalogLock.Lock()
-	defer c.catalogLock.Unlock()
+	unlock, err := c.lockCatalog("database", "")
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	dbName := d.Get(dbNameAttr).(string)
 
 	// Needed in order to set the owner of the db if the connection user is not a
 	// superuser
-	err := grantRoleMembership(c.DB(), d.Get(dbOwnerAttr).(string), c.config.Username)
+	owner := d.Get(dbOwnerAttr).(string)
+	unlockMembership, err := c.lockRoleMembership(owner)
 	if err != nil {
-		return errwrap.Wrapf(fmt.Sprintf("Error adding connection user (%q) to ROLE %q: {{err}}", c.config.Username, d.Get(dbOwnerAttr).(string)), err)
+		return err
+	}
+	defer unlockMembership()
+
+	granted, err := grantRoleMembership(c, c.DB(), owner)
+	if err != nil {
+		return err
+	}
+	if granted {
+		defer func() {
+			if revokeErr := revokeRoleMembership(c, c.DB(), owner); revokeErr != nil && err == nil {
+				err = revokeErr
+			}
+		}()
 	}
-	defer func() {
-		//undo the grant if the connection user is not a superuser
-		err = revokeRoleMembership(c.DB(), d.Get(dbOwnerAttr).(string), c.config.Username)
-		if err != nil {
-			err = errwrap.Wrapf(fmt.Sprintf("Error removing connection user (%q) from ROLE %q: {{err}}", c.config.Username, d.Get(dbOwnerAttr).(string)), err)
-		}
-	}()
 
 	if c.featureSupported(featureDBIsTemplate) {
 		if isTemplate := d.Get(dbIsTemplateAttr).(bool); isTemplate {
@@ -248,6 +314,7 @@ func resourcePostgreSQLDatabaseDelete(d *schema.ResourceData, meta interface{})
 		return err
 	}
 
+	c.releaseDB(dbName)
 	sql := fmt.Sprintf("DROP DATABASE %s", pq.QuoteIdentifier(dbName))
 	if _, err := c.DB().Exec(sql); err != nil {
 		return errwrap.Wrapf("Error dropping database: {{err}}", err)
@@ -260,8 +327,8 @@ func resourcePostgreSQLDatabaseDelete(d *schema.ResourceData, meta interface{})
 
 func resourcePostgreSQLDatabaseExists(d *schema.ResourceData, meta interface{}) (bool, error) {
 	c := meta.(*Client)
-	c.catalogLock.RLock()
-	defer c.catalogLock.RUnlock()
+	unlock := c.rlockCatalog("database", "")
+	defer unlock()
 
 	var dbName string
 	err := c.DB().QueryRow("SELECT d.datname from pg_database d WHERE datname=$1", d.Id()).Scan(&dbName)
@@ -275,12 +342,12 @@ func resourcePostgreSQLDatabaseExists(d *schema.ResourceData, meta interface{})
 	return true, nil
 }
 
-func resourcePostgreSQLDatabaseRead(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLDatabaseRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.RLock()
-	defer c.catalogLock.RUnlock()
+	unlock := c.rlockCatalog("database", "")
+	defer unlock()
 
-	return resourcePostgreSQLDatabaseReadImpl(d, meta)
+	return diagnostics(resourcePostgreSQLDatabaseReadImpl(d, meta))
 }
 
 func resourcePostgreSQLDatabaseReadImpl(d *schema.ResourceData, meta interface{}) error {
@@ -291,7 +358,7 @@ func resourcePostgreSQLDatabaseReadImpl(d *schema.ResourceData, meta interface{}
 	err := c.DB().QueryRow("SELECT d.datname, pg_catalog.pg_get_userbyid(d.datdba) from pg_database d WHERE datname=$1", dbId).Scan(&dbName, &ownerName)
 	switch {
 	case err == sql.ErrNoRows:
-		log.Printf("[WARN] PostgreSQL database (%q) not found", dbId)
+		logEvent("WARN", "PostgreSQL database not found", logFields{"database": dbId})
 		d.SetId("")
 		return nil
 	case err != nil:
@@ -323,7 +390,7 @@ func resourcePostgreSQLDatabaseReadImpl(d *schema.ResourceData, meta interface{}
 		)
 	switch {
 	case err == sql.ErrNoRows:
-		log.Printf("[WARN] PostgreSQL database (%q) not found", dbId)
+		logEvent("WARN", "PostgreSQL database not found", logFields{"database": dbId})
 		d.SetId("")
 		return nil
 	case err != nil:
@@ -365,41 +432,63 @@ func resourcePostgreSQLDatabaseReadImpl(d *schema.ResourceData, meta interface{}
 		d.Set(dbIsTemplateAttr, dbIsTemplate)
 	}
 
+	// Only whether the privileges are still revoked is read, so that they are
+	// revoked again if PUBLIC was granted them back, e.g. by a restore.
+	if d.Get(dbRevokePublicAttr).(bool) {
+		revoked, err := dbPublicPrivilegesRevoked(c, dbName)
+		if err != nil {
+			return err
+		}
+
+		d.Set(dbRevokePublicAttr, revoked)
+	}
+
 	return nil
 }
 
-func resourcePostgreSQLDatabaseUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLDatabaseUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.Lock()
-	defer c.catalogLock.Unlock()
+	if err := checkDBFeatures(c, d); err != nil {
+		return diagnostics(err)
+	}
+
+	unlock, err := c.lockCatalog("database", "")
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
 
 	if err := setDBName(c.DB(), d); err != nil {
-		return err
+		return diagnostics(err)
 	}
 
 	if err := setDBOwner(c, d); err != nil {
-		return err
+		return diagnostics(err)
 	}
 
 	if err := setDBTablespace(c.DB(), d); err != nil {
-		return err
+		return diagnostics(err)
 	}
 
 	if err := setDBConnLimit(c.DB(), d); err != nil {
-		return err
+		return diagnostics(err)
 	}
 
 	if err := setDBAllowConns(c, d); err != nil {
-		return err
+		return diagnostics(err)
 	}
 
 	if err := setDBIsTemplate(c, d); err != nil {
-		return err
+		return diagnostics(err)
+	}
+
+	if err := setDBRevokePublic(c, d); err != nil {
+		return diagnostics(err)
 	}
 
 	// Empty values: ALTER DATABASE name RESET configuration_parameter;
 
-	return resourcePostgreSQLDatabaseReadImpl(d, meta)
+	return diagnostics(resourcePostgreSQLDatabaseReadImpl(d, meta))
 }
 
 func setDBName(db *sql.DB, d *schema.ResourceData) error {
@@ -423,7 +512,7 @@ func setDBName(db *sql.DB, d *schema.ResourceData) error {
 	return nil
 }
 
-func setDBOwner(c *Client, d *schema.ResourceData) error {
+func setDBOwner(c *Client, d *schema.ResourceData) (err error) {
 	if !d.HasChange(dbOwnerAttr) {
 		return nil
 	}
@@ -434,17 +523,23 @@ func setDBOwner(c *Client, d *schema.ResourceData) error {
 	}
 
 	//needed in order to set the owner of the db if the connection user is not a superuser
-	err := grantRoleMembership(c.DB(), d.Get(dbOwnerAttr).(string), c.config.Username)
+	unlockMembership, err := c.lockRoleMembership(owner)
 	if err != nil {
-		return errwrap.Wrapf(fmt.Sprintf("Error adding connection user (%q) to ROLE %q: {{err}}", c.config.Username, d.Get(dbOwnerAttr).(string)), err)
+		return err
+	}
+	defer unlockMembership()
+
+	granted, err := grantRoleMembership(c, c.DB(), owner)
+	if err != nil {
+		return err
+	}
+	if granted {
+		defer func() {
+			if revokeErr := revokeRoleMembership(c, c.DB(), owner); revokeErr != nil && err == nil {
+				err = revokeErr
+			}
+		}()
 	}
-	defer func() {
-		// undo the grant if the connection user is not a superuser
-		err = revokeRoleMembership(c.DB(), d.Get(dbOwnerAttr).(string), c.config.Username)
-		if err != nil {
-			err = errwrap.Wrapf(fmt.Sprintf("Error removing connection user (%q) from ROLE %q: {{err}}", c.config.Username, d.Get(dbOwnerAttr).(string)), err)
-		}
-	}()
 
 	dbName := d.Get(dbNameAttr).(string)
 	sql := fmt.Sprintf("ALTER DATABASE %s OWNER TO %s", pq.QuoteIdentifier(dbName), pq.QuoteIdentifier(owner))
@@ -497,7 +592,7 @@ func setDBAllowConns(c *Client, d *schema.ResourceData) error {
 	}
 
 	if !c.featureSupported(featureDBAllowConnections) {
-		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support database ALLOW_CONNECTIONS", c.version.String())
+		return c.featureError(featureDBAllowConnections, "database ALLOW_CONNECTIONS")
 	}
 
 	allowConns := d.Get(dbAllowConnsAttr).(bool)
@@ -524,7 +619,7 @@ func setDBIsTemplate(c *Client, d *schema.ResourceData) error {
 
 func doSetDBIsTemplate(c *Client, dbName string, isTemplate bool) error {
 	if !c.featureSupported(featureDBIsTemplate) {
-		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support database IS_TEMPLATE", c.version.String())
+		return c.featureError(featureDBIsTemplate, "database IS_TEMPLATE")
 	}
 
 	sql := fmt.Sprintf("ALTER DATABASE %s IS_TEMPLATE $1", pq.QuoteIdentifier(dbName))
@@ -535,26 +630,120 @@ func doSetDBIsTemplate(c *Client, dbName string, isTemplate bool) error {
 	return nil
 }
 
-func grantRoleMembership(db *sql.DB, dbOwner string, connUsername string) error {
-	if dbOwner != "" && dbOwner != connUsername {
-		sql := fmt.Sprintf("GRANT %s TO %s", pq.QuoteIdentifier(dbOwner), pq.QuoteIdentifier(connUsername))
-		if _, err := db.Exec(sql); err != nil {
-			// is already member or role
-			if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
-				return nil
+func setDBRevokePublic(c *Client, d *schema.ResourceData) error {
+	if !d.HasChange(dbRevokePublicAttr) || !d.Get(dbRevokePublicAttr).(bool) {
+		return nil
+	}
+
+	return revokeDBPublicPrivileges(c, d.Get(dbNameAttr).(string))
+}
+
+// revokeDBPublicPrivileges revokes from PUBLIC the privileges it is granted by
+// default on the database and, before PostgreSQL 15, on its public schema.
+// The schema comes first, while the connection user can still connect to the
+// database as a member of PUBLIC.  The owner of the database can connect to it
+// and, from PostgreSQL 15, owns its public schema: the connection user acts as
+// a member of the owner, so that it is not locked out when the privileges are
+// revoked again, e.g. after a restore.
+func revokeDBPublicPrivileges(c *Client, dbName string) (err error) {
+	unlock, err := c.lockCatalog("grant", dbName)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var owner string
+	if err := c.DB().QueryRow("SELECT pg_catalog.pg_get_userbyid(datdba) FROM pg_catalog.pg_database WHERE datname = $1", dbName).Scan(&owner); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error reading the owner of database %q: {{err}}", dbName), err)
+	}
+
+	unlockMembership, err := c.lockRoleMembership(owner)
+	if err != nil {
+		return err
+	}
+	defer unlockMembership()
+
+	// The membership is granted outside of a transaction, so that the
+	// connection to the database sees it.
+	granted, err := grantRoleMembership(c, c.DB(), owner)
+	if err != nil {
+		return err
+	}
+	if granted {
+		defer func() {
+			if revokeErr := revokeRoleMembership(c, c.DB(), owner); revokeErr != nil && err == nil {
+				err = revokeErr
 			}
-			return errwrap.Wrapf("Error granting membership: {{err}}", err)
+		}()
+	}
+
+	db, err := c.DBFor(dbName, "")
+	if err != nil {
+		return err
+	}
+
+	var publicSchema bool
+	if err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_namespace WHERE nspname = 'public')").Scan(&publicSchema); err != nil {
+		return errwrap.Wrapf("Error reading schema public: {{err}}", err)
+	}
+	if publicSchema {
+		if _, err := db.Exec("REVOKE CREATE ON SCHEMA public FROM PUBLIC"); err != nil {
+			return errwrap.Wrapf("Error revoking the privileges of PUBLIC on schema public: {{err}}", err)
 		}
 	}
+
+	sql := fmt.Sprintf("REVOKE CONNECT, TEMPORARY ON DATABASE %s FROM PUBLIC", pq.QuoteIdentifier(dbName))
+	if _, err := c.DB().Exec(sql); err != nil {
+		return errwrap.Wrapf("Error revoking the privileges of PUBLIC on database: {{err}}", err)
+	}
+
 	return nil
 }
 
-func revokeRoleMembership(db *sql.DB, dbOwner string, connUsername string) error {
-	if dbOwner != "" && dbOwner != connUsername {
-		sql := fmt.Sprintf("REVOKE %s FROM %s", pq.QuoteIdentifier(dbOwner), pq.QuoteIdentifier(connUsername))
-		if _, err := db.Exec(sql); err != nil {
-			return errwrap.Wrapf("Error revoking membership: {{err}}", err)
-		}
+// dbPublicPrivilegesRevoked returns whether PUBLIC holds none of the privileges
+// revokeDBPublicPrivileges revokes.  ACLs which are NULL hold the default
+// privileges.  The database is only connected to if the connection user can.
+func dbPublicPrivilegesRevoked(c *Client, dbName string) (bool, error) {
+	var granted bool
+	err := c.DB().QueryRow(`SELECT EXISTS (SELECT 1 FROM (`+
+		`SELECT pg_catalog.aclexplode(COALESCE(d.datacl, pg_catalog.acldefault('d', d.datdba))) AS a `+
+		`FROM pg_catalog.pg_database d WHERE d.datname = $1) AS e `+
+		`WHERE (e.a).grantee = 0 AND (e.a).privilege_type IN ('CONNECT', 'TEMPORARY'))`, dbName).Scan(&granted)
+	if err != nil {
+		return false, errwrap.Wrapf("Error reading the privileges of PUBLIC on database: {{err}}", err)
 	}
-	return nil
+	if granted {
+		return false, nil
+	}
+
+	// Once PUBLIC can not connect to the database, the connection user may
+	// not either, unless it is a superuser or a member of the owner: the
+	// privileges on the public schema, revoked first, are then assumed to
+	// still be revoked rather than granting the membership to read them.
+	var canConnect bool
+	if err := c.DB().QueryRow("SELECT pg_catalog.has_database_privilege($1, 'CONNECT')", dbName).Scan(&canConnect); err != nil {
+		return false, errwrap.Wrapf("Error reading the privileges of the connection user on database: {{err}}", err)
+	}
+	if !canConnect {
+		logEvent("WARN", "Can not connect to the database to read the privileges of PUBLIC on schema public", logFields{
+			"database": dbName,
+			"user":     c.config.Username,
+		})
+		return true, nil
+	}
+
+	db, err := c.DBFor(dbName, "")
+	if err != nil {
+		return false, err
+	}
+
+	err = db.QueryRow(`SELECT EXISTS (SELECT 1 FROM (` +
+		`SELECT pg_catalog.aclexplode(COALESCE(n.nspacl, pg_catalog.acldefault('n', n.nspowner))) AS a ` +
+		`FROM pg_catalog.pg_namespace n WHERE n.nspname = 'public') AS e ` +
+		`WHERE (e.a).grantee = 0 AND (e.a).privilege_type = 'CREATE')`).Scan(&granted)
+	if err != nil {
+		return false, errwrap.Wrapf("Error reading the privileges of PUBLIC on schema public: {{err}}", err)
+	}
+
+	return !granted, nil
 }
@@ -6,8 +6,9 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform/helper/resource"
-	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccPostgresqlDatabase_Basic(t *testing.T) {
@@ -133,6 +134,91 @@ func TestAccPostgresqlDatabase_DefaultOwner(t *testing.T) {
 	})
 }
 
+func TestAccPostgresqlDatabase_RevokePublicPrivileges(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgreSQLDatabaseRevokePublicConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.hardened", "revoke_public_privileges", "true"),
+					func(*terraform.State) error {
+						testAccPostgresqlExec(t, `DO $$ BEGIN `+
+							`IF pg_catalog.has_database_privilege('public', 'hardened_db', 'CONNECT') `+
+							`OR pg_catalog.has_database_privilege('public', 'hardened_db', 'TEMPORARY') THEN `+
+							`RAISE EXCEPTION 'PUBLIC must not connect to hardened_db'; `+
+							`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+			{
+				// Granted back, e.g. by a restore.
+				PreConfig: func() {
+					testAccPostgresqlExec(t, "GRANT CONNECT ON DATABASE hardened_db TO PUBLIC")
+				},
+				Config:             testAccPostgreSQLDatabaseRevokePublicConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccPostgreSQLDatabaseRevokePublicConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.hardened", "revoke_public_privileges", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_NonSuperuser(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP DATABASE IF EXISTS nonsuper_db",
+		"DROP ROLE IF EXISTS nonsuper_owner",
+		"REVOKE CREATE ON DATABASE postgres FROM nonsuper_admin",
+		"DROP ROLE IF EXISTS nonsuper_admin",
+	)
+
+	// The resources are managed by a provider connecting as a role with
+	// CREATEDB and CREATEROLE, the way cloud providers set up their
+	// administrative users.
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE ROLE nonsuper_admin LOGIN CREATEDB CREATEROLE PASSWORD 'nonsuper'",
+				"GRANT CREATE ON DATABASE postgres TO nonsuper_admin",
+			)
+		},
+		Providers: map[string]*schema.Provider{
+			"postgresql": Provider(),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgreSQLDatabaseNonSuperuserConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"postgresql_database.db", "owner", "nonsuper_owner"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.db", "revoke_public_privileges", "true"),
+					resource.TestCheckResourceAttr(
+						"postgresql_schema.schema", "owner", "nonsuper_owner"),
+					func(*terraform.State) error {
+						// The temporary membership must have been revoked.
+						testAccPostgresqlExec(t, `DO $$ BEGIN `+
+							`IF pg_catalog.pg_has_role('nonsuper_admin', 'nonsuper_owner', 'MEMBER') THEN `+
+							`RAISE EXCEPTION 'nonsuper_admin is still a member of nonsuper_owner'; `+
+							`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckPostgresqlDatabaseDestroy(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client)
 
@@ -202,17 +288,17 @@ resource "postgresql_role" "myrole" {
 
 resource "postgresql_database" "mydb" {
    name = "mydb"
-   owner = "${postgresql_role.myrole.name}"
+   owner = postgresql_role.myrole.name
 }
 
 resource "postgresql_database" "mydb2" {
    name = "mydb2"
-   owner = "${postgresql_role.myrole.name}"
+   owner = postgresql_role.myrole.name
 }
 
 resource "postgresql_database" "default_opts" {
    name = "default_opts_name"
-   owner = "${postgresql_role.myrole.name}"
+   owner = postgresql_role.myrole.name
    template = "template0"
    encoding = "UTF8"
    lc_collate = "C"
@@ -225,7 +311,7 @@ resource "postgresql_database" "default_opts" {
 
 resource "postgresql_database" "modified_opts" {
    name = "custom_template_db"
-   owner = "${postgresql_role.myrole.name}"
+   owner = postgresql_role.myrole.name
    template = "template0"
    encoding = "UTF8"
    lc_collate = "en_US.UTF-8"
@@ -238,7 +324,7 @@ resource "postgresql_database" "modified_opts" {
 
 resource "postgresql_database" "pathological_opts" {
    name = "bad_template_db"
-   owner = "${postgresql_role.myrole.name}"
+   owner = postgresql_role.myrole.name
    template = "template0"
    encoding = "LATIN1"
    lc_collate = "C"
@@ -261,7 +347,7 @@ resource "postgresql_database" "pg_default_opts" {
   }
 
   name = "pg_defaults_db"
-  owner = "${postgresql_role.myrole.name}"
+  owner = postgresql_role.myrole.name
   template = "DEFAULT"
   encoding = "DEFAULT"
   lc_collate = "DEFAULT"
@@ -277,3 +363,33 @@ resource "postgresql_database" "mydb_default_owner" {
 }
 
 `
+
+var testAccPostgreSQLDatabaseNonSuperuserConfig = `
+provider "postgresql" {
+  username  = "nonsuper_admin"
+  password  = "nonsuper"
+  superuser = false
+}
+
+resource "postgresql_role" "owner" {
+  name = "nonsuper_owner"
+}
+
+resource "postgresql_database" "db" {
+  name                     = "nonsuper_db"
+  owner                    = postgresql_role.owner.name
+  revoke_public_privileges = true
+}
+
+resource "postgresql_schema" "schema" {
+  name  = "nonsuper_schema"
+  owner = postgresql_role.owner.name
+}
+`
+
+var testAccPostgreSQLDatabaseRevokePublicConfig = `
+resource "postgresql_database" "hardened" {
+  name                     = "hardened_db"
+  revoke_public_privileges = true
+}
+`
@@ -0,0 +1,448 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	defaultPrivilegesRoleAttr       = "role"
+	defaultPrivilegesDatabaseAttr   = "database"
+	defaultPrivilegesOwnerAttr      = "owner"
+	defaultPrivilegesSchemaAttr     = "schema"
+	defaultPrivilegesObjectTypeAttr = "object_type"
+	defaultPrivilegesPrivilegesAttr = "privileges"
+	defaultPrivilegesWithOptionAttr = "with_grant_option"
+)
+
+// defaultPrivilegesObjectType describes how the default privileges on a type of
+// objects are altered and read.
+type defaultPrivilegesObjectType struct {
+	// privileges are the privileges which can be granted on the objects.
+	privileges []string
+
+	// keyword is the keyword of the objects in ALTER DEFAULT PRIVILEGES, e.g.
+	// TABLES.
+	keyword string
+
+	// defACLType is the pg_default_acl.defaclobjtype of the objects, and
+	// aclDefaultType the type acldefault() takes for them.
+	defACLType     string
+	aclDefaultType string
+
+	// global is true for the objects whose default privileges can only be
+	// altered for every schema.
+	global bool
+}
+
+var defaultPrivilegesObjectTypes = map[string]defaultPrivilegesObjectType{
+	"table": {
+		privileges:     grantObjectTypes["table"].privileges,
+		keyword:        "TABLES",
+		defACLType:     "r",
+		aclDefaultType: "r",
+	},
+	"sequence": {
+		privileges:     grantObjectTypes["sequence"].privileges,
+		keyword:        "SEQUENCES",
+		defACLType:     "S",
+		aclDefaultType: "s",
+	},
+	"function": {
+		privileges:     grantObjectTypes["function"].privileges,
+		keyword:        "FUNCTIONS",
+		defACLType:     "f",
+		aclDefaultType: "f",
+	},
+	// ROUTINES is a synonym of FUNCTIONS, which also covers procedures.
+	"routine": {
+		privileges:     grantObjectTypes["routine"].privileges,
+		keyword:        "ROUTINES",
+		defACLType:     "f",
+		aclDefaultType: "f",
+	},
+	"type": {
+		privileges:     grantObjectTypes["type"].privileges,
+		keyword:        "TYPES",
+		defACLType:     "T",
+		aclDefaultType: "T",
+	},
+	"schema": {
+		privileges:     grantObjectTypes["schema"].privileges,
+		keyword:        "SCHEMAS",
+		defACLType:     "n",
+		aclDefaultType: "n",
+		global:         true,
+	},
+}
+
+// defaultPrivilegesQuery selects the default privileges the role of OID $5 is
+// granted on the objects of pg_default_acl type $3, or acldefault() type $4,
+// created by the role $1 in the schema $2, or in every schema if empty.  Without
+// an entry in pg_default_acl, the objects created in every schema get the
+// built-in default privileges, and the ones created in a schema get no more.
+var defaultPrivilegesQuery = `SELECT ` + aclPrivileges(`COALESCE(`+
+	`(SELECT da.defaclacl FROM pg_catalog.pg_default_acl da WHERE da.defaclrole = o.oid `+
+	`AND da.defaclnamespace = CASE WHEN $2 = '' THEN 0 ELSE (SELECT n.oid FROM pg_catalog.pg_namespace n WHERE n.nspname = $2) END `+
+	`AND da.defaclobjtype = $3::"char"), `+
+	`CASE WHEN $2 = '' THEN pg_catalog.acldefault($4::"char", o.oid) END)`, "$5") + ` ` +
+	`FROM pg_catalog.pg_roles o WHERE o.rolname = $1`
+
+// defaultPrivilegesObjectTypeFeatures are the features altering the default
+// privileges on the object types requires, if any.
+var defaultPrivilegesObjectTypeFeatures = map[string]featureName{
+	"routine": featureProKind,
+	"type":    featureTypePrivileges,
+	"schema":  featureDefaultPrivilegesOnSchemas,
+}
+
+// checkDefaultPrivilegesFeatures returns an error if the server does not
+// support default privileges on the object type.
+func checkDefaultPrivilegesFeatures(c *Client, d attrChanges) error {
+	return checkValueFeatures(c, d, defaultPrivilegesObjectTypeAttr, defaultPrivilegesObjectTypeFeatures)
+}
+
+func resourcePostgreSQLDefaultPrivileges() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourcePostgreSQLDefaultPrivilegesCreate,
+		ReadContext:   resourcePostgreSQLDefaultPrivilegesRead,
+		UpdateContext: resourcePostgreSQLDefaultPrivilegesUpdate,
+		DeleteContext: resourcePostgreSQLDefaultPrivilegesDelete,
+		CustomizeDiff: planFeatures(checkDefaultPrivilegesFeatures),
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePostgreSQLDefaultPrivilegesImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			defaultPrivilegesRoleAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The role the privileges are granted to, or PUBLIC",
+			},
+			defaultPrivilegesDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The database the objects are created in, instead of the provider's database",
+			},
+			defaultPrivilegesOwnerAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The role creating the objects",
+			},
+			defaultPrivilegesSchemaAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Description:  "The schema the objects are created in, instead of every schema",
+				ValidateFunc: validateGrantSchema,
+			},
+			defaultPrivilegesObjectTypeAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The type of the objects: table, sequence, function, routine, type or schema",
+				ValidateFunc: validateDefaultPrivilegesObjectType,
+			},
+			defaultPrivilegesPrivilegesAttr: {
+				Type:        schema.TypeSet,
+				Required:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The privileges granted by default",
+			},
+			defaultPrivilegesWithOptionAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the role can grant the privileges to others",
+			},
+		},
+	}
+}
+
+func validateDefaultPrivilegesObjectType(v interface{}, key string) (warnings []string, errors []error) {
+	if _, ok := defaultPrivilegesObjectTypes[v.(string)]; !ok {
+		errors = append(errors, fmt.Errorf("%s must be one of function, routine, schema, sequence, table, type, got %q", key, v.(string)))
+	}
+	return
+}
+
+// defaultPrivileges are the default privileges a postgresql_default_privileges
+// manages.
+type defaultPrivileges struct {
+	role       string
+	database   string
+	owner      string
+	schema     string
+	objectType string
+	privileges []string
+	withOption bool
+}
+
+// newDefaultPrivileges returns the default privileges configured in d, checking
+// that they can be altered.
+func newDefaultPrivileges(d *schema.ResourceData) (*defaultPrivileges, error) {
+	p := &defaultPrivileges{
+		role:       d.Get(defaultPrivilegesRoleAttr).(string),
+		database:   d.Get(defaultPrivilegesDatabaseAttr).(string),
+		owner:      d.Get(defaultPrivilegesOwnerAttr).(string),
+		schema:     d.Get(defaultPrivilegesSchemaAttr).(string),
+		objectType: d.Get(defaultPrivilegesObjectTypeAttr).(string),
+		privileges: setToSortedStrings(d.Get(defaultPrivilegesPrivilegesAttr).(*schema.Set)),
+		withOption: d.Get(defaultPrivilegesWithOptionAttr).(bool),
+	}
+	objectType := defaultPrivilegesObjectTypes[p.objectType]
+
+	if objectType.global && p.schema != "" {
+		return nil, fmt.Errorf("%s can not be set for the default privileges on %s objects", defaultPrivilegesSchemaAttr, p.objectType)
+	}
+	if p.withOption && quoteGrantee(p.role) == "PUBLIC" {
+		return nil, fmt.Errorf("privileges can not be granted to PUBLIC with grant option")
+	}
+	for _, privilege := range p.privileges {
+		if !stringInSlice(privilege, objectType.privileges) {
+			return nil, fmt.Errorf("invalid privilege %q, the privileges which can be granted are %s", privilege, strings.Join(objectType.privileges, ", "))
+		}
+	}
+
+	return p, nil
+}
+
+// defaultPrivilegesImportFormat is the format of the IDs of default privileges.
+const defaultPrivilegesImportFormat = "role/database/owner/schema/object_type"
+
+// id returns the ID of the resource managing the default privileges, in
+// defaultPrivilegesImportFormat.
+func (p *defaultPrivileges) id() string {
+	return importID(p.role, p.database, p.owner, p.schema, p.objectType)
+}
+
+// alterQuery returns the ALTER DEFAULT PRIVILEGES statement up to the action.
+func (p *defaultPrivileges) alterQuery() string {
+	query := "ALTER DEFAULT PRIVILEGES FOR ROLE " + pq.QuoteIdentifier(p.owner)
+	if p.schema != "" {
+		query += " IN SCHEMA " + pq.QuoteIdentifier(p.schema)
+	}
+
+	return query
+}
+
+// revokeQuery returns the statement revoking the default privileges from the
+// role.
+func (p *defaultPrivileges) revokeQuery() string {
+	return fmt.Sprintf("%s REVOKE ALL ON %s FROM %s", p.alterQuery(), defaultPrivilegesObjectTypes[p.objectType].keyword, quoteGrantee(p.role))
+}
+
+// grantQuery returns the statement granting the default privileges, or nothing
+// if there are none.
+func (p *defaultPrivileges) grantQuery() string {
+	if len(p.privileges) == 0 {
+		return ""
+	}
+
+	query := fmt.Sprintf("%s GRANT %s ON %s TO %s", p.alterQuery(), strings.Join(p.privileges, ", "), defaultPrivilegesObjectTypes[p.objectType].keyword, quoteGrantee(p.role))
+	if p.withOption {
+		query += " WITH GRANT OPTION"
+	}
+
+	return query
+}
+
+func resourcePostgreSQLDefaultPrivilegesCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if err := resourcePostgreSQLDefaultPrivilegesAlter(d, meta, true); err != nil {
+		return diagnostics(err)
+	}
+
+	p, err := newDefaultPrivileges(d)
+	if err != nil {
+		return diagnostics(err)
+	}
+	d.SetId(p.id())
+
+	return diagnostics(resourcePostgreSQLDefaultPrivilegesReadImpl(d, meta))
+}
+
+func resourcePostgreSQLDefaultPrivilegesUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if err := resourcePostgreSQLDefaultPrivilegesAlter(d, meta, true); err != nil {
+		return diagnostics(err)
+	}
+
+	return diagnostics(resourcePostgreSQLDefaultPrivilegesReadImpl(d, meta))
+}
+
+func resourcePostgreSQLDefaultPrivilegesDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if err := resourcePostgreSQLDefaultPrivilegesAlter(d, meta, false); err != nil {
+		return diagnostics(err)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// resourcePostgreSQLDefaultPrivilegesAlter revokes the default privileges of
+// the role and, if grant is true, grants it the configured ones, in a
+// transaction.  Altering the default privileges of another role than the
+// connection user requires being a member of it.
+func resourcePostgreSQLDefaultPrivilegesAlter(d *schema.ResourceData, meta interface{}, grant bool) error {
+	c := meta.(*Client)
+	p, err := newDefaultPrivileges(d)
+	if err != nil {
+		return err
+	}
+	if err := checkDefaultPrivilegesFeatures(c, d); err != nil {
+		return err
+	}
+	if c.redshift {
+		return fmt.Errorf("Default privileges are not supported on Redshift")
+	}
+	if grant && isSystemSchema(p.schema) {
+		if err := c.checkUnsafeGrant("default privileges on the %s objects of system schema %s to role %s", p.objectType, p.schema, p.role); err != nil {
+			return err
+		}
+	}
+
+	unlock, err := c.lockCatalog("grant", c.databaseName(p.database))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	unlockMembership, err := c.lockRoleMembership(p.owner)
+	if err != nil {
+		return err
+	}
+	defer unlockMembership()
+
+	db, err := c.DBFor(p.database, "")
+	if err != nil {
+		return err
+	}
+
+	return c.inTxn(db, "default privileges", func(txn *sql.Tx) error {
+		granted, err := grantRoleMembership(c, txn, p.owner)
+		if err != nil {
+			return err
+		}
+
+		queries := []string{p.revokeQuery()}
+		if grant {
+			queries = append(queries, p.grantQuery())
+		}
+		for _, query := range queries {
+			if query == "" {
+				continue
+			}
+			if _, err := txn.Exec(query); err != nil {
+				return errwrap.Wrapf(fmt.Sprintf("Error altering the default privileges of role %s: {{err}}", p.role), err)
+			}
+		}
+
+		if granted {
+			return revokeRoleMembership(c, txn, p.owner)
+		}
+
+		return nil
+	})
+}
+
+// resourcePostgreSQLDefaultPrivilegesImport sets the arguments of the default
+// privileges from their ID.  The privileges, and grant option, are then read.
+func resourcePostgreSQLDefaultPrivilegesImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts, err := splitImportID(d.Id(), 5, 5, defaultPrivilegesImportFormat)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := defaultPrivilegesObjectTypes[parts[4]]; !ok {
+		return nil, fmt.Errorf("invalid object type %q, expected one of function, routine, schema, sequence, table, type", parts[4])
+	}
+
+	d.Set(defaultPrivilegesRoleAttr, parts[0])
+	d.Set(defaultPrivilegesDatabaseAttr, parts[1])
+	d.Set(defaultPrivilegesOwnerAttr, parts[2])
+	d.Set(defaultPrivilegesSchemaAttr, parts[3])
+	d.Set(defaultPrivilegesObjectTypeAttr, parts[4])
+
+	p, err := newDefaultPrivileges(d)
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(p.id())
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourcePostgreSQLDefaultPrivilegesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	unlock := c.rlockCatalog("grant", c.databaseName(d.Get(defaultPrivilegesDatabaseAttr).(string)))
+	defer unlock()
+
+	return diagnostics(resourcePostgreSQLDefaultPrivilegesReadImpl(d, meta))
+}
+
+func resourcePostgreSQLDefaultPrivilegesReadImpl(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	p, err := newDefaultPrivileges(d)
+	if err != nil {
+		return err
+	}
+
+	db, err := c.DBFor(p.database, "")
+	if err != nil {
+		return err
+	}
+
+	// PUBLIC is represented by the grantee OID 0 in ACLs.
+	var roleOID int64
+	if quoteGrantee(p.role) != "PUBLIC" {
+		err := db.QueryRow("SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $1", p.role).Scan(&roleOID)
+		switch {
+		case err == sql.ErrNoRows:
+			logEvent("WARN", "PostgreSQL role of default privileges not found", logFields{"role": p.role})
+			d.SetId("")
+			return nil
+		case err != nil:
+			return errwrap.Wrapf("Error reading role: {{err}}", err)
+		}
+	}
+
+	objectType := defaultPrivilegesObjectTypes[p.objectType]
+	var acl pq.StringArray
+	err = db.QueryRow(defaultPrivilegesQuery, p.owner, p.schema, objectType.defACLType, objectType.aclDefaultType, roleOID).Scan(&acl)
+	switch {
+	case err == sql.ErrNoRows:
+		logEvent("WARN", "PostgreSQL owner of default privileges not found", logFields{"owner": p.owner})
+		d.SetId("")
+		return nil
+	case err != nil:
+		return errwrap.Wrapf(fmt.Sprintf("Error reading the default privileges of role %s: {{err}}", p.role), err)
+	}
+
+	// The privileges are granted with grant option if all of them are.
+	privileges := make([]string, 0, len(acl))
+	withOption := len(acl) > 0
+	for _, privilege := range acl {
+		privilege, option := strings.TrimSuffix(privilege, "*"), strings.HasSuffix(privilege, "*")
+		if !stringInSlice(privilege, privileges) {
+			privileges = append(privileges, privilege)
+		}
+		withOption = withOption && option
+	}
+	if len(privileges) == 0 {
+		withOption = p.withOption
+	}
+	d.Set(defaultPrivilegesPrivilegesAttr, privileges)
+	d.Set(defaultPrivilegesWithOptionAttr, withOption)
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package postgresql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestDefaultPrivilegesQueries(t *testing.T) {
+	tests := []struct {
+		config map[string]interface{}
+
+		id     string
+		revoke string
+		grant  string
+		err    string
+	}{
+		{
+			config: map[string]interface{}{
+				"role":        "reader",
+				"owner":       "app",
+				"schema":      "public",
+				"object_type": "table",
+				"privileges":  []interface{}{"SELECT"},
+			},
+			id:     "reader//app/public/table",
+			revoke: `ALTER DEFAULT PRIVILEGES FOR ROLE "app" IN SCHEMA "public" REVOKE ALL ON TABLES FROM "reader"`,
+			grant:  `ALTER DEFAULT PRIVILEGES FOR ROLE "app" IN SCHEMA "public" GRANT SELECT ON TABLES TO "reader"`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "public",
+				"database":    "shop",
+				"owner":       "app",
+				"object_type": "function",
+				"privileges":  []interface{}{},
+			},
+			id:     "public/shop/app//function",
+			revoke: `ALTER DEFAULT PRIVILEGES FOR ROLE "app" REVOKE ALL ON FUNCTIONS FROM PUBLIC`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":              "lead",
+				"owner":             "app",
+				"object_type":       "schema",
+				"privileges":        []interface{}{"USAGE", "CREATE"},
+				"with_grant_option": true,
+			},
+			id:     "lead//app//schema",
+			revoke: `ALTER DEFAULT PRIVILEGES FOR ROLE "app" REVOKE ALL ON SCHEMAS FROM "lead"`,
+			grant:  `ALTER DEFAULT PRIVILEGES FOR ROLE "app" GRANT CREATE, USAGE ON SCHEMAS TO "lead" WITH GRANT OPTION`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "lead",
+				"owner":       "app",
+				"schema":      "public",
+				"object_type": "schema",
+				"privileges":  []interface{}{"USAGE"},
+			},
+			err: "schema can not be set",
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "reader",
+				"owner":       "app",
+				"object_type": "sequence",
+				"privileges":  []interface{}{"EXECUTE"},
+			},
+			err: `invalid privilege "EXECUTE"`,
+		},
+	}
+
+	for _, test := range tests {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLDefaultPrivileges().Schema, test.config)
+		p, err := newDefaultPrivileges(d)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%v: expected an error containing %q, got %v", test.config, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %v", test.config, err)
+			continue
+		}
+
+		if id := p.id(); id != test.id {
+			t.Errorf("%v: expected ID %q, got %q", test.config, test.id, id)
+		}
+		if revoke := p.revokeQuery(); revoke != test.revoke {
+			t.Errorf("%v: expected %q, got %q", test.config, test.revoke, revoke)
+		}
+		if grant := p.grantQuery(); grant != test.grant {
+			t.Errorf("%v: expected %q, got %q", test.config, test.grant, grant)
+		}
+	}
+}
+
+func TestAccPostgresqlDefaultPrivileges_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP SCHEMA IF EXISTS defpriv_app CASCADE",
+		"DROP OWNED BY defpriv_owner",
+		"DROP ROLE IF EXISTS defpriv_owner",
+		"DROP ROLE IF EXISTS defpriv_reader",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE ROLE defpriv_owner",
+				"CREATE ROLE defpriv_reader",
+				"CREATE SCHEMA defpriv_app AUTHORIZATION defpriv_owner",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDefaultPrivilegesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_default_privileges.tables", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_default_privileges.functions", "privileges.#", "0"),
+					func(*terraform.State) error {
+						testAccPostgresqlExec(t,
+							"SET ROLE defpriv_owner; "+
+								"CREATE TABLE defpriv_app.orders (id int); "+
+								"CREATE FUNCTION defpriv_app.one() RETURNS int AS 'SELECT 1' LANGUAGE SQL; "+
+								"RESET ROLE",
+							`DO $$ BEGIN `+
+								`IF NOT pg_catalog.has_table_privilege('defpriv_reader', 'defpriv_app.orders', 'SELECT') `+
+								`OR pg_catalog.has_function_privilege('public', 'defpriv_app.one()', 'EXECUTE') THEN `+
+								`RAISE EXCEPTION 'the default privileges were not applied'; `+
+								`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      "postgresql_default_privileges.tables",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// The built-in default privileges granted back.
+				PreConfig: func() {
+					testAccPostgresqlExec(t, "ALTER DEFAULT PRIVILEGES FOR ROLE defpriv_owner GRANT EXECUTE ON FUNCTIONS TO PUBLIC")
+				},
+				Config:             testAccPostgresqlDefaultPrivilegesConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDefaultPrivilegesConfig = `
+resource "postgresql_default_privileges" "tables" {
+  role        = "defpriv_reader"
+  owner       = "defpriv_owner"
+  schema      = "defpriv_app"
+  object_type = "table"
+  privileges  = ["SELECT"]
+}
+
+resource "postgresql_default_privileges" "functions" {
+  role        = "public"
+  owner       = "defpriv_owner"
+  object_type = "function"
+  privileges  = []
+}
+`
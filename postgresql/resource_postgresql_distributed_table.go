@@ -0,0 +1,272 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	distributedTableDatabaseAttr     = "database"
+	distributedTableSchemaAttr       = "schema"
+	distributedTableTableAttr        = "table"
+	distributedTableColumnAttr       = "distribution_column"
+	distributedTableReferenceAttr    = "reference"
+	distributedTableShardCountAttr   = "shard_count"
+	distributedTableColocateWithAttr = "colocate_with"
+)
+
+// distributedTableQuery selects the type, distribution column and shard count
+// of the Citus table $1, which citus_tables lists as <none> and 1 for reference
+// tables.
+const distributedTableQuery = `SELECT t.citus_table_type, t.distribution_column, t.shard_count ` +
+	`FROM citus_tables t WHERE t.table_name = pg_catalog.to_regclass($1)`
+
+func resourcePostgreSQLDistributedTable() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourcePostgreSQLDistributedTableCreate,
+		ReadContext:   resourcePostgreSQLDistributedTableRead,
+		UpdateContext: resourcePostgreSQLDistributedTableUpdate,
+		DeleteContext: resourcePostgreSQLDistributedTableDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePostgreSQLDistributedTableImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			distributedTableDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The database of the table, instead of the provider's database",
+			},
+			distributedTableSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "public",
+				Description: "The schema of the table",
+			},
+			distributedTableTableAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The table distributed among the workers",
+			},
+			distributedTableColumnAttr: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "The column the rows of the table are distributed by",
+				ConflictsWith: []string{distributedTableReferenceAttr},
+			},
+			distributedTableReferenceAttr: {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				ForceNew:      true,
+				Description:   "Whether the table is a reference table, replicated to every worker, instead of distributed",
+				ConflictsWith: []string{distributedTableColumnAttr},
+			},
+			distributedTableShardCountAttr: {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Computed:      true,
+				Description:   "The number of shards of the table, instead of citus.shard_count",
+				ConflictsWith: []string{distributedTableReferenceAttr},
+			},
+			distributedTableColocateWithAttr: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "The table the shards of the table are colocated with, default to colocate it with the tables of the same distribution column type and shard count, or none",
+				ConflictsWith: []string{distributedTableReferenceAttr},
+			},
+		},
+	}
+}
+
+// distributedTableRelation returns the qualified name of the table, as the
+// functions of Citus take it.
+func distributedTableRelation(d *schema.ResourceData) string {
+	return pq.QuoteIdentifier(d.Get(distributedTableSchemaAttr).(string)) + "." + pq.QuoteIdentifier(d.Get(distributedTableTableAttr).(string))
+}
+
+// distributedTableArgs returns the named arguments of create_distributed_table
+// and alter_distributed_table for the set, or changed when altering, settings
+// of the table, following the table itself, and their values.
+func distributedTableArgs(d *schema.ResourceData, alter bool) (string, []interface{}) {
+	names := []string{"$1::REGCLASS"}
+	args := []interface{}{distributedTableRelation(d)}
+	add := func(name, cast string, value interface{}) {
+		args = append(args, value)
+		names = append(names, fmt.Sprintf("%s => $%d::%s", name, len(args), cast))
+	}
+
+	if !alter || d.HasChange(distributedTableColumnAttr) {
+		add("distribution_column", "TEXT", d.Get(distributedTableColumnAttr).(string))
+	}
+	if v, ok := d.GetOk(distributedTableShardCountAttr); ok && (!alter || d.HasChange(distributedTableShardCountAttr)) {
+		add("shard_count", "INT", v.(int))
+	}
+	if v, ok := d.GetOk(distributedTableColocateWithAttr); ok && (!alter || d.HasChange(distributedTableColocateWithAttr)) {
+		add("colocate_with", "TEXT", v.(string))
+	}
+
+	return strings.Join(names, ", "), args
+}
+
+func resourcePostgreSQLDistributedTableCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	database := d.Get(distributedTableDatabaseAttr).(string)
+
+	unlock, err := c.lockCatalog("table", c.databaseName(database))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
+
+	db, err := c.DBFor(database, "")
+	if err != nil {
+		return diagnostics(err)
+	}
+
+	relation := distributedTableRelation(d)
+	var query string
+	var args []interface{}
+	switch _, distributed := d.GetOk(distributedTableColumnAttr); {
+	case d.Get(distributedTableReferenceAttr).(bool):
+		query, args = "SELECT create_reference_table($1::REGCLASS)", []interface{}{relation}
+	case distributed:
+		names, namedArgs := distributedTableArgs(d, false)
+		query, args = fmt.Sprintf("SELECT create_distributed_table(%s)", names), namedArgs
+	default:
+		return diagnostics(fmt.Errorf("Error distributing table %s: either %s or %s is required", relation, distributedTableColumnAttr, distributedTableReferenceAttr))
+	}
+	if _, err := db.Exec(query, args...); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error distributing table %s: {{err}}", relation), err))
+	}
+
+	d.SetId(importID(database, d.Get(distributedTableSchemaAttr).(string), d.Get(distributedTableTableAttr).(string)))
+
+	return diagnostics(resourcePostgreSQLDistributedTableReadImpl(d, meta))
+}
+
+// resourcePostgreSQLDistributedTableUpdate changes the distribution column,
+// shard count or colocation of the table with alter_distributed_table, which
+// copies the rows into a new table.
+func resourcePostgreSQLDistributedTableUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	database := d.Get(distributedTableDatabaseAttr).(string)
+
+	unlock, err := c.lockCatalog("table", c.databaseName(database))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
+
+	db, err := c.DBFor(database, "")
+	if err != nil {
+		return diagnostics(err)
+	}
+
+	names, args := distributedTableArgs(d, true)
+	if len(args) > 1 {
+		if _, err := db.Exec(fmt.Sprintf("SELECT alter_distributed_table(%s)", names), args...); err != nil {
+			return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error altering distributed table %s: {{err}}", distributedTableRelation(d)), err))
+		}
+	}
+
+	return diagnostics(resourcePostgreSQLDistributedTableReadImpl(d, meta))
+}
+
+// resourcePostgreSQLDistributedTableImport sets the table from its ID,
+// database/schema/table, with an empty database for the provider's.
+func resourcePostgreSQLDistributedTableImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts, err := splitImportID(d.Id(), 3, 3, "database/schema/table")
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set(distributedTableDatabaseAttr, parts[0])
+	d.Set(distributedTableSchemaAttr, parts[1])
+	d.Set(distributedTableTableAttr, parts[2])
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourcePostgreSQLDistributedTableRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	unlock := c.rlockCatalog("table", c.databaseName(d.Get(distributedTableDatabaseAttr).(string)))
+	defer unlock()
+
+	return diagnostics(resourcePostgreSQLDistributedTableReadImpl(d, meta))
+}
+
+// resourcePostgreSQLDistributedTableReadImpl reads the distribution of the
+// table.  The table it is colocated with is not read: Citus only records the
+// group of colocated tables.
+func resourcePostgreSQLDistributedTableReadImpl(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	relation := distributedTableRelation(d)
+
+	db, err := c.DBFor(d.Get(distributedTableDatabaseAttr).(string), "")
+	if err != nil {
+		return err
+	}
+
+	var tableType, column string
+	var shardCount int
+	err = db.QueryRow(distributedTableQuery, relation).Scan(&tableType, &column, &shardCount)
+	switch {
+	case err == sql.ErrNoRows:
+		logEvent("WARN", "Citus distributed table not found", logFields{"table": relation})
+		d.SetId("")
+		return nil
+	case err != nil:
+		return errwrap.Wrapf(fmt.Sprintf("Error reading distributed table %s: {{err}}", relation), err)
+	}
+
+	if tableType == "reference" {
+		d.Set(distributedTableReferenceAttr, true)
+		d.Set(distributedTableColumnAttr, "")
+		d.Set(distributedTableShardCountAttr, 0)
+		return nil
+	}
+
+	d.Set(distributedTableReferenceAttr, false)
+	d.Set(distributedTableColumnAttr, column)
+	d.Set(distributedTableShardCountAttr, shardCount)
+
+	return nil
+}
+
+// resourcePostgreSQLDistributedTableDelete turns the table back into a local
+// table of the coordinator, copying its rows back from the workers, unless it
+// was dropped.
+func resourcePostgreSQLDistributedTableDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	database := d.Get(distributedTableDatabaseAttr).(string)
+
+	unlock, err := c.lockCatalog("table", c.databaseName(database))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
+
+	db, err := c.DBFor(database, "")
+	if err != nil {
+		return diagnostics(err)
+	}
+
+	relation := distributedTableRelation(d)
+	if _, err := db.Exec("SELECT undistribute_table(t) FROM pg_catalog.to_regclass($1) t WHERE t IS NOT NULL", relation); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error undistributing table %s: {{err}}", relation), err))
+	}
+
+	d.SetId("")
+
+	return nil
+}
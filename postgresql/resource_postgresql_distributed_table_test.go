@@ -0,0 +1,101 @@
+package postgresql
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDistributedTableArgs(t *testing.T) {
+	tests := []struct {
+		config map[string]interface{}
+		names  string
+		args   []interface{}
+	}{
+		{
+			config: map[string]interface{}{"distribution_column": "tenant_id"},
+			names:  "$1::REGCLASS, distribution_column => $2::TEXT",
+			args:   []interface{}{`"public"."events"`, "tenant_id"},
+		},
+		{
+			config: map[string]interface{}{
+				"schema":              "app",
+				"distribution_column": "tenant_id",
+				"shard_count":         64,
+				"colocate_with":       "none",
+			},
+			names: "$1::REGCLASS, distribution_column => $2::TEXT, shard_count => $3::INT, colocate_with => $4::TEXT",
+			args:  []interface{}{`"app"."events"`, "tenant_id", 64, "none"},
+		},
+	}
+
+	for _, test := range tests {
+		test.config["table"] = "events"
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLDistributedTable().Schema, test.config)
+		names, args := distributedTableArgs(d, false)
+		if names != test.names || !reflect.DeepEqual(args, test.args) {
+			t.Errorf("%v: expected %q %v, got %q %v", test.config, test.names, test.args, names, args)
+		}
+	}
+}
+
+func TestAccPostgresqlDistributedTable_Basic(t *testing.T) {
+	// Citus is preloaded by the server, so only servers set up for it can run
+	// the test.
+	if os.Getenv("TF_ACC_CITUS") == "" {
+		t.Skip("TF_ACC_CITUS must be set for Citus acceptance tests")
+	}
+
+	defer testAccPostgresqlExec(t,
+		"DROP TABLE IF EXISTS distributed_events",
+		"DROP TABLE IF EXISTS distributed_tenants",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE EXTENSION IF NOT EXISTS citus",
+				"CREATE TABLE distributed_events (tenant_id BIGINT NOT NULL, id BIGINT NOT NULL)",
+				"CREATE TABLE distributed_tenants (id BIGINT PRIMARY KEY)",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDistributedTableConfig(8),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_distributed_table.events", "distribution_column", "tenant_id"),
+					resource.TestCheckResourceAttr("postgresql_distributed_table.events", "shard_count", "8"),
+					resource.TestCheckResourceAttr("postgresql_distributed_table.tenants", "reference", "true"),
+				),
+			},
+			{
+				Config: testAccPostgresqlDistributedTableConfig(16),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_distributed_table.events", "shard_count", "16"),
+				),
+			},
+		},
+	})
+}
+
+func testAccPostgresqlDistributedTableConfig(shardCount int) string {
+	return fmt.Sprintf(`
+resource "postgresql_distributed_table" "events" {
+  table               = "distributed_events"
+  distribution_column = "tenant_id"
+  shard_count         = %d
+  colocate_with       = "none"
+}
+
+resource "postgresql_distributed_table" "tenants" {
+  table     = "distributed_tenants"
+  reference = true
+}
+`, shardCount)
+}
@@ -2,31 +2,34 @@ package postgresql
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
 
 	"github.com/hashicorp/errwrap"
-	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/lib/pq"
 )
 
 const (
-	extNameAttr    = "name"
-	extSchemaAttr  = "schema"
-	extVersionAttr = "version"
+	extNameAttr        = "name"
+	extDatabaseAttr    = "database"
+	extSessionRoleAttr = "session_role"
+	extSchemaAttr      = "schema"
+	extVersionAttr     = "version"
 )
 
 func resourcePostgreSQLExtension() *schema.Resource {
 	return &schema.Resource{
-		Create: resourcePostgreSQLExtensionCreate,
-		Read:   resourcePostgreSQLExtensionRead,
-		Update: resourcePostgreSQLExtensionUpdate,
-		Delete: resourcePostgreSQLExtensionDelete,
-		Exists: resourcePostgreSQLExtensionExists,
+		CreateContext: resourcePostgreSQLExtensionCreate,
+		ReadContext:   resourcePostgreSQLExtensionRead,
+		UpdateContext: resourcePostgreSQLExtensionUpdate,
+		DeleteContext: resourcePostgreSQLExtensionDelete,
+		Exists:        resourcePostgreSQLExtensionExists,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importDatabaseObject(extDatabaseAttr, extNameAttr),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -35,6 +38,18 @@ func resourcePostgreSQLExtension() *schema.Resource {
 				Required: true,
 				ForceNew: true,
 			},
+			extDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The database to create the extension in, instead of the provider's database",
+			},
+			extSessionRoleAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The role to act as to manage the extension, instead of the provider's session role",
+			},
 			extSchemaAttr: {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -51,12 +66,23 @@ func resourcePostgreSQLExtension() *schema.Resource {
 	}
 }
 
-func resourcePostgreSQLExtensionCreate(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLExtensionCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.Lock()
-	defer c.catalogLock.Unlock()
+	unlock, err := c.lockCatalog("extension", c.databaseName(d.Get(extDatabaseAttr).(string)))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
+
+	db, err := c.DBFor(d.Get(extDatabaseAttr).(string), d.Get(extSessionRoleAttr).(string))
+	if err != nil {
+		return diagnostics(err)
+	}
 
 	extName := d.Get(extNameAttr).(string)
+	if err := checkAuroraExtension(c, db, extName, d.Get(extSchemaAttr).(string)); err != nil {
+		return diagnostics(err)
+	}
 
 	b := bytes.NewBufferString("CREATE EXTENSION ")
 	fmt.Fprint(b, pq.QuoteIdentifier(extName))
@@ -70,23 +96,28 @@ func resourcePostgreSQLExtensionCreate(d *schema.ResourceData, meta interface{})
 	}
 
 	sql := b.String()
-	if _, err := c.DB().Exec(sql); err != nil {
-		return errwrap.Wrapf("Error creating extension: {{err}}", err)
+	if _, err := db.Exec(sql); err != nil {
+		return diagnostics(errwrap.Wrapf("Error creating extension: {{err}}", err))
 	}
 
-	d.SetId(extName)
+	d.SetId(c.databaseObjectID(d.Get(extDatabaseAttr).(string), extName))
 
-	return resourcePostgreSQLExtensionReadImpl(d, meta)
+	return diagnostics(resourcePostgreSQLExtensionReadImpl(d, meta))
 }
 
 func resourcePostgreSQLExtensionExists(d *schema.ResourceData, meta interface{}) (bool, error) {
 	c := meta.(*Client)
-	c.catalogLock.Lock()
-	defer c.catalogLock.Unlock()
+	unlock := c.rlockCatalog("extension", c.databaseName(d.Get(extDatabaseAttr).(string)))
+	defer unlock()
+
+	db, err := c.DBFor(d.Get(extDatabaseAttr).(string), d.Get(extSessionRoleAttr).(string))
+	if err != nil {
+		return false, err
+	}
 
 	var extensionName string
 	query := "SELECT extname FROM pg_catalog.pg_extension WHERE extname = $1"
-	err := c.DB().QueryRow(query, d.Id()).Scan(&extensionName)
+	err = db.QueryRow(query, databaseObjectName(d.Id())).Scan(&extensionName)
 	switch {
 	case err == sql.ErrNoRows:
 		return false, nil
@@ -97,26 +128,30 @@ func resourcePostgreSQLExtensionExists(d *schema.ResourceData, meta interface{})
 	return true, nil
 }
 
-func resourcePostgreSQLExtensionRead(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLExtensionRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.RLock()
-	defer c.catalogLock.RUnlock()
+	unlock := c.rlockCatalog("extension", c.databaseName(d.Get(extDatabaseAttr).(string)))
+	defer unlock()
 
-	return resourcePostgreSQLExtensionReadImpl(d, meta)
+	return diagnostics(resourcePostgreSQLExtensionReadImpl(d, meta))
 }
 
 func resourcePostgreSQLExtensionReadImpl(d *schema.ResourceData, meta interface{}) error {
 	c := meta.(*Client)
+	db, err := c.DBFor(d.Get(extDatabaseAttr).(string), d.Get(extSessionRoleAttr).(string))
+	if err != nil {
+		return err
+	}
 
-	extID := d.Id()
+	extID := databaseObjectName(d.Id())
 	var extName, extSchema, extVersion string
 	query := `SELECT e.extname, n.nspname, e.extversion ` +
 		`FROM pg_catalog.pg_extension e, pg_catalog.pg_namespace n ` +
 		`WHERE n.oid = e.extnamespace AND e.extname = $1`
-	err := c.DB().QueryRow(query, extID).Scan(&extName, &extSchema, &extVersion)
+	err = db.QueryRow(query, extID).Scan(&extName, &extSchema, &extVersion)
 	switch {
 	case err == sql.ErrNoRows:
-		log.Printf("[WARN] PostgreSQL extension (%s) not found", d.Id())
+		logEvent("WARN", "PostgreSQL extension not found", logFields{"extension": d.Id()})
 		d.SetId("")
 		return nil
 	case err != nil:
@@ -124,23 +159,32 @@ func resourcePostgreSQLExtensionReadImpl(d *schema.ResourceData, meta interface{
 	}
 
 	d.Set(extNameAttr, extName)
+	d.Set(extDatabaseAttr, c.databaseName(d.Get(extDatabaseAttr).(string)))
 	d.Set(extSchemaAttr, extSchema)
 	d.Set(extVersionAttr, extVersion)
-	d.SetId(extName)
+	d.SetId(c.databaseObjectID(d.Get(extDatabaseAttr).(string), extName))
 
 	return nil
 }
 
-func resourcePostgreSQLExtensionDelete(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLExtensionDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.Lock()
-	defer c.catalogLock.Unlock()
+	unlock, err := c.lockCatalog("extension", c.databaseName(d.Get(extDatabaseAttr).(string)))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
 
-	extID := d.Id()
+	db, err := c.DBFor(d.Get(extDatabaseAttr).(string), d.Get(extSessionRoleAttr).(string))
+	if err != nil {
+		return diagnostics(err)
+	}
+
+	extID := databaseObjectName(d.Id())
 
 	sql := fmt.Sprintf("DROP EXTENSION %s", pq.QuoteIdentifier(extID))
-	if _, err := c.DB().Exec(sql); err != nil {
-		return errwrap.Wrapf("Error deleting extension: {{err}}", err)
+	if _, err := db.Exec(sql); err != nil {
+		return diagnostics(errwrap.Wrapf("Error deleting extension: {{err}}", err))
 	}
 
 	d.SetId("")
@@ -148,22 +192,36 @@ func resourcePostgreSQLExtensionDelete(d *schema.ResourceData, meta interface{})
 	return nil
 }
 
-func resourcePostgreSQLExtensionUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLExtensionUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.Lock()
-	defer c.catalogLock.Unlock()
+	unlock, err := c.lockCatalog("extension", c.databaseName(d.Get(extDatabaseAttr).(string)))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
+
+	db, err := c.DBFor(d.Get(extDatabaseAttr).(string), d.Get(extSessionRoleAttr).(string))
+	if err != nil {
+		return diagnostics(err)
+	}
 
 	// Can't rename a schema
 
-	if err := setExtSchema(c.DB(), d); err != nil {
-		return err
+	if d.HasChange(extSchemaAttr) {
+		if err := checkAuroraExtension(c, db, databaseObjectName(d.Id()), d.Get(extSchemaAttr).(string)); err != nil {
+			return diagnostics(err)
+		}
 	}
 
-	if err := setExtVersion(c.DB(), d); err != nil {
-		return err
+	if err := setExtSchema(db, d); err != nil {
+		return diagnostics(err)
+	}
+
+	if err := setExtVersion(db, d); err != nil {
+		return diagnostics(err)
 	}
 
-	return resourcePostgreSQLExtensionReadImpl(d, meta)
+	return diagnostics(resourcePostgreSQLExtensionReadImpl(d, meta))
 }
 
 func setExtSchema(db *sql.DB, d *schema.ResourceData) error {
@@ -171,7 +229,7 @@ func setExtSchema(db *sql.DB, d *schema.ResourceData) error {
 		return nil
 	}
 
-	extID := d.Id()
+	extID := databaseObjectName(d.Id())
 	_, nraw := d.GetChange(extSchemaAttr)
 	n := nraw.(string)
 	if n == "" {
@@ -192,7 +250,7 @@ func setExtVersion(db *sql.DB, d *schema.ResourceData) error {
 		return nil
 	}
 
-	extID := d.Id()
+	extID := databaseObjectName(d.Id())
 
 	b := bytes.NewBufferString("ALTER EXTENSION ")
 	fmt.Fprintf(b, "%s UPDATE", pq.QuoteIdentifier(extID))
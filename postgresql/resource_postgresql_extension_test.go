@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform/helper/resource"
-	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccPostgresqlExtension_Basic(t *testing.T) {
@@ -42,7 +42,7 @@ func testAccCheckPostgresqlExtensionDestroy(s *terraform.State) error {
 			continue
 		}
 
-		exists, err := checkExtensionExists(client, rs.Primary.ID)
+		exists, err := checkExtensionExists(client, rs.Primary.Attributes[extDatabaseAttr], rs.Primary.Attributes[extNameAttr])
 
 		if err != nil {
 			return fmt.Errorf("Error checking extension %s", err)
@@ -68,7 +68,7 @@ func testAccCheckPostgresqlExtensionExists(n string) resource.TestCheckFunc {
 		}
 
 		client := testAccProvider.Meta().(*Client)
-		exists, err := checkExtensionExists(client, rs.Primary.ID)
+		exists, err := checkExtensionExists(client, rs.Primary.Attributes[extDatabaseAttr], rs.Primary.Attributes[extNameAttr])
 
 		if err != nil {
 			return fmt.Errorf("Error checking extension %s", err)
@@ -118,9 +118,43 @@ func TestAccPostgresqlExtension_SchemaRename(t *testing.T) {
 	})
 }
 
-func checkExtensionExists(client *Client, extensionName string) (bool, error) {
+func TestAccPostgresqlExtension_Database(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlExtensionDatabaseConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists("postgresql_extension.ext_other_db"),
+					resource.TestCheckResourceAttr(
+						"postgresql_extension.ext_other_db", "database", "ext_other_db"),
+					func(s *terraform.State) error {
+						client := testAccProvider.Meta().(*Client)
+						exists, err := checkExtensionExists(client, "", "pg_trgm")
+						if err != nil {
+							return err
+						}
+						if exists {
+							return fmt.Errorf("Extension was created in the provider's database")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func checkExtensionExists(client *Client, database, extensionName string) (bool, error) {
+	db, err := client.DBFor(database, "")
+	if err != nil {
+		return false, err
+	}
+
 	var _rez bool
-	err := client.DB().QueryRow("SELECT TRUE from pg_catalog.pg_extension d WHERE extname=$1", extensionName).Scan(&_rez)
+	err = db.QueryRow("SELECT TRUE from pg_catalog.pg_extension d WHERE extname=$1", extensionName).Scan(&_rez)
 	switch {
 	case err == sql.ErrNoRows:
 		return false, nil
@@ -144,7 +178,7 @@ resource "postgresql_schema" "ext1foo" {
 
 resource "postgresql_extension" "ext1trgm" {
   name = "pg_trgm"
-  schema = "${postgresql_schema.ext1foo.name}"
+  schema = postgresql_schema.ext1foo.name
 }
 `
 
@@ -155,6 +189,17 @@ resource "postgresql_schema" "ext1foo" {
 
 resource "postgresql_extension" "ext1trgm" {
   name = "pg_trgm"
-  schema = "${postgresql_schema.ext1foo.name}"
+  schema = postgresql_schema.ext1foo.name
+}
+`
+
+var testAccPostgresqlExtensionDatabaseConfig = `
+resource "postgresql_database" "other" {
+  name = "ext_other_db"
+}
+
+resource "postgresql_extension" "ext_other_db" {
+  name     = "pg_trgm"
+  database = postgresql_database.other.name
 }
 `
@@ -0,0 +1,822 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	grantRoleAttr       = "role"
+	grantDatabaseAttr   = "database"
+	grantSchemaAttr     = "schema"
+	grantObjectTypeAttr = "object_type"
+	grantObjectsAttr    = "objects"
+	grantColumnsAttr    = "columns"
+	grantPrivilegesAttr = "privileges"
+	grantWithOptionAttr = "with_grant_option"
+	grantExclusiveAttr  = "exclusive"
+)
+
+// grantObjectType describes how privileges on a type of objects are granted
+// and read.
+type grantObjectType struct {
+	// privileges are the privileges which can be granted on the objects.
+	privileges []string
+
+	// keyword is the keyword of the objects in GRANT, e.g. TABLE, and all
+	// the one granting on all the objects in a schema, e.g. ALL TABLES, if
+	// any: without it, the objects must be given.
+	keyword string
+	all     string
+
+	// schemaless is true for the objects which are not in a schema, e.g.
+	// databases, schemas and foreign servers.
+	schemaless bool
+
+	// oids is true for the objects given by OID, e.g. large objects.
+	oids bool
+
+	// signatures is true for the objects given with the types of their
+	// arguments, e.g. "add(integer, integer)", which are passed to aclQuery
+	// schema-qualified.
+	signatures bool
+
+	// aclQuery returns the query selecting the names and the privileges
+	// granted to the role of OID $2 of the objects in the schema $1,
+	// limited to the objects $3 if not NULL.
+	aclQuery func(c *Client) string
+}
+
+// columnPrivileges are the privileges which can be granted on columns.
+var columnPrivileges = []string{"INSERT", "REFERENCES", "SELECT", "UPDATE"}
+
+var grantObjectTypes = map[string]grantObjectType{
+	"database": {
+		privileges: []string{"CONNECT", "CREATE", "TEMPORARY"},
+		keyword:    "DATABASE",
+		schemaless: true,
+		// Databases without an ACL have the default privileges, which grant
+		// CONNECT and TEMPORARY to PUBLIC.  $1, the schema, is empty.
+		aclQuery: func(*Client) string {
+			return `SELECT d.datname, ` + aclPrivileges("COALESCE(d.datacl, pg_catalog.acldefault('d', d.datdba))", "$2") + ` ` +
+				`FROM pg_catalog.pg_database d WHERE $1::TEXT = '' AND d.datname = ANY($3)`
+		},
+	},
+	"schema": {
+		privileges: []string{"CREATE", "USAGE"},
+		keyword:    "SCHEMA",
+		schemaless: true,
+		aclQuery: func(*Client) string {
+			return `SELECT n.nspname, ` + aclPrivileges("n.nspacl", "$2") + ` ` +
+				`FROM pg_catalog.pg_namespace n WHERE $1::TEXT = '' AND n.nspname = ANY($3)`
+		},
+	},
+	"table": {
+		privileges: []string{"DELETE", "INSERT", "REFERENCES", "SELECT", "TRIGGER", "TRUNCATE", "UPDATE"},
+		keyword:    "TABLE",
+		all:        "ALL TABLES",
+		aclQuery: func(*Client) string {
+			return relationACLQuery("'r', 'v', 'm', 'f', 'p'")
+		},
+	},
+	"sequence": {
+		privileges: []string{"SELECT", "UPDATE", "USAGE"},
+		keyword:    "SEQUENCE",
+		all:        "ALL SEQUENCES",
+		aclQuery: func(*Client) string {
+			return relationACLQuery("'S'")
+		},
+	},
+	"function": {
+		privileges: []string{"EXECUTE"},
+		keyword:    "FUNCTION",
+		all:        "ALL FUNCTIONS",
+		signatures: true,
+		aclQuery: func(c *Client) string {
+			// ALL FUNCTIONS covers aggregate and window functions, but
+			// not procedures.
+			if c.featureSupported(featureProKind) {
+				return routineACLQuery("p.prokind <> 'p'")
+			}
+			return routineACLQuery("TRUE")
+		},
+	},
+	"procedure": {
+		privileges: []string{"EXECUTE"},
+		keyword:    "PROCEDURE",
+		all:        "ALL PROCEDURES",
+		signatures: true,
+		aclQuery: func(*Client) string {
+			return routineACLQuery("p.prokind = 'p'")
+		},
+	},
+	"routine": {
+		privileges: []string{"EXECUTE"},
+		keyword:    "ROUTINE",
+		all:        "ALL ROUTINES",
+		signatures: true,
+		aclQuery: func(*Client) string {
+			return routineACLQuery("TRUE")
+		},
+	},
+	"foreign_data_wrapper": {
+		privileges: []string{"USAGE"},
+		keyword:    "FOREIGN DATA WRAPPER",
+		schemaless: true,
+		aclQuery: func(*Client) string {
+			return `SELECT w.fdwname, ` + aclPrivileges("w.fdwacl", "$2") + ` ` +
+				`FROM pg_catalog.pg_foreign_data_wrapper w WHERE $1::TEXT = '' AND w.fdwname = ANY($3)`
+		},
+	},
+	"foreign_server": {
+		privileges: []string{"USAGE"},
+		keyword:    "FOREIGN SERVER",
+		schemaless: true,
+		aclQuery: func(*Client) string {
+			return `SELECT s.srvname, ` + aclPrivileges("s.srvacl", "$2") + ` ` +
+				`FROM pg_catalog.pg_foreign_server s WHERE $1::TEXT = '' AND s.srvname = ANY($3)`
+		},
+	},
+	"large_object": {
+		privileges: []string{"SELECT", "UPDATE"},
+		keyword:    "LARGE OBJECT",
+		schemaless: true,
+		oids:       true,
+		aclQuery: func(*Client) string {
+			return `SELECT l.oid::TEXT, ` + aclPrivileges("l.lomacl", "$2") + ` ` +
+				`FROM pg_catalog.pg_largeobject_metadata l WHERE $1::TEXT = '' AND l.oid = ANY($3::TEXT[]::OID[])`
+		},
+	},
+	"type": {
+		privileges: []string{"USAGE"},
+		keyword:    "TYPE",
+		aclQuery: func(*Client) string {
+			return typeACLQuery("t.typtype <> 'd'")
+		},
+	},
+	"domain": {
+		privileges: []string{"USAGE"},
+		keyword:    "DOMAIN",
+		aclQuery: func(*Client) string {
+			return typeACLQuery("t.typtype = 'd'")
+		},
+	},
+}
+
+// typeACLQuery returns the aclQuery of the types matching the condition on
+// pg_type t.  Types without an ACL have the default privileges, which grant
+// USAGE to PUBLIC.
+func typeACLQuery(kind string) string {
+	return `SELECT t.typname, ` + aclPrivileges("COALESCE(t.typacl, pg_catalog.acldefault('T', t.typowner))", "$2") + ` ` +
+		`FROM pg_catalog.pg_type t JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace ` +
+		`WHERE n.nspname = $1 AND ` + kind + ` AND t.typname = ANY($3)`
+}
+
+// relationACLQuery returns the aclQuery of the relations of the kinds.
+func relationACLQuery(kinds string) string {
+	return `SELECT c.relname, ` + aclPrivileges("c.relacl", "$2") + ` ` +
+		`FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+		`WHERE n.nspname = $1 AND c.relkind IN (` + kinds + `) AND ($3::TEXT[] IS NULL OR c.relname = ANY($3))`
+}
+
+// routineACLQuery returns the aclQuery of the functions or procedures matching
+// the condition on pg_proc p.  Routines without an ACL have the default
+// privileges, which grant EXECUTE to PUBLIC.
+func routineACLQuery(kind string) string {
+	return `SELECT p.oid::pg_catalog.regprocedure::TEXT, ` + aclPrivileges("COALESCE(p.proacl, pg_catalog.acldefault('f', p.proowner))", "$2") + ` ` +
+		`FROM pg_catalog.pg_proc p JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace ` +
+		`WHERE n.nspname = $1 AND ` + kind + ` AND ($3::TEXT[] IS NULL OR ` +
+		`p.oid = ANY(ARRAY(SELECT pg_catalog.to_regprocedure(o) FROM pg_catalog.unnest($3::TEXT[]) AS o)))`
+}
+
+// columnACLQuery selects the names and the privileges granted to the role of
+// OID $3 of the columns $4 of the table $2 in the schema $1.
+var columnACLQuery = `SELECT a.attname, ` + aclPrivileges("a.attacl", "$3") + ` ` +
+	`FROM pg_catalog.pg_attribute a JOIN pg_catalog.pg_class c ON c.oid = a.attrelid ` +
+	`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+	`WHERE n.nspname = $1 AND c.relname = $2 AND a.attname = ANY($4) AND a.attnum > 0 AND NOT a.attisdropped`
+
+// aclPrivileges returns the expression of the array of the privileges granted
+// to the role of OID grantee in the ACL, by any grantor, followed by a * when
+// they are granted with grant option, as in aclitems.  As in roleGrantsQuery,
+// aclexplode() is called in the target list so that it works on servers older
+// than 9.3.
+func aclPrivileges(acl, grantee string) string {
+	return fmt.Sprintf(`ARRAY(SELECT DISTINCT (e.a).privilege_type || CASE WHEN (e.a).is_grantable THEN '*' ELSE '' END `+
+		`FROM (SELECT pg_catalog.aclexplode(%s) AS a) AS e WHERE (e.a).grantee = %s)`, acl, grantee)
+}
+
+// grantObjectTypeFeatures are the features granting privileges on the object
+// types requires, if any.
+var grantObjectTypeFeatures = map[string]featureName{
+	"procedure":    featureProKind,
+	"routine":      featureProKind,
+	"large_object": featureLargeObjectPrivileges,
+	"type":         featureTypePrivileges,
+	"domain":       featureTypePrivileges,
+}
+
+// checkGrantFeatures returns an error if the server does not support granting
+// privileges on the object type of the grant.
+func checkGrantFeatures(c *Client, d attrChanges) error {
+	return checkValueFeatures(c, d, grantObjectTypeAttr, grantObjectTypeFeatures)
+}
+
+func resourcePostgreSQLGrant() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourcePostgreSQLGrantCreate,
+		ReadContext:   resourcePostgreSQLGrantRead,
+		UpdateContext: resourcePostgreSQLGrantUpdate,
+		DeleteContext: resourcePostgreSQLGrantDelete,
+		CustomizeDiff: planFeatures(checkGrantFeatures),
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePostgreSQLGrantImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			grantRoleAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The role the privileges are granted to, or PUBLIC",
+			},
+			grantDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The database of the objects, instead of the provider's database",
+			},
+			grantSchemaAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Description:  "The schema of the objects",
+				ValidateFunc: validateGrantSchema,
+			},
+			grantObjectTypeAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The type of the objects the privileges are granted on: database, schema, table, sequence, function, procedure, routine, type, domain, foreign_data_wrapper, foreign_server or large_object",
+				ValidateFunc: validateGrantObjectType,
+			},
+			grantObjectsAttr: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The objects the privileges are granted on, instead of all the objects of the type in the schema.  Routines are given with the types of their arguments, e.g. add(integer, integer)",
+			},
+			grantColumnsAttr: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The columns of the table the privileges are granted on, instead of the whole table",
+			},
+			grantPrivilegesAttr: {
+				Type:        schema.TypeSet,
+				Required:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The privileges granted",
+			},
+			grantWithOptionAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the role can grant the privileges to others",
+			},
+			grantExclusiveAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the privileges the role has on the objects but the granted ones are revoked",
+			},
+		},
+	}
+}
+
+func validateGrantObjectType(v interface{}, key string) (warnings []string, errors []error) {
+	if _, ok := grantObjectTypes[v.(string)]; !ok {
+		errors = append(errors, fmt.Errorf("%s must be one of %s, got %q", key, strings.Join(grantObjectTypeNames(), ", "), v.(string)))
+	}
+	return
+}
+
+// grantObjectTypeNames returns the object types of postgresql_grant.
+func grantObjectTypeNames() []string {
+	names := make([]string, 0, len(grantObjectTypes))
+	for name := range grantObjectTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// grant is the grant of privileges a postgresql_grant manages.
+type grant struct {
+	role       string
+	database   string
+	schema     string
+	objectType string
+	objects    []string
+	columns    []string
+	privileges []string
+	withOption bool
+	exclusive  bool
+}
+
+// newGrant returns the grant configured in d, checking that it can be made.
+func newGrant(d *schema.ResourceData) (*grant, error) {
+	g := &grant{
+		role:       d.Get(grantRoleAttr).(string),
+		database:   d.Get(grantDatabaseAttr).(string),
+		schema:     d.Get(grantSchemaAttr).(string),
+		objectType: d.Get(grantObjectTypeAttr).(string),
+		objects:    setToSortedStrings(d.Get(grantObjectsAttr).(*schema.Set)),
+		columns:    setToSortedStrings(d.Get(grantColumnsAttr).(*schema.Set)),
+		privileges: setToSortedStrings(d.Get(grantPrivilegesAttr).(*schema.Set)),
+		withOption: d.Get(grantWithOptionAttr).(bool),
+		exclusive:  d.Get(grantExclusiveAttr).(bool),
+	}
+	objectType := grantObjectTypes[g.objectType]
+
+	switch {
+	case objectType.schemaless && g.schema != "":
+		return nil, fmt.Errorf("%s can not be set to grant privileges on %s objects", grantSchemaAttr, g.objectType)
+	case !objectType.schemaless && g.schema == "":
+		return nil, fmt.Errorf("%s is required to grant privileges on %s objects", grantSchemaAttr, g.objectType)
+	case objectType.all == "" && len(g.objects) == 0:
+		return nil, fmt.Errorf("%s is required to grant privileges on %s objects", grantObjectsAttr, g.objectType)
+	}
+
+	if objectType.oids {
+		for _, object := range g.objects {
+			if _, err := strconv.ParseUint(object, 10, 32); err != nil {
+				return nil, fmt.Errorf("%s object %q must be an OID", g.objectType, object)
+			}
+		}
+	}
+	if objectType.signatures {
+		for _, object := range g.objects {
+			if !strings.HasSuffix(object, ")") || !strings.Contains(object, "(") {
+				return nil, fmt.Errorf("%s object %q must be given with the types of its arguments, e.g. %s()", g.objectType, object, object)
+			}
+		}
+	}
+
+	if g.withOption && g.grantee() == "PUBLIC" {
+		return nil, fmt.Errorf("privileges can not be granted to PUBLIC with grant option")
+	}
+
+	allowed := objectType.privileges
+	if len(g.columns) > 0 {
+		if g.objectType != "table" || len(g.objects) != 1 {
+			return nil, fmt.Errorf("%s requires %s to be table and %s to be a single table", grantColumnsAttr, grantObjectTypeAttr, grantObjectsAttr)
+		}
+		allowed = columnPrivileges
+	}
+	for _, privilege := range g.privileges {
+		if !stringInSlice(privilege, allowed) {
+			return nil, fmt.Errorf("invalid privilege %q, the privileges which can be granted are %s", privilege, strings.Join(allowed, ", "))
+		}
+	}
+
+	return g, nil
+}
+
+// grantImportFormat is the format of the IDs of grants.
+const grantImportFormat = "role/database/schema/object_type[/objects[/columns]]"
+
+// id returns the ID of the resource managing the grant, in grantImportFormat.
+func (g *grant) id() string {
+	parts := []string{g.role, g.database, g.schema, g.objectType}
+	if len(g.objects) > 0 {
+		parts = append(parts, strings.Join(g.objects, ","))
+	}
+	if len(g.columns) > 0 {
+		parts = append(parts, strings.Join(g.columns, ","))
+	}
+
+	return importID(parts...)
+}
+
+// splitGrantObjects splits a comma-separated list of objects, leaving the
+// commas between the types of the arguments of routines.
+func splitGrantObjects(list string) []string {
+	var objects []string
+	depth, start := 0, 0
+	for i, ch := range list {
+		switch {
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case ch == ',' && depth == 0:
+			objects = append(objects, strings.TrimSpace(list[start:i]))
+			start = i + 1
+		}
+	}
+
+	return append(objects, strings.TrimSpace(list[start:]))
+}
+
+// grantee returns the role as it is written in GRANT and REVOKE.
+func (g *grant) grantee() string {
+	return quoteGrantee(g.role)
+}
+
+// target returns the objects as they are written in GRANT and REVOKE, after
+// ON.
+func (g *grant) target() string {
+	objectType := grantObjectTypes[g.objectType]
+	if len(g.objects) == 0 {
+		return fmt.Sprintf("%s IN SCHEMA %s", objectType.all, pq.QuoteIdentifier(g.schema))
+	}
+
+	return objectType.keyword + " " + strings.Join(g.qualifiedObjects(), ", ")
+}
+
+// qualifiedObjects returns the objects qualified with their schema, if they are
+// in one, and quoted, e.g. "public"."add"(integer, integer).
+func (g *grant) qualifiedObjects() []string {
+	objects := make([]string, len(g.objects))
+	for i, object := range g.objects {
+		switch {
+		case grantObjectTypes[g.objectType].oids:
+			objects[i] = object
+			continue
+		case grantObjectTypes[g.objectType].schemaless:
+			objects[i] = pq.QuoteIdentifier(object)
+			continue
+		}
+		if grantObjectTypes[g.objectType].signatures {
+			// The types of the arguments are written as they are.
+			paren := strings.Index(object, "(")
+			object, args := object[:paren], object[paren:]
+			objects[i] = pq.QuoteIdentifier(g.schema) + "." + pq.QuoteIdentifier(strings.TrimSpace(object)) + args
+			continue
+		}
+		objects[i] = pq.QuoteIdentifier(g.schema) + "." + pq.QuoteIdentifier(object)
+	}
+
+	return objects
+}
+
+// revokeQuery returns the statement revoking the privileges on the objects,
+// or on the columns, from the role: all of them if the grant is exclusive, the
+// granted ones otherwise, or nothing if there are none.
+func (g *grant) revokeQuery() string {
+	if !g.exclusive {
+		if len(g.privileges) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("REVOKE %s ON %s FROM %s", g.privilegesList(g.privileges), g.target(), g.grantee())
+	}
+	if len(g.columns) > 0 {
+		return fmt.Sprintf("REVOKE ALL (%s) ON %s FROM %s", quoteIdentifiers(g.columns), g.target(), g.grantee())
+	}
+
+	return fmt.Sprintf("REVOKE ALL PRIVILEGES ON %s FROM %s", g.target(), g.grantee())
+}
+
+// revokeOthersQueries returns the statements revoking the privileges which are
+// not granted and, without grant option, the grant option of the ones which
+// are.  The privileges revoked are all the others if the grant is exclusive, or
+// the previously granted ones otherwise.  Privileges which stay granted are not
+// revoked, so that the ones the role granted to others in turn are not revoked
+// either.
+func (g *grant) revokeOthersQueries(previous []string) []string {
+	allowed := previous
+	if g.exclusive {
+		allowed = grantObjectTypes[g.objectType].privileges
+		if len(g.columns) > 0 {
+			allowed = columnPrivileges
+		}
+	}
+	var others []string
+	for _, privilege := range allowed {
+		if !stringInSlice(privilege, g.privileges) {
+			others = append(others, privilege)
+		}
+	}
+
+	var queries []string
+	if len(others) > 0 {
+		queries = append(queries, fmt.Sprintf("REVOKE %s ON %s FROM %s", g.privilegesList(others), g.target(), g.grantee()))
+	}
+	if !g.withOption && len(g.privileges) > 0 && g.grantee() != "PUBLIC" {
+		queries = append(queries, fmt.Sprintf("REVOKE GRANT OPTION FOR %s ON %s FROM %s", g.privilegesList(g.privileges), g.target(), g.grantee()))
+	}
+
+	return queries
+}
+
+// grantQuery returns the statement granting the privileges, or nothing if there
+// are none.
+func (g *grant) grantQuery() string {
+	if len(g.privileges) == 0 {
+		return ""
+	}
+
+	query := fmt.Sprintf("GRANT %s ON %s TO %s", g.privilegesList(g.privileges), g.target(), g.grantee())
+	if g.withOption {
+		query += " WITH GRANT OPTION"
+	}
+
+	return query
+}
+
+// privilegesList returns the privileges as they are written in GRANT and
+// REVOKE, on the columns if any.
+func (g *grant) privilegesList(privileges []string) string {
+	if len(g.columns) == 0 {
+		return strings.Join(privileges, ", ")
+	}
+
+	list := make([]string, len(privileges))
+	for i, privilege := range privileges {
+		list[i] = fmt.Sprintf("%s (%s)", privilege, quoteIdentifiers(g.columns))
+	}
+
+	return strings.Join(list, ", ")
+}
+
+func resourcePostgreSQLGrantCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if err := resourcePostgreSQLGrantUpdateImpl(d, meta, nil); err != nil {
+		return diagnostics(err)
+	}
+
+	g, err := newGrant(d)
+	if err != nil {
+		return diagnostics(err)
+	}
+	d.SetId(g.id())
+
+	return diagnostics(resourcePostgreSQLGrantReadImpl(d, meta))
+}
+
+func resourcePostgreSQLGrantUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	previous, _ := d.GetChange(grantPrivilegesAttr)
+	if err := resourcePostgreSQLGrantUpdateImpl(d, meta, setToSortedStrings(previous.(*schema.Set))); err != nil {
+		return diagnostics(err)
+	}
+
+	return diagnostics(resourcePostgreSQLGrantReadImpl(d, meta))
+}
+
+// resourcePostgreSQLGrantUpdateImpl revokes the privileges the role has on the
+// objects but the configured ones, or the previous ones which are no longer
+// configured if the grant is not exclusive, and grants it those, in a
+// transaction.
+func resourcePostgreSQLGrantUpdateImpl(d *schema.ResourceData, meta interface{}, previous []string) error {
+	c := meta.(*Client)
+	g, err := newGrant(d)
+	if err != nil {
+		return err
+	}
+	// Imported grants are read with all their privileges, so this is only
+	// checked when they are applied.
+	if !g.exclusive && len(g.privileges) == 0 {
+		return fmt.Errorf("%s can only be empty, to revoke all the privileges, with %s", grantPrivilegesAttr, grantExclusiveAttr)
+	}
+	if err := checkGrantFeatures(c, d); err != nil {
+		return err
+	}
+	if _, ok := redshiftACLQueries[g.objectType]; c.redshift && (!ok || len(g.columns) > 0) {
+		return fmt.Errorf("Granting privileges on %s objects, or on columns, is not supported on Redshift", g.objectType)
+	}
+	if err := checkSystemSchemaGrant(c, g); err != nil {
+		return err
+	}
+
+	unlock, err := c.lockCatalog("grant", c.databaseName(g.database))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	db, err := c.DBFor(g.database, "")
+	if err != nil {
+		return err
+	}
+
+	return c.inTxn(db, "grant", func(txn *sql.Tx) error {
+		for _, query := range append(g.revokeOthersQueries(previous), g.grantQuery()) {
+			if query == "" {
+				continue
+			}
+			if _, err := txn.Exec(query); err != nil {
+				return errwrap.Wrapf(fmt.Sprintf("Error granting privileges to role %s: {{err}}", g.role), err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func resourcePostgreSQLGrantRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	unlock := c.rlockCatalog("grant", c.databaseName(d.Get(grantDatabaseAttr).(string)))
+	defer unlock()
+
+	return diagnostics(resourcePostgreSQLGrantReadImpl(d, meta))
+}
+
+// resourcePostgreSQLGrantReadImpl reads the privileges the role has on every
+// one of the objects, or columns.  Privileges missing on some of them are not
+// read, so that granting them again is planned.  Unless the grant is exclusive,
+// only the configured privileges are read, or all of them when there are none,
+// e.g. on import.
+func resourcePostgreSQLGrantReadImpl(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	g, err := newGrant(d)
+	if err != nil {
+		return err
+	}
+
+	db, err := c.DBFor(g.database, "")
+	if err != nil {
+		return err
+	}
+
+	// PUBLIC is represented by the grantee OID 0 in ACLs.  The grantees of
+	// Redshift, which may be groups, are matched by name.
+	var roleOID int64
+	if g.grantee() != "PUBLIC" && !c.redshift {
+		err := db.QueryRow("SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $1", g.role).Scan(&roleOID)
+		switch {
+		case err == sql.ErrNoRows:
+			logEvent("WARN", "PostgreSQL role of grant not found", logFields{"role": g.role})
+			d.SetId("")
+			return nil
+		case err != nil:
+			return errwrap.Wrapf("Error reading role: {{err}}", err)
+		}
+	}
+
+	var rows *sql.Rows
+	expected := g.objects
+	switch {
+	case c.redshift:
+		var objects interface{}
+		if len(g.objects) > 0 {
+			objects = pq.Array(g.objects)
+		}
+		rows, err = db.Query(redshiftACLQueries[g.objectType], g.schema, objects)
+	case len(g.columns) > 0:
+		expected = g.columns
+		rows, err = db.Query(columnACLQuery, g.schema, g.objects[0], roleOID, pq.Array(g.columns))
+	default:
+		objectType := grantObjectTypes[g.objectType]
+		var objects interface{}
+		switch {
+		case len(g.objects) > 0 && objectType.signatures:
+			objects = pq.Array(g.qualifiedObjects())
+		case len(g.objects) > 0:
+			objects = pq.Array(g.objects)
+		}
+		rows, err = db.Query(objectType.aclQuery(c), g.schema, roleOID, objects)
+	}
+	if err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error reading privileges of role %s: {{err}}", g.role), err)
+	}
+	defer rows.Close()
+
+	// held counts the objects each privilege is held on, and grantable the
+	// ones it is held on with grant option.
+	held := make(map[string]int)
+	grantable := make(map[string]int)
+	found := 0
+	for rows.Next() {
+		var name string
+		var privileges pq.StringArray
+		if c.redshift {
+			var acl string
+			if err := rows.Scan(&name, &acl); err != nil {
+				return errwrap.Wrapf(fmt.Sprintf("Error reading privileges of role %s: {{err}}", g.role), err)
+			}
+			privileges = redshiftACLPrivileges(acl, g.grantee())
+		} else if err := rows.Scan(&name, &privileges); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error reading privileges of role %s: {{err}}", g.role), err)
+		}
+		found++
+
+		// A privilege granted by several grantors is listed once per
+		// grantor.
+		withOption := make(map[string]bool, len(privileges))
+		for _, privilege := range privileges {
+			privilege, option := strings.TrimSuffix(privilege, "*"), strings.HasSuffix(privilege, "*")
+			withOption[privilege] = withOption[privilege] || option
+		}
+		for privilege, option := range withOption {
+			held[privilege]++
+			if option {
+				grantable[privilege]++
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error reading privileges of role %s: {{err}}", g.role), err)
+	}
+
+	// Without any objects in the schema, nothing is granted, nor missing.
+	// The privileges are granted with grant option if all of them are on all
+	// the objects.
+	privileges, withOption := g.privileges, g.withOption
+	if found > 0 || len(expected) > 0 {
+		privileges = make([]string, 0, len(held))
+		for privilege, count := range held {
+			if !g.exclusive && len(g.privileges) > 0 && !stringInSlice(privilege, g.privileges) {
+				continue
+			}
+			if count == found && found >= len(expected) {
+				privileges = append(privileges, privilege)
+			}
+		}
+		if len(privileges) > 0 {
+			withOption = true
+			for _, privilege := range privileges {
+				withOption = withOption && grantable[privilege] == found
+			}
+		}
+	}
+	d.Set(grantPrivilegesAttr, privileges)
+	d.Set(grantWithOptionAttr, withOption)
+
+	return nil
+}
+
+// resourcePostgreSQLGrantImport sets the arguments of the grant from its ID.
+// The privileges, and grant option, are then read from the objects, so that
+// the privileges already granted are neither revoked nor granted again.
+func resourcePostgreSQLGrantImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts, err := splitImportID(d.Id(), 4, 6, grantImportFormat)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := grantObjectTypes[parts[3]]; !ok {
+		return nil, fmt.Errorf("invalid object type %q, expected one of %s", parts[3], strings.Join(grantObjectTypeNames(), ", "))
+	}
+
+	d.Set(grantRoleAttr, parts[0])
+	d.Set(grantDatabaseAttr, parts[1])
+	d.Set(grantSchemaAttr, parts[2])
+	d.Set(grantObjectTypeAttr, parts[3])
+	if len(parts) > 4 {
+		d.Set(grantObjectsAttr, splitGrantObjects(parts[4]))
+	}
+	if len(parts) > 5 {
+		d.Set(grantColumnsAttr, strings.Split(parts[5], ","))
+	}
+
+	g, err := newGrant(d)
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(g.id())
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourcePostgreSQLGrantDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	g, err := newGrant(d)
+	if err != nil {
+		return diagnostics(err)
+	}
+
+	unlock, err := c.lockCatalog("grant", c.databaseName(g.database))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
+
+	db, err := c.DBFor(g.database, "")
+	if err != nil {
+		return diagnostics(err)
+	}
+
+	if query := g.revokeQuery(); query != "" {
+		if _, err := db.Exec(query); err != nil {
+			return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error revoking privileges from role %s: {{err}}", g.role), err))
+		}
+	}
+
+	d.SetId("")
+
+	return nil
+}
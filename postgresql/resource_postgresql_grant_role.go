@@ -0,0 +1,363 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/blang/semver"
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lib/pq"
+)
+
+const (
+	grantRoleName = "postgresql_grant_role"
+
+	grantRoleMemberAttr  = "role"
+	grantRoleGrantedAttr = "grant_role"
+	grantRoleAdminAttr   = "with_admin_option"
+	grantRoleGrantorAttr = "grantor"
+)
+
+// grantRoleMembershipQuery selects the grantor and the admin option of the
+// membership of the role $1 in the role $2.  Since PostgreSQL 16, each grantor
+// has its own grant of a membership: the one of the connection user, which
+// the resource manages, is read first, so that the ones of other admins are
+// neither reported as changes nor revoked.
+const grantRoleMembershipQuery = `SELECT pg_catalog.pg_get_userbyid(m.grantor), m.admin_option ` +
+	`FROM pg_catalog.pg_auth_members m ` +
+	`JOIN pg_catalog.pg_roles r ON r.oid = m.roleid JOIN pg_catalog.pg_roles u ON u.oid = m.member ` +
+	`WHERE u.rolname = $1 AND r.rolname = $2 ` +
+	`ORDER BY m.grantor = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = current_user) DESC, m.admin_option DESC ` +
+	`LIMIT 1`
+
+// predefinedRoles maps the predefined roles to the versions of PostgreSQL which
+// have them.
+var predefinedRoles = map[string]semver.Range{
+	"pg_signal_backend":           semver.MustParseRange(">=9.6.0"),
+	"pg_monitor":                  semver.MustParseRange(">=10.0.0"),
+	"pg_read_all_settings":        semver.MustParseRange(">=10.0.0"),
+	"pg_read_all_stats":           semver.MustParseRange(">=10.0.0"),
+	"pg_stat_scan_tables":         semver.MustParseRange(">=10.0.0"),
+	"pg_execute_server_program":   semver.MustParseRange(">=11.0.0"),
+	"pg_read_server_files":        semver.MustParseRange(">=11.0.0"),
+	"pg_write_server_files":       semver.MustParseRange(">=11.0.0"),
+	"pg_database_owner":           semver.MustParseRange(">=14.0.0"),
+	"pg_read_all_data":            semver.MustParseRange(">=14.0.0"),
+	"pg_write_all_data":           semver.MustParseRange(">=14.0.0"),
+	"pg_checkpoint":               semver.MustParseRange(">=15.0.0"),
+	"pg_create_subscription":      semver.MustParseRange(">=16.0.0"),
+	"pg_use_reserved_connections": semver.MustParseRange(">=16.0.0"),
+	"pg_maintain":                 semver.MustParseRange(">=17.0.0"),
+	"pg_signal_autovacuum_worker": semver.MustParseRange(">=18.0.0"),
+}
+
+// checkPredefinedRole returns an error if role is a predefined role which the
+// server of the version does not have, or whose membership can not be granted.
+// Other roles are left to the server to check.
+func checkPredefinedRole(version semver.Version, role string) error {
+	supported, ok := predefinedRoles[role]
+	switch {
+	case !ok:
+		return nil
+	case !supported(version):
+		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not have the predefined role %s", version.String(), role)
+	case role == "pg_database_owner":
+		// Its only member is the owner of the current database.
+		return fmt.Errorf("membership in the predefined role %s can not be granted", role)
+	}
+
+	return nil
+}
+
+// checkGrantRoleFeatures returns an error if grant_role is a predefined role
+// the server does not have, or whose membership can not be granted.
+func checkGrantRoleFeatures(c *Client, d attrChanges) error {
+	role, ok := d.GetOk(grantRoleGrantedAttr)
+	if !ok || !d.HasChange(grantRoleGrantedAttr) {
+		return nil
+	}
+	if err := checkPredefinedRole(c.version, role.(string)); err != nil {
+		return &attrError{attr: grantRoleGrantedAttr, err: err}
+	}
+
+	return nil
+}
+
+// grantRoleResource is the resource of the membership of a role in another,
+// served by terraform-plugin-framework.
+type grantRoleResource struct {
+	client *Client
+}
+
+// grantRoleModel is the state of the membership of role in grant_role.
+type grantRoleModel struct {
+	ID      types.String `tfsdk:"id"`
+	Member  types.String `tfsdk:"role"`
+	Role    types.String `tfsdk:"grant_role"`
+	Admin   types.Bool   `tfsdk:"with_admin_option"`
+	Grantor types.String `tfsdk:"grantor"`
+}
+
+func newGrantRoleResource() resource.Resource {
+	return &grantRoleResource{}
+}
+
+func (r *grantRoleResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = grantRoleName
+}
+
+func (r *grantRoleResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:      true,
+				Description:   "The roles of the membership, role/grant_role",
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			grantRoleMemberAttr: schema.StringAttribute{
+				Required:      true,
+				Description:   "The role made a member of grant_role",
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Validators:    []validator.String{stringvalidator.LengthAtLeast(1)},
+			},
+			grantRoleGrantedAttr: schema.StringAttribute{
+				Required:      true,
+				Description:   "The role role is made a member of",
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Validators:    []validator.String{stringvalidator.LengthAtLeast(1)},
+			},
+			grantRoleAdminAttr: schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Whether role can grant membership in grant_role to others",
+			},
+			// The grantor may change with the admin option.
+			grantRoleGrantorAttr: schema.StringAttribute{
+				Computed:    true,
+				Description: "The role which granted the membership",
+			},
+		},
+	}
+}
+
+func (r *grantRoleResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, diags := configuredClient(req.ProviderData)
+	resp.Diagnostics.Append(diags...)
+	r.client = c
+}
+
+// ModifyPlan fails the plans granting a predefined role the server does not
+// have.
+func (r *grantRoleResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	modifyPlanFeatures(ctx, r.client, req, resp, checkGrantRoleFeatures)
+}
+
+func (r *grantRoleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var m grantRoleModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &m)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	c := r.client
+	member, role := m.Member.ValueString(), m.Role.ValueString()
+	m.ID = types.StringValue(importID(member, role))
+	err := c.operation(grantRoleName, "create", m.ID.ValueString(), func() error {
+		if err := checkGrantRoleFeatures(c, planChanges{ctx: ctx, plan: req.Plan}); err != nil {
+			return err
+		}
+		if err := checkCreateRoleEscalation(c, c.DB(), member, role); err != nil {
+			return err
+		}
+
+		unlock, err := c.lockRoleMembership(role)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		if err := grantMembership(c, member, role, m.Admin.ValueBool()); err != nil {
+			return err
+		}
+
+		_, err = readGrantRole(c, &m)
+		return err
+	})
+	resp.Diagnostics.Append(frameworkDiagnostics(err)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &m)...)
+}
+
+func (r *grantRoleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var m grantRoleModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &m)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	c := r.client.forRead()
+	found := false
+	err := c.operation(grantRoleName, "read", m.ID.ValueString(), func() error {
+		unlock := c.rlockCatalog("role", "")
+		defer unlock()
+
+		var err error
+		found, err = readGrantRole(c, &m)
+		return err
+	})
+	resp.Diagnostics.Append(frameworkDiagnostics(err)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !found {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &m)...)
+}
+
+func (r *grantRoleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var m, prior grantRoleModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &m)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	c := r.client
+	member, role := m.Member.ValueString(), m.Role.ValueString()
+	err := c.operation(grantRoleName, "update", m.ID.ValueString(), func() error {
+		unlock, err := c.lockRoleMembership(role)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		if !m.Admin.Equal(prior.Admin) {
+			if err := setGrantRoleAdmin(c, member, role, m.Admin.ValueBool()); err != nil {
+				return err
+			}
+		}
+
+		_, err = readGrantRole(c, &m)
+		return err
+	})
+	resp.Diagnostics.Append(frameworkDiagnostics(err)...)
+	if resp.Diagnostics.HasError() {
+		// Terraform must not record the change as made.
+		resp.State.Raw = req.State.Raw
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &m)...)
+}
+
+func (r *grantRoleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var m grantRoleModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &m)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	c := r.client
+	member, role := m.Member.ValueString(), m.Role.ValueString()
+	err := c.operation(grantRoleName, "delete", m.ID.ValueString(), func() error {
+		unlock, err := c.lockRoleMembership(role)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		sql := fmt.Sprintf("REVOKE %s FROM %s", pq.QuoteIdentifier(role), pq.QuoteIdentifier(member))
+		if _, err := c.DB().Exec(sql); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error revoking membership in role %s from role %s: {{err}}", role, member), err)
+		}
+
+		return nil
+	})
+	resp.Diagnostics.Append(frameworkDiagnostics(err)...)
+}
+
+// ImportState sets the roles of the membership from its ID, role/grant_role.
+// The admin option is then read.
+func (r *grantRoleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts, err := splitImportID(req.ID, 2, 2, "role/grant_role")
+	if err != nil {
+		resp.Diagnostics.Append(frameworkDiagnostics(err)...)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(grantRoleMemberAttr), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(grantRoleGrantedAttr), parts[1])...)
+}
+
+// grantMembership makes member a member of role.  Granting the admin option to
+// an existing member only adds the option.
+func grantMembership(c *Client, member, role string, admin bool) error {
+	sql := fmt.Sprintf("GRANT %s TO %s", pq.QuoteIdentifier(role), pq.QuoteIdentifier(member))
+	if admin {
+		sql += " WITH ADMIN OPTION"
+	}
+	if _, err := c.DB().Exec(sql); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error granting membership in role %s to role %s: {{err}}", role, member), err)
+	}
+
+	return nil
+}
+
+// setGrantRoleAdmin grants or revokes the admin option of the membership of
+// member in role.  The membership itself is kept.
+func setGrantRoleAdmin(c *Client, member, role string, admin bool) error {
+	if admin {
+		return grantMembership(c, member, role, true)
+	}
+
+	sql := fmt.Sprintf("REVOKE ADMIN OPTION FOR %s FROM %s", pq.QuoteIdentifier(role), pq.QuoteIdentifier(member))
+	if _, err := c.DB().Exec(sql); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error revoking the admin option of role %s in role %s: {{err}}", member, role), err)
+	}
+
+	return nil
+}
+
+// readGrantRole reads the admin option and the grantor of the membership of m,
+// and returns false if there is none.
+func readGrantRole(c *Client, m *grantRoleModel) (bool, error) {
+	member, role := m.Member.ValueString(), m.Role.ValueString()
+
+	var grantor string
+	var admin bool
+	err := c.DB().QueryRow(grantRoleMembershipQuery, member, role).Scan(&grantor, &admin)
+	switch {
+	case err == sql.ErrNoRows:
+		logEvent("WARN", "PostgreSQL role membership not found", logFields{"role": member, "grant_role": role})
+		return false, nil
+	case err != nil:
+		return false, errwrap.Wrapf("Error reading role membership: {{err}}", err)
+	}
+
+	m.Admin = types.BoolValue(admin)
+	m.Grantor = types.StringValue(grantor)
+
+	return true, nil
+}
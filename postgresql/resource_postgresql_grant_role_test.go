@@ -0,0 +1,124 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestCheckPredefinedRole(t *testing.T) {
+	tests := []struct {
+		version string
+		role    string
+		err     string
+	}{
+		{"9.6.24", "pg_signal_backend", ""},
+		{"9.6.24", "pg_monitor", "does not have the predefined role pg_monitor"},
+		{"13.14.0", "pg_read_all_data", "does not have the predefined role pg_read_all_data"},
+		{"14.11.0", "pg_read_all_data", ""},
+		{"16.2.0", "pg_maintain", "does not have the predefined role pg_maintain"},
+		{"17.0.0", "pg_maintain", ""},
+		{"16.2.0", "pg_database_owner", "can not be granted"},
+		{"9.4.26", "analysts", ""},
+	}
+
+	for _, test := range tests {
+		err := checkPredefinedRole(semver.MustParse(test.version), test.role)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s on %s: %v", test.role, test.version, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("%s on %s: expected an error containing %q, got %v", test.role, test.version, test.err, err)
+		}
+	}
+}
+
+func TestAccPostgresqlGrantRole_AdminOption(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP ROLE IF EXISTS grant_role_member",
+		"DROP ROLE IF EXISTS grant_role_group",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE ROLE grant_role_group",
+				"CREATE ROLE grant_role_member",
+			)
+		},
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgresqlGrantRoleConfig, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant_role.member", "with_admin_option", "true"),
+					resource.TestCheckResourceAttrSet("postgresql_grant_role.member", "grantor"),
+				),
+			},
+			{
+				ResourceName:      "postgresql_grant_role.member",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// Revoking the admin option keeps the membership.
+				Config: fmt.Sprintf(testAccPostgresqlGrantRoleConfig, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant_role.member", "with_admin_option", "false"),
+					func(*terraform.State) error {
+						testAccPostgresqlExec(t, `DO $$ BEGIN `+
+							`IF NOT pg_catalog.pg_has_role('grant_role_member', 'grant_role_group', 'MEMBER') `+
+							`OR pg_catalog.pg_has_role('grant_role_member', 'grant_role_group', 'MEMBER WITH ADMIN OPTION') THEN `+
+							`RAISE EXCEPTION 'grant_role_member must only be a member of grant_role_group'; `+
+							`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlGrantRoleConfig = `
+resource "postgresql_grant_role" "member" {
+  role              = "grant_role_member"
+  grant_role        = "grant_role_group"
+  with_admin_option = %t
+}
+`
+
+func TestAccPostgresqlGrantRole_PredefinedRole(t *testing.T) {
+	defer testAccPostgresqlExec(t, "DROP ROLE IF EXISTS grant_role_monitoring")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t, "CREATE ROLE grant_role_monitoring LOGIN")
+		},
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlGrantRolePredefinedConfig,
+				Check: func(*terraform.State) error {
+					testAccPostgresqlExec(t, `DO $$ BEGIN `+
+						`IF NOT pg_catalog.pg_has_role('grant_role_monitoring', 'pg_monitor', 'MEMBER') THEN `+
+						`RAISE EXCEPTION 'grant_role_monitoring must be a member of pg_monitor'; `+
+						`END IF; END $$`)
+					return nil
+				},
+			},
+		},
+	})
+}
+
+var testAccPostgresqlGrantRolePredefinedConfig = `
+resource "postgresql_grant_role" "monitoring" {
+  role       = "grant_role_monitoring"
+  grant_role = "pg_monitor"
+}
+`
@@ -0,0 +1,1007 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestGrantQueries(t *testing.T) {
+	tests := []struct {
+		config map[string]interface{}
+
+		id       string
+		revoke   string
+		previous []string
+		others   []string
+		grant    string
+		err      string
+	}{
+		{
+			config: map[string]interface{}{
+				"role":        "app",
+				"schema":      "public",
+				"object_type": "table",
+				"privileges":  []interface{}{"SELECT", "INSERT"},
+			},
+			id:     "app//public/table",
+			revoke: `REVOKE INSERT, SELECT ON ALL TABLES IN SCHEMA "public" FROM "app"`,
+			others: []string{
+				`REVOKE GRANT OPTION FOR INSERT, SELECT ON ALL TABLES IN SCHEMA "public" FROM "app"`,
+			},
+			grant: `GRANT INSERT, SELECT ON ALL TABLES IN SCHEMA "public" TO "app"`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "analyst",
+				"schema":      "public",
+				"object_type": "table",
+				"objects":     []interface{}{"customers"},
+				"columns":     []interface{}{"id"},
+				"privileges":  []interface{}{"SELECT"},
+			},
+			id:       "analyst//public/table/customers/id",
+			revoke:   `REVOKE SELECT ("id") ON TABLE "public"."customers" FROM "analyst"`,
+			previous: []string{"SELECT", "UPDATE"},
+			others: []string{
+				`REVOKE UPDATE ("id") ON TABLE "public"."customers" FROM "analyst"`,
+				`REVOKE GRANT OPTION FOR SELECT ("id") ON TABLE "public"."customers" FROM "analyst"`,
+			},
+			grant: `GRANT SELECT ("id") ON TABLE "public"."customers" TO "analyst"`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "public",
+				"schema":      "api",
+				"object_type": "routine",
+				"privileges":  []interface{}{},
+			},
+			id:       "public//api/routine",
+			previous: []string{"EXECUTE"},
+			others: []string{
+				`REVOKE EXECUTE ON ALL ROUTINES IN SCHEMA "api" FROM PUBLIC`,
+			},
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "app",
+				"schema":      "public",
+				"object_type": "table",
+				"privileges":  []interface{}{"SELECT", "INSERT"},
+				"exclusive":   true,
+			},
+			id:     "app//public/table",
+			revoke: `REVOKE ALL PRIVILEGES ON ALL TABLES IN SCHEMA "public" FROM "app"`,
+			others: []string{
+				`REVOKE DELETE, REFERENCES, TRIGGER, TRUNCATE, UPDATE ON ALL TABLES IN SCHEMA "public" FROM "app"`,
+				`REVOKE GRANT OPTION FOR INSERT, SELECT ON ALL TABLES IN SCHEMA "public" FROM "app"`,
+			},
+			grant: `GRANT INSERT, SELECT ON ALL TABLES IN SCHEMA "public" TO "app"`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "public",
+				"database":    "shop",
+				"schema":      "public",
+				"object_type": "table",
+				"objects":     []interface{}{"orders", "customers"},
+				"privileges":  []interface{}{},
+				"exclusive":   true,
+			},
+			id:     "public/shop/public/table/customers,orders",
+			revoke: `REVOKE ALL PRIVILEGES ON TABLE "public"."customers", "public"."orders" FROM PUBLIC`,
+			others: []string{
+				`REVOKE DELETE, INSERT, REFERENCES, SELECT, TRIGGER, TRUNCATE, UPDATE ON TABLE "public"."customers", "public"."orders" FROM PUBLIC`,
+			},
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "analyst",
+				"schema":      "public",
+				"object_type": "table",
+				"objects":     []interface{}{"customers"},
+				"columns":     []interface{}{"id", "country"},
+				"privileges":  []interface{}{"SELECT"},
+				"exclusive":   true,
+			},
+			id:     "analyst//public/table/customers/country,id",
+			revoke: `REVOKE ALL ("country", "id") ON TABLE "public"."customers" FROM "analyst"`,
+			others: []string{
+				`REVOKE INSERT ("country", "id"), REFERENCES ("country", "id"), UPDATE ("country", "id") ON TABLE "public"."customers" FROM "analyst"`,
+				`REVOKE GRANT OPTION FOR SELECT ("country", "id") ON TABLE "public"."customers" FROM "analyst"`,
+			},
+			grant: `GRANT SELECT ("country", "id") ON TABLE "public"."customers" TO "analyst"`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "analyst",
+				"schema":      "public",
+				"object_type": "table",
+				"objects":     []interface{}{"customers"},
+				"columns":     []interface{}{"id"},
+				"privileges":  []interface{}{"DELETE"},
+			},
+			err: `invalid privilege "DELETE"`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "analyst",
+				"schema":      "public",
+				"object_type": "table",
+				"columns":     []interface{}{"id"},
+				"privileges":  []interface{}{"SELECT"},
+			},
+			err: "single table",
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "app",
+				"object_type": "table",
+				"privileges":  []interface{}{"SELECT"},
+			},
+			err: "schema is required",
+		},
+		{
+			config: map[string]interface{}{
+				"role":              "lead",
+				"schema":            "public",
+				"object_type":       "table",
+				"objects":           []interface{}{"orders"},
+				"privileges":        []interface{}{"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER"},
+				"exclusive":         true,
+				"with_grant_option": true,
+			},
+			id:     "lead//public/table/orders",
+			revoke: `REVOKE ALL PRIVILEGES ON TABLE "public"."orders" FROM "lead"`,
+			grant:  `GRANT DELETE, INSERT, REFERENCES, SELECT, TRIGGER, TRUNCATE, UPDATE ON TABLE "public"."orders" TO "lead" WITH GRANT OPTION`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":              "public",
+				"schema":            "public",
+				"object_type":       "table",
+				"privileges":        []interface{}{"SELECT"},
+				"with_grant_option": true,
+			},
+			err: "PUBLIC with grant option",
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "public",
+				"object_type": "database",
+				"objects":     []interface{}{"tenant_a", "tenant_b"},
+				"privileges":  []interface{}{},
+				"exclusive":   true,
+			},
+			id:     "public///database/tenant_a,tenant_b",
+			revoke: `REVOKE ALL PRIVILEGES ON DATABASE "tenant_a", "tenant_b" FROM PUBLIC`,
+			others: []string{
+				`REVOKE CONNECT, CREATE, TEMPORARY ON DATABASE "tenant_a", "tenant_b" FROM PUBLIC`,
+			},
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "tenant_a",
+				"object_type": "database",
+				"objects":     []interface{}{"tenant_a"},
+				"privileges":  []interface{}{"CONNECT", "TEMPORARY"},
+				"exclusive":   true,
+			},
+			id:     "tenant_a///database/tenant_a",
+			revoke: `REVOKE ALL PRIVILEGES ON DATABASE "tenant_a" FROM "tenant_a"`,
+			others: []string{
+				`REVOKE CREATE ON DATABASE "tenant_a" FROM "tenant_a"`,
+				`REVOKE GRANT OPTION FOR CONNECT, TEMPORARY ON DATABASE "tenant_a" FROM "tenant_a"`,
+			},
+			grant: `GRANT CONNECT, TEMPORARY ON DATABASE "tenant_a" TO "tenant_a"`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "tenant_a",
+				"schema":      "public",
+				"object_type": "database",
+				"objects":     []interface{}{"tenant_a"},
+				"privileges":  []interface{}{"CONNECT"},
+			},
+			err: "schema can not be set",
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "tenant_a",
+				"object_type": "database",
+				"privileges":  []interface{}{"CONNECT"},
+			},
+			err: "objects is required",
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "app",
+				"database":    "shop",
+				"object_type": "schema",
+				"objects":     []interface{}{"api", "Reports"},
+				"privileges":  []interface{}{"USAGE"},
+				"exclusive":   true,
+			},
+			id:     "app/shop//schema/Reports,api",
+			revoke: `REVOKE ALL PRIVILEGES ON SCHEMA "Reports", "api" FROM "app"`,
+			others: []string{
+				`REVOKE CREATE ON SCHEMA "Reports", "api" FROM "app"`,
+				`REVOKE GRANT OPTION FOR USAGE ON SCHEMA "Reports", "api" FROM "app"`,
+			},
+			grant: `GRANT USAGE ON SCHEMA "Reports", "api" TO "app"`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "app",
+				"schema":      "public",
+				"object_type": "sequence",
+				"objects":     []interface{}{"orders_id_seq"},
+				"privileges":  []interface{}{"USAGE", "SELECT"},
+				"exclusive":   true,
+			},
+			id:     "app//public/sequence/orders_id_seq",
+			revoke: `REVOKE ALL PRIVILEGES ON SEQUENCE "public"."orders_id_seq" FROM "app"`,
+			others: []string{
+				`REVOKE UPDATE ON SEQUENCE "public"."orders_id_seq" FROM "app"`,
+				`REVOKE GRANT OPTION FOR SELECT, USAGE ON SEQUENCE "public"."orders_id_seq" FROM "app"`,
+			},
+			grant: `GRANT SELECT, USAGE ON SEQUENCE "public"."orders_id_seq" TO "app"`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "app",
+				"schema":      "public",
+				"object_type": "sequence",
+				"privileges":  []interface{}{"DELETE"},
+			},
+			err: `invalid privilege "DELETE"`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "etl",
+				"object_type": "foreign_server",
+				"objects":     []interface{}{"warehouse"},
+				"privileges":  []interface{}{"USAGE"},
+				"exclusive":   true,
+			},
+			id:     "etl///foreign_server/warehouse",
+			revoke: `REVOKE ALL PRIVILEGES ON FOREIGN SERVER "warehouse" FROM "etl"`,
+			others: []string{
+				`REVOKE GRANT OPTION FOR USAGE ON FOREIGN SERVER "warehouse" FROM "etl"`,
+			},
+			grant: `GRANT USAGE ON FOREIGN SERVER "warehouse" TO "etl"`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "etl",
+				"object_type": "foreign_data_wrapper",
+				"objects":     []interface{}{"postgres_fdw"},
+				"privileges":  []interface{}{"USAGE"},
+				"exclusive":   true,
+			},
+			id:     "etl///foreign_data_wrapper/postgres_fdw",
+			revoke: `REVOKE ALL PRIVILEGES ON FOREIGN DATA WRAPPER "postgres_fdw" FROM "etl"`,
+			others: []string{
+				`REVOKE GRANT OPTION FOR USAGE ON FOREIGN DATA WRAPPER "postgres_fdw" FROM "etl"`,
+			},
+			grant: `GRANT USAGE ON FOREIGN DATA WRAPPER "postgres_fdw" TO "etl"`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "archive",
+				"object_type": "large_object",
+				"objects":     []interface{}{"16401", "16400"},
+				"privileges":  []interface{}{"SELECT"},
+				"exclusive":   true,
+			},
+			id:     "archive///large_object/16400,16401",
+			revoke: `REVOKE ALL PRIVILEGES ON LARGE OBJECT 16400, 16401 FROM "archive"`,
+			others: []string{
+				`REVOKE UPDATE ON LARGE OBJECT 16400, 16401 FROM "archive"`,
+				`REVOKE GRANT OPTION FOR SELECT ON LARGE OBJECT 16400, 16401 FROM "archive"`,
+			},
+			grant: `GRANT SELECT ON LARGE OBJECT 16400, 16401 TO "archive"`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "archive",
+				"object_type": "large_object",
+				"objects":     []interface{}{"1; DROP TABLE orders"},
+				"privileges":  []interface{}{"SELECT"},
+			},
+			err: "must be an OID",
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "app",
+				"schema":      "public",
+				"object_type": "domain",
+				"objects":     []interface{}{"email"},
+				"privileges":  []interface{}{"USAGE"},
+				"exclusive":   true,
+			},
+			id:     "app//public/domain/email",
+			revoke: `REVOKE ALL PRIVILEGES ON DOMAIN "public"."email" FROM "app"`,
+			others: []string{
+				`REVOKE GRANT OPTION FOR USAGE ON DOMAIN "public"."email" FROM "app"`,
+			},
+			grant: `GRANT USAGE ON DOMAIN "public"."email" TO "app"`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "app",
+				"schema":      "public",
+				"object_type": "type",
+				"privileges":  []interface{}{"USAGE"},
+			},
+			err: "objects is required",
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "app",
+				"schema":      "api",
+				"object_type": "function",
+				"objects":     []interface{}{"add(integer, integer)", "Now()"},
+				"privileges":  []interface{}{"EXECUTE"},
+				"exclusive":   true,
+			},
+			id:     "app//api/function/Now(),add(integer, integer)",
+			revoke: `REVOKE ALL PRIVILEGES ON FUNCTION "api"."Now"(), "api"."add"(integer, integer) FROM "app"`,
+			others: []string{
+				`REVOKE GRANT OPTION FOR EXECUTE ON FUNCTION "api"."Now"(), "api"."add"(integer, integer) FROM "app"`,
+			},
+			grant: `GRANT EXECUTE ON FUNCTION "api"."Now"(), "api"."add"(integer, integer) TO "app"`,
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "public",
+				"schema":      "api",
+				"object_type": "routine",
+				"privileges":  []interface{}{},
+				"exclusive":   true,
+			},
+			id:     "public//api/routine",
+			revoke: `REVOKE ALL PRIVILEGES ON ALL ROUTINES IN SCHEMA "api" FROM PUBLIC`,
+			others: []string{
+				`REVOKE EXECUTE ON ALL ROUTINES IN SCHEMA "api" FROM PUBLIC`,
+			},
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "app",
+				"schema":      "api",
+				"object_type": "procedure",
+				"objects":     []interface{}{"refresh"},
+				"privileges":  []interface{}{"EXECUTE"},
+			},
+			err: "types of its arguments",
+		},
+		{
+			config: map[string]interface{}{
+				"role":        "app",
+				"schema":      "api",
+				"object_type": "function",
+				"privileges":  []interface{}{"SELECT"},
+			},
+			err: `invalid privilege "SELECT"`,
+		},
+	}
+
+	for _, test := range tests {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, test.config)
+		g, err := newGrant(d)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%v: expected an error containing %q, got %v", test.config, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %v", test.config, err)
+			continue
+		}
+
+		if id := g.id(); id != test.id {
+			t.Errorf("%v: expected ID %q, got %q", test.config, test.id, id)
+		}
+
+		// Importing the grant by its ID sets the same arguments.
+		imported := schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{})
+		imported.SetId(test.id)
+		if _, err := resourcePostgreSQLGrantImport(context.Background(), imported, nil); err != nil {
+			t.Errorf("%v: %v", test.config, err)
+		} else if i, _ := newGrant(imported); imported.Id() != test.id || i.role != g.role || i.database != g.database || i.schema != g.schema ||
+			i.objectType != g.objectType || !reflect.DeepEqual(i.objects, g.objects) || !reflect.DeepEqual(i.columns, g.columns) {
+			t.Errorf("%v: expected the grant imported from %q to match, got %+v", test.config, test.id, i)
+		}
+		if revoke := g.revokeQuery(); revoke != test.revoke {
+			t.Errorf("%v: expected %q, got %q", test.config, test.revoke, revoke)
+		}
+		if others := g.revokeOthersQueries(test.previous); !reflect.DeepEqual(others, test.others) {
+			t.Errorf("%v: expected %q, got %q", test.config, test.others, others)
+		}
+		if grant := g.grantQuery(); grant != test.grant {
+			t.Errorf("%v: expected %q, got %q", test.config, test.grant, grant)
+		}
+	}
+}
+
+func TestGrantImportErrors(t *testing.T) {
+	tests := []struct {
+		id  string
+		err string
+	}{
+		{"app/shop/public", "expected role/database/schema/object_type"},
+		{"app//public/view", `invalid object type "view"`},
+		{"app///table/orders", "schema is required"},
+		{"app//api/function/add", "types of its arguments"},
+	}
+
+	for _, test := range tests {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{})
+		d.SetId(test.id)
+		if _, err := resourcePostgreSQLGrantImport(context.Background(), d, nil); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: expected an error containing %q, got %v", test.id, test.err, err)
+		}
+	}
+}
+
+func TestAccPostgresqlGrant_Columns(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP TABLE IF EXISTS grant_customers",
+		"DROP ROLE IF EXISTS grant_analyst",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE TABLE grant_customers (id int, country text, email text)",
+				"CREATE ROLE grant_analyst",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlGrantColumnsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.columns", "privileges.#", "1"),
+					func(*terraform.State) error {
+						testAccPostgresqlExec(t, `DO $$ BEGIN `+
+							`IF NOT pg_catalog.has_column_privilege('grant_analyst', 'grant_customers', 'country', 'SELECT') `+
+							`OR pg_catalog.has_column_privilege('grant_analyst', 'grant_customers', 'email', 'SELECT') THEN `+
+							`RAISE EXCEPTION 'grant_analyst must only select id and country'; `+
+							`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      "postgresql_grant.columns",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+var testAccPostgresqlGrantColumnsConfig = `
+resource "postgresql_grant" "columns" {
+  role        = "grant_analyst"
+  schema      = "public"
+  object_type = "table"
+  objects     = ["grant_customers"]
+  columns     = ["id", "country"]
+  privileges  = ["SELECT"]
+}
+`
+
+func TestAccPostgresqlGrant_Functions(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP FUNCTION IF EXISTS grant_add(integer, integer)",
+		"DROP FUNCTION IF EXISTS grant_sub(integer, integer)",
+		"DROP ROLE IF EXISTS grant_app",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE FUNCTION grant_add(a integer, b integer) RETURNS integer AS 'SELECT a + b' LANGUAGE SQL",
+				"CREATE FUNCTION grant_sub(a integer, b integer) RETURNS integer AS 'SELECT a - b' LANGUAGE SQL",
+				"CREATE ROLE grant_app",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlGrantFunctionsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.public", "privileges.#", "0"),
+					resource.TestCheckResourceAttr("postgresql_grant.app", "privileges.#", "1"),
+					func(*terraform.State) error {
+						testAccPostgresqlExec(t, `DO $$ BEGIN `+
+							`IF NOT pg_catalog.has_function_privilege('grant_app', 'grant_add(integer, integer)', 'EXECUTE') `+
+							`OR pg_catalog.has_function_privilege('grant_app', 'grant_sub(integer, integer)', 'EXECUTE') THEN `+
+							`RAISE EXCEPTION 'grant_app must only execute grant_add'; `+
+							`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      "postgresql_grant.public",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// Whether the grant is exclusive is not read.
+				ImportStateVerifyIgnore: []string{"exclusive"},
+			},
+		},
+	})
+}
+
+var testAccPostgresqlGrantFunctionsConfig = `
+resource "postgresql_grant" "public" {
+  role        = "public"
+  schema      = "public"
+  object_type = "function"
+  objects     = ["grant_add(integer, integer)", "grant_sub(int, int)"]
+  privileges  = []
+  exclusive   = true
+}
+
+resource "postgresql_grant" "app" {
+  role        = "grant_app"
+  schema      = "public"
+  object_type = "function"
+  objects     = ["grant_add(int4, int4)"]
+  privileges  = ["EXECUTE"]
+
+  depends_on = [postgresql_grant.public]
+}
+`
+
+func TestAccPostgresqlGrant_Sequences(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP TABLE IF EXISTS grant_orders",
+		"DROP ROLE IF EXISTS grant_writer",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE TABLE grant_orders (id serial, item text)",
+				"CREATE ROLE grant_writer",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlGrantSequencesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.sequences", "privileges.#", "1"),
+					func(*terraform.State) error {
+						testAccPostgresqlExec(t, `DO $$ BEGIN `+
+							`IF NOT pg_catalog.has_sequence_privilege('grant_writer', 'grant_orders_id_seq', 'USAGE') `+
+							`OR pg_catalog.has_sequence_privilege('grant_writer', 'grant_orders_id_seq', 'UPDATE') THEN `+
+							`RAISE EXCEPTION 'grant_writer must only use grant_orders_id_seq'; `+
+							`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlGrantSequencesConfig = `
+resource "postgresql_grant" "sequences" {
+  role        = "grant_writer"
+  schema      = "public"
+  object_type = "sequence"
+  objects     = ["grant_orders_id_seq"]
+  privileges  = ["USAGE"]
+}
+`
+
+func TestAccPostgresqlGrant_Databases(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP DATABASE IF EXISTS grant_tenant",
+		"DROP ROLE IF EXISTS grant_tenant",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE DATABASE grant_tenant",
+				"CREATE ROLE grant_tenant",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlGrantDatabasesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.public", "privileges.#", "0"),
+					resource.TestCheckResourceAttr("postgresql_grant.tenant", "privileges.#", "2"),
+					func(*terraform.State) error {
+						testAccPostgresqlExec(t, `DO $$ BEGIN `+
+							`IF NOT pg_catalog.has_database_privilege('grant_tenant', 'grant_tenant', 'CONNECT') `+
+							`OR pg_catalog.has_database_privilege('grant_tenant', 'grant_tenant', 'CREATE') `+
+							`OR pg_catalog.has_database_privilege('public', 'grant_tenant', 'CONNECT') THEN `+
+							`RAISE EXCEPTION 'only grant_tenant must connect to grant_tenant'; `+
+							`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlGrantDatabasesConfig = `
+resource "postgresql_grant" "public" {
+  role        = "public"
+  object_type = "database"
+  objects     = ["grant_tenant"]
+  privileges  = []
+  exclusive   = true
+}
+
+resource "postgresql_grant" "tenant" {
+  role        = "grant_tenant"
+  object_type = "database"
+  objects     = ["grant_tenant"]
+  privileges  = ["CONNECT", "TEMPORARY"]
+
+  depends_on = [postgresql_grant.public]
+}
+`
+
+func TestAccPostgresqlGrant_Schemas(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP SCHEMA IF EXISTS grant_api",
+		"DROP ROLE IF EXISTS grant_reader",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE SCHEMA grant_api",
+				"CREATE ROLE grant_reader",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlGrantSchemasConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.schemas", "privileges.#", "1"),
+					func(*terraform.State) error {
+						testAccPostgresqlExec(t, `DO $$ BEGIN `+
+							`IF NOT pg_catalog.has_schema_privilege('grant_reader', 'grant_api', 'USAGE') `+
+							`OR pg_catalog.has_schema_privilege('grant_reader', 'grant_api', 'CREATE') THEN `+
+							`RAISE EXCEPTION 'grant_reader must only use grant_api'; `+
+							`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+			{
+				// Revoked outside of Terraform.
+				PreConfig: func() {
+					testAccPostgresqlExec(t, "REVOKE USAGE ON SCHEMA grant_api FROM grant_reader")
+				},
+				Config:             testAccPostgresqlGrantSchemasConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+var testAccPostgresqlGrantSchemasConfig = `
+resource "postgresql_grant" "schemas" {
+  role        = "grant_reader"
+  object_type = "schema"
+  objects     = ["grant_api"]
+  privileges  = ["USAGE"]
+}
+`
+
+func TestAccPostgresqlGrant_Types(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP TYPE IF EXISTS grant_status",
+		"DROP DOMAIN IF EXISTS grant_email",
+		"DROP ROLE IF EXISTS grant_app",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE TYPE grant_status AS ENUM ('new', 'done')",
+				"CREATE DOMAIN grant_email AS text CHECK (VALUE LIKE '%@%')",
+				"REVOKE USAGE ON TYPE grant_status FROM PUBLIC",
+				"REVOKE USAGE ON DOMAIN grant_email FROM PUBLIC",
+				"CREATE ROLE grant_app",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlGrantTypesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.type", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.domain", "privileges.#", "1"),
+					func(*terraform.State) error {
+						testAccPostgresqlExec(t, `DO $$ BEGIN `+
+							`IF NOT pg_catalog.has_type_privilege('grant_app', 'grant_status', 'USAGE') `+
+							`OR NOT pg_catalog.has_type_privilege('grant_app', 'grant_email', 'USAGE') THEN `+
+							`RAISE EXCEPTION 'grant_app must use grant_status and grant_email'; `+
+							`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlGrantTypesConfig = `
+resource "postgresql_grant" "type" {
+  role        = "grant_app"
+  schema      = "public"
+  object_type = "type"
+  objects     = ["grant_status"]
+  privileges  = ["USAGE"]
+}
+
+resource "postgresql_grant" "domain" {
+  role        = "grant_app"
+  schema      = "public"
+  object_type = "domain"
+  objects     = ["grant_email"]
+  privileges  = ["USAGE"]
+}
+`
+
+func TestAccPostgresqlGrant_ForeignServers(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP SERVER IF EXISTS grant_warehouse",
+		"DROP FOREIGN DATA WRAPPER IF EXISTS grant_fdw",
+		"DROP ROLE IF EXISTS grant_etl",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE FOREIGN DATA WRAPPER grant_fdw",
+				"CREATE SERVER grant_warehouse FOREIGN DATA WRAPPER grant_fdw",
+				"CREATE ROLE grant_etl",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlGrantForeignServersConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.fdw", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.server", "privileges.#", "1"),
+					func(*terraform.State) error {
+						testAccPostgresqlExec(t, `DO $$ BEGIN `+
+							`IF NOT pg_catalog.has_foreign_data_wrapper_privilege('grant_etl', 'grant_fdw', 'USAGE') `+
+							`OR NOT pg_catalog.has_server_privilege('grant_etl', 'grant_warehouse', 'USAGE') THEN `+
+							`RAISE EXCEPTION 'grant_etl must use grant_fdw and grant_warehouse'; `+
+							`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlGrantForeignServersConfig = `
+resource "postgresql_grant" "fdw" {
+  role        = "grant_etl"
+  object_type = "foreign_data_wrapper"
+  objects     = ["grant_fdw"]
+  privileges  = ["USAGE"]
+}
+
+resource "postgresql_grant" "server" {
+  role        = "grant_etl"
+  object_type = "foreign_server"
+  objects     = ["grant_warehouse"]
+  privileges  = ["USAGE"]
+}
+`
+
+func TestAccPostgresqlGrant_LargeObjects(t *testing.T) {
+	// The large object is created with a fixed OID, so that the
+	// configuration can refer to it.
+	const oid = "424242"
+	defer testAccPostgresqlExec(t,
+		"SELECT pg_catalog.lo_unlink(oid) FROM pg_catalog.pg_largeobject_metadata WHERE oid = "+oid,
+		"DROP ROLE IF EXISTS grant_archive",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"SELECT pg_catalog.lo_create("+oid+")",
+				"CREATE ROLE grant_archive",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlGrantLargeObjectsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.large_objects", "privileges.#", "1"),
+					func(*terraform.State) error {
+						testAccPostgresqlExec(t, `DO $$ BEGIN `+
+							`IF (SELECT pg_catalog.array_agg(a.privilege_type::TEXT) `+
+							`FROM pg_catalog.pg_largeobject_metadata l, pg_catalog.aclexplode(l.lomacl) AS a `+
+							`WHERE l.oid = `+oid+` AND a.grantee = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = 'grant_archive')) `+
+							`IS DISTINCT FROM ARRAY['SELECT'] THEN `+
+							`RAISE EXCEPTION 'grant_archive must only read the large object'; `+
+							`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlGrantLargeObjectsConfig = `
+resource "postgresql_grant" "large_objects" {
+  role        = "grant_archive"
+  object_type = "large_object"
+  objects     = ["424242"]
+  privileges  = ["SELECT"]
+}
+`
+
+func TestAccPostgresqlGrant_WithGrantOption(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP TABLE IF EXISTS grant_reports",
+		"DROP ROLE IF EXISTS grant_analyst",
+		"DROP ROLE IF EXISTS grant_lead",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE TABLE grant_reports (id int)",
+				"CREATE ROLE grant_lead",
+				"CREATE ROLE grant_analyst",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgresqlGrantWithGrantOptionConfig, `["SELECT"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.lead", "with_grant_option", "true"),
+					func(*terraform.State) error {
+						// The lead delegates SELECT to an analyst, in a single
+						// query to run it on one connection.
+						testAccPostgresqlExec(t, "SET ROLE grant_lead; GRANT SELECT ON grant_reports TO grant_analyst; RESET ROLE")
+						return nil
+					},
+				),
+			},
+			{
+				// Granting another privilege keeps the delegated one.
+				Config: fmt.Sprintf(testAccPostgresqlGrantWithGrantOptionConfig, `["SELECT", "INSERT"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.lead", "privileges.#", "2"),
+					resource.TestCheckResourceAttr("postgresql_grant.lead", "with_grant_option", "true"),
+					func(*terraform.State) error {
+						testAccPostgresqlExec(t, `DO $$ BEGIN `+
+							`IF NOT pg_catalog.has_table_privilege('grant_analyst', 'grant_reports', 'SELECT') THEN `+
+							`RAISE EXCEPTION 'grant_analyst must still select grant_reports'; `+
+							`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlGrantWithGrantOptionConfig = `
+resource "postgresql_grant" "lead" {
+  role              = "grant_lead"
+  schema            = "public"
+  object_type       = "table"
+  objects           = ["grant_reports"]
+  privileges        = %s
+  with_grant_option = true
+}
+`
+
+func TestAccPostgresqlGrant_Exclusive(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP TABLE IF EXISTS grant_invoices",
+		"DROP ROLE IF EXISTS grant_billing",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE TABLE grant_invoices (id int)",
+				"CREATE ROLE grant_billing",
+				"GRANT INSERT ON grant_invoices TO grant_billing",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				// The privileges not configured are kept, and not read.
+				Config: fmt.Sprintf(testAccPostgresqlGrantExclusiveConfig, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.billing", "privileges.#", "1"),
+					func(*terraform.State) error {
+						testAccPostgresqlExec(t, `DO $$ BEGIN `+
+							`IF NOT pg_catalog.has_table_privilege('grant_billing', 'grant_invoices', 'SELECT') `+
+							`OR NOT pg_catalog.has_table_privilege('grant_billing', 'grant_invoices', 'INSERT') THEN `+
+							`RAISE EXCEPTION 'grant_billing must select and insert into grant_invoices'; `+
+							`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlGrantExclusiveConfig, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.billing", "privileges.#", "1"),
+					func(*terraform.State) error {
+						testAccPostgresqlExec(t, `DO $$ BEGIN `+
+							`IF NOT pg_catalog.has_table_privilege('grant_billing', 'grant_invoices', 'SELECT') `+
+							`OR pg_catalog.has_table_privilege('grant_billing', 'grant_invoices', 'INSERT') THEN `+
+							`RAISE EXCEPTION 'grant_billing must only select from grant_invoices'; `+
+							`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+			{
+				// Granted outside of Terraform.
+				PreConfig: func() {
+					testAccPostgresqlExec(t, "GRANT DELETE ON grant_invoices TO grant_billing")
+				},
+				Config:             fmt.Sprintf(testAccPostgresqlGrantExclusiveConfig, true),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+var testAccPostgresqlGrantExclusiveConfig = `
+resource "postgresql_grant" "billing" {
+  role        = "grant_billing"
+  schema      = "public"
+  object_type = "table"
+  objects     = ["grant_invoices"]
+  privileges  = ["SELECT"]
+  exclusive   = %t
+}
+`
@@ -0,0 +1,323 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	hypertableDatabaseAttr          = "database"
+	hypertableSchemaAttr            = "schema"
+	hypertableTableAttr             = "table"
+	hypertableTimeColumnAttr        = "time_column"
+	hypertableChunkTimeIntervalAttr = "chunk_time_interval"
+	hypertableRetentionAttr         = "retention_period"
+	hypertableCompressionAttr       = "compress_after"
+)
+
+// hypertableDimensionQuery selects the time column and the chunk time interval
+// of the hypertable $2 of the schema $1, and whether the interval is $3.
+const hypertableDimensionQuery = `SELECT d.column_name, COALESCE(d.time_interval::TEXT, ''), ` +
+	`COALESCE(d.time_interval = NULLIF($3, '')::INTERVAL, false) ` +
+	`FROM timescaledb_information.dimensions d ` +
+	`WHERE d.hypertable_schema = $1 AND d.hypertable_name = $2 AND d.dimension_number = 1`
+
+// hypertablePolicyQuery selects the interval of the policy of the job $3, e.g.
+// policy_retention, of the hypertable $2 of the schema $1, named $4 in its
+// configuration, and whether it is $5.  No row is selected without a policy.
+const hypertablePolicyQuery = `SELECT j.config->>$4, COALESCE((j.config->>$4)::INTERVAL = NULLIF($5, '')::INTERVAL, false) ` +
+	`FROM timescaledb_information.jobs j ` +
+	`WHERE j.hypertable_schema = $1 AND j.hypertable_name = $2 AND j.proc_name = $3`
+
+// hypertablePolicy is a policy of hypertables, added and removed with the
+// functions of TimescaleDB.  The policies of dropped tables are left alone.
+type hypertablePolicy struct {
+	attr   string
+	proc   string
+	key    string
+	add    string
+	remove string
+}
+
+var hypertablePolicies = []hypertablePolicy{
+	{
+		attr:   hypertableRetentionAttr,
+		proc:   "policy_retention",
+		key:    "drop_after",
+		add:    "SELECT add_retention_policy($1::REGCLASS, $2::INTERVAL, if_not_exists => true)",
+		remove: "SELECT remove_retention_policy(c, if_exists => true) FROM pg_catalog.to_regclass($1) c WHERE c IS NOT NULL",
+	},
+	{
+		attr:   hypertableCompressionAttr,
+		proc:   "policy_compression",
+		key:    "compress_after",
+		add:    "SELECT add_compression_policy($1::REGCLASS, $2::INTERVAL, if_not_exists => true)",
+		remove: "SELECT remove_compression_policy(c, if_exists => true) FROM pg_catalog.to_regclass($1) c WHERE c IS NOT NULL",
+	},
+}
+
+func resourcePostgreSQLHypertable() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourcePostgreSQLHypertableCreate,
+		ReadContext:   resourcePostgreSQLHypertableRead,
+		UpdateContext: resourcePostgreSQLHypertableUpdate,
+		DeleteContext: resourcePostgreSQLHypertableDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePostgreSQLHypertableImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			hypertableDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The database of the table, instead of the provider's database",
+			},
+			hypertableSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "public",
+				Description: "The schema of the table",
+			},
+			hypertableTableAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The table turned into a hypertable",
+			},
+			hypertableTimeColumnAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The time column the rows of the hypertable are partitioned by",
+			},
+			hypertableChunkTimeIntervalAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The interval of time covered by each chunk, e.g. 1 day",
+			},
+			hypertableRetentionAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The age of the chunks dropped by the retention policy, e.g. 90 days. No retention policy if not set",
+			},
+			hypertableCompressionAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The age of the chunks compressed by the compression policy, e.g. 7 days. No compression policy if not set",
+			},
+		},
+	}
+}
+
+// hypertableRelation returns the qualified name of the table of the hypertable,
+// as the functions of TimescaleDB take it.
+func hypertableRelation(d *schema.ResourceData) string {
+	return pq.QuoteIdentifier(d.Get(hypertableSchemaAttr).(string)) + "." + pq.QuoteIdentifier(d.Get(hypertableTableAttr).(string))
+}
+
+func resourcePostgreSQLHypertableCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	database := d.Get(hypertableDatabaseAttr).(string)
+
+	unlock, err := c.lockCatalog("table", c.databaseName(database))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
+
+	db, err := c.DBFor(database, "")
+	if err != nil {
+		return diagnostics(err)
+	}
+
+	relation := hypertableRelation(d)
+	query := "SELECT create_hypertable($1::REGCLASS, $2, if_not_exists => true, migrate_data => true)"
+	args := []interface{}{relation, d.Get(hypertableTimeColumnAttr).(string)}
+	if v, ok := d.GetOk(hypertableChunkTimeIntervalAttr); ok {
+		query = "SELECT create_hypertable($1::REGCLASS, $2, chunk_time_interval => $3::INTERVAL, if_not_exists => true, migrate_data => true)"
+		args = append(args, v.(string))
+	}
+	if _, err := db.Exec(query, args...); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error creating hypertable %s: {{err}}", relation), err))
+	}
+
+	if err := setHypertablePolicies(d, db); err != nil {
+		return diagnostics(err)
+	}
+
+	d.SetId(importID(database, d.Get(hypertableSchemaAttr).(string), d.Get(hypertableTableAttr).(string)))
+
+	return diagnostics(resourcePostgreSQLHypertableReadImpl(d, meta))
+}
+
+func resourcePostgreSQLHypertableUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	database := d.Get(hypertableDatabaseAttr).(string)
+
+	unlock, err := c.lockCatalog("table", c.databaseName(database))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
+
+	db, err := c.DBFor(database, "")
+	if err != nil {
+		return diagnostics(err)
+	}
+
+	relation := hypertableRelation(d)
+	if d.HasChange(hypertableChunkTimeIntervalAttr) {
+		if v, ok := d.GetOk(hypertableChunkTimeIntervalAttr); ok {
+			// Only the chunks created from now on are affected.
+			if _, err := db.Exec("SELECT set_chunk_time_interval($1::REGCLASS, $2::INTERVAL)", relation, v.(string)); err != nil {
+				return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error setting the chunk time interval of hypertable %s: {{err}}", relation), err))
+			}
+		}
+	}
+
+	if err := setHypertablePolicies(d, db); err != nil {
+		return diagnostics(err)
+	}
+
+	return diagnostics(resourcePostgreSQLHypertableReadImpl(d, meta))
+}
+
+// setHypertablePolicies adds, replaces or removes the changed policies of the
+// hypertable.  Compressing chunks requires compression to be enabled on the
+// hypertable, which is left enabled once the policy is removed: disabling it
+// requires decompressing every chunk.
+func setHypertablePolicies(d *schema.ResourceData, db *sql.DB) error {
+	relation := hypertableRelation(d)
+	for _, policy := range hypertablePolicies {
+		if !d.HasChange(policy.attr) {
+			continue
+		}
+
+		if _, err := db.Exec(policy.remove, relation); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error removing the %s of hypertable %s: {{err}}", policy.proc, relation), err)
+		}
+
+		v, ok := d.GetOk(policy.attr)
+		if !ok {
+			continue
+		}
+		if policy.attr == hypertableCompressionAttr {
+			if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s SET (timescaledb.compress)", relation)); err != nil {
+				return errwrap.Wrapf(fmt.Sprintf("Error enabling the compression of hypertable %s: {{err}}", relation), err)
+			}
+		}
+		if _, err := db.Exec(policy.add, relation, v.(string)); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error adding the %s of hypertable %s: {{err}}", policy.proc, relation), err)
+		}
+	}
+
+	return nil
+}
+
+// resourcePostgreSQLHypertableImport sets the table of the hypertable from its
+// ID, database/schema/table, with an empty database for the provider's.
+func resourcePostgreSQLHypertableImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts, err := splitImportID(d.Id(), 3, 3, "database/schema/table")
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set(hypertableDatabaseAttr, parts[0])
+	d.Set(hypertableSchemaAttr, parts[1])
+	d.Set(hypertableTableAttr, parts[2])
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourcePostgreSQLHypertableRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	unlock := c.rlockCatalog("table", c.databaseName(d.Get(hypertableDatabaseAttr).(string)))
+	defer unlock()
+
+	return diagnostics(resourcePostgreSQLHypertableReadImpl(d, meta))
+}
+
+// resourcePostgreSQLHypertableReadImpl reads the hypertable and its policies.
+// The intervals are kept as configured when the server has them, however it
+// prints them, e.g. 24 hours rather than 1 day.
+func resourcePostgreSQLHypertableReadImpl(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	schemaName := d.Get(hypertableSchemaAttr).(string)
+	tableName := d.Get(hypertableTableAttr).(string)
+
+	db, err := c.DBFor(d.Get(hypertableDatabaseAttr).(string), "")
+	if err != nil {
+		return err
+	}
+
+	var timeColumn, interval string
+	var sameInterval bool
+	err = db.QueryRow(hypertableDimensionQuery, schemaName, tableName, d.Get(hypertableChunkTimeIntervalAttr).(string)).Scan(&timeColumn, &interval, &sameInterval)
+	switch {
+	case err == sql.ErrNoRows:
+		logEvent("WARN", "TimescaleDB hypertable not found", logFields{"schema": schemaName, "table": tableName})
+		d.SetId("")
+		return nil
+	case err != nil:
+		return errwrap.Wrapf(fmt.Sprintf("Error reading hypertable %s.%s: {{err}}", schemaName, tableName), err)
+	}
+
+	d.Set(hypertableTimeColumnAttr, timeColumn)
+	if !sameInterval {
+		d.Set(hypertableChunkTimeIntervalAttr, interval)
+	}
+
+	for _, policy := range hypertablePolicies {
+		var after string
+		var same bool
+		err := db.QueryRow(hypertablePolicyQuery, schemaName, tableName, policy.proc, policy.key, d.Get(policy.attr).(string)).Scan(&after, &same)
+		switch {
+		case err == sql.ErrNoRows:
+			d.Set(policy.attr, "")
+		case err != nil:
+			return errwrap.Wrapf(fmt.Sprintf("Error reading the %s of hypertable %s.%s: {{err}}", policy.proc, schemaName, tableName), err)
+		case !same:
+			d.Set(policy.attr, after)
+		}
+	}
+
+	return nil
+}
+
+// resourcePostgreSQLHypertableDelete removes the policies of the hypertable.
+// The table stays a hypertable, which TimescaleDB can not undo.
+func resourcePostgreSQLHypertableDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	database := d.Get(hypertableDatabaseAttr).(string)
+
+	unlock, err := c.lockCatalog("table", c.databaseName(database))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
+
+	db, err := c.DBFor(database, "")
+	if err != nil {
+		return diagnostics(err)
+	}
+
+	relation := hypertableRelation(d)
+	for _, policy := range hypertablePolicies {
+		if _, err := db.Exec(policy.remove, relation); err != nil {
+			return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error removing the %s of hypertable %s: {{err}}", policy.proc, relation), err))
+		}
+	}
+
+	d.SetId("")
+
+	return nil
+}
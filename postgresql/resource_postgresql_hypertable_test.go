@@ -0,0 +1,72 @@
+package postgresql
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlHypertable_Basic(t *testing.T) {
+	// TimescaleDB is preloaded by the server, so only servers set up for it
+	// can run the test.
+	if os.Getenv("TF_ACC_TIMESCALEDB") == "" {
+		t.Skip("TF_ACC_TIMESCALEDB must be set for TimescaleDB acceptance tests")
+	}
+
+	defer testAccPostgresqlExec(t, "DROP TABLE IF EXISTS hypertable_metrics")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE EXTENSION IF NOT EXISTS timescaledb",
+				"CREATE TABLE hypertable_metrics (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION)",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlHypertableConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_hypertable.metrics", "time_column", "time"),
+					resource.TestCheckResourceAttr("postgresql_hypertable.metrics", "chunk_time_interval", "24 hours"),
+					resource.TestCheckResourceAttr("postgresql_hypertable.metrics", "retention_period", "90 days"),
+					resource.TestCheckResourceAttr("postgresql_hypertable.metrics", "compress_after", ""),
+				),
+			},
+			{
+				Config: testAccPostgresqlHypertableUpdateConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_hypertable.metrics", "chunk_time_interval", "7 days"),
+					resource.TestCheckResourceAttr("postgresql_hypertable.metrics", "retention_period", ""),
+					resource.TestCheckResourceAttr("postgresql_hypertable.metrics", "compress_after", "14 days"),
+				),
+			},
+			{
+				ResourceName:      "postgresql_hypertable.metrics",
+				ImportState:       true,
+				ImportStateId:     "/public/hypertable_metrics",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+var testAccPostgresqlHypertableConfig = `
+resource "postgresql_hypertable" "metrics" {
+  table               = "hypertable_metrics"
+  time_column         = "time"
+  chunk_time_interval = "24 hours"
+  retention_period    = "90 days"
+}
+`
+
+var testAccPostgresqlHypertableUpdateConfig = `
+resource "postgresql_hypertable" "metrics" {
+  table               = "hypertable_metrics"
+  time_column         = "time"
+  chunk_time_interval = "7 days"
+  compress_after      = "14 days"
+}
+`
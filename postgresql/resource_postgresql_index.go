@@ -0,0 +1,307 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	indexDatabaseAttr = "database"
+	indexSchemaAttr   = "schema"
+	indexTableAttr    = "table"
+	indexNameAttr     = "name"
+	indexMethodAttr   = "method"
+	indexUniqueAttr   = "unique"
+	indexColumnAttr   = "column"
+	indexOptionsAttr  = "options"
+
+	indexColumnNameAttr    = "name"
+	indexColumnOpClassAttr = "operator_class"
+)
+
+// indexQuery selects the table, access method, uniqueness, key columns with
+// their operator classes and the storage parameters of the index $2 of the
+// schema $1.
+const indexQuery = `SELECT tc.relname, am.amname, i.indisunique, ` +
+	`ARRAY(SELECT a.attname FROM pg_catalog.generate_subscripts(i.indclass::OID[], 1) s ` +
+	`JOIN pg_catalog.pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = (i.indkey::INT2[])[s] ORDER BY s), ` +
+	`ARRAY(SELECT opc.opcname FROM pg_catalog.generate_subscripts(i.indclass::OID[], 1) s ` +
+	`JOIN pg_catalog.pg_opclass opc ON opc.oid = (i.indclass::OID[])[s] ORDER BY s), ` +
+	`COALESCE(ic.reloptions, '{}'::TEXT[]) ` +
+	`FROM pg_catalog.pg_index i ` +
+	`JOIN pg_catalog.pg_class ic ON ic.oid = i.indexrelid ` +
+	`JOIN pg_catalog.pg_class tc ON tc.oid = i.indrelid ` +
+	`JOIN pg_catalog.pg_namespace n ON n.oid = ic.relnamespace ` +
+	`JOIN pg_catalog.pg_am am ON am.oid = ic.relam ` +
+	`WHERE n.nspname = $1 AND ic.relname = $2`
+
+func resourcePostgreSQLIndex() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourcePostgreSQLIndexCreate,
+		ReadContext:   resourcePostgreSQLIndexRead,
+		DeleteContext: resourcePostgreSQLIndexDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePostgreSQLIndexImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			indexDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The database of the table, instead of the provider's database",
+			},
+			indexSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "public",
+				Description: "The schema of the table and the index",
+			},
+			indexTableAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The table the index is on",
+			},
+			indexNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the index",
+			},
+			indexMethodAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "btree",
+				Description: "The access method of the index, e.g. btree, gin, or ivfflat and hnsw with pgvector",
+			},
+			indexUniqueAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Whether the index only allows distinct values",
+			},
+			indexColumnAttr: {
+				Type:     schema.TypeList,
+				Required: true,
+				ForceNew: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						indexColumnNameAttr: {
+							Type:        schema.TypeString,
+							Required:    true,
+							ForceNew:    true,
+							Description: "The indexed column",
+						},
+						indexColumnOpClassAttr: {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Computed:    true,
+							ForceNew:    true,
+							Description: "The operator class of the column, e.g. vector_cosine_ops, instead of the default one of its type",
+						},
+					},
+				},
+			},
+			indexOptionsAttr: {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The storage parameters of the index, e.g. lists or m and ef_construction",
+			},
+		},
+	}
+}
+
+// indexRelation returns the qualified name of the index.
+func indexRelation(d *schema.ResourceData) string {
+	return pq.QuoteIdentifier(d.Get(indexSchemaAttr).(string)) + "." + pq.QuoteIdentifier(d.Get(indexNameAttr).(string))
+}
+
+// indexCreateQuery returns the CREATE INDEX statement of the index, with the
+// storage parameters in the order of their names.
+func indexCreateQuery(d *schema.ResourceData) string {
+	var columns []string
+	for _, column := range d.Get(indexColumnAttr).([]interface{}) {
+		column := column.(map[string]interface{})
+		definition := pq.QuoteIdentifier(column[indexColumnNameAttr].(string))
+		if opClass := column[indexColumnOpClassAttr].(string); opClass != "" {
+			definition += " " + pq.QuoteIdentifier(opClass)
+		}
+		columns = append(columns, definition)
+	}
+
+	unique := ""
+	if d.Get(indexUniqueAttr).(bool) {
+		unique = "UNIQUE "
+	}
+	query := fmt.Sprintf("CREATE %sINDEX %s ON %s.%s USING %s (%s)", unique,
+		pq.QuoteIdentifier(d.Get(indexNameAttr).(string)),
+		pq.QuoteIdentifier(d.Get(indexSchemaAttr).(string)),
+		pq.QuoteIdentifier(d.Get(indexTableAttr).(string)),
+		pq.QuoteIdentifier(d.Get(indexMethodAttr).(string)),
+		strings.Join(columns, ", "))
+
+	options := d.Get(indexOptionsAttr).(map[string]interface{})
+	if len(options) > 0 {
+		names := make([]string, 0, len(options))
+		for name := range options {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		params := make([]string, 0, len(names))
+		for _, name := range names {
+			params = append(params, fmt.Sprintf("%s = '%s'", pq.QuoteIdentifier(name), pqQuoteLiteral(options[name].(string))))
+		}
+		query += fmt.Sprintf(" WITH (%s)", strings.Join(params, ", "))
+	}
+
+	return query
+}
+
+func resourcePostgreSQLIndexCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	database := d.Get(indexDatabaseAttr).(string)
+
+	unlock, err := c.lockCatalog("table", c.databaseName(database))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
+
+	db, err := c.DBFor(database, "")
+	if err != nil {
+		return diagnostics(err)
+	}
+
+	if _, err := db.Exec(indexCreateQuery(d)); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error creating index %s: {{err}}", indexRelation(d)), err))
+	}
+
+	d.SetId(importID(database, d.Get(indexSchemaAttr).(string), d.Get(indexNameAttr).(string)))
+
+	return diagnostics(resourcePostgreSQLIndexReadImpl(d, meta))
+}
+
+// resourcePostgreSQLIndexImport sets the index from its ID,
+// database/schema/name, with an empty database for the provider's.  The table
+// is read back from the index.
+func resourcePostgreSQLIndexImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts, err := splitImportID(d.Id(), 3, 3, "database/schema/name")
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set(indexDatabaseAttr, parts[0])
+	d.Set(indexSchemaAttr, parts[1])
+	d.Set(indexNameAttr, parts[2])
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourcePostgreSQLIndexRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	unlock := c.rlockCatalog("table", c.databaseName(d.Get(indexDatabaseAttr).(string)))
+	defer unlock()
+
+	return diagnostics(resourcePostgreSQLIndexReadImpl(d, meta))
+}
+
+// resourcePostgreSQLIndexReadImpl reads the definition of the index.  Only
+// the key columns are read: expressions have no column name.
+func resourcePostgreSQLIndexReadImpl(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	relation := indexRelation(d)
+
+	db, err := c.DBFor(d.Get(indexDatabaseAttr).(string), "")
+	if err != nil {
+		return err
+	}
+
+	var table, method string
+	var unique bool
+	var columnNames, opClasses, options []string
+	err = db.QueryRow(indexQuery, d.Get(indexSchemaAttr).(string), d.Get(indexNameAttr).(string)).Scan(
+		&table, &method, &unique, pq.Array(&columnNames), pq.Array(&opClasses), pq.Array(&options))
+	switch {
+	case err == sql.ErrNoRows:
+		logEvent("WARN", "PostgreSQL index not found", logFields{"index": relation})
+		d.SetId("")
+		return nil
+	case err != nil:
+		return errwrap.Wrapf(fmt.Sprintf("Error reading index %s: {{err}}", relation), err)
+	}
+
+	columns := make([]interface{}, 0, len(columnNames))
+	for i, name := range columnNames {
+		column := map[string]interface{}{indexColumnNameAttr: name}
+		if i < len(opClasses) {
+			column[indexColumnOpClassAttr] = opClasses[i]
+		}
+		columns = append(columns, column)
+	}
+
+	d.Set(indexTableAttr, table)
+	d.Set(indexMethodAttr, method)
+	d.Set(indexUniqueAttr, unique)
+	if err := d.Set(indexColumnAttr, columns); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error setting the columns of index %s: {{err}}", relation), err)
+	}
+	if err := d.Set(indexOptionsAttr, parseIndexOptions(options)); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error setting the options of index %s: {{err}}", relation), err)
+	}
+
+	return nil
+}
+
+// parseIndexOptions returns the storage parameters stored as name=value
+// strings by name.
+func parseIndexOptions(options []string) map[string]interface{} {
+	m := make(map[string]interface{}, len(options))
+	for _, option := range options {
+		if i := strings.Index(option, "="); i >= 0 {
+			m[option[:i]] = option[i+1:]
+		}
+	}
+
+	return m
+}
+
+func resourcePostgreSQLIndexDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	database := d.Get(indexDatabaseAttr).(string)
+
+	unlock, err := c.lockCatalog("table", c.databaseName(database))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
+
+	db, err := c.DBFor(database, "")
+	if err != nil {
+		return diagnostics(err)
+	}
+
+	relation := indexRelation(d)
+	if _, err := db.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s", relation)); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error dropping index %s: {{err}}", relation), err))
+	}
+
+	d.SetId("")
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package postgresql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestIndexCreateQuery(t *testing.T) {
+	tests := []struct {
+		config   map[string]interface{}
+		expected string
+	}{
+		{
+			config: map[string]interface{}{
+				"column": []interface{}{map[string]interface{}{"name": "email"}},
+				"unique": true,
+			},
+			expected: `CREATE UNIQUE INDEX "items_idx" ON "public"."items" USING "btree" ("email")`,
+		},
+		{
+			config: map[string]interface{}{
+				"schema":  "app",
+				"method":  "hnsw",
+				"column":  []interface{}{map[string]interface{}{"name": "embedding", "operator_class": "vector_cosine_ops"}},
+				"options": map[string]interface{}{"m": "16", "ef_construction": "64"},
+			},
+			expected: `CREATE INDEX "items_idx" ON "app"."items" USING "hnsw" ("embedding" "vector_cosine_ops") WITH ("ef_construction" = '64', "m" = '16')`,
+		},
+	}
+
+	for _, test := range tests {
+		test.config["table"] = "items"
+		test.config["name"] = "items_idx"
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLIndex().Schema, test.config)
+		if query := indexCreateQuery(d); query != test.expected {
+			t.Errorf("%v: expected %q, got %q", test.config, test.expected, query)
+		}
+	}
+}
+
+func TestParseIndexOptions(t *testing.T) {
+	options := parseIndexOptions([]string{"lists=100", "fillfactor=70"})
+	expected := map[string]interface{}{"lists": "100", "fillfactor": "70"}
+	if !reflect.DeepEqual(options, expected) {
+		t.Errorf("expected %v, got %v", expected, options)
+	}
+}
+
+func TestAccPostgresqlIndex_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t, "DROP TABLE IF EXISTS index_items")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t, "CREATE TABLE index_items (id BIGINT, email TEXT)")
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlIndexConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_index.email", "method", "btree"),
+					resource.TestCheckResourceAttr("postgresql_index.email", "column.0.name", "email"),
+					resource.TestCheckResourceAttr("postgresql_index.email", "column.0.operator_class", "text_ops"),
+					resource.TestCheckResourceAttr("postgresql_index.email", "options.fillfactor", "70"),
+				),
+			},
+			{
+				ResourceName:      "postgresql_index.email",
+				ImportState:       true,
+				ImportStateId:     "/public/index_items_email",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+const testAccPostgresqlIndexConfig = `
+resource "postgresql_index" "email" {
+  table  = "index_items"
+  name   = "index_items_email"
+  unique = true
+
+  column {
+    name = "email"
+  }
+
+  options = {
+    fillfactor = "70"
+  }
+}
+`
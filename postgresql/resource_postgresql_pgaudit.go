@@ -0,0 +1,246 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	pgauditDatabaseAttr  = "database"
+	pgauditRoleAttr      = "role"
+	pgauditLogAttr       = "log"
+	pgauditAuditRoleAttr = "audit_role"
+
+	pgauditLogParam  = "pgaudit.log"
+	pgauditRoleParam = "pgaudit.role"
+)
+
+// pgauditLogClasses are the classes of statements pgaudit.log can log, each of
+// which can be excluded with a minus sign, e.g. all, -misc.
+var pgauditLogClasses = []string{"read", "write", "function", "role", "ddl", "misc", "misc_set", "all", "none"}
+
+// pgauditQuery selects whether the role $1 and the database $2 exist, or true
+// when empty, and the settings of the role in the database, with setrole = 0
+// for every role and setdatabase = 0 for every database.
+const pgauditQuery = `SELECT $1::TEXT = '' OR EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = $1), ` +
+	`$2::TEXT = '' OR EXISTS (SELECT 1 FROM pg_catalog.pg_database WHERE datname = $2), ` +
+	`COALESCE((SELECT rs.setconfig FROM pg_catalog.pg_db_role_setting rs ` +
+	`WHERE rs.setrole = CASE WHEN $1 = '' THEN 0 ELSE (SELECT r.oid FROM pg_catalog.pg_roles r WHERE r.rolname = $1) END ` +
+	`AND rs.setdatabase = CASE WHEN $2 = '' THEN 0 ELSE (SELECT d.oid FROM pg_catalog.pg_database d WHERE d.datname = $2) END), '{}')`
+
+func resourcePostgreSQLPgAudit() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourcePostgreSQLPgAuditCreate,
+		ReadContext:   resourcePostgreSQLPgAuditRead,
+		UpdateContext: resourcePostgreSQLPgAuditUpdate,
+		DeleteContext: resourcePostgreSQLPgAuditDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePostgreSQLPgAuditImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			pgauditDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The database the settings apply in, instead of every database",
+			},
+			pgauditRoleAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The role the settings apply to, instead of every role",
+			},
+			pgauditLogAttr: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validatePgAuditLogClass},
+				Description: "The classes of statements logged by session auditing, in order, e.g. all and -misc",
+			},
+			pgauditAuditRoleAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The role whose privileges select the statements logged by object auditing",
+			},
+		},
+	}
+}
+
+func validatePgAuditLogClass(v interface{}, key string) (warnings []string, errors []error) {
+	class := strings.TrimPrefix(v.(string), "-")
+	if !stringInSlice(class, pgauditLogClasses) {
+		errors = append(errors, fmt.Errorf("%s must be one of %s, optionally prefixed with -, got %q", key, strings.Join(pgauditLogClasses, ", "), v.(string)))
+	}
+	return
+}
+
+// pgauditAlterPrefix returns the ALTER ROLE ... [IN DATABASE ...], or ALTER
+// DATABASE for every role, the settings are altered with.
+func pgauditAlterPrefix(role, database string) string {
+	if role == "" {
+		return fmt.Sprintf("ALTER DATABASE %s", pq.QuoteIdentifier(database))
+	}
+	return roleSettingAlterPrefix(role, database)
+}
+
+// pgauditQueries returns the queries setting the pgaudit parameters of the
+// resource, or resetting the empty ones.
+func pgauditQueries(d *schema.ResourceData) []string {
+	prefix := pgauditAlterPrefix(d.Get(pgauditRoleAttr).(string), d.Get(pgauditDatabaseAttr).(string))
+	query := func(param, value string) string {
+		if value == "" {
+			return fmt.Sprintf("%s RESET %s", prefix, pq.QuoteIdentifier(param))
+		}
+		return fmt.Sprintf("%s SET %s TO '%s'", prefix, pq.QuoteIdentifier(param), pqQuoteLiteral(value))
+	}
+
+	return []string{
+		query(pgauditLogParam, strings.Join(interfaceStrings(d.Get(pgauditLogAttr).([]interface{})), ", ")),
+		query(pgauditRoleParam, d.Get(pgauditAuditRoleAttr).(string)),
+	}
+}
+
+func resourcePostgreSQLPgAuditCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if d.Get(pgauditRoleAttr).(string) == "" && d.Get(pgauditDatabaseAttr).(string) == "" {
+		return diagnostics(fmt.Errorf("Error setting pgaudit: %s, %s or both are required", pgauditDatabaseAttr, pgauditRoleAttr))
+	}
+
+	if err := resourcePostgreSQLPgAuditAlter(d, meta); err != nil {
+		return diagnostics(err)
+	}
+
+	d.SetId(importID(d.Get(pgauditDatabaseAttr).(string), d.Get(pgauditRoleAttr).(string)))
+
+	return diagnostics(resourcePostgreSQLPgAuditReadImpl(d, meta))
+}
+
+func resourcePostgreSQLPgAuditUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if err := resourcePostgreSQLPgAuditAlter(d, meta); err != nil {
+		return diagnostics(err)
+	}
+
+	return diagnostics(resourcePostgreSQLPgAuditReadImpl(d, meta))
+}
+
+// resourcePostgreSQLPgAuditAlter sets the pgaudit parameters in a transaction.
+// They can only be set by superusers, or rds_superuser on RDS.
+func resourcePostgreSQLPgAuditAlter(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+
+	unlock, err := c.lockCatalog("role", "")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return c.inTxn(c.DB(), "pgaudit settings", func(txn *sql.Tx) error {
+		for _, query := range pgauditQueries(d) {
+			if _, err := txn.Exec(query); err != nil {
+				return errwrap.Wrapf("Error setting pgaudit: {{err}}", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// resourcePostgreSQLPgAuditImport sets the scope from the ID, database/role,
+// with an empty database or role for every one.
+func resourcePostgreSQLPgAuditImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts, err := splitImportID(d.Id(), 2, 2, "database/role")
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set(pgauditDatabaseAttr, parts[0])
+	d.Set(pgauditRoleAttr, parts[1])
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourcePostgreSQLPgAuditRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	unlock := c.rlockCatalog("role", "")
+	defer unlock()
+
+	return diagnostics(resourcePostgreSQLPgAuditReadImpl(d, meta))
+}
+
+func resourcePostgreSQLPgAuditReadImpl(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	role := d.Get(pgauditRoleAttr).(string)
+	database := d.Get(pgauditDatabaseAttr).(string)
+
+	var roleExists, dbExists bool
+	var config pq.StringArray
+	if err := c.DB().QueryRow(pgauditQuery, role, database).Scan(&roleExists, &dbExists, &config); err != nil {
+		return errwrap.Wrapf("Error reading pgaudit settings: {{err}}", err)
+	}
+	if !roleExists || !dbExists {
+		logEvent("WARN", "PostgreSQL role or database of pgaudit settings not found", logFields{"role": role, "database": database})
+		d.SetId("")
+		return nil
+	}
+
+	log, auditRole := parsePgAuditSettings(config)
+	d.Set(pgauditLogAttr, log)
+	d.Set(pgauditAuditRoleAttr, auditRole)
+
+	return nil
+}
+
+// parsePgAuditSettings returns the classes of pgaudit.log and the role of
+// pgaudit.role among settings stored as name=value strings.
+func parsePgAuditSettings(config []string) ([]string, string) {
+	var log []string
+	var auditRole string
+	for _, setting := range config {
+		i := strings.Index(setting, "=")
+		if i < 0 {
+			continue
+		}
+
+		switch name, value := setting[:i], setting[i+1:]; name {
+		case pgauditLogParam:
+			for _, class := range strings.Split(value, ",") {
+				if class = strings.ToLower(strings.TrimSpace(class)); class != "" {
+					log = append(log, class)
+				}
+			}
+		case pgauditRoleParam:
+			auditRole = value
+		}
+	}
+
+	return log, auditRole
+}
+
+// resourcePostgreSQLPgAuditDelete resets the pgaudit parameters, but not the
+// other settings of the scope.
+func resourcePostgreSQLPgAuditDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+
+	unlock, err := c.lockCatalog("role", "")
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
+
+	prefix := pgauditAlterPrefix(d.Get(pgauditRoleAttr).(string), d.Get(pgauditDatabaseAttr).(string))
+	for _, param := range []string{pgauditLogParam, pgauditRoleParam} {
+		if _, err := c.DB().Exec(fmt.Sprintf("%s RESET %s", prefix, pq.QuoteIdentifier(param))); err != nil {
+			return diagnostics(errwrap.Wrapf("Error resetting pgaudit: {{err}}", err))
+		}
+	}
+
+	d.SetId("")
+
+	return nil
+}
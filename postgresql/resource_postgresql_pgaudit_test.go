@@ -0,0 +1,133 @@
+package postgresql
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestPgAuditQueries(t *testing.T) {
+	tests := []struct {
+		raw     map[string]interface{}
+		queries []string
+	}{
+		{
+			raw: map[string]interface{}{
+				pgauditDatabaseAttr: "shop",
+				pgauditLogAttr:      []interface{}{"all", "-misc"},
+			},
+			queries: []string{
+				`ALTER DATABASE "shop" SET "pgaudit.log" TO 'all, -misc'`,
+				`ALTER DATABASE "shop" RESET "pgaudit.role"`,
+			},
+		},
+		{
+			raw: map[string]interface{}{
+				pgauditDatabaseAttr:  "shop",
+				pgauditRoleAttr:      "app",
+				pgauditAuditRoleAttr: "auditor",
+			},
+			queries: []string{
+				`ALTER ROLE "app" IN DATABASE "shop" RESET "pgaudit.log"`,
+				`ALTER ROLE "app" IN DATABASE "shop" SET "pgaudit.role" TO 'auditor'`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLPgAudit().Schema, test.raw)
+		if queries := pgauditQueries(d); !reflect.DeepEqual(queries, test.queries) {
+			t.Errorf("%v: expected %q, got %q", test.raw, test.queries, queries)
+		}
+	}
+}
+
+func TestParsePgAuditSettings(t *testing.T) {
+	log, auditRole := parsePgAuditSettings([]string{
+		"work_mem=64MB",
+		"pgaudit.log=DDL, role,-misc",
+		"pgaudit.role=auditor",
+	})
+	if expected := []string{"ddl", "role", "-misc"}; !reflect.DeepEqual(log, expected) {
+		t.Errorf("expected %q, got %q", expected, log)
+	}
+	if auditRole != "auditor" {
+		t.Errorf("expected auditor, got %q", auditRole)
+	}
+}
+
+func TestValidatePgAuditLogClass(t *testing.T) {
+	for _, class := range []string{"read", "-misc_set", "all"} {
+		if _, errs := validatePgAuditLogClass(class, "log"); len(errs) != 0 {
+			t.Errorf("%s: unexpected errors %v", class, errs)
+		}
+	}
+	for _, class := range []string{"select", "--ddl", "DDL"} {
+		if _, errs := validatePgAuditLogClass(class, "log"); len(errs) == 0 {
+			t.Errorf("%s: expected an error", class)
+		}
+	}
+}
+
+func TestAccPostgresqlPgAudit_Basic(t *testing.T) {
+	// pgaudit.* can only be set with pgaudit loaded, from
+	// shared_preload_libraries, so only servers set up for it can run the
+	// test.
+	if os.Getenv("TF_ACC_PGAUDIT") == "" {
+		t.Skip("TF_ACC_PGAUDIT must be set for pgAudit acceptance tests")
+	}
+
+	defer testAccPostgresqlExec(t,
+		"DROP DATABASE IF EXISTS pgaudit_db",
+		"DROP ROLE IF EXISTS pgaudit_app",
+		"DROP ROLE IF EXISTS pgaudit_auditor",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE ROLE pgaudit_app",
+				"CREATE ROLE pgaudit_auditor",
+				"CREATE DATABASE pgaudit_db",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlPgAuditConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_pgaudit.db", "log.#", "2"),
+					resource.TestCheckResourceAttr("postgresql_pgaudit.db", "log.0", "ddl"),
+					resource.TestCheckResourceAttr("postgresql_pgaudit.db", "log.1", "role"),
+					resource.TestCheckResourceAttr("postgresql_pgaudit.app", "log.#", "2"),
+					resource.TestCheckResourceAttr("postgresql_pgaudit.app", "log.1", "-misc"),
+					resource.TestCheckResourceAttr("postgresql_pgaudit.app", "audit_role", "pgaudit_auditor"),
+				),
+			},
+			{
+				ResourceName:      "postgresql_pgaudit.app",
+				ImportState:       true,
+				ImportStateId:     "pgaudit_db/pgaudit_app",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+var testAccPostgresqlPgAuditConfig = `
+resource "postgresql_pgaudit" "db" {
+  database = "pgaudit_db"
+  log      = ["ddl", "role"]
+}
+
+resource "postgresql_pgaudit" "app" {
+  database   = "pgaudit_db"
+  role       = "pgaudit_app"
+  log        = ["all", "-misc"]
+  audit_role = "pgaudit_auditor"
+}
+`
@@ -1,46 +1,89 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
+	"regexp"
 	"strings"
 
 	"github.com/hashicorp/errwrap"
-	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/lib/pq"
 )
 
 const (
-	roleBypassRLSAttr         = "bypass_row_level_security"
-	roleConnLimitAttr         = "connection_limit"
-	roleCreateDBAttr          = "create_database"
-	roleCreateRoleAttr        = "create_role"
-	roleEncryptedPassAttr     = "encrypted_password"
-	roleInheritAttr           = "inherit"
-	roleLoginAttr             = "login"
-	roleNameAttr              = "name"
-	rolePasswordAttr          = "password"
-	roleReplicationAttr       = "replication"
-	roleSkipDropRoleAttr      = "skip_drop_role"
-	roleSkipReassignOwnedAttr = "skip_reassign_owned"
-	roleSuperuserAttr         = "superuser"
-	roleValidUntilAttr        = "valid_until"
+	roleBypassRLSAttr          = "bypass_row_level_security"
+	roleConnLimitAttr          = "connection_limit"
+	roleCreateDBAttr           = "create_database"
+	roleCreateRoleAttr         = "create_role"
+	roleDropOwnedAttr          = "drop_owned"
+	roleEncryptedPassAttr      = "encrypted_password"
+	roleGeneratePasswordAttr   = "generate_password"
+	roleGeneratedPassAttr      = "generated_password"
+	roleIdleInTxnTimeoutAttr   = "idle_in_transaction_session_timeout"
+	roleIdleSessionTimeoutAttr = "idle_session_timeout"
+	roleIgnorePasswordAttr     = "ignore_password_changes"
+	roleInheritAttr            = "inherit"
+	roleLoginAttr              = "login"
+	roleNameAttr               = "name"
+	rolePasswordAttr           = "password"
+	roleReassignOwnedToAttr    = "reassign_owned_to"
+	roleReplicationAttr        = "replication"
+	roleRolesAttr              = "roles"
+	roleRotationKeepersAttr    = "rotation_keepers"
+	roleSkipDropRoleAttr       = "skip_drop_role"
+	roleSkipReassignOwnedAttr  = "skip_reassign_owned"
+	roleStatementTimeoutAttr   = "statement_timeout"
+	roleSuperuserAttr          = "superuser"
+	roleValidUntilAttr         = "valid_until"
 
 	// Deprecated options
 	roleDepEncryptedAttr = "encrypted"
 )
 
+// roleSettingAttrs are the attributes of roles set with ALTER ROLE ... SET in
+// every database, named after their configuration parameters.
+var roleSettingAttrs = []string{
+	roleIdleInTxnTimeoutAttr,
+	roleIdleSessionTimeoutAttr,
+	roleStatementTimeoutAttr,
+}
+
+// roleAttrFeatures are the features the attributes of roles require, if any.
+var roleAttrFeatures = map[string]featureName{
+	roleBypassRLSAttr:          featureRLS,
+	roleIdleInTxnTimeoutAttr:   featureIdleInTransactionSessionTimeout,
+	roleIdleSessionTimeoutAttr: featureIdleSessionTimeout,
+}
+
+// checkRoleFeatures returns an error if the role has attributes, or a
+// password verifier, the server does not support.
+func checkRoleFeatures(c *Client, d attrChanges) error {
+	if err := checkAttrFeatures(c, d, roleAttrFeatures); err != nil {
+		return err
+	}
+
+	password := d.Get(rolePasswordAttr).(string)
+	if d.HasChange(rolePasswordAttr) && passwordVerifier(password) == "scram-sha-256" && !c.featureSupported(featureSCRAM) {
+		return &attrError{attr: rolePasswordAttr, err: c.featureError(featureSCRAM, "SCRAM-SHA-256 password verifiers")}
+	}
+
+	return nil
+}
+
 func resourcePostgreSQLRole() *schema.Resource {
 	return &schema.Resource{
-		Create: resourcePostgreSQLRoleCreate,
-		Read:   resourcePostgreSQLRoleRead,
-		Update: resourcePostgreSQLRoleUpdate,
-		Delete: resourcePostgreSQLRoleDelete,
-		Exists: resourcePostgreSQLRoleExists,
+		CreateContext: resourcePostgreSQLRoleCreate,
+		ReadContext:   resourcePostgreSQLRoleRead,
+		UpdateContext: resourcePostgreSQLRoleUpdate,
+		DeleteContext: resourcePostgreSQLRoleDelete,
+		Exists:        resourcePostgreSQLRoleExists,
+		CustomizeDiff: planFeatures(checkRoleFeatures),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -57,6 +100,30 @@ func resourcePostgreSQLRole() *schema.Resource {
 				DefaultFunc: schema.EnvDefaultFunc("PGPASSWORD", nil),
 				Description: "Sets the role's password",
 			},
+			roleGeneratePasswordAttr: {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{rolePasswordAttr},
+				Description:   "Generate a random password for the role, exposed as generated_password",
+			},
+			roleGeneratedPassAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The password generated for the role with generate_password",
+			},
+			roleIgnorePasswordAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Do not read the role's password, so that changes made outside of Terraform, e.g. rotations, are not reverted",
+			},
+			roleRotationKeepersAttr: {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Arbitrary values whose change sets the role's password again, generating a new one with generate_password",
+			},
 			roleDepEncryptedAttr: {
 				Type:       schema.TypeString,
 				Optional:   true,
@@ -135,14 +202,56 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Default:     false,
 				Description: "Skip actually running the REASSIGN OWNED command when removing a role from PostgreSQL",
 			},
+			roleReassignOwnedToAttr: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "The role the objects owned by the role are reassigned to in every database when removing it",
+				ConflictsWith: []string{roleSkipReassignOwnedAttr},
+			},
+			roleRolesAttr: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The roles the role is a member of, instead of postgresql_grant_role resources",
+			},
+			roleStatementTimeoutAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The statement_timeout of the role's sessions, e.g. 30s",
+			},
+			roleIdleInTxnTimeoutAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The idle_in_transaction_session_timeout of the role's sessions, e.g. 5min",
+			},
+			roleIdleSessionTimeoutAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The idle_session_timeout of the role's sessions, e.g. 1h",
+			},
+			roleDropOwnedAttr: {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				Description:   "Drop the objects owned by the role, and the privileges granted to it, in every database when removing it",
+				ConflictsWith: []string{roleSkipReassignOwnedAttr},
+			},
 		},
 	}
 }
 
-func resourcePostgreSQLRoleCreate(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLRoleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.Lock()
-	defer c.catalogLock.Unlock()
+	if err := checkRoleFeatures(c, d); err != nil {
+		return diagnostics(err)
+	}
+
+	unlock, err := c.lockCatalog("role", "")
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
 
 	stringOpts := []struct {
 		hclKey string
@@ -164,7 +273,8 @@ func resourcePostgreSQLRoleCreate(d *schema.ResourceData, meta interface{}) erro
 		sqlKeyDisable string
 	}
 	boolOpts := []boolOptType{
-		{roleSuperuserAttr, "CREATEDB", "NOCREATEDB"},
+		{roleSuperuserAttr, "SUPERUSER", "NOSUPERUSER"},
+		{roleCreateDBAttr, "CREATEDB", "NOCREATEDB"},
 		{roleCreateRoleAttr, "CREATEROLE", "NOCREATEROLE"},
 		{roleInheritAttr, "INHERIT", "NOINHERIT"},
 		{roleLoginAttr, "LOGIN", "NOLOGIN"},
@@ -182,6 +292,11 @@ func resourcePostgreSQLRoleCreate(d *schema.ResourceData, meta interface{}) erro
 
 	for _, opt := range stringOpts {
 		v, ok := d.GetOk(opt.hclKey)
+		if opt.hclKey == rolePasswordAttr {
+			if v, ok, err = rolePassword(d); err != nil {
+				return diagnostics(err)
+			}
+		}
 		if !ok {
 			continue
 		}
@@ -190,22 +305,17 @@ func resourcePostgreSQLRoleCreate(d *schema.ResourceData, meta interface{}) erro
 		if val != "" {
 			switch {
 			case opt.hclKey == rolePasswordAttr:
-				if strings.ToUpper(v.(string)) == "NULL" {
-					createOpts = append(createOpts, "PASSWORD NULL")
-				} else {
-					if d.Get(roleEncryptedPassAttr).(bool) {
-						createOpts = append(createOpts, "ENCRYPTED")
-					} else {
-						createOpts = append(createOpts, "UNENCRYPTED")
-					}
-					createOpts = append(createOpts, fmt.Sprintf("%s '%s'", opt.sqlKey, pqQuoteLiteral(val)))
+				passwordOpt, err := rolePasswordOption(c, d, val)
+				if err != nil {
+					return diagnostics(err)
 				}
+				createOpts = append(createOpts, passwordOpt)
 			case opt.hclKey == roleValidUntilAttr:
 				switch {
 				case v.(string) == "", strings.ToLower(v.(string)) == "infinity":
 					createOpts = append(createOpts, fmt.Sprintf("%s '%s'", opt.sqlKey, "infinity"))
 				default:
-					createOpts = append(createOpts, fmt.Sprintf("%s %s", opt.sqlKey, pq.QuoteIdentifier(val)))
+					createOpts = append(createOpts, fmt.Sprintf("%s '%s'", opt.sqlKey, pqQuoteLiteral(val)))
 				}
 			default:
 				createOpts = append(createOpts, fmt.Sprintf("%s %s", opt.sqlKey, pq.QuoteIdentifier(val)))
@@ -245,51 +355,89 @@ func resourcePostgreSQLRoleCreate(d *schema.ResourceData, meta interface{}) erro
 
 	sql := fmt.Sprintf("CREATE ROLE %s%s", pq.QuoteIdentifier(roleName), createStr)
 	if _, err := c.DB().Exec(sql); err != nil {
-		return errwrap.Wrapf(fmt.Sprintf("Error creating role %s: {{err}}", roleName), err)
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error creating role %s: {{err}}", roleName), err))
+	}
+
+	if err := setRoleSettings(c, c.DB(), d); err != nil {
+		return diagnostics(err)
+	}
+
+	if err := setRoleMemberships(c, c.DB(), d); err != nil {
+		return diagnostics(err)
 	}
 
 	d.SetId(roleName)
 
-	return resourcePostgreSQLRoleReadImpl(d, meta)
+	return diagnostics(resourcePostgreSQLRoleReadImpl(d, meta))
 }
 
-func resourcePostgreSQLRoleDelete(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLRoleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.Lock()
-	defer c.catalogLock.Unlock()
-
-	txn, err := c.DB().Begin()
+	unlock, err := c.lockCatalog("role", "")
 	if err != nil {
-		return err
+		return diagnostics(err)
 	}
-	defer txn.Rollback()
+	defer unlock()
 
 	roleName := d.Get(roleNameAttr).(string)
 
-	queries := make([]string, 0, 3)
-	if !d.Get(roleSkipReassignOwnedAttr).(bool) {
-		if c.featureSupported(featureReassignOwnedCurrentUser) {
-			queries = append(queries, fmt.Sprintf("REASSIGN OWNED BY %s TO CURRENT_USER", pq.QuoteIdentifier(roleName)))
-		} else {
-			queries = append(queries, fmt.Sprintf("REASSIGN OWNED BY %s TO %s", pq.QuoteIdentifier(roleName), pq.QuoteIdentifier(c.config.Username)))
+	// With reassign_owned_to or drop_owned, the objects of the role are
+	// cleaned up in every database, otherwise in the provider's only.
+	reassignTo := d.Get(roleReassignOwnedToAttr).(string)
+	dropOwned := d.Get(roleDropOwnedAttr).(bool)
+	inEveryDatabase := reassignTo != "" || dropOwned
+	if inEveryDatabase {
+		if err := dropOwnedInDatabases(c, roleName, reassignTo, dropOwned); err != nil {
+			return diagnostics(err)
 		}
-		queries = append(queries, fmt.Sprintf("DROP OWNED BY %s", pq.QuoteIdentifier(roleName)))
 	}
 
-	if !d.Get(roleSkipDropRoleAttr).(bool) {
-		queries = append(queries, fmt.Sprintf("DROP ROLE %s", pq.QuoteIdentifier(roleName)))
+	// REASSIGN OWNED requires the privileges of the role being dropped,
+	// which non-superusers only have as members of the role.
+	reassignOwned := !d.Get(roleSkipReassignOwnedAttr).(bool) && !inEveryDatabase
+	if reassignOwned {
+		unlockMembership, err := c.lockRoleMembership(roleName)
+		if err != nil {
+			return diagnostics(err)
+		}
+		defer unlockMembership()
 	}
 
-	if len(queries) > 0 {
+	err = c.inTxn(c.DB(), "role", func(txn *sql.Tx) error {
+		var granted bool
+		if reassignOwned {
+			var err error
+			if granted, err = grantRoleMembership(c, txn, roleName); err != nil {
+				return err
+			}
+		}
+
+		queries := make([]string, 0, 3)
+		if reassignOwned {
+			if c.featureSupported(featureReassignOwnedCurrentUser) {
+				queries = append(queries, fmt.Sprintf("REASSIGN OWNED BY %s TO CURRENT_USER", pq.QuoteIdentifier(roleName)))
+			} else {
+				queries = append(queries, fmt.Sprintf("REASSIGN OWNED BY %s TO %s", pq.QuoteIdentifier(roleName), pq.QuoteIdentifier(c.config.Username)))
+			}
+			queries = append(queries, fmt.Sprintf("DROP OWNED BY %s", pq.QuoteIdentifier(roleName)))
+		}
+
+		if !d.Get(roleSkipDropRoleAttr).(bool) {
+			queries = append(queries, fmt.Sprintf("DROP ROLE %s", pq.QuoteIdentifier(roleName)))
+		} else if granted {
+			queries = append(queries, fmt.Sprintf("REVOKE %s FROM %s", pq.QuoteIdentifier(roleName), pq.QuoteIdentifier(c.config.Username)))
+		}
+
 		for _, query := range queries {
 			if _, err := txn.Exec(query); err != nil {
 				return errwrap.Wrapf("Error deleting role: {{err}}", err)
 			}
 		}
 
-		if err := txn.Commit(); err != nil {
-			return errwrap.Wrapf("Error committing schema: {{err}}", err)
-		}
+		return nil
+	})
+	if err != nil {
+		return diagnostics(err)
 	}
 
 	d.SetId("")
@@ -297,10 +445,94 @@ func resourcePostgreSQLRoleDelete(d *schema.ResourceData, meta interface{}) erro
 	return nil
 }
 
+// dropOwnedInDatabases reassigns the objects owned by the role to reassignTo,
+// if set, then, if dropOwned, drops the ones left and revokes the privileges
+// granted to the role, in every database which accepts connections, so that
+// the role can be dropped.  Each database is cleaned up in its own
+// transaction.
+func dropOwnedInDatabases(c *Client, roleName, reassignTo string, dropOwned bool) (err error) {
+	// REASSIGN OWNED and DROP OWNED require the privileges of the role, and
+	// REASSIGN OWNED the ones of the new owner, which non-superusers only have
+	// as members of the roles.  The memberships are granted outside of the
+	// transactions so that the connections to every database see them.
+	for _, role := range []string{roleName, reassignTo} {
+		if role == "" {
+			continue
+		}
+
+		unlockMembership, err := c.lockRoleMembership(role)
+		if err != nil {
+			return err
+		}
+		defer unlockMembership()
+
+		granted, err := grantRoleMembership(c, c.DB(), role)
+		if err != nil {
+			return err
+		}
+		if granted {
+			role := role
+			defer func() {
+				if revokeErr := revokeRoleMembership(c, c.DB(), role); revokeErr != nil && err == nil {
+					err = revokeErr
+				}
+			}()
+		}
+	}
+
+	var queries []string
+	if reassignTo != "" {
+		queries = append(queries, fmt.Sprintf("REASSIGN OWNED BY %s TO %s", pq.QuoteIdentifier(roleName), pq.QuoteIdentifier(reassignTo)))
+	}
+	if dropOwned {
+		queries = append(queries, fmt.Sprintf("DROP OWNED BY %s", pq.QuoteIdentifier(roleName)))
+	}
+
+	rows, err := c.DB().Query("SELECT datname FROM pg_catalog.pg_database WHERE datallowconn AND NOT datistemplate ORDER BY datname")
+	if err != nil {
+		return errwrap.Wrapf("Error listing databases: {{err}}", err)
+	}
+	var databases []string
+	for rows.Next() {
+		var database string
+		if err := rows.Scan(&database); err != nil {
+			rows.Close()
+			return errwrap.Wrapf("Error listing databases: {{err}}", err)
+		}
+		databases = append(databases, database)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return errwrap.Wrapf("Error listing databases: {{err}}", err)
+	}
+
+	for _, database := range databases {
+		db, err := c.DBFor(database, "")
+		if err != nil {
+			return err
+		}
+
+		err = c.inTxn(db, fmt.Sprintf("the cleanup of role %s in database %s", roleName, database), func(txn *sql.Tx) error {
+			for _, query := range queries {
+				if _, err := txn.Exec(query); err != nil {
+					return errwrap.Wrapf(fmt.Sprintf("Error cleaning up the objects of role %s in database %s: {{err}}", roleName, database), err)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func resourcePostgreSQLRoleExists(d *schema.ResourceData, meta interface{}) (bool, error) {
 	c := meta.(*Client)
-	c.catalogLock.RLock()
-	defer c.catalogLock.RUnlock()
+	unlock := c.rlockCatalog("role", "")
+	defer unlock()
 
 	var roleName string
 	err := c.DB().QueryRow("SELECT rolname FROM pg_catalog.pg_roles WHERE rolname=$1", d.Id()).Scan(&roleName)
@@ -314,19 +546,19 @@ func resourcePostgreSQLRoleExists(d *schema.ResourceData, meta interface{}) (boo
 	return true, nil
 }
 
-func resourcePostgreSQLRoleRead(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLRoleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.RLock()
-	defer c.catalogLock.RUnlock()
+	unlock := c.rlockCatalog("role", "")
+	defer unlock()
 
-	return resourcePostgreSQLRoleReadImpl(d, meta)
+	return diagnostics(resourcePostgreSQLRoleReadImpl(d, meta))
 }
 
 func resourcePostgreSQLRoleReadImpl(d *schema.ResourceData, meta interface{}) error {
 	c := meta.(*Client)
 
 	roleID := d.Id()
-	var roleSuperuser, roleInherit, roleCreateRole, roleCreateDB, roleCanLogin, roleReplication bool
+	var roleSuperuser, roleInherit, roleCreateRole, roleCreateDB, roleCanLogin, roleReplication, roleBypassRLS bool
 	var roleConnLimit int
 	var roleName, roleValidUntil string
 
@@ -341,9 +573,7 @@ func resourcePostgreSQLRoleReadImpl(d *schema.ResourceData, meta interface{}) er
 		"rolconnlimit",
 		`COALESCE(rolvaliduntil::TEXT, 'infinity')`,
 	}
-
-	roleSQL := fmt.Sprintf("SELECT %s FROM pg_catalog.pg_roles WHERE rolname=$1", strings.Join(columns, ", "))
-	err := c.DB().QueryRow(roleSQL, roleID).Scan(
+	values := []interface{}{
 		&roleName,
 		&roleSuperuser,
 		&roleInherit,
@@ -353,10 +583,17 @@ func resourcePostgreSQLRoleReadImpl(d *schema.ResourceData, meta interface{}) er
 		&roleReplication,
 		&roleConnLimit,
 		&roleValidUntil,
-	)
+	}
+	if c.featureSupported(featureRLS) {
+		columns = append(columns, "rolbypassrls")
+		values = append(values, &roleBypassRLS)
+	}
+
+	roleSQL := fmt.Sprintf("SELECT %s FROM pg_catalog.pg_roles WHERE rolname=$1", strings.Join(columns, ", "))
+	err := c.DB().QueryRow(roleSQL, roleID).Scan(values...)
 	switch {
 	case err == sql.ErrNoRows:
-		log.Printf("[WARN] PostgreSQL ROLE (%s) not found", roleID)
+		logEvent("WARN", "PostgreSQL role not found", logFields{"role": roleID})
 		d.SetId("")
 		return nil
 	case err != nil:
@@ -374,22 +611,58 @@ func resourcePostgreSQLRoleReadImpl(d *schema.ResourceData, meta interface{}) er
 	d.Set(roleSkipDropRoleAttr, d.Get(roleSkipDropRoleAttr).(bool))
 	d.Set(roleSkipReassignOwnedAttr, d.Get(roleSkipReassignOwnedAttr).(bool))
 	d.Set(roleSuperuserAttr, roleSuperuser)
+	d.Set(roleBypassRLSAttr, roleBypassRLS)
+
+	// The server returns VALID UNTIL in its own format, e.g. "2030-01-01
+	// 00:00:00+00" for "2030-01-01": keep the configured date and time if
+	// it is the same.
+	if validUntil := d.Get(roleValidUntilAttr).(string); validUntil != "" && validUntil != roleValidUntil {
+		var same bool
+		if err := c.DB().QueryRow("SELECT $1::timestamptz = $2::timestamptz", validUntil, roleValidUntil).Scan(&same); err == nil && same {
+			roleValidUntil = validUntil
+		}
+	}
 	d.Set(roleValidUntilAttr, roleValidUntil)
 
-	if c.featureSupported(featureRLS) {
-		var roleBypassRLS bool
-		roleSQL := "SELECT rolbypassrls FROM pg_catalog.pg_roles WHERE rolname=$1"
-		err = c.DB().QueryRow(roleSQL, roleID).Scan(&roleBypassRLS)
+	var dbExists bool
+	var config pq.StringArray
+	if err := c.DB().QueryRow(roleSettingQuery, roleName, "").Scan(&dbExists, &config); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error reading the settings of role %s: {{err}}", roleName), err)
+	}
+	settings := make(map[string]string, len(config))
+	for _, setting := range config {
+		if i := strings.Index(setting, "="); i > 0 {
+			settings[setting[:i]] = setting[i+1:]
+		}
+	}
+	for _, attr := range roleSettingAttrs {
+		d.Set(attr, settings[attr])
+	}
+
+	// The memberships are only read once managed with roles, so that the
+	// ones of postgresql_grant_role resources are not reported as changes.
+	if d.Get(roleRolesAttr).(*schema.Set).Len() > 0 {
+		roles, err := roleMemberships(c.DB(), roleName)
 		if err != nil {
-			return errwrap.Wrapf("Error reading RLS properties for ROLE: {{err}}", err)
+			return err
 		}
-		d.Set(roleBypassRLSAttr, roleBypassRLS)
+		d.Set(roleRolesAttr, roles)
 	}
 
 	d.SetId(roleName)
 
-	if !roleSuperuser {
-		// Return early if not superuser user
+	if !c.superuser {
+		// Only superusers can read the password verifiers.
+		return nil
+	}
+	if d.Get(roleIgnorePasswordAttr).(bool) {
+		// The password is managed outside of Terraform once set.
+		return nil
+	}
+	if password := d.Get(rolePasswordAttr).(string); password != "" && passwordVerifier(password) == "" {
+		// The verifier the server stores is not the plaintext password
+		// set.  It is read on import, and for the verifiers set, to
+		// detect changes made outside of Terraform.
 		return nil
 	}
 
@@ -406,54 +679,172 @@ func resourcePostgreSQLRoleReadImpl(d *schema.ResourceData, meta interface{}) er
 	return nil
 }
 
-func resourcePostgreSQLRoleUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLRoleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.Lock()
-	defer c.catalogLock.Unlock()
+	if err := checkRoleFeatures(c, d); err != nil {
+		return diagnostics(err)
+	}
+
+	unlock, err := c.lockCatalog("role", "")
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
 
 	db := c.DB()
 
 	if err := setRoleName(db, d); err != nil {
-		return err
+		return diagnostics(err)
 	}
 
 	if err := setRoleBypassRLS(c, db, d); err != nil {
-		return err
+		return diagnostics(err)
 	}
 
 	if err := setRoleConnLimit(db, d); err != nil {
-		return err
+		return diagnostics(err)
 	}
 
 	if err := setRoleCreateDB(db, d); err != nil {
-		return err
+		return diagnostics(err)
+	}
+
+	if d.HasChange(roleCreateRoleAttr) && d.Get(roleCreateRoleAttr).(bool) {
+		if err := checkCreateRoleEscalation(c, db, d.Get(roleNameAttr).(string), ""); err != nil {
+			return diagnostics(err)
+		}
 	}
 
 	if err := setRoleCreateRole(db, d); err != nil {
-		return err
+		return diagnostics(err)
 	}
 
 	if err := setRoleInherit(db, d); err != nil {
-		return err
+		return diagnostics(err)
 	}
 
 	if err := setRoleLogin(db, d); err != nil {
-		return err
+		return diagnostics(err)
 	}
 
 	if err := setRoleReplication(db, d); err != nil {
-		return err
+		return diagnostics(err)
 	}
 
 	if err := setRoleSuperuser(db, d); err != nil {
-		return err
+		return diagnostics(err)
 	}
 
 	if err := setRoleValidUntil(db, d); err != nil {
+		return diagnostics(err)
+	}
+
+	if err := setRolePassword(c, db, d); err != nil {
+		return diagnostics(err)
+	}
+
+	if err := setRoleSettings(c, db, d); err != nil {
+		return diagnostics(err)
+	}
+
+	if err := setRoleMemberships(c, db, d); err != nil {
+		return diagnostics(err)
+	}
+
+	return diagnostics(resourcePostgreSQLRoleReadImpl(d, meta))
+}
+
+// rolePassword returns the password to set for the role, if any: a new random
+// one with generate_password, which is then exposed as generated_password, or
+// the password attribute.
+func rolePassword(d *schema.ResourceData) (string, bool, error) {
+	if !d.Get(roleGeneratePasswordAttr).(bool) {
+		v, ok := d.GetOk(rolePasswordAttr)
+		if !ok {
+			return "", false, nil
+		}
+		return v.(string), true, nil
+	}
+
+	password, err := generatePassword()
+	if err != nil {
+		return "", false, errwrap.Wrapf("Error generating the role's password: {{err}}", err)
+	}
+	d.Set(roleGeneratedPassAttr, password)
+
+	return password, true, nil
+}
+
+func setRolePassword(c *Client, db *sql.DB, d *schema.ResourceData) error {
+	if !d.HasChange(rolePasswordAttr) && !d.HasChange(roleGeneratePasswordAttr) && !d.HasChange(roleRotationKeepersAttr) {
+		return nil
+	}
+
+	password, ok, err := rolePassword(d)
+	if err != nil {
 		return err
 	}
+	if !ok || password == "" {
+		// Roles without a password explicitly set are left alone.
+		return nil
+	}
 
-	return resourcePostgreSQLRoleReadImpl(d, meta)
+	passwordOpt, err := rolePasswordOption(c, d, password)
+	if err != nil {
+		return err
+	}
+
+	roleName := d.Get(roleNameAttr).(string)
+	sql := fmt.Sprintf("ALTER ROLE %s %s", pq.QuoteIdentifier(roleName), passwordOpt)
+	if _, err := db.Exec(sql); err != nil {
+		return errwrap.Wrapf("Error updating role PASSWORD: {{err}}", err)
+	}
+
+	return nil
+}
+
+// rolePasswordOption returns the option of CREATE or ALTER ROLE setting the
+// password.  Password verifiers are stored as they are by the server, with
+// ENCRYPTED, so that plaintext passwords need not be known to Terraform.
+func rolePasswordOption(c *Client, d *schema.ResourceData, password string) (string, error) {
+	if strings.ToUpper(password) == "NULL" {
+		return "PASSWORD NULL", nil
+	}
+
+	switch passwordVerifier(password) {
+	case "scram-sha-256":
+		if !c.featureSupported(featureSCRAM) {
+			return "", c.featureError(featureSCRAM, "SCRAM-SHA-256 password verifiers")
+		}
+		return fmt.Sprintf("ENCRYPTED PASSWORD '%s'", pqQuoteLiteral(password)), nil
+	case "md5":
+		return fmt.Sprintf("ENCRYPTED PASSWORD '%s'", pqQuoteLiteral(password)), nil
+	}
+
+	if d.Get(roleEncryptedPassAttr).(bool) {
+		return fmt.Sprintf("ENCRYPTED PASSWORD '%s'", pqQuoteLiteral(password)), nil
+	}
+
+	return fmt.Sprintf("UNENCRYPTED PASSWORD '%s'", pqQuoteLiteral(password)), nil
+}
+
+var (
+	md5VerifierRE   = regexp.MustCompile(`^md5[0-9a-f]{32}$`)
+	scramVerifierRE = regexp.MustCompile(`^SCRAM-SHA-256\$[0-9]+:[A-Za-z0-9+/]+=*\$[A-Za-z0-9+/]+=*:[A-Za-z0-9+/]+=*$`)
+)
+
+// passwordVerifier returns the kind of password verifier, "md5" or
+// "scram-sha-256", the password is, as PostgreSQL stores them, or "" for
+// plaintext passwords.
+func passwordVerifier(password string) string {
+	switch {
+	case md5VerifierRE.MatchString(password):
+		return "md5"
+	case scramVerifierRE.MatchString(password):
+		return "scram-sha-256"
+	}
+
+	return ""
 }
 
 func setRoleName(db *sql.DB, d *schema.ResourceData) error {
@@ -468,6 +859,12 @@ func setRoleName(db *sql.DB, d *schema.ResourceData) error {
 		return errors.New("Error setting role name to an empty string")
 	}
 
+	// MD5 verifiers are salted with the role name, so that PostgreSQL
+	// clears them when renaming roles.
+	if password := d.Get(rolePasswordAttr).(string); passwordVerifier(password) == "md5" && !d.HasChange(rolePasswordAttr) {
+		return fmt.Errorf("Error renaming role %q to %q: its MD5 password verifier is only valid for the old name, set the verifier for the new name along", o, n)
+	}
+
 	sql := fmt.Sprintf("ALTER ROLE %s RENAME TO %s", pq.QuoteIdentifier(o), pq.QuoteIdentifier(n))
 	if _, err := db.Exec(sql); err != nil {
 		return errwrap.Wrapf("Error updating role NAME: {{err}}", err)
@@ -478,13 +875,110 @@ func setRoleName(db *sql.DB, d *schema.ResourceData) error {
 	return nil
 }
 
+// setRoleSettings sets the parameters of roleSettingAttrs changed for the role
+// in every database, resetting the ones set to an empty string.
+func setRoleSettings(c *Client, db *sql.DB, d *schema.ResourceData) error {
+	prefix := roleSettingAlterPrefix(d.Get(roleNameAttr).(string), "")
+	for _, attr := range roleSettingAttrs {
+		if !d.HasChange(attr) {
+			continue
+		}
+
+		value := d.Get(attr).(string)
+		sql := fmt.Sprintf("%s RESET %s", prefix, pq.QuoteIdentifier(attr))
+		if value != "" {
+			if feature, ok := roleAttrFeatures[attr]; ok && !c.featureSupported(feature) {
+				return &attrError{attr: attr, err: c.featureError(feature, attr)}
+			}
+			sql = roleSettingSetQuery(prefix, attr, value)
+		}
+		if _, err := db.Exec(sql); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error updating role %s: {{err}}", attr), err)
+		}
+	}
+
+	return nil
+}
+
+// roleMemberships returns the roles the role is a member of, directly.
+func roleMemberships(q queryer, role string) ([]string, error) {
+	rows, err := q.Query(`SELECT DISTINCT r.rolname FROM pg_catalog.pg_auth_members m `+
+		`JOIN pg_catalog.pg_roles r ON r.oid = m.roleid JOIN pg_catalog.pg_roles u ON u.oid = m.member `+
+		`WHERE u.rolname = $1 ORDER BY r.rolname`, role)
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("Error reading the memberships of role %s: {{err}}", role), err)
+	}
+	defer rows.Close()
+
+	var roles []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("Error reading the memberships of role %s: {{err}}", role), err)
+		}
+		roles = append(roles, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("Error reading the memberships of role %s: {{err}}", role), err)
+	}
+
+	return roles, nil
+}
+
+// setRoleMemberships grants the role membership in the roles added to roles,
+// and revokes the ones removed from it.
+func setRoleMemberships(c *Client, db *sql.DB, d *schema.ResourceData) error {
+	if !d.HasChange(roleRolesAttr) {
+		return nil
+	}
+
+	roleName := d.Get(roleNameAttr).(string)
+	o, n := d.GetChange(roleRolesAttr)
+	revoked := setToSortedStrings(o.(*schema.Set).Difference(n.(*schema.Set)))
+	granted := setToSortedStrings(n.(*schema.Set).Difference(o.(*schema.Set)))
+
+	for _, role := range revoked {
+		unlock, err := c.lockRoleMembership(role)
+		if err != nil {
+			return err
+		}
+		sql := fmt.Sprintf("REVOKE %s FROM %s", pq.QuoteIdentifier(role), pq.QuoteIdentifier(roleName))
+		_, err = db.Exec(sql)
+		unlock()
+		if err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error revoking membership in role %s from role %s: {{err}}", role, roleName), err)
+		}
+	}
+
+	for _, role := range granted {
+		if err := checkPredefinedRole(c.version, role); err != nil {
+			return err
+		}
+		if err := checkCreateRoleEscalation(c, db, roleName, role); err != nil {
+			return err
+		}
+
+		unlock, err := c.lockRoleMembership(role)
+		if err != nil {
+			return err
+		}
+		err = grantMembership(c, roleName, role, false)
+		unlock()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func setRoleBypassRLS(c *Client, db *sql.DB, d *schema.ResourceData) error {
 	if !d.HasChange(roleBypassRLSAttr) {
 		return nil
 	}
 
 	if !c.featureSupported(featureRLS) {
-		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support PostgreSQL Row-Level Security", c.version.String())
+		return &attrError{attr: roleBypassRLSAttr, err: c.featureError(featureRLS, "Row-Level Security")}
 	}
 
 	bypassRLS := d.Get(roleBypassRLSAttr).(bool)
@@ -636,9 +1130,7 @@ func setRoleValidUntil(db *sql.DB, d *schema.ResourceData) error {
 	}
 
 	validUntil := d.Get(roleValidUntilAttr).(string)
-	if validUntil == "" {
-		return nil
-	} else if strings.ToLower(validUntil) == "infinity" {
+	if validUntil == "" || strings.ToLower(validUntil) == "infinity" {
 		validUntil = "infinity"
 	}
 
@@ -0,0 +1,268 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	roleSettingRoleAttr     = "role"
+	roleSettingDatabaseAttr = "database"
+	roleSettingSettingsAttr = "settings"
+)
+
+// roleSettingListParams are the parameters whose values are lists of
+// identifiers, which must be given to SET as one literal per element: a
+// single literal would be taken as one, quoted, element.
+var roleSettingListParams = []string{
+	"local_preload_libraries",
+	"search_path",
+	"session_preload_libraries",
+	"temp_tablespaces",
+}
+
+// roleSettingQuery selects whether the database $2 exists, or true when $2 is
+// empty, and the settings of the role $1 in it, or in every database when $2 is
+// empty, which are stored with setdatabase = 0.
+const roleSettingQuery = `SELECT $2::TEXT = '' OR EXISTS (SELECT 1 FROM pg_catalog.pg_database WHERE datname = $2), ` +
+	`COALESCE((SELECT rs.setconfig FROM pg_catalog.pg_db_role_setting rs WHERE rs.setrole = r.oid ` +
+	`AND rs.setdatabase = CASE WHEN $2 = '' THEN 0 ELSE (SELECT d.oid FROM pg_catalog.pg_database d WHERE d.datname = $2) END), '{}') ` +
+	`FROM pg_catalog.pg_roles r WHERE r.rolname = $1`
+
+func resourcePostgreSQLRoleSetting() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourcePostgreSQLRoleSettingCreate,
+		ReadContext:   resourcePostgreSQLRoleSettingRead,
+		UpdateContext: resourcePostgreSQLRoleSettingUpdate,
+		DeleteContext: resourcePostgreSQLRoleSettingDelete,
+
+		Schema: map[string]*schema.Schema{
+			roleSettingRoleAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The role the settings apply to",
+			},
+			roleSettingDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The database the settings apply in, instead of every database",
+			},
+			roleSettingSettingsAttr: {
+				Type:        schema.TypeMap,
+				Required:    true,
+				Description: "The configuration parameters set for the role, by name",
+			},
+		},
+	}
+}
+
+// roleSettingAlterPrefix returns the ALTER ROLE ... [IN DATABASE ...] the
+// settings of role in database, or in every database if empty, are altered
+// with.
+func roleSettingAlterPrefix(role, database string) string {
+	sql := fmt.Sprintf("ALTER ROLE %s", pq.QuoteIdentifier(role))
+	if database != "" {
+		sql += fmt.Sprintf(" IN DATABASE %s", pq.QuoteIdentifier(database))
+	}
+	return sql
+}
+
+// roleSettingSetQuery returns the query setting the parameter name to value.
+// The values of list parameters, e.g. search_path, are split on commas, and the
+// double quotes around their elements removed, so that they are read back as
+// given when the elements are quoted as PostgreSQL quotes identifiers.
+func roleSettingSetQuery(prefix, name, value string) string {
+	if !stringInSlice(strings.ToLower(name), roleSettingListParams) {
+		return fmt.Sprintf("%s SET %s TO '%s'", prefix, pq.QuoteIdentifier(name), pqQuoteLiteral(value))
+	}
+
+	var elems []string
+	for _, elem := range splitRoleSettingList(value) {
+		elems = append(elems, fmt.Sprintf("'%s'", pqQuoteLiteral(elem)))
+	}
+	if len(elems) == 0 {
+		elems = []string{"''"}
+	}
+	return fmt.Sprintf("%s SET %s TO %s", prefix, pq.QuoteIdentifier(name), strings.Join(elems, ", "))
+}
+
+// splitRoleSettingList splits a list of identifiers on the commas outside of
+// double quotes, and unquotes the quoted elements.  The spaces around the
+// elements are trimmed.
+func splitRoleSettingList(value string) []string {
+	var elems []string
+	var elem strings.Builder
+	quoted := false
+	for i := 0; i < len(value); i++ {
+		switch ch := value[i]; {
+		case ch == '"' && quoted && i+1 < len(value) && value[i+1] == '"':
+			elem.WriteByte('"')
+			i++
+		case ch == '"':
+			quoted = !quoted
+		case ch == ',' && !quoted:
+			elems = append(elems, strings.TrimSpace(elem.String()))
+			elem.Reset()
+		default:
+			elem.WriteByte(ch)
+		}
+	}
+	if last := strings.TrimSpace(elem.String()); last != "" || len(elems) > 0 {
+		elems = append(elems, last)
+	}
+	return elems
+}
+
+// roleSettingQueries returns the queries resetting the parameters of old
+// missing from new, then setting the ones whose values changed, sorted by name.
+func roleSettingQueries(prefix string, old, new map[string]interface{}) []string {
+	var reset, set []string
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			reset = append(reset, name)
+		}
+	}
+	for name, value := range new {
+		if oldValue, ok := old[name]; !ok || oldValue.(string) != value.(string) {
+			set = append(set, name)
+		}
+	}
+	sort.Strings(reset)
+	sort.Strings(set)
+
+	queries := make([]string, 0, len(reset)+len(set))
+	for _, name := range reset {
+		queries = append(queries, fmt.Sprintf("%s RESET %s", prefix, pq.QuoteIdentifier(name)))
+	}
+	for _, name := range set {
+		queries = append(queries, roleSettingSetQuery(prefix, name, new[name].(string)))
+	}
+	return queries
+}
+
+func resourcePostgreSQLRoleSettingCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	role := d.Get(roleSettingRoleAttr).(string)
+	database := d.Get(roleSettingDatabaseAttr).(string)
+
+	if err := resourcePostgreSQLRoleSettingAlter(d, meta, nil); err != nil {
+		return diagnostics(err)
+	}
+
+	d.SetId(role + "_" + database)
+
+	return diagnostics(resourcePostgreSQLRoleSettingReadImpl(d, meta))
+}
+
+func resourcePostgreSQLRoleSettingUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	old, _ := d.GetChange(roleSettingSettingsAttr)
+	if err := resourcePostgreSQLRoleSettingAlter(d, meta, old.(map[string]interface{})); err != nil {
+		return diagnostics(err)
+	}
+
+	return diagnostics(resourcePostgreSQLRoleSettingReadImpl(d, meta))
+}
+
+// resourcePostgreSQLRoleSettingAlter alters the settings of the role from old
+// to the configured ones, in a transaction.  With a nil old, all the settings of
+// the role are reset first.
+func resourcePostgreSQLRoleSettingAlter(d *schema.ResourceData, meta interface{}, old map[string]interface{}) error {
+	c := meta.(*Client)
+	role := d.Get(roleSettingRoleAttr).(string)
+	prefix := roleSettingAlterPrefix(role, d.Get(roleSettingDatabaseAttr).(string))
+
+	unlock, err := c.lockCatalog("role", "")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	queries := roleSettingQueries(prefix, old, d.Get(roleSettingSettingsAttr).(map[string]interface{}))
+	if old == nil {
+		queries = append([]string{prefix + " RESET ALL"}, queries...)
+	}
+
+	return c.inTxn(c.DB(), "role settings", func(txn *sql.Tx) error {
+		for _, query := range queries {
+			if _, err := txn.Exec(query); err != nil {
+				return errwrap.Wrapf(fmt.Sprintf("Error altering the settings of role %s: {{err}}", role), err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func resourcePostgreSQLRoleSettingRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	unlock := c.rlockCatalog("role", "")
+	defer unlock()
+
+	return diagnostics(resourcePostgreSQLRoleSettingReadImpl(d, meta))
+}
+
+func resourcePostgreSQLRoleSettingReadImpl(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	role := d.Get(roleSettingRoleAttr).(string)
+	database := d.Get(roleSettingDatabaseAttr).(string)
+
+	var dbExists bool
+	var config pq.StringArray
+	err := c.DB().QueryRow(roleSettingQuery, role, database).Scan(&dbExists, &config)
+	switch {
+	case err == sql.ErrNoRows:
+		logEvent("WARN", "PostgreSQL role of settings not found", logFields{"role": role})
+		d.SetId("")
+		return nil
+	case err != nil:
+		return errwrap.Wrapf(fmt.Sprintf("Error reading the settings of role %s: {{err}}", role), err)
+	}
+	if !dbExists {
+		logEvent("WARN", "PostgreSQL database of role settings not found", logFields{"role": role, "database": database})
+		d.SetId("")
+		return nil
+	}
+
+	// NOTE: the settings are stored as name=value strings, and values may
+	// themselves contain an equals sign.
+	settings := make(map[string]interface{}, len(config))
+	for _, setting := range config {
+		if i := strings.Index(setting, "="); i > 0 {
+			settings[setting[:i]] = setting[i+1:]
+		}
+	}
+	if err := d.Set(roleSettingSettingsAttr, settings); err != nil {
+		return errwrap.Wrapf("Error setting role settings: {{err}}", err)
+	}
+
+	return nil
+}
+
+func resourcePostgreSQLRoleSettingDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	role := d.Get(roleSettingRoleAttr).(string)
+
+	unlock, err := c.lockCatalog("role", "")
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
+
+	sql := roleSettingAlterPrefix(role, d.Get(roleSettingDatabaseAttr).(string)) + " RESET ALL"
+	if _, err := c.DB().Exec(sql); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error resetting the settings of role %s: {{err}}", role), err))
+	}
+
+	d.SetId("")
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package postgresql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestRoleSettingQueries(t *testing.T) {
+	tests := []struct {
+		database string
+		old, new map[string]interface{}
+
+		queries []string
+	}{
+		{
+			new: map[string]interface{}{
+				"work_mem":         "64MB",
+				"application_name": "it's me",
+			},
+			queries: []string{
+				`ALTER ROLE "app" SET "application_name" TO 'it''s me'`,
+				`ALTER ROLE "app" SET "work_mem" TO '64MB'`,
+			},
+		},
+		{
+			database: "shop",
+			old: map[string]interface{}{
+				"work_mem":            "64MB",
+				"statement_timeout":   "30s",
+				"search_path":         "public",
+				"lock_timeout":        "5s",
+				"idle_in_transaction": "1min",
+			},
+			new: map[string]interface{}{
+				"work_mem":          "64MB",
+				"statement_timeout": "1min",
+				"search_path":       `"$user", app, "Mixed ""Case"""`,
+			},
+			queries: []string{
+				`ALTER ROLE "app" IN DATABASE "shop" RESET "idle_in_transaction"`,
+				`ALTER ROLE "app" IN DATABASE "shop" RESET "lock_timeout"`,
+				`ALTER ROLE "app" IN DATABASE "shop" SET "search_path" TO '$user', 'app', 'Mixed "Case"'`,
+				`ALTER ROLE "app" IN DATABASE "shop" SET "statement_timeout" TO '1min'`,
+			},
+		},
+		{
+			new: map[string]interface{}{
+				"temp_tablespaces": "",
+			},
+			queries: []string{
+				`ALTER ROLE "app" SET "temp_tablespaces" TO ''`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		queries := roleSettingQueries(roleSettingAlterPrefix("app", test.database), test.old, test.new)
+		if !reflect.DeepEqual(queries, test.queries) {
+			t.Errorf("%v -> %v: expected %q, got %q", test.old, test.new, test.queries, queries)
+		}
+	}
+}
+
+func TestAccPostgresqlRoleSetting_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP DATABASE IF EXISTS role_setting_db",
+		"DROP ROLE IF EXISTS role_setting_app",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE ROLE role_setting_app",
+				"CREATE DATABASE role_setting_db",
+				// Not managed, so reset on create.
+				"ALTER ROLE role_setting_app IN DATABASE role_setting_db SET lock_timeout TO '5s'",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRoleSettingConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role_setting.everywhere", "settings.%", "1"),
+					resource.TestCheckResourceAttr("postgresql_role_setting.everywhere", "settings.work_mem", "16MB"),
+					resource.TestCheckResourceAttr("postgresql_role_setting.db", "settings.%", "2"),
+					resource.TestCheckResourceAttr("postgresql_role_setting.db", "settings.work_mem", "64MB"),
+					resource.TestCheckResourceAttr("postgresql_role_setting.db", "settings.search_path", `"$user", app`),
+				),
+			},
+			{
+				// The settings of both the role and database are read.
+				PreConfig: func() {
+					testAccPostgresqlExec(t, "ALTER ROLE role_setting_app IN DATABASE role_setting_db SET work_mem TO '128MB'")
+				},
+				Config:             testAccPostgresqlRoleSettingConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccPostgresqlRoleSettingConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role_setting.db", "settings.work_mem", "64MB"),
+					resource.TestCheckResourceAttr("postgresql_role_setting.everywhere", "settings.work_mem", "16MB"),
+					func(*terraform.State) error {
+						testAccPostgresqlExec(t, `DO $$ BEGIN `+
+							`IF (SELECT count(*) FROM pg_catalog.pg_db_role_setting rs `+
+							`JOIN pg_catalog.pg_roles r ON r.oid = rs.setrole WHERE r.rolname = 'role_setting_app') <> 2 THEN `+
+							`RAISE EXCEPTION 'role_setting_app must have settings in role_setting_db and every database'; `+
+							`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlRoleSettingConfig = `
+resource "postgresql_role_setting" "everywhere" {
+  role = "role_setting_app"
+
+  settings = {
+    work_mem = "16MB"
+  }
+}
+
+resource "postgresql_role_setting" "db" {
+  role     = "role_setting_app"
+  database = "role_setting_db"
+
+  settings = {
+    work_mem    = "64MB"
+    search_path = "\"$user\", app"
+  }
+}
+`
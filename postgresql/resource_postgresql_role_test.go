@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform/helper/resource"
-	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccPostgresqlRole_Basic(t *testing.T) {
@@ -60,6 +60,76 @@ func TestAccPostgresqlRole_Update(t *testing.T) {
 					resource.TestCheckResourceAttr("postgresql_role.update_role", "name", "update_role2"),
 					resource.TestCheckResourceAttr("postgresql_role.update_role", "login", "true"),
 					resource.TestCheckResourceAttr("postgresql_role.update_role", "connection_limit", "5"),
+					resource.TestCheckResourceAttr("postgresql_role.update_role", "create_database", "true"),
+					resource.TestCheckResourceAttr("postgresql_role.update_role", "create_role", "true"),
+					resource.TestCheckResourceAttr("postgresql_role.update_role", "inherit", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.update_role", "valid_until", "2099-01-01"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_ReassignOwnedTo(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP DATABASE IF EXISTS role_owned_db",
+		"DROP ROLE IF EXISTS role_heir",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE DATABASE role_owned_db",
+				"CREATE ROLE role_heir",
+			)
+		},
+		Providers: testAccProviders,
+		CheckDestroy: func(s *terraform.State) error {
+			if err := testAccCheckPostgresqlRoleDestroy(s); err != nil {
+				return err
+			}
+
+			client := testAccProvider.Meta().(*Client)
+			db, err := client.DBFor("role_owned_db", "")
+			if err != nil {
+				return err
+			}
+			// Let role_owned_db be dropped.
+			defer client.releaseDB("role_owned_db")
+
+			var owner string
+			if err := db.QueryRow("SELECT tableowner FROM pg_catalog.pg_tables WHERE tablename = 'owned_table'").Scan(&owner); err != nil {
+				return fmt.Errorf("Error reading the owner of owned_table: %s", err)
+			}
+			if owner != "role_heir" {
+				return fmt.Errorf("Expected owned_table to be reassigned to role_heir, got %s", owner)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRoleReassignOwnedToConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("postgresql_role.owner", "false"),
+					func(*terraform.State) error {
+						// The role owns a table in another database than
+						// the provider's.
+						db, err := testAccProvider.Meta().(*Client).DBFor("role_owned_db", "")
+						if err != nil {
+							return err
+						}
+						for _, query := range []string{
+							"CREATE TABLE owned_table (id int)",
+							"ALTER TABLE owned_table OWNER TO role_owner",
+							"GRANT SELECT ON owned_table TO role_owner",
+						} {
+							if _, err := db.Exec(query); err != nil {
+								return err
+							}
+						}
+						return nil
+					},
 				),
 			},
 		},
@@ -190,5 +260,187 @@ resource "postgresql_role" "update_role" {
   name = "update_role2"
   login = true
   connection_limit = 5
+  create_database = true
+  create_role = true
+  inherit = false
+  valid_until = "2099-01-01"
+}
+`
+
+func TestPasswordVerifier(t *testing.T) {
+	tests := []struct {
+		password string
+		expected string
+	}{
+		{"mypass", ""},
+		{"md5c98cbfeb6a347a47eb8e96cfb4c4b890", "md5"},
+		{"md5C98CBFEB6A347A47EB8E96CFB4C4B890", ""},
+		{"md5c98cbfeb6a347a47", ""},
+		{"SCRAM-SHA-256$4096:Cxx6hUyRnO9OrQQm0nlEYA==$4qbWbvpDq0A2Ni4lAkAHC7BHnJSqbY6j2mXM3XhW+Ts=:FkX5bTBz8tqAq4Ttmkp5ZrrNM5+3CBq5pqMkYjlnVO4=", "scram-sha-256"},
+		{"SCRAM-SHA-256$4096:Cxx6hUyRnO9OrQQm0nlEYA==", ""},
+	}
+
+	for _, test := range tests {
+		if kind := passwordVerifier(test.password); kind != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.password, test.expected, kind)
+		}
+	}
+}
+
+var testAccPostgresqlRoleReassignOwnedToConfig = `
+resource "postgresql_role" "owner" {
+  name              = "role_owner"
+  reassign_owned_to = "role_heir"
+  drop_owned        = true
+}
+`
+
+func TestAccPostgresqlRole_IgnorePasswordChanges(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRoleIgnorePasswordChangesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("postgresql_role.ignore_pwd", "true"),
+					resource.TestCheckResourceAttr("postgresql_role.ignore_pwd", "ignore_password_changes", "true"),
+				),
+			},
+			{
+				// The rotated password is neither read nor set back.
+				PreConfig: func() {
+					testAccPostgresqlExec(t, "ALTER ROLE role_ignore_pwd PASSWORD 'rotated'")
+				},
+				Config:   testAccPostgresqlRoleIgnorePasswordChangesConfig,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+var testAccPostgresqlRoleIgnorePasswordChangesConfig = `
+resource "postgresql_role" "ignore_pwd" {
+  name                    = "role_ignore_pwd"
+  login                   = true
+  password                = "md5de768a14544ded10275ea53fefc61387"
+  ignore_password_changes = true
+}
+`
+
+func TestAccPostgresqlRole_Timeouts(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRoleTimeouts1Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("postgresql_role.timeouts", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.timeouts", "statement_timeout", "30s"),
+					resource.TestCheckResourceAttr("postgresql_role.timeouts", "idle_in_transaction_session_timeout", "5min"),
+					resource.TestCheckResourceAttr("postgresql_role.timeouts", "idle_session_timeout", ""),
+				),
+			},
+			{
+				// The settings are read back.
+				PreConfig: func() {
+					testAccPostgresqlExec(t, "ALTER ROLE role_timeouts SET statement_timeout TO '1min'")
+				},
+				Config:             testAccPostgresqlRoleTimeouts1Config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccPostgresqlRoleTimeouts2Config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.timeouts", "statement_timeout", ""),
+					resource.TestCheckResourceAttr("postgresql_role.timeouts", "idle_in_transaction_session_timeout", "10min"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlRoleTimeouts1Config = `
+resource "postgresql_role" "timeouts" {
+  name                                = "role_timeouts"
+  statement_timeout                   = "30s"
+  idle_in_transaction_session_timeout = "5min"
+}
+`
+
+var testAccPostgresqlRoleTimeouts2Config = `
+resource "postgresql_role" "timeouts" {
+  name                                = "role_timeouts"
+  idle_in_transaction_session_timeout = "10min"
+}
+`
+
+func TestAccPostgresqlRole_Roles(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP ROLE IF EXISTS role_roles_readers",
+		"DROP ROLE IF EXISTS role_roles_writers",
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE ROLE role_roles_readers",
+				"CREATE ROLE role_roles_writers",
+			)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRoleRoles1Config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("postgresql_role.member", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.member", "roles.#", "1"),
+				),
+			},
+			{
+				// Memberships revoked outside of Terraform are granted
+				// again.
+				PreConfig: func() {
+					testAccPostgresqlExec(t, "REVOKE role_roles_readers FROM role_roles_member")
+				},
+				Config:             testAccPostgresqlRoleRoles1Config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccPostgresqlRoleRoles2Config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.member", "roles.#", "1"),
+					func(*terraform.State) error {
+						testAccPostgresqlExec(t, `DO $$ BEGIN `+
+							`IF pg_has_role('role_roles_member', 'role_roles_readers', 'MEMBER') `+
+							`OR NOT pg_has_role('role_roles_member', 'role_roles_writers', 'MEMBER') THEN `+
+							`RAISE EXCEPTION 'role_roles_member must only be a member of role_roles_writers'; `+
+							`END IF; END $$`)
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlRoleRoles1Config = `
+resource "postgresql_role" "member" {
+  name  = "role_roles_member"
+  roles = ["role_roles_readers"]
+}
+`
+
+var testAccPostgresqlRoleRoles2Config = `
+resource "postgresql_role" "member" {
+  name  = "role_roles_member"
+  roles = ["role_roles_writers"]
 }
 `
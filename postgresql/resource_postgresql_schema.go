@@ -2,24 +2,27 @@ package postgresql
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
 	"reflect"
 	"strings"
 
 	"github.com/hashicorp/errwrap"
-	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/lib/pq"
 	"github.com/sean-/postgresql-acl"
 )
 
 const (
-	schemaNameAttr    = "name"
-	schemaOwnerAttr   = "owner"
-	schemaPolicyAttr  = "policy"
-	schemaIfNotExists = "if_not_exists"
+	schemaNameAttr        = "name"
+	schemaDatabaseAttr    = "database"
+	schemaSessionRoleAttr = "session_role"
+	schemaOwnerAttr       = "owner"
+	schemaPolicyAttr      = "policy"
+	schemaIfNotExists     = "if_not_exists"
 
 	schemaPolicyCreateAttr          = "create"
 	schemaPolicyCreateWithGrantAttr = "create_with_grant"
@@ -30,13 +33,13 @@ const (
 
 func resourcePostgreSQLSchema() *schema.Resource {
 	return &schema.Resource{
-		Create: resourcePostgreSQLSchemaCreate,
-		Read:   resourcePostgreSQLSchemaRead,
-		Update: resourcePostgreSQLSchemaUpdate,
-		Delete: resourcePostgreSQLSchemaDelete,
-		Exists: resourcePostgreSQLSchemaExists,
+		CreateContext: resourcePostgreSQLSchemaCreate,
+		ReadContext:   resourcePostgreSQLSchemaRead,
+		UpdateContext: resourcePostgreSQLSchemaUpdate,
+		DeleteContext: resourcePostgreSQLSchemaDelete,
+		Exists:        resourcePostgreSQLSchemaExists,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importDatabaseObject(schemaDatabaseAttr, schemaNameAttr),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -45,6 +48,18 @@ func resourcePostgreSQLSchema() *schema.Resource {
 				Required:    true,
 				Description: "The name of the schema",
 			},
+			schemaDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The database to create the schema in, instead of the provider's database",
+			},
+			schemaSessionRoleAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The role to act as to manage the schema, instead of the provider's session role",
+			},
 			schemaOwnerAttr: {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -64,18 +79,16 @@ func resourcePostgreSQLSchema() *schema.Resource {
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						schemaPolicyCreateAttr: {
-							Type:          schema.TypeBool,
-							Optional:      true,
-							Default:       false,
-							Description:   "If true, allow the specified ROLEs to CREATE new objects within the schema(s)",
-							ConflictsWith: []string{schemaPolicyAttr + "." + schemaPolicyCreateWithGrantAttr},
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "If true, allow the specified ROLEs to CREATE new objects within the schema(s)",
 						},
 						schemaPolicyCreateWithGrantAttr: {
-							Type:          schema.TypeBool,
-							Optional:      true,
-							Default:       false,
-							Description:   "If true, allow the specified ROLEs to CREATE new objects within the schema(s) and GRANT the same CREATE privilege to different ROLEs",
-							ConflictsWith: []string{schemaPolicyAttr + "." + schemaPolicyCreateAttr},
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "If true, allow the specified ROLEs to CREATE new objects within the schema(s) and GRANT the same CREATE privilege to different ROLEs",
 						},
 						schemaPolicyRoleAttr: {
 							Type:        schema.TypeString,
@@ -85,18 +98,16 @@ func resourcePostgreSQLSchema() *schema.Resource {
 							Description: "ROLE who will receive this policy (default: PUBLIC)",
 						},
 						schemaPolicyUsageAttr: {
-							Type:          schema.TypeBool,
-							Optional:      true,
-							Default:       false,
-							Description:   "If true, allow the specified ROLEs to use objects within the schema(s)",
-							ConflictsWith: []string{schemaPolicyAttr + "." + schemaPolicyUsageWithGrantAttr},
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "If true, allow the specified ROLEs to use objects within the schema(s)",
 						},
 						schemaPolicyUsageWithGrantAttr: {
-							Type:          schema.TypeBool,
-							Optional:      true,
-							Default:       false,
-							Description:   "If true, allow the specified ROLEs to use objects within the schema(s) and GRANT the same USAGE privilege to different ROLEs",
-							ConflictsWith: []string{schemaPolicyAttr + "." + schemaPolicyUsageAttr},
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "If true, allow the specified ROLEs to use objects within the schema(s) and GRANT the same USAGE privilege to different ROLEs",
 						},
 					},
 				},
@@ -105,7 +116,7 @@ func resourcePostgreSQLSchema() *schema.Resource {
 	}
 }
 
-func resourcePostgreSQLSchemaCreate(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLSchemaCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
 
 	queries := []string{}
@@ -155,51 +166,78 @@ func resourcePostgreSQLSchemaCreate(d *schema.ResourceData, meta interface{}) er
 		queries = append(queries, policy.Grants(schemaName)...)
 	}
 
-	c.catalogLock.Lock()
-	defer c.catalogLock.Unlock()
+	unlock, err := c.lockCatalog("schema", c.databaseName(d.Get(schemaDatabaseAttr).(string)))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
 
-	txn, err := c.DB().Begin()
+	db, err := c.DBFor(d.Get(schemaDatabaseAttr).(string), d.Get(schemaSessionRoleAttr).(string))
 	if err != nil {
-		return err
+		return diagnostics(err)
 	}
-	defer txn.Rollback()
 
-	for _, query := range queries {
-		if _, err = txn.Exec(query); err != nil {
-			return errwrap.Wrapf(fmt.Sprintf("Error creating schema %s: {{err}}", schemaName), err)
-		}
+	// Needed in order to set the owner of the schema if the connection user
+	// is not a superuser
+	owner := d.Get(schemaOwnerAttr).(string)
+	unlockMembership, err := c.lockRoleMembership(owner)
+	if err != nil {
+		return diagnostics(err)
 	}
+	defer unlockMembership()
+
+	err = c.inTxn(db, "schema", func(txn *sql.Tx) error {
+		granted, err := grantRoleMembership(c, txn, owner)
+		if err != nil {
+			return err
+		}
+
+		for _, query := range queries {
+			if _, err = txn.Exec(query); err != nil {
+				return errwrap.Wrapf(fmt.Sprintf("Error creating schema %s: {{err}}", schemaName), err)
+			}
+		}
+
+		if granted {
+			return revokeRoleMembership(c, txn, owner)
+		}
 
-	if err := txn.Commit(); err != nil {
-		return errwrap.Wrapf("Error committing schema: {{err}}", err)
+		return nil
+	})
+	if err != nil {
+		return diagnostics(err)
 	}
 
-	d.SetId(schemaName)
+	d.SetId(c.databaseObjectID(d.Get(schemaDatabaseAttr).(string), schemaName))
 
-	return resourcePostgreSQLSchemaReadImpl(d, meta)
+	return diagnostics(resourcePostgreSQLSchemaReadImpl(d, meta))
 }
 
-func resourcePostgreSQLSchemaDelete(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLSchemaDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.Lock()
-	defer c.catalogLock.Unlock()
+	unlock, err := c.lockCatalog("schema", c.databaseName(d.Get(schemaDatabaseAttr).(string)))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
 
-	txn, err := c.DB().Begin()
+	db, err := c.DBFor(d.Get(schemaDatabaseAttr).(string), d.Get(schemaSessionRoleAttr).(string))
 	if err != nil {
-		return err
+		return diagnostics(err)
 	}
-	defer txn.Rollback()
 
 	schemaName := d.Get(schemaNameAttr).(string)
 
 	// NOTE(sean@): Deliberately not performing a cascading drop.
-	sql := fmt.Sprintf("DROP SCHEMA %s", pq.QuoteIdentifier(schemaName))
-	if _, err = txn.Exec(sql); err != nil {
-		return errwrap.Wrapf("Error deleting schema: {{err}}", err)
-	}
+	err = c.inTxn(db, "schema", func(txn *sql.Tx) error {
+		if _, err := txn.Exec(fmt.Sprintf("DROP SCHEMA %s", pq.QuoteIdentifier(schemaName))); err != nil {
+			return errwrap.Wrapf("Error deleting schema: {{err}}", err)
+		}
 
-	if err := txn.Commit(); err != nil {
-		return errwrap.Wrapf("Error committing schema: {{err}}", err)
+		return nil
+	})
+	if err != nil {
+		return diagnostics(err)
 	}
 
 	d.SetId("")
@@ -209,11 +247,16 @@ func resourcePostgreSQLSchemaDelete(d *schema.ResourceData, meta interface{}) er
 
 func resourcePostgreSQLSchemaExists(d *schema.ResourceData, meta interface{}) (bool, error) {
 	c := meta.(*Client)
-	c.catalogLock.RLock()
-	defer c.catalogLock.RUnlock()
+	unlock := c.rlockCatalog("schema", c.databaseName(d.Get(schemaDatabaseAttr).(string)))
+	defer unlock()
+
+	db, err := c.DBFor(d.Get(schemaDatabaseAttr).(string), d.Get(schemaSessionRoleAttr).(string))
+	if err != nil {
+		return false, err
+	}
 
 	var schemaName string
-	err := c.DB().QueryRow("SELECT n.nspname FROM pg_catalog.pg_namespace n WHERE n.nspname=$1", d.Id()).Scan(&schemaName)
+	err = db.QueryRow("SELECT n.nspname FROM pg_catalog.pg_namespace n WHERE n.nspname=$1", databaseObjectName(d.Id())).Scan(&schemaName)
 	switch {
 	case err == sql.ErrNoRows:
 		return false, nil
@@ -224,24 +267,29 @@ func resourcePostgreSQLSchemaExists(d *schema.ResourceData, meta interface{}) (b
 	return true, nil
 }
 
-func resourcePostgreSQLSchemaRead(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLSchemaRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.RLock()
-	defer c.catalogLock.RUnlock()
+	unlock := c.rlockCatalog("schema", c.databaseName(d.Get(schemaDatabaseAttr).(string)))
+	defer unlock()
 
-	return resourcePostgreSQLSchemaReadImpl(d, meta)
+	return diagnostics(resourcePostgreSQLSchemaReadImpl(d, meta))
 }
 
 func resourcePostgreSQLSchemaReadImpl(d *schema.ResourceData, meta interface{}) error {
 	c := meta.(*Client)
 
-	schemaId := d.Id()
+	db, err := c.DBFor(d.Get(schemaDatabaseAttr).(string), d.Get(schemaSessionRoleAttr).(string))
+	if err != nil {
+		return err
+	}
+
+	schemaId := databaseObjectName(d.Id())
 	var schemaName, schemaOwner string
 	var schemaACLs []string
-	err := c.DB().QueryRow("SELECT n.nspname, pg_catalog.pg_get_userbyid(n.nspowner), COALESCE(n.nspacl, '{}'::aclitem[])::TEXT[] FROM pg_catalog.pg_namespace n WHERE n.nspname=$1", schemaId).Scan(&schemaName, &schemaOwner, pq.Array(&schemaACLs))
+	err = db.QueryRow("SELECT n.nspname, pg_catalog.pg_get_userbyid(n.nspowner), COALESCE(n.nspacl, '{}'::aclitem[])::TEXT[] FROM pg_catalog.pg_namespace n WHERE n.nspname=$1", schemaId).Scan(&schemaName, &schemaOwner, pq.Array(&schemaACLs))
 	switch {
 	case err == sql.ErrNoRows:
-		log.Printf("[WARN] PostgreSQL schema (%s) not found", schemaId)
+		logEvent("WARN", "PostgreSQL schema not found", logFields{"schema": schemaId})
 		d.SetId("")
 		return nil
 	case err != nil:
@@ -271,43 +319,45 @@ func resourcePostgreSQLSchemaReadImpl(d *schema.ResourceData, meta interface{})
 		}
 
 		d.Set(schemaNameAttr, schemaName)
+		d.Set(schemaDatabaseAttr, c.databaseName(d.Get(schemaDatabaseAttr).(string)))
 		d.Set(schemaOwnerAttr, schemaOwner)
-		d.SetId(schemaName)
+		d.SetId(c.databaseObjectID(d.Get(schemaDatabaseAttr).(string), schemaName))
 		return nil
 	}
 }
 
-func resourcePostgreSQLSchemaUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLSchemaUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.Lock()
-	defer c.catalogLock.Unlock()
-
-	txn, err := c.DB().Begin()
+	unlock, err := c.lockCatalog("schema", c.databaseName(d.Get(schemaDatabaseAttr).(string)))
 	if err != nil {
-		return err
+		return diagnostics(err)
 	}
-	defer txn.Rollback()
+	defer unlock()
 
-	if err := setSchemaName(txn, d); err != nil {
-		return err
+	db, err := c.DBFor(d.Get(schemaDatabaseAttr).(string), d.Get(schemaSessionRoleAttr).(string))
+	if err != nil {
+		return diagnostics(err)
 	}
 
-	if err := setSchemaOwner(txn, d); err != nil {
-		return err
-	}
+	err = c.inTxn(db, "schema", func(txn *sql.Tx) error {
+		if err := setSchemaName(c, txn, d); err != nil {
+			return err
+		}
 
-	if err := setSchemaPolicy(txn, d); err != nil {
-		return err
-	}
+		if err := setSchemaOwner(c, txn, d); err != nil {
+			return err
+		}
 
-	if err := txn.Commit(); err != nil {
-		return errwrap.Wrapf("Error committing schema: {{err}}", err)
+		return setSchemaPolicy(txn, d)
+	})
+	if err != nil {
+		return diagnostics(err)
 	}
 
-	return resourcePostgreSQLSchemaReadImpl(d, meta)
+	return diagnostics(resourcePostgreSQLSchemaReadImpl(d, meta))
 }
 
-func setSchemaName(txn *sql.Tx, d *schema.ResourceData) error {
+func setSchemaName(c *Client, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(schemaNameAttr) {
 		return nil
 	}
@@ -323,28 +373,42 @@ func setSchemaName(txn *sql.Tx, d *schema.ResourceData) error {
 	if _, err := txn.Exec(sql); err != nil {
 		return errwrap.Wrapf("Error updating schema NAME: {{err}}", err)
 	}
-	d.SetId(n)
+	d.SetId(c.databaseObjectID(d.Get(schemaDatabaseAttr).(string), n))
 
 	return nil
 }
 
-func setSchemaOwner(txn *sql.Tx, d *schema.ResourceData) error {
+func setSchemaOwner(c *Client, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(schemaOwnerAttr) {
 		return nil
 	}
 
-	oraw, nraw := d.GetChange(schemaOwnerAttr)
-	o := oraw.(string)
-	n := nraw.(string)
+	schemaName := d.Get(schemaNameAttr).(string)
+	n := d.Get(schemaOwnerAttr).(string)
 	if n == "" {
 		return errors.New("Error setting schema owner to an empty string")
 	}
 
-	sql := fmt.Sprintf("ALTER SCHEMA %s OWNER TO %s", pq.QuoteIdentifier(o), pq.QuoteIdentifier(n))
+	unlockMembership, err := c.lockRoleMembership(n)
+	if err != nil {
+		return err
+	}
+	defer unlockMembership()
+
+	granted, err := grantRoleMembership(c, txn, n)
+	if err != nil {
+		return err
+	}
+
+	sql := fmt.Sprintf("ALTER SCHEMA %s OWNER TO %s", pq.QuoteIdentifier(schemaName), pq.QuoteIdentifier(n))
 	if _, err := txn.Exec(sql); err != nil {
 		return errwrap.Wrapf("Error updating schema OWNER: {{err}}", err)
 	}
 
+	if granted {
+		return revokeRoleMembership(c, txn, n)
+	}
+
 	return nil
 }
 
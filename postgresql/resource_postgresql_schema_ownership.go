@@ -0,0 +1,270 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	schemaOwnershipDatabaseAttr = "database"
+	schemaOwnershipSchemaAttr   = "schema"
+	schemaOwnershipOwnerAttr    = "owner"
+	schemaOwnershipMisownedAttr = "misowned_objects"
+)
+
+// misownedObjectsQuery selects the kind, e.g. TABLE, the qualified name and the
+// owner of the objects of the schema $1 not owned by the role $2: relations,
+// routines and types.  The sequences owned by columns, and the row types and
+// array types, follow their table or type, and the members of extensions the
+// extension, so they are left out.  The keyword of routines, which depends on
+// the version of the server, is formatted in.
+const misownedObjectsQuery = `SELECT kind, name, owner FROM (` +
+	`SELECT CASE c.relkind WHEN 'S' THEN 'SEQUENCE' WHEN 'v' THEN 'VIEW' WHEN 'm' THEN 'MATERIALIZED VIEW' ` +
+	`WHEN 'f' THEN 'FOREIGN TABLE' ELSE 'TABLE' END AS kind, ` +
+	`pg_catalog.format('%%I.%%I', n.nspname, c.relname) AS name, pg_catalog.pg_get_userbyid(c.relowner) AS owner, c.relowner AS ownerid, c.oid, 'pg_catalog.pg_class'::regclass AS classid ` +
+	`FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+	`WHERE n.nspname = $1 AND c.relkind IN ('r', 'p', 'v', 'm', 'f', 'S') ` +
+	`AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d WHERE d.classid = 'pg_catalog.pg_class'::regclass AND d.objid = c.oid ` +
+	`AND d.refclassid = 'pg_catalog.pg_class'::regclass AND d.deptype IN ('a', 'i')) ` +
+	`UNION ALL ` +
+	`SELECT %s, ` +
+	`pg_catalog.format('%%I.%%I(%%s)', n.nspname, p.proname, pg_catalog.pg_get_function_identity_arguments(p.oid)), ` +
+	`pg_catalog.pg_get_userbyid(p.proowner), p.proowner, p.oid, 'pg_catalog.pg_proc'::regclass ` +
+	`FROM pg_catalog.pg_proc p JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace WHERE n.nspname = $1 ` +
+	`UNION ALL ` +
+	`SELECT CASE t.typtype WHEN 'd' THEN 'DOMAIN' ELSE 'TYPE' END, pg_catalog.format('%%I.%%I', n.nspname, t.typname), ` +
+	`pg_catalog.pg_get_userbyid(t.typowner), t.typowner, t.oid, 'pg_catalog.pg_type'::regclass ` +
+	`FROM pg_catalog.pg_type t JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace ` +
+	`WHERE n.nspname = $1 AND t.typtype IN ('b', 'c', 'd', 'e', 'r') AND t.typcategory <> 'A' ` +
+	`AND (t.typrelid = 0 OR (SELECT c.relkind FROM pg_catalog.pg_class c WHERE c.oid = t.typrelid) = 'c')` +
+	`) AS o ` +
+	`WHERE o.ownerid IS DISTINCT FROM (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $2) ` +
+	`AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d WHERE d.classid = o.classid AND d.objid = o.oid AND d.deptype = 'e') ` +
+	`ORDER BY kind, name`
+
+// misownedObject is an object of the schema not owned by the configured owner.
+type misownedObject struct {
+	kind  string
+	name  string
+	owner string
+}
+
+func resourcePostgreSQLSchemaOwnership() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourcePostgreSQLSchemaOwnershipCreate,
+		ReadContext:   resourcePostgreSQLSchemaOwnershipRead,
+		UpdateContext: resourcePostgreSQLSchemaOwnershipUpdate,
+		DeleteContext: resourcePostgreSQLSchemaOwnershipDelete,
+
+		Schema: map[string]*schema.Schema{
+			schemaOwnershipDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The database of the schema, instead of the provider's database",
+			},
+			schemaOwnershipSchemaAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The schema whose objects are owned by owner",
+			},
+			schemaOwnershipOwnerAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The role owning the objects of the schema",
+			},
+			schemaOwnershipMisownedAttr: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The objects of the schema owned by other roles, read so that their ownership is transferred on apply; not to be set",
+			},
+		},
+	}
+}
+
+// misownedObjects returns the objects of the schema not owned by owner.
+func misownedObjects(c *Client, q queryer, schemaName, owner string) ([]misownedObject, error) {
+	routineKind := `CASE WHEN p.proisagg THEN 'AGGREGATE' ELSE 'FUNCTION' END`
+	if c.featureSupported(featureProKind) {
+		routineKind = `CASE p.prokind WHEN 'a' THEN 'AGGREGATE' WHEN 'p' THEN 'PROCEDURE' ELSE 'FUNCTION' END`
+	}
+	query := fmt.Sprintf(misownedObjectsQuery, routineKind)
+
+	rows, err := q.Query(query, schemaName, owner)
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("Error reading the owners of the objects of schema %s: {{err}}", schemaName), err)
+	}
+	defer rows.Close()
+
+	var objects []misownedObject
+	for rows.Next() {
+		var object misownedObject
+		if err := rows.Scan(&object.kind, &object.name, &object.owner); err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("Error reading the owners of the objects of schema %s: {{err}}", schemaName), err)
+		}
+		objects = append(objects, object)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("Error reading the owners of the objects of schema %s: {{err}}", schemaName), err)
+	}
+
+	return objects, nil
+}
+
+func resourcePostgreSQLSchemaOwnershipCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if err := resourcePostgreSQLSchemaOwnershipTransfer(d, meta); err != nil {
+		return diagnostics(err)
+	}
+
+	d.SetId(importID(d.Get(schemaOwnershipDatabaseAttr).(string), d.Get(schemaOwnershipSchemaAttr).(string)))
+
+	return diagnostics(resourcePostgreSQLSchemaOwnershipReadImpl(d, meta))
+}
+
+func resourcePostgreSQLSchemaOwnershipUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if err := resourcePostgreSQLSchemaOwnershipTransfer(d, meta); err != nil {
+		return diagnostics(err)
+	}
+
+	return diagnostics(resourcePostgreSQLSchemaOwnershipReadImpl(d, meta))
+}
+
+// resourcePostgreSQLSchemaOwnershipTransfer transfers the objects of the schema
+// owned by other roles to the owner, in a transaction.  Transferring objects
+// requires the privileges of their owners and of the new one, which
+// non-superusers only have as members of the roles.
+func resourcePostgreSQLSchemaOwnershipTransfer(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	database := d.Get(schemaOwnershipDatabaseAttr).(string)
+	schemaName := d.Get(schemaOwnershipSchemaAttr).(string)
+	owner := d.Get(schemaOwnershipOwnerAttr).(string)
+
+	unlock, err := c.lockCatalog("schema", c.databaseName(database))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	db, err := c.DBFor(database, "")
+	if err != nil {
+		return err
+	}
+
+	objects, err := misownedObjects(c, db, schemaName, owner)
+	if err != nil {
+		return err
+	}
+	if len(objects) == 0 {
+		return nil
+	}
+
+	// The memberships are locked in order, so that concurrent transfers do
+	// not deadlock.
+	roles := []string{owner}
+	for _, object := range objects {
+		if !stringInSlice(object.owner, roles) {
+			roles = append(roles, object.owner)
+		}
+	}
+	sort.Strings(roles)
+	for _, role := range roles {
+		unlockMembership, err := c.lockRoleMembership(role)
+		if err != nil {
+			return err
+		}
+		defer unlockMembership()
+	}
+
+	return c.inTxn(db, "the ownership transfer", func(txn *sql.Tx) error {
+		var granted []string
+		for _, role := range roles {
+			ok, err := grantRoleMembership(c, txn, role)
+			if err != nil {
+				return err
+			}
+			if ok {
+				granted = append(granted, role)
+			}
+		}
+
+		// The objects are read again in the transaction, in case they
+		// changed.
+		objects, err := misownedObjects(c, txn, schemaName, owner)
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			query := fmt.Sprintf("ALTER %s %s OWNER TO %s", object.kind, object.name, pq.QuoteIdentifier(owner))
+			if _, err := txn.Exec(query); err != nil {
+				return errwrap.Wrapf(fmt.Sprintf("Error transferring %s %s to role %s: {{err}}", object.kind, object.name, owner), err)
+			}
+		}
+
+		for _, role := range granted {
+			if err := revokeRoleMembership(c, txn, role); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func resourcePostgreSQLSchemaOwnershipRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*Client)
+	unlock := c.rlockCatalog("schema", c.databaseName(d.Get(schemaOwnershipDatabaseAttr).(string)))
+	defer unlock()
+
+	return diagnostics(resourcePostgreSQLSchemaOwnershipReadImpl(d, meta))
+}
+
+func resourcePostgreSQLSchemaOwnershipReadImpl(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	database := d.Get(schemaOwnershipDatabaseAttr).(string)
+	schemaName := d.Get(schemaOwnershipSchemaAttr).(string)
+
+	db, err := c.DBFor(database, "")
+	if err != nil {
+		return err
+	}
+
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_namespace WHERE nspname = $1)", schemaName).Scan(&exists); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error reading schema %s: {{err}}", schemaName), err)
+	}
+	if !exists {
+		logEvent("WARN", "PostgreSQL schema of ownership not found", logFields{"schema": schemaName})
+		d.SetId("")
+		return nil
+	}
+
+	objects, err := misownedObjects(c, db, schemaName, d.Get(schemaOwnershipOwnerAttr).(string))
+	if err != nil {
+		return err
+	}
+	names := make([]string, len(objects))
+	for i, object := range objects {
+		names[i] = object.kind + " " + object.name
+	}
+	d.Set(schemaOwnershipMisownedAttr, names)
+
+	return nil
+}
+
+// resourcePostgreSQLSchemaOwnershipDelete leaves the owners of the objects as
+// they are.
+func resourcePostgreSQLSchemaOwnershipDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId("")
+
+	return nil
+}
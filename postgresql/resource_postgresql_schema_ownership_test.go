@@ -0,0 +1,81 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccPostgresqlSchemaOwnership_Basic(t *testing.T) {
+	defer testAccPostgresqlExec(t,
+		"DROP SCHEMA IF EXISTS ownership_app CASCADE",
+		"DROP ROLE IF EXISTS ownership_owner",
+		"DROP ROLE IF EXISTS ownership_deployer",
+	)
+
+	// checkOwned checks that the objects of the schema, but the extension
+	// members, are owned by ownership_owner.
+	checkOwned := func(*terraform.State) error {
+		testAccPostgresqlExec(t, `DO $$ BEGIN `+
+			`IF EXISTS (SELECT 1 FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace `+
+			`WHERE n.nspname = 'ownership_app' AND c.relowner <> 'ownership_owner'::regrole) `+
+			`OR EXISTS (SELECT 1 FROM pg_catalog.pg_proc p JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace `+
+			`WHERE n.nspname = 'ownership_app' AND p.proowner <> 'ownership_owner'::regrole) THEN `+
+			`RAISE EXCEPTION 'the objects of ownership_app must be owned by ownership_owner'; `+
+			`END IF; END $$`)
+		return nil
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPostgresqlExec(t,
+				"CREATE ROLE ownership_owner",
+				"CREATE ROLE ownership_deployer",
+				"CREATE SCHEMA ownership_app",
+				"GRANT CREATE, USAGE ON SCHEMA ownership_app TO ownership_owner, ownership_deployer",
+				"SET ROLE ownership_deployer; "+
+					"CREATE TABLE ownership_app.orders (id serial, item text); "+
+					"CREATE SEQUENCE ownership_app.invoice_numbers; "+
+					"CREATE VIEW ownership_app.recent_orders AS SELECT * FROM ownership_app.orders; "+
+					"CREATE FUNCTION ownership_app.one() RETURNS int AS 'SELECT 1' LANGUAGE SQL; "+
+					"CREATE TYPE ownership_app.status AS ENUM ('new', 'paid'); "+
+					"RESET ROLE",
+			)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlSchemaOwnershipConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_schema_ownership.app", "misowned_objects.#", "0"),
+					checkOwned,
+				),
+			},
+			{
+				// Created by another role after the transfer.
+				PreConfig: func() {
+					testAccPostgresqlExec(t, "SET ROLE ownership_deployer; CREATE TABLE ownership_app.customers (id int); RESET ROLE")
+				},
+				Config:             testAccPostgresqlSchemaOwnershipConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccPostgresqlSchemaOwnershipConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_schema_ownership.app", "misowned_objects.#", "0"),
+					checkOwned,
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlSchemaOwnershipConfig = `
+resource "postgresql_schema_ownership" "app" {
+  schema = "ownership_app"
+  owner  = "ownership_owner"
+}
+`
@@ -6,8 +6,8 @@ import (
 	"testing"
 
 	"github.com/hashicorp/errwrap"
-	"github.com/hashicorp/terraform/helper/resource"
-	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccPostgresqlSchema_Basic(t *testing.T) {
@@ -31,22 +31,28 @@ func TestAccPostgresqlSchema_Basic(t *testing.T) {
 					resource.TestCheckResourceAttr("postgresql_schema.test2", "owner", "role_all_without_grant"),
 					resource.TestCheckResourceAttr("postgresql_schema.test2", "if_not_exists", "false"),
 					resource.TestCheckResourceAttr("postgresql_schema.test2", "policy.#", "1"),
-					resource.TestCheckResourceAttr("postgresql_schema.test2", "policy.1948480595.create", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test2", "policy.1948480595.create_with_grant", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test2", "policy.1948480595.usage", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test2", "policy.1948480595.usage_with_grant", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test2", "policy.1948480595.role", "role_all_without_grant"),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_schema.test2", "policy.*", map[string]string{
+						"create":            "true",
+						"create_with_grant": "false",
+						"usage":             "true",
+						"usage_with_grant":  "false",
+						"role":              "role_all_without_grant",
+					}),
 
 					resource.TestCheckResourceAttr("postgresql_schema.test3", "name", "baz"),
 					resource.TestCheckResourceAttr("postgresql_schema.test3", "owner", "role_all_without_grant"),
 					resource.TestCheckResourceAttr("postgresql_schema.test3", "if_not_exists", "true"),
 					resource.TestCheckResourceAttr("postgresql_schema.test3", "policy.#", "2"),
-					resource.TestCheckResourceAttr("postgresql_schema.test3", "policy.1013320538.create_with_grant", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test3", "policy.1013320538.usage_with_grant", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test3", "policy.1013320538.role", "role_all_with_grant"),
-					resource.TestCheckResourceAttr("postgresql_schema.test3", "policy.1948480595.create", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test3", "policy.1948480595.usage", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test3", "policy.1948480595.role", "role_all_without_grant"),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_schema.test3", "policy.*", map[string]string{
+						"create_with_grant": "true",
+						"usage_with_grant":  "true",
+						"role":              "role_all_with_grant",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_schema.test3", "policy.*", map[string]string{
+						"create": "true",
+						"usage":  "true",
+						"role":   "role_all_without_grant",
+					}),
 				),
 			},
 		},
@@ -75,41 +81,55 @@ func TestAccPostgresqlSchema_AddPolicy(t *testing.T) {
 					resource.TestCheckResourceAttr("postgresql_schema.test4", "name", "test4"),
 					resource.TestCheckResourceAttr("postgresql_schema.test4", "owner", "all_without_grant_stay"),
 					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.#", "7"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.108605972.create", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.108605972.create_with_grant", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.108605972.role", "all_with_grantstay"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.108605972.usage", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.108605972.usage_with_grant", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.1417738359.create", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.1417738359.create_with_grant", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.1417738359.role", "policy_move"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.1417738359.usage", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.1417738359.usage_with_grant", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.1762357194.create", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.1762357194.create_with_grant", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.1762357194.role", "all_without_grant_drop"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.1762357194.usage", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.1762357194.usage_with_grant", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.2524457447.create", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.2524457447.create_with_grant", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.2524457447.role", "all_without_grant_stay"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.2524457447.usage", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.2524457447.usage_with_grant", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.3959936977.create", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.3959936977.create_with_grant", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.3959936977.role", "policy_compose"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.3959936977.usage", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.3959936977.usage_with_grant", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.4178211897.create", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.4178211897.create_with_grant", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.4178211897.role", "all_with_grantdrop"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.4178211897.usage", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.4178211897.usage_with_grant", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.815478369.create", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.815478369.create_with_grant", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.815478369.role", "policy_compose"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.815478369.usage", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.815478369.usage_with_grant", "false"),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_schema.test4", "policy.*", map[string]string{
+						"create":            "false",
+						"create_with_grant": "true",
+						"role":              "all_with_grantstay",
+						"usage":             "false",
+						"usage_with_grant":  "true",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_schema.test4", "policy.*", map[string]string{
+						"create":            "true",
+						"create_with_grant": "false",
+						"role":              "policy_move",
+						"usage":             "true",
+						"usage_with_grant":  "false",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_schema.test4", "policy.*", map[string]string{
+						"create":            "true",
+						"create_with_grant": "false",
+						"role":              "all_without_grant_drop",
+						"usage":             "true",
+						"usage_with_grant":  "false",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_schema.test4", "policy.*", map[string]string{
+						"create":            "true",
+						"create_with_grant": "false",
+						"role":              "all_without_grant_stay",
+						"usage":             "true",
+						"usage_with_grant":  "false",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_schema.test4", "policy.*", map[string]string{
+						"create":            "false",
+						"create_with_grant": "true",
+						"role":              "policy_compose",
+						"usage":             "false",
+						"usage_with_grant":  "true",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_schema.test4", "policy.*", map[string]string{
+						"create":            "false",
+						"create_with_grant": "true",
+						"role":              "all_with_grantdrop",
+						"usage":             "false",
+						"usage_with_grant":  "true",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_schema.test4", "policy.*", map[string]string{
+						"create":            "true",
+						"create_with_grant": "false",
+						"role":              "policy_compose",
+						"usage":             "true",
+						"usage_with_grant":  "false",
+					}),
 				),
 			},
 			{
@@ -126,36 +146,48 @@ func TestAccPostgresqlSchema_AddPolicy(t *testing.T) {
 					resource.TestCheckResourceAttr("postgresql_schema.test4", "name", "test4"),
 					resource.TestCheckResourceAttr("postgresql_schema.test4", "owner", "all_without_grant_stay"),
 					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.#", "6"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.108605972.create", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.108605972.create_with_grant", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.108605972.role", "all_with_grantstay"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.108605972.usage", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.108605972.usage_with_grant", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.2524457447.create", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.2524457447.create_with_grant", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.2524457447.role", "all_without_grant_stay"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.2524457447.usage", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.2524457447.usage_with_grant", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.3831594020.create", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.3831594020.create_with_grant", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.3831594020.role", "policy_move"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.3831594020.usage", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.3831594020.usage_with_grant", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.3959936977.create", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.3959936977.create_with_grant", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.3959936977.role", "policy_compose"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.3959936977.usage", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.3959936977.usage_with_grant", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.468685299.create", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.468685299.create_with_grant", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.468685299.role", "policy_new"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.468685299.usage", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.468685299.usage_with_grant", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.815478369.create", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.815478369.create_with_grant", "false"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.815478369.role", "policy_compose"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.815478369.usage", "true"),
-					resource.TestCheckResourceAttr("postgresql_schema.test4", "policy.815478369.usage_with_grant", "false"),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_schema.test4", "policy.*", map[string]string{
+						"create":            "false",
+						"create_with_grant": "true",
+						"role":              "all_with_grantstay",
+						"usage":             "false",
+						"usage_with_grant":  "true",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_schema.test4", "policy.*", map[string]string{
+						"create":            "true",
+						"create_with_grant": "false",
+						"role":              "all_without_grant_stay",
+						"usage":             "true",
+						"usage_with_grant":  "false",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_schema.test4", "policy.*", map[string]string{
+						"create":            "false",
+						"create_with_grant": "true",
+						"role":              "policy_move",
+						"usage":             "false",
+						"usage_with_grant":  "true",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_schema.test4", "policy.*", map[string]string{
+						"create":            "false",
+						"create_with_grant": "true",
+						"role":              "policy_compose",
+						"usage":             "false",
+						"usage_with_grant":  "true",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_schema.test4", "policy.*", map[string]string{
+						"create":            "true",
+						"create_with_grant": "false",
+						"role":              "policy_new",
+						"usage":             "true",
+						"usage_with_grant":  "false",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("postgresql_schema.test4", "policy.*", map[string]string{
+						"create":            "true",
+						"create_with_grant": "false",
+						"role":              "policy_compose",
+						"usage":             "true",
+						"usage_with_grant":  "false",
+					}),
 				),
 			},
 		},
@@ -170,7 +202,7 @@ func testAccCheckPostgresqlSchemaDestroy(s *terraform.State) error {
 			continue
 		}
 
-		exists, err := checkSchemaExists(client, rs.Primary.ID)
+		exists, err := checkSchemaExists(client, rs.Primary.Attributes[schemaNameAttr])
 		if err != nil {
 			return fmt.Errorf("Error checking schema %s", err)
 		}
@@ -200,7 +232,7 @@ func testAccCheckPostgresqlSchemaExists(n string, schemaName string) resource.Te
 		}
 
 		client := testAccProvider.Meta().(*Client)
-		exists, err := checkSchemaExists(client, rs.Primary.ID)
+		exists, err := checkSchemaExists(client, rs.Primary.Attributes[schemaNameAttr])
 
 		if err != nil {
 			return fmt.Errorf("Error checking schema %s", err)
@@ -245,31 +277,31 @@ resource "postgresql_schema" "test1" {
 
 resource "postgresql_schema" "test2" {
   name = "bar"
-  owner = "${postgresql_role.role_all_without_grant.name}"
+  owner = postgresql_role.role_all_without_grant.name
   if_not_exists = false
 
   policy {
     create = true
     usage = true
-    role = "${postgresql_role.role_all_without_grant.name}"
+    role = postgresql_role.role_all_without_grant.name
   }
 }
 
 resource "postgresql_schema" "test3" {
   name = "baz"
-  owner = "${postgresql_role.role_all_without_grant.name}"
+  owner = postgresql_role.role_all_without_grant.name
   if_not_exists = true
 
   policy {
     create_with_grant = true
     usage_with_grant = true
-    role = "${postgresql_role.role_all_with_grant.name}"
+    role = postgresql_role.role_all_with_grant.name
   }
 
   policy {
     create = true
     usage = true
-    role = "${postgresql_role.role_all_without_grant.name}"
+    role = postgresql_role.role_all_without_grant.name
   }
 }
 `
@@ -301,48 +333,48 @@ resource "postgresql_role" "all_with_grantdrop" {
 
 resource "postgresql_schema" "test4" {
   name = "test4"
-  owner = "${postgresql_role.all_without_grant_stay.name}"
+  owner = postgresql_role.all_without_grant_stay.name
 
   policy {
     create = true
     usage = true
-    role = "${postgresql_role.all_without_grant_stay.name}"
+    role = postgresql_role.all_without_grant_stay.name
   }
 
   policy {
     create = true
     usage = true
-    role = "${postgresql_role.all_without_grant_drop.name}"
+    role = postgresql_role.all_without_grant_drop.name
   }
 
   policy {
     create = true
     usage = true
-    role = "${postgresql_role.policy_compose.name}"
+    role = postgresql_role.policy_compose.name
   }
 
   policy {
     create = true
     usage = true
-    role = "${postgresql_role.policy_move.name}"
+    role = postgresql_role.policy_move.name
   }
 
   policy {
     create_with_grant = true
     usage_with_grant = true
-    role = "${postgresql_role.all_with_grantstay.name}"
+    role = postgresql_role.all_with_grantstay.name
   }
 
   policy {
     create_with_grant = true
     usage_with_grant = true
-    role = "${postgresql_role.all_with_grantdrop.name}"
+    role = postgresql_role.all_with_grantdrop.name
   }
 
   policy {
     create_with_grant = true
     usage_with_grant = true
-    role = "${postgresql_role.policy_compose.name}"
+    role = postgresql_role.policy_compose.name
   }
 }
 `
@@ -374,42 +406,42 @@ resource "postgresql_role" "policy_new" {
 
 resource "postgresql_schema" "test4" {
   name = "test4"
-  owner = "${postgresql_role.all_without_grant_stay.name}"
+  owner = postgresql_role.all_without_grant_stay.name
 
   policy {
     create = true
     usage = true
-    role = "${postgresql_role.all_without_grant_stay.name}"
+    role = postgresql_role.all_without_grant_stay.name
   }
 
   policy {
     create = true
     usage = true
-    role = "${postgresql_role.policy_compose.name}"
+    role = postgresql_role.policy_compose.name
   }
 
   policy {
     create_with_grant = true
     usage_with_grant = true
-    role = "${postgresql_role.all_with_grantstay.name}"
+    role = postgresql_role.all_with_grantstay.name
   }
 
   policy {
     create_with_grant = true
     usage_with_grant = true
-    role = "${postgresql_role.policy_compose.name}"
+    role = postgresql_role.policy_compose.name
   }
 
   policy {
     create_with_grant = true
     usage_with_grant = true
-    role = "${postgresql_role.policy_move.name}"
+    role = postgresql_role.policy_move.name
   }
 
   policy {
     create = true
     usage = true
-    role = "${postgresql_role.policy_new.name}"
+    role = postgresql_role.policy_new.name
   }
 }
 `
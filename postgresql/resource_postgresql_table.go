@@ -1,38 +1,51 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
 
 	"github.com/hashicorp/errwrap"
-	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/lib/pq"
 )
 
 const (
 	tableNameAttr        = "name"
+	tableDatabaseAttr    = "database"
+	tableSessionRoleAttr = "session_role"
 	tableCreateTableAttr = "create_table"
+	tableDistStyleAttr   = "dist_style"
+	tableDistKeyAttr     = "dist_key"
+	tableSortKeysAttr    = "sort_keys"
+	tableColumnarAttr    = "columnar"
 	columnAttr           = "column"
 	columnNameAttr       = "name"
 	columnTypeAttr       = "type"
 	columnMaxLengthAttr  = "max_length"
 	columnDefaultAttr    = "default"
 	columnIsNullAttr     = "is_null"
+
+	tableDistributedByAttr       = "distributed_by"
+	tableDistributedRandomlyAttr = "distributed_randomly"
+	tableSplitIntoTabletsAttr    = "split_into_tablets"
+	tableColocatedAttr           = "colocated"
 )
 
 func resourcePostgreSQLTable() *schema.Resource {
 	return &schema.Resource{
-		Create: resourcePostgreSQLTableCreate,
-		Read:   resourcePostgreSQLTableRead,
-		Update: resourcePostgreSQLTableUpdate,
-		Delete: resourcePostgreSQLTableDelete,
-		Exists: resourcePostgreSQLTableExists,
+		CreateContext: resourcePostgreSQLTableCreate,
+		ReadContext:   resourcePostgreSQLTableRead,
+		UpdateContext: resourcePostgreSQLTableUpdate,
+		DeleteContext: resourcePostgreSQLTableDelete,
+		Exists:        resourcePostgreSQLTableExists,
+		CustomizeDiff: planFeatures(checkTableFeatures),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importDatabaseObject(tableDatabaseAttr, tableNameAttr),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -41,6 +54,70 @@ func resourcePostgreSQLTable() *schema.Resource {
 				Required:    true,
 				Description: "The name of the table",
 			},
+			tableDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The database to create the table in, instead of the provider's database",
+			},
+			tableSessionRoleAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The role to act as to manage the table, instead of the provider's session role",
+			},
+			tableDistStyleAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The distribution style of the table on Redshift: AUTO, EVEN, KEY or ALL",
+				ValidateFunc: validateDistStyle,
+			},
+			tableDistKeyAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The column the rows of the table are distributed by on Redshift, with the KEY distribution style",
+			},
+			tableSortKeysAttr: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The columns the rows of the table are sorted by on Redshift, in order",
+			},
+			tableColumnarAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the table is in the column store of the columnar engine on AlloyDB",
+			},
+			tableDistributedByAttr: {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Description:   "The columns the rows of the table are distributed by on Greenplum and Cloudberry",
+				ConflictsWith: []string{tableDistributedRandomlyAttr},
+			},
+			tableDistributedRandomlyAttr: {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Description:   "Whether the rows of the table are distributed randomly on Greenplum and Cloudberry",
+				ConflictsWith: []string{tableDistributedByAttr},
+			},
+			tableSplitIntoTabletsAttr: {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The number of tablets the table is split into when created on YugabyteDB",
+			},
+			tableColocatedAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     true,
+				Description: "Whether the table is colocated, in a colocated database on YugabyteDB",
+			},
 			columnAttr: {
 				Type:     schema.TypeList,
 				Optional: true,
@@ -51,8 +128,9 @@ func resourcePostgreSQLTable() *schema.Resource {
 							Required: true,
 						},
 						columnTypeAttr: {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:             schema.TypeString,
+							Required:         true,
+							DiffSuppressFunc: suppressPostGISTypeDiff,
 						},
 						columnMaxLengthAttr: {
 							Type:     schema.TypeInt,
@@ -74,28 +152,133 @@ func resourcePostgreSQLTable() *schema.Resource {
 	}
 }
 
-func resourcePostgreSQLTableCreate(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLTableCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.Lock()
-	defer c.catalogLock.Unlock()
+	if err := checkTableFeatures(c, d); err != nil {
+		return diagnostics(err)
+	}
+
+	unlock, err := c.lockCatalog("table", c.databaseName(d.Get(tableDatabaseAttr).(string)))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
+
+	db, err := c.DBFor(d.Get(tableDatabaseAttr).(string), d.Get(tableSessionRoleAttr).(string))
+	if err != nil {
+		return diagnostics(err)
+	}
 
 	tableName := d.Get(tableNameAttr).(string)
 
+	if c.redshift {
+		return diagnostics(resourcePostgreSQLTableCreateRedshift(d, meta, db))
+	}
+	if c.greenplum {
+		return diagnostics(resourcePostgreSQLTableCreateGreenplum(d, meta, db))
+	}
+
 	sql := fmt.Sprintf("CREATE TABLE %s ()", pq.QuoteIdentifier(tableName))
-	log.Printf("[DEBUG] table create: `%s`", sql)
-	if _, err := c.DB().Exec(sql); err != nil {
+	if c.yugabyte {
+		options, err := yugabyteTableOptions(d, db)
+		if err != nil {
+			return diagnostics(err)
+		}
+		sql += options
+	}
+	if _, err := db.Exec(sql); err != nil {
+		return diagnostics(errwrap.Wrapf(fmt.Sprintf("Error creating table %s: {{err}}", tableName), err))
+	}
+
+	d.SetId(c.databaseObjectID(d.Get(tableDatabaseAttr).(string), tableName))
+
+	return diagnostics(resourcePostgreSQLTableUpdateImpl(d, meta))
+}
+
+// resourcePostgreSQLTableCreateRedshift creates the table with its columns and
+// its distribution and sort keys: Redshift has no tables without columns, and
+// only alters some of the keys.
+func resourcePostgreSQLTableCreateRedshift(d *schema.ResourceData, meta interface{}, db *sql.DB) error {
+	tableName := d.Get(tableNameAttr).(string)
+
+	options, err := redshiftTableOptions(d)
+	if err != nil {
+		return err
+	}
+
+	var definitions []string
+	for _, column := range d.Get(columnAttr).([]interface{}) {
+		definitions = append(definitions, columnDefinition(column.(map[string]interface{})))
+	}
+	if len(definitions) == 0 {
+		return fmt.Errorf("Error creating table %s: Redshift tables need at least one %s", tableName, columnAttr)
+	}
+
+	sql := fmt.Sprintf("CREATE TABLE %s (%s)", pq.QuoteIdentifier(tableName), strings.Join(definitions, ", "))
+	if options != "" {
+		sql += " " + options
+	}
+	if _, err := db.Exec(sql); err != nil {
 		return errwrap.Wrapf(fmt.Sprintf("Error creating table %s: {{err}}", tableName), err)
 	}
 
-	d.SetId(tableName)
+	d.SetId(meta.(*Client).databaseObjectID(d.Get(tableDatabaseAttr).(string), tableName))
 
-	return resourcePostgreSQLTableUpdateImpl(d, meta)
+	return resourcePostgreSQLTableReadImpl(d, meta)
 }
 
-func resourcePostgreSQLTableDelete(d *schema.ResourceData, meta interface{}) error {
+// checkTableFeatures returns an error if the table has attributes only
+// another kind of server supports.
+func checkTableFeatures(c *Client, d attrChanges) error {
+	checks := []struct {
+		server bool
+		check  func(attrChanges) error
+	}{
+		{c.redshift, checkRedshiftTableAttrs},
+		{c.greenplum, checkGreenplumTableAttrs},
+		{c.yugabyte, checkYugabyteTableAttrs},
+		{c.alloydb, checkAlloyDBTableAttrs},
+	}
+	for _, check := range checks {
+		if check.server {
+			continue
+		}
+		if err := check.check(d); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkRedshiftTableAttrs returns an error if the attributes only Redshift
+// supports are set.
+func checkRedshiftTableAttrs(d attrChanges) error {
+	for _, attr := range []string{tableDistStyleAttr, tableDistKeyAttr, tableSortKeysAttr} {
+		if _, ok := d.GetOk(attr); ok {
+			return &attrError{attr: attr, err: fmt.Errorf("%s is only supported on Redshift", attr)}
+		}
+	}
+
+	return nil
+}
+
+func validateDistStyle(v interface{}, key string) (warnings []string, errors []error) {
+	switch strings.ToUpper(v.(string)) {
+	case "AUTO", "EVEN", "KEY", "ALL":
+	default:
+		errors = append(errors, fmt.Errorf("%s must be one of AUTO, EVEN, KEY or ALL, got %q", key, v.(string)))
+	}
+	return
+}
+
+func resourcePostgreSQLTableDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.Lock()
-	defer c.catalogLock.Unlock()
+	unlock, err := c.lockCatalog("table", c.databaseName(d.Get(tableDatabaseAttr).(string)))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
 
 	d.SetId("")
 
@@ -106,13 +289,16 @@ const tableExistsQuery = "SELECT table_name FROM information_schema.tables WHERE
 
 func resourcePostgreSQLTableExists(d *schema.ResourceData, meta interface{}) (bool, error) {
 	c := meta.(*Client)
-	c.catalogLock.RLock()
-	defer c.catalogLock.RUnlock()
+	unlock := c.rlockCatalog("table", c.databaseName(d.Get(tableDatabaseAttr).(string)))
+	defer unlock()
 
-	log.Printf("[DEBUG] table exists: `%s`", d.Id())
+	db, err := c.DBFor(d.Get(tableDatabaseAttr).(string), d.Get(tableSessionRoleAttr).(string))
+	if err != nil {
+		return false, err
+	}
 
 	var tableName string
-	err := c.DB().QueryRow(tableExistsQuery, d.Id()).Scan(&tableName)
+	err = db.QueryRow(tableExistsQuery, databaseObjectName(d.Id())).Scan(&tableName)
 	switch {
 	case err == sql.ErrNoRows:
 		return false, nil
@@ -123,12 +309,12 @@ func resourcePostgreSQLTableExists(d *schema.ResourceData, meta interface{}) (bo
 	return true, nil
 }
 
-func resourcePostgreSQLTableRead(d *schema.ResourceData, meta interface{}) error {
+func resourcePostgreSQLTableRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.RLock()
-	defer c.catalogLock.RUnlock()
+	unlock := c.rlockCatalog("table", c.databaseName(d.Get(tableDatabaseAttr).(string)))
+	defer unlock()
 
-	return resourcePostgreSQLTableReadImpl(d, meta)
+	return diagnostics(resourcePostgreSQLTableReadImpl(d, meta))
 }
 
 const columnsDescribeQuery = `
@@ -147,8 +333,14 @@ var typeAliases = map[string]string{
 	"int4": "int",
 }
 
-func useTypeAlias(columnType string) string {
-	if v, found := typeAliases[columnType]; found {
+// cockroachTypeAliases are the typeAliases of CockroachDB, whose INT is an
+// INT8.
+var cockroachTypeAliases = map[string]string{
+	"int8": "int",
+}
+
+func useTypeAlias(aliases map[string]string, columnType string) string {
+	if v, found := aliases[columnType]; found {
 		return v
 	}
 	return columnType
@@ -165,7 +357,7 @@ func parseIsNullable(data string) bool {
 	return strings.ToLower(data) == "yes"
 }
 
-func columns(db *sql.DB, tableName string) ([]interface{}, error) {
+func columns(db *sql.DB, tableName string, aliases map[string]string) ([]interface{}, error) {
 	var columns []interface{}
 	rows, _ := db.Query(columnsDescribeQuery, tableName)
 	for rows.Next() {
@@ -180,7 +372,7 @@ func columns(db *sql.DB, tableName string) ([]interface{}, error) {
 		}
 		column := map[string]interface{}{
 			columnNameAttr:   name,
-			columnTypeAttr:   useTypeAlias(columnType),
+			columnTypeAttr:   useTypeAlias(aliases, columnType),
 			columnIsNullAttr: parseIsNullable(isNullable),
 		}
 		if maxLength.Valid {
@@ -191,6 +383,37 @@ func columns(db *sql.DB, tableName string) ([]interface{}, error) {
 		}
 		columns = append(columns, column)
 	}
+
+	// The typmods of PostGIS columns, e.g. geometry(Point,4326), are only in
+	// geometry_columns and geography_columns, which only exist with PostGIS,
+	// and the ones of pgvector columns, e.g. vector(1536), in pg_attribute.
+	typeMods := []struct {
+		has   func(string) bool
+		types func(*sql.DB, string) (map[string]string, error)
+	}{
+		{has: isPostGISType, types: postgisColumnTypes},
+		{has: func(columnType string) bool { return stringInSlice(columnType, pgvectorTypes) }, types: pgvectorColumnTypes},
+	}
+	for _, typeMod := range typeMods {
+		for _, column := range columns {
+			if !typeMod.has(column.(map[string]interface{})[columnTypeAttr].(string)) {
+				continue
+			}
+
+			types, err := typeMod.types(db, tableName)
+			if err != nil {
+				return columns, err
+			}
+			for _, column := range columns {
+				column := column.(map[string]interface{})
+				if columnType, ok := types[column[columnNameAttr].(string)]; ok {
+					column[columnTypeAttr] = columnType
+				}
+			}
+			break
+		}
+	}
+
 	return columns, nil
 }
 
@@ -198,19 +421,21 @@ const tableDescribeQuery = "SELECT table_name FROM information_schema.tables WHE
 
 func resourcePostgreSQLTableReadImpl(d *schema.ResourceData, meta interface{}) error {
 	c := meta.(*Client)
-	db := c.DB()
-	tableID := d.Id()
+	db, err := c.DBFor(d.Get(tableDatabaseAttr).(string), d.Get(tableSessionRoleAttr).(string))
+	if err != nil {
+		return err
+	}
 
-	var tableName string
+	tableID := databaseObjectName(d.Id())
 
-	log.Printf("[DEBUG] table read: `%s`", tableID)
+	var tableName string
 
-	err := db.QueryRow(tableDescribeQuery, tableID).Scan(
+	err = db.QueryRow(tableDescribeQuery, tableID).Scan(
 		&tableName,
 	)
 	switch {
 	case err == sql.ErrNoRows:
-		log.Printf("[WARN] PostgreSQL TABLE (%s) not found", tableID)
+		logEvent("WARN", "PostgreSQL table not found", logFields{"table": tableID})
 		d.SetId("")
 		return nil
 	case err != nil:
@@ -218,9 +443,14 @@ func resourcePostgreSQLTableReadImpl(d *schema.ResourceData, meta interface{}) e
 	}
 
 	d.Set(tableNameAttr, tableName)
-	d.SetId(tableName)
+	d.Set(tableDatabaseAttr, c.databaseName(d.Get(tableDatabaseAttr).(string)))
+	d.SetId(c.databaseObjectID(d.Get(tableDatabaseAttr).(string), tableName))
 
-	columns, err := columns(db, tableName)
+	aliases := typeAliases
+	if c.cockroach {
+		aliases = cockroachTypeAliases
+	}
+	columns, err := columns(db, tableName, aliases)
 
 	if err != nil {
 		return errwrap.Wrapf(fmt.Sprintf("Error reading columns TABLE (%s): {{err}}", tableID), err)
@@ -230,18 +460,93 @@ func resourcePostgreSQLTableReadImpl(d *schema.ResourceData, meta interface{}) e
 		return errwrap.Wrapf(fmt.Sprintf("Error setting columns TABLE (%s): {{err}}", tableID), err)
 	}
 
+	if c.alloydb {
+		if err := readTableColumnar(d, db, tableName); err != nil {
+			return err
+		}
+	}
+
+	if c.yugabyte {
+		if err := readYugabyteColocation(d, db, tableName); err != nil {
+			return err
+		}
+	}
+
+	if c.greenplum {
+		return readGreenplumDistribution(d, db, tableName)
+	}
+
+	if c.redshift {
+		return readRedshiftTableKeys(d, db, tableName)
+	}
+
 	return nil
 }
 
-func resourcePostgreSQLTableUpdate(d *schema.ResourceData, meta interface{}) error {
+// readRedshiftTableKeys reads the distribution style and the distribution and
+// sort keys of the table on Redshift.  The AUTO distribution styles are read as
+// AUTO, whichever style Redshift picked.
+func readRedshiftTableKeys(d *schema.ResourceData, db *sql.DB, tableName string) error {
+	var distStyle string
+	err := db.QueryRow(`SELECT CASE c.reldiststyle WHEN 0 THEN 'EVEN' WHEN 1 THEN 'KEY' WHEN 8 THEN 'ALL' ELSE 'AUTO' END `+
+		`FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace `+
+		`WHERE n.nspname = 'public' AND c.relname = $1`, tableName).Scan(&distStyle)
+	if err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error reading the distribution style of TABLE (%s): {{err}}", tableName), err)
+	}
+
+	// pg_table_def only lists the tables of the schemas in the search_path,
+	// which public is in by default.
+	rows, err := db.Query(`SELECT "column", distkey, sortkey FROM pg_catalog.pg_table_def `+
+		`WHERE schemaname = 'public' AND tablename = $1 AND (distkey OR sortkey > 0) ORDER BY sortkey`, tableName)
+	if err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error reading the keys of TABLE (%s): {{err}}", tableName), err)
+	}
+	defer rows.Close()
+
+	var distKey string
+	var sortKeys []string
+	for rows.Next() {
+		var column string
+		var isDistKey bool
+		var sortKey int
+		if err := rows.Scan(&column, &isDistKey, &sortKey); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error reading the keys of TABLE (%s): {{err}}", tableName), err)
+		}
+		if isDistKey && distStyle == "KEY" {
+			distKey = column
+		}
+		if sortKey > 0 {
+			sortKeys = append(sortKeys, column)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error reading the keys of TABLE (%s): {{err}}", tableName), err)
+	}
+
+	d.Set(tableDistStyleAttr, distStyle)
+	d.Set(tableDistKeyAttr, distKey)
+	d.Set(tableSortKeysAttr, sortKeys)
+
+	return nil
+}
+
+func resourcePostgreSQLTableUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*Client)
-	c.catalogLock.Lock()
-	defer c.catalogLock.Unlock()
+	if err := checkTableFeatures(c, d); err != nil {
+		return diagnostics(err)
+	}
 
-	return resourcePostgreSQLTableUpdateImpl(d, meta)
+	unlock, err := c.lockCatalog("table", c.databaseName(d.Get(tableDatabaseAttr).(string)))
+	if err != nil {
+		return diagnostics(err)
+	}
+	defer unlock()
+
+	return diagnostics(resourcePostgreSQLTableUpdateImpl(d, meta))
 }
 
-func renameTableIfNeeded(d *schema.ResourceData, db *sql.DB) error {
+func renameTableIfNeeded(c *Client, d *schema.ResourceData, db *sql.DB) error {
 	if !d.HasChange(columnNameAttr) {
 		return nil
 	}
@@ -255,12 +560,11 @@ func renameTableIfNeeded(d *schema.ResourceData, db *sql.DB) error {
 	}
 
 	sql := fmt.Sprintf("ALTER TABLE %s RENAME TO %s", pq.QuoteIdentifier(old), pq.QuoteIdentifier(new))
-	log.Printf("[DEBUG] table rename: `%s`", sql)
 	if _, err := db.Exec(sql); err != nil {
 		return errwrap.Wrapf("Error updating table NAME: {{err}}", err)
 	}
 
-	d.SetId(new)
+	d.SetId(c.databaseObjectID(d.Get(tableDatabaseAttr).(string), new))
 
 	return nil
 }
@@ -295,19 +599,20 @@ func buildColumnNotNull(column map[string]interface{}) string {
 	return ""
 }
 
-func createColumn(db *sql.DB, tableName string, column map[string]interface{}) error {
-	columnName := column[columnNameAttr].(string)
-	columnType := column[columnTypeAttr].(string)
-
-	sql := fmt.Sprintf(
-		"ALTER TABLE %s ADD COLUMN %s %s%s%s%s",
-		tableName,
-		pq.QuoteIdentifier(columnName),
-		columnType,
+// columnDefinition returns the definition of the column in CREATE TABLE and
+// ALTER TABLE ... ADD COLUMN.
+func columnDefinition(column map[string]interface{}) string {
+	return fmt.Sprintf(
+		"%s %s%s%s%s",
+		pq.QuoteIdentifier(column[columnNameAttr].(string)),
+		column[columnTypeAttr].(string),
 		buildColumnMaxLength(column),
 		buildColumnDefault(column),
 		buildColumnNotNull(column))
-	log.Printf("[DEBUG] create column: `%s`", sql)
+}
+
+func createColumn(db *sql.DB, tableName string, column map[string]interface{}) error {
+	sql := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", tableName, columnDefinition(column))
 	if _, err := db.Exec(sql); err != nil {
 		return errwrap.Wrapf("Error updating table NAME: {{err}}", err)
 	}
@@ -321,18 +626,14 @@ func alterColumnsIfNeeded(d *schema.ResourceData, db *sql.DB) error {
 	oldRaw, newRaw := d.GetChange(columnAttr)
 	old := oldRaw.([]interface{})
 	new := newRaw.([]interface{})
-	log.Print("alter columns:")
-	log.Print(old)
-	log.Print(new)
 
 	// TODO: drop all columns that should be dropped
-	log.Print("diff:")
 	for i, newColumnRaw := range new {
 		newColumn := newColumnRaw.(map[string]interface{})
 		isNewColumn := i >= len(old)
 
 		if isNewColumn {
-			if err := createColumn(db, d.Id(), newColumn); err != nil {
+			if err := createColumn(db, databaseObjectName(d.Id()), newColumn); err != nil {
 				return err
 			}
 		}
@@ -345,10 +646,13 @@ func alterColumnsIfNeeded(d *schema.ResourceData, db *sql.DB) error {
 
 func resourcePostgreSQLTableUpdateImpl(d *schema.ResourceData, meta interface{}) error {
 	c := meta.(*Client)
-	db := c.DB()
+	db, err := c.DBFor(d.Get(tableDatabaseAttr).(string), d.Get(tableSessionRoleAttr).(string))
+	if err != nil {
+		return err
+	}
 
 	if !d.IsNewResource() {
-		if err := renameTableIfNeeded(d, db); err != nil {
+		if err := renameTableIfNeeded(c, d, db); err != nil {
 			return err
 		}
 	}
@@ -357,5 +661,52 @@ func resourcePostgreSQLTableUpdateImpl(d *schema.ResourceData, meta interface{})
 		return err
 	}
 
+	if c.redshift {
+		if err := alterRedshiftTableKeysIfNeeded(d, db); err != nil {
+			return err
+		}
+	}
+
+	if c.greenplum {
+		if err := alterGreenplumDistributionIfNeeded(d, db); err != nil {
+			return err
+		}
+	}
+
+	if err := setTableColumnarIfNeeded(c, d, db); err != nil {
+		return err
+	}
+
 	return resourcePostgreSQLTableReadImpl(d, meta)
 }
+
+// alterRedshiftTableKeysIfNeeded alters the distribution style and key and the
+// sort keys of the table on Redshift.
+func alterRedshiftTableKeysIfNeeded(d *schema.ResourceData, db *sql.DB) error {
+	tableName := pq.QuoteIdentifier(databaseObjectName(d.Id()))
+
+	var queries []string
+	if d.HasChange(tableDistStyleAttr) || d.HasChange(tableDistKeyAttr) {
+		if distKey := d.Get(tableDistKeyAttr).(string); distKey != "" {
+			queries = append(queries, fmt.Sprintf("ALTER TABLE %s ALTER DISTKEY %s", tableName, pq.QuoteIdentifier(distKey)))
+		} else if distStyle := strings.ToUpper(d.Get(tableDistStyleAttr).(string)); distStyle != "" {
+			queries = append(queries, fmt.Sprintf("ALTER TABLE %s ALTER DISTSTYLE %s", tableName, distStyle))
+		}
+	}
+	if d.HasChange(tableSortKeysAttr) {
+		sortKeys := interfaceStrings(d.Get(tableSortKeysAttr).([]interface{}))
+		sortKey := "NONE"
+		if len(sortKeys) > 0 {
+			sortKey = "(" + quoteIdentifiers(sortKeys) + ")"
+		}
+		queries = append(queries, fmt.Sprintf("ALTER TABLE %s ALTER SORTKEY %s", tableName, sortKey))
+	}
+
+	for _, query := range queries {
+		if _, err := db.Exec(query); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error altering the keys of TABLE (%s): {{err}}", d.Id()), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,405 @@
+package postgresql
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	scramSHA256     = "SCRAM-SHA-256"
+	scramSHA256Plus = "SCRAM-SHA-256-PLUS"
+
+	// Authentication request codes of the PostgreSQL protocol
+	authOK           = 0
+	authSASL         = 10
+	authSASLContinue = 11
+	authSASLFinal    = 12
+)
+
+// scramDialer is a pq.Dialer authenticating the connections it establishes
+// with SCRAM-SHA-256 (RFC 7677) on lib/pq's behalf, so that they can be bound
+// to the SSL channel with SCRAM-SHA-256-PLUS, which lib/pq does not support.
+// lib/pq must not negotiate SSL itself for it to see the authentication
+// requests, so SSL is negotiated by sslDialer below it instead.
+type scramDialer struct {
+	forward  pq.Dialer
+	password string
+
+	// channelBinding is libpq's channel_binding: disable, prefer or
+	// require.
+	channelBinding string
+}
+
+// Dial implements pq.Dialer.
+func (d *scramDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialTimeout(network, address, 0)
+}
+
+// DialTimeout implements pq.Dialer.
+func (d *scramDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	conn, err := d.forward.DialTimeout(network, address, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &scramConn{
+		Conn:           conn,
+		password:       d.password,
+		channelBinding: d.channelBinding,
+	}, nil
+}
+
+// scramConn hands lib/pq the messages the server sends until the connection
+// is authenticated, answering SASL authentication requests itself and handing
+// lib/pq the AuthenticationOk that follows.
+type scramConn struct {
+	net.Conn
+	password       string
+	channelBinding string
+
+	// done is set once the connection is authenticated, or lib/pq
+	// negotiates SSL, after which reads are passed through.
+	done bool
+
+	// scram is set once the connection authenticated with SCRAM.
+	scram bool
+
+	// pending is what is left to be read by lib/pq of the last message.
+	pending []byte
+}
+
+func (c *scramConn) Write(b []byte) (int, error) {
+	if !c.done && len(b) == 8 && binary.BigEndian.Uint32(b[4:8]) == sslRequestCode {
+		// What follows is encrypted.
+		c.done = true
+	}
+
+	return c.Conn.Write(b)
+}
+
+func (c *scramConn) Read(b []byte) (int, error) {
+	for len(c.pending) == 0 && !c.done {
+		if err := c.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(c.pending) != 0 {
+		n := copy(b, c.pending)
+		c.pending = c.pending[n:]
+		return n, nil
+	}
+
+	return c.Conn.Read(b)
+}
+
+// next reads the next message from the server, handling it if it is a SASL
+// authentication request, or queuing it to be read by lib/pq otherwise.
+func (c *scramConn) next() error {
+	typ, body, err := readMessage(c.Conn)
+	if err != nil {
+		return err
+	}
+
+	if typ == 'R' && len(body) >= 4 {
+		switch code := binary.BigEndian.Uint32(body); code {
+		case authSASL:
+			return c.authenticate(body[4:])
+		case authOK:
+			if c.channelBinding == "require" && !c.scram {
+				return fmt.Errorf("channel binding required, but the server authenticated the connection without it")
+			}
+			c.done = true
+		default:
+			// lib/pq answers the password requests.
+			if c.channelBinding == "require" {
+				return fmt.Errorf("channel binding required, but the server requested password authentication")
+			}
+		}
+	} else {
+		// Errors, and anything unexpected, are for lib/pq to report.
+		c.done = true
+	}
+
+	c.pending = appendMessage(nil, typ, body)
+
+	return nil
+}
+
+// authenticate carries out the SASL exchange the server asked for with one
+// of the given mechanisms.
+func (c *scramConn) authenticate(data []byte) error {
+	mechanisms := strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+	offers := func(mechanism string) bool {
+		for _, m := range mechanisms {
+			if m == mechanism {
+				return true
+			}
+		}
+		return false
+	}
+
+	tlsConn, _ := c.Conn.(*tls.Conn)
+
+	client := &scramClient{password: c.password, gs2Header: "n,,"}
+	mechanism := scramSHA256
+	switch {
+	case tlsConn != nil && c.channelBinding != "disable" && offers(scramSHA256Plus):
+		certs := tlsConn.ConnectionState().PeerCertificates
+		if len(certs) == 0 {
+			return fmt.Errorf("no server certificate to bind the channel to")
+		}
+		mechanism = scramSHA256Plus
+		client.gs2Header = "p=tls-server-end-point,,"
+		client.channelBinding = tlsServerEndPoint(certs[0])
+	case c.channelBinding == "require":
+		return fmt.Errorf("channel binding required, but the server does not support it on this connection")
+	case !offers(scramSHA256):
+		return fmt.Errorf("unsupported SASL authentication mechanisms %s", strings.Join(mechanisms, ", "))
+	case tlsConn != nil && c.channelBinding != "disable":
+		// Channel binding is supported, but the server does not offer it.
+		client.gs2Header = "y,,"
+	}
+
+	clientFirst, err := client.first()
+	if err != nil {
+		return err
+	}
+
+	var initial []byte
+	initial = append(initial, mechanism...)
+	initial = append(initial, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(initial[len(initial)-4:], uint32(len(clientFirst)))
+	initial = append(initial, clientFirst...)
+	if _, err := c.Conn.Write(appendMessage(nil, 'p', initial)); err != nil {
+		return err
+	}
+
+	serverFirst, err := c.readSASL(authSASLContinue)
+	if serverFirst == nil || err != nil {
+		return err
+	}
+
+	clientFinal, err := client.final(string(serverFirst))
+	if err != nil {
+		return err
+	}
+	if _, err := c.Conn.Write(appendMessage(nil, 'p', []byte(clientFinal))); err != nil {
+		return err
+	}
+
+	serverFinal, err := c.readSASL(authSASLFinal)
+	if serverFinal == nil || err != nil {
+		return err
+	}
+	if err := client.verify(string(serverFinal)); err != nil {
+		return err
+	}
+
+	// The server follows with AuthenticationOk.
+	c.scram = true
+
+	return nil
+}
+
+// readSASL reads the data of the SASL authentication message with the given
+// code.  If the server sends an error instead, it is queued for lib/pq and
+// nil is returned.
+func (c *scramConn) readSASL(code uint32) ([]byte, error) {
+	typ, body, err := readMessage(c.Conn)
+	if err != nil {
+		return nil, err
+	}
+	if typ == 'E' {
+		c.done = true
+		c.pending = appendMessage(nil, typ, body)
+		return nil, nil
+	}
+	if typ != 'R' || len(body) < 4 || binary.BigEndian.Uint32(body) != code {
+		return nil, fmt.Errorf("unexpected message %q during SASL authentication", typ)
+	}
+
+	return body[4:], nil
+}
+
+// readMessage reads a message of the PostgreSQL protocol: its type and body.
+func readMessage(r io.Reader) (byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+
+	n := binary.BigEndian.Uint32(header[1:5])
+	if n < 4 || n > 1<<20 {
+		return 0, nil, fmt.Errorf("invalid message length %d", n)
+	}
+
+	body := make([]byte, n-4)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+
+	return header[0], body, nil
+}
+
+// appendMessage appends a message of the PostgreSQL protocol to b.
+func appendMessage(b []byte, typ byte, body []byte) []byte {
+	b = append(b, typ, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(b[len(b)-4:], uint32(len(body)+4))
+	return append(b, body...)
+}
+
+// tlsServerEndPoint returns the tls-server-end-point channel binding data of
+// the server certificate (RFC 5929): its hash with the hash function of its
+// signature, or SHA-256 if that is MD5 or SHA-1.
+func tlsServerEndPoint(cert *x509.Certificate) []byte {
+	var h hash.Hash
+	switch cert.SignatureAlgorithm {
+	case x509.SHA384WithRSA, x509.SHA384WithRSAPSS, x509.ECDSAWithSHA384:
+		h = sha512.New384()
+	case x509.SHA512WithRSA, x509.SHA512WithRSAPSS, x509.ECDSAWithSHA512:
+		h = sha512.New()
+	default:
+		h = sha256.New()
+	}
+	h.Write(cert.Raw)
+
+	return h.Sum(nil)
+}
+
+// scramClient is the client side of a SCRAM-SHA-256 exchange.  The user name
+// is left empty, as PostgreSQL uses the one of the startup message.  The
+// password is used as is, without SASLprep, which leaves ASCII passwords
+// unchanged.
+type scramClient struct {
+	password       string
+	gs2Header      string
+	channelBinding []byte
+
+	nonce           string
+	clientFirstBare string
+	serverSignature []byte
+}
+
+// first returns the client-first-message.
+func (c *scramClient) first() (string, error) {
+	if c.nonce == "" {
+		b := make([]byte, 18)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		c.nonce = base64.StdEncoding.EncodeToString(b)
+	}
+
+	c.clientFirstBare = "n=,r=" + c.nonce
+
+	return c.gs2Header + c.clientFirstBare, nil
+}
+
+// final returns the client-final-message answering serverFirst.
+func (c *scramClient) final(serverFirst string) (string, error) {
+	var nonce, salt string
+	var iterations int
+	for _, attr := range strings.Split(serverFirst, ",") {
+		if len(attr) < 2 || attr[1] != '=' {
+			continue
+		}
+		switch attr[0] {
+		case 'r':
+			nonce = attr[2:]
+		case 's':
+			salt = attr[2:]
+		case 'i':
+			iterations, _ = strconv.Atoi(attr[2:])
+		}
+	}
+	if !strings.HasPrefix(nonce, c.nonce) || len(nonce) == len(c.nonce) {
+		return "", fmt.Errorf("invalid SCRAM server nonce")
+	}
+	saltBytes, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil || len(saltBytes) == 0 {
+		return "", fmt.Errorf("invalid SCRAM salt")
+	}
+	if iterations < 1 {
+		return "", fmt.Errorf("invalid SCRAM iteration count")
+	}
+
+	channelBinding := base64.StdEncoding.EncodeToString(append([]byte(c.gs2Header), c.channelBinding...))
+	clientFinalWithoutProof := "c=" + channelBinding + ",r=" + nonce
+	authMessage := []byte(c.clientFirstBare + "," + serverFirst + "," + clientFinalWithoutProof)
+
+	saltedPassword := pbkdf2SHA256([]byte(c.password), saltBytes, iterations)
+	clientKey := hmacSHA256(saltedPassword, []byte("Client Key"))
+	storedKey := sha256.Sum256(clientKey)
+	clientSignature := hmacSHA256(storedKey[:], authMessage)
+	proof := make([]byte, len(clientKey))
+	for i := range clientKey {
+		proof[i] = clientKey[i] ^ clientSignature[i]
+	}
+
+	serverKey := hmacSHA256(saltedPassword, []byte("Server Key"))
+	c.serverSignature = hmacSHA256(serverKey, authMessage)
+
+	return clientFinalWithoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof), nil
+}
+
+// verify checks the server's signature in serverFinal, proving it knows the
+// password too.
+func (c *scramClient) verify(serverFinal string) error {
+	if strings.HasPrefix(serverFinal, "e=") {
+		return fmt.Errorf("SCRAM authentication failed: %s", serverFinal[2:])
+	}
+	if !strings.HasPrefix(serverFinal, "v=") {
+		return fmt.Errorf("invalid SCRAM server-final-message")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(strings.SplitN(serverFinal[2:], ",", 2)[0])
+	if err != nil || !hmac.Equal(signature, c.serverSignature) {
+		return fmt.Errorf("invalid SCRAM server signature")
+	}
+
+	return nil
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// pbkdf2SHA256 derives a key of the size of SHA-256's output (RFC 8018), the
+// single block SCRAM-SHA-256 needs.
+func pbkdf2SHA256(password, salt []byte, iterations int) []byte {
+	mac := hmac.New(sha256.New, password)
+	mac.Write(salt)
+	mac.Write([]byte{0, 0, 0, 1})
+	u := mac.Sum(nil)
+
+	key := make([]byte, len(u))
+	copy(key, u)
+	for i := 1; i < iterations; i++ {
+		mac.Reset()
+		mac.Write(u)
+		u = mac.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+
+	return key
+}
@@ -0,0 +1,244 @@
+package postgresql
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestScramClient(t *testing.T) {
+	// The nonces, salt and iteration count of the example exchange of RFC
+	// 7677, with PostgreSQL's empty user name.
+	client := &scramClient{
+		password:  "pencil",
+		gs2Header: "n,,",
+		nonce:     "rOprNGfwEbeRWgbNEkqO",
+	}
+
+	first, err := client.first()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != "n,,n=,r=rOprNGfwEbeRWgbNEkqO" {
+		t.Errorf("unexpected client-first-message %q", first)
+	}
+
+	if _, err := client.final("r=someoneelse,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"); err == nil {
+		t.Error("expected a server nonce not extending the client's to be rejected")
+	}
+
+	serverFirst := "r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"
+	final, err := client.final(serverFirst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authMessage := client.clientFirstBare + "," + serverFirst + ",c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0"
+	if expected := "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=" + testSCRAMProof("pencil", "W22ZaJ0SNY7soEsUEjb6gQ==", authMessage); final != expected {
+		t.Errorf("expected client-final-message %q, got %q", expected, final)
+	}
+
+	if err := client.verify("v=" + base64.StdEncoding.EncodeToString([]byte("forged"))); err == nil {
+		t.Error("expected a forged server signature to be rejected")
+	}
+	if err := client.verify("e=invalid-proof"); err == nil || !strings.Contains(err.Error(), "invalid-proof") {
+		t.Errorf("expected the server error to be reported, got %v", err)
+	}
+	if err := client.verify("v=" + base64.StdEncoding.EncodeToString(client.serverSignature)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPBKDF2SHA256(t *testing.T) {
+	// RFC 7914 section 11
+	key := pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1)
+	if got := fmt.Sprintf("%x", key); got != "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" {
+		t.Errorf("unexpected key %s", got)
+	}
+}
+
+func TestScramDialer(t *testing.T) {
+	certPEM, keyPEM := testCert(t, "db.example.com", nil)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := x509.ParseCertificate(cert.Certificate[0])
+
+	tests := []struct {
+		name           string
+		tls            bool
+		mechanisms     []string
+		channelBinding string
+		password       string
+
+		gs2Header string
+		err       string
+	}{
+		{"plain", false, []string{scramSHA256}, "prefer", "secret", "n,,", ""},
+		{"wrong password", false, []string{scramSHA256}, "prefer", "wrong", "n,,", "password authentication failed"},
+		{"binding", true, []string{scramSHA256Plus, scramSHA256}, "prefer", "secret", "p=tls-server-end-point,,", ""},
+		{"binding disabled", true, []string{scramSHA256Plus, scramSHA256}, "disable", "secret", "n,,", ""},
+		{"binding not offered", true, []string{scramSHA256}, "prefer", "secret", "y,,", ""},
+		{"binding required", true, []string{scramSHA256Plus, scramSHA256}, "require", "secret", "p=tls-server-end-point,,", ""},
+		{"binding required without SSL", false, []string{scramSHA256Plus, scramSHA256}, "require", "secret", "", "channel binding required"},
+		{"unsupported mechanism", false, []string{"SCRAM-SHA-512"}, "prefer", "secret", "", "unsupported SASL authentication mechanisms"},
+	}
+
+	for _, test := range tests {
+		serverErrs := make(chan error, 1)
+		var clientConn net.Conn
+		dialer := &scramDialer{
+			forward: pipeDialer(func(client, server net.Conn) net.Conn {
+				raw := server
+				var cbData []byte
+				if test.tls {
+					server = tls.Server(server, &tls.Config{Certificates: []tls.Certificate{cert}})
+					client = tls.Client(client, &tls.Config{InsecureSkipVerify: true})
+				}
+				if strings.HasPrefix(test.gs2Header, "p=") {
+					cbData = tlsServerEndPoint(leaf)
+				}
+				go func() {
+					// The pipe is closed without TLS close notification,
+					// which would wait for the client to read it.
+					defer raw.Close()
+					serverErrs <- serveSCRAM(server, "secret", test.mechanisms, test.gs2Header, cbData)
+				}()
+
+				clientConn = client
+				return client
+			}),
+			password:       test.password,
+			channelBinding: test.channelBinding,
+		}
+
+		conn, err := pq.DialOpen(dialer, "host=db.example.com user=terraform sslmode=disable")
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s: %v", test.name, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("%s: expected an error containing %q, got %v", test.name, test.err, err)
+		}
+		if err == nil {
+			conn.Close()
+		}
+		// lib/pq leaves the connection open when it fails.
+		clientConn.Close()
+
+		if err := <-serverErrs; err != nil && test.err == "" {
+			t.Errorf("%s: server: %v", test.name, err)
+		}
+	}
+}
+
+// pipeDialer connects to a server the function serves over the server end of a
+// pipe, returning the connection to use over the client end.
+type pipeDialer func(client, server net.Conn) net.Conn
+
+func (d pipeDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialTimeout(network, address, 0)
+}
+
+func (d pipeDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	client, server := net.Pipe()
+	return d(client, server), nil
+}
+
+func testSCRAMProof(password, salt, authMessage string) string {
+	saltBytes, _ := base64.StdEncoding.DecodeString(salt)
+	saltedPassword := pbkdf2SHA256([]byte(password), saltBytes, 4096)
+	clientKey := hmacSHA256(saltedPassword, []byte("Client Key"))
+	storedKey := sha256.Sum256(clientKey)
+	signature := hmacSHA256(storedKey[:], []byte(authMessage))
+	for i := range signature {
+		signature[i] ^= clientKey[i]
+	}
+
+	return base64.StdEncoding.EncodeToString(signature)
+}
+
+// serveSCRAM plays a PostgreSQL server authenticating a connection with SCRAM,
+// offering the mechanisms and expecting the gs2 header and channel binding
+// data.
+func serveSCRAM(conn net.Conn, password string, mechanisms []string, gs2Header string, cbData []byte) error {
+	// Startup message
+	size := make([]byte, 4)
+	if _, err := io.ReadFull(conn, size); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(conn, make([]byte, binary.BigEndian.Uint32(size)-4)); err != nil {
+		return err
+	}
+
+	sasl := []byte{0, 0, 0, authSASL}
+	for _, mechanism := range mechanisms {
+		sasl = append(append(sasl, mechanism...), 0)
+	}
+	if _, err := conn.Write(appendMessage(nil, 'R', append(sasl, 0))); err != nil {
+		return err
+	}
+
+	typ, body, err := readMessage(conn)
+	if err != nil {
+		return err
+	}
+	if typ != 'p' {
+		return fmt.Errorf("expected SASLInitialResponse, got %q", typ)
+	}
+	i := strings.IndexByte(string(body), 0)
+	clientFirst := string(body[i+5:])
+	if !strings.HasPrefix(clientFirst, gs2Header+"n=,r=") {
+		return fmt.Errorf("unexpected client-first-message %q", clientFirst)
+	}
+	clientFirstBare := clientFirst[len(gs2Header):]
+	nonce := clientFirstBare[len("n=,r="):] + "server"
+
+	serverFirst := "r=" + nonce + ",s=" + base64.StdEncoding.EncodeToString([]byte("salt")) + ",i=4096"
+	if _, err := conn.Write(appendMessage(nil, 'R', append([]byte{0, 0, 0, authSASLContinue}, serverFirst...))); err != nil {
+		return err
+	}
+
+	typ, body, err = readMessage(conn)
+	if err != nil {
+		return err
+	}
+	if typ != 'p' {
+		return fmt.Errorf("expected SASLResponse, got %q", typ)
+	}
+	clientFinal := string(body)
+	channelBinding := "c=" + base64.StdEncoding.EncodeToString(append([]byte(gs2Header), cbData...))
+	withoutProof := channelBinding + ",r=" + nonce
+	if !strings.HasPrefix(clientFinal, withoutProof+",p=") {
+		return fmt.Errorf("unexpected client-final-message %q", clientFinal)
+	}
+
+	authMessage := clientFirstBare + "," + serverFirst + "," + withoutProof
+	if clientFinal[len(withoutProof)+3:] != testSCRAMProof(password, base64.StdEncoding.EncodeToString([]byte("salt")), authMessage) {
+		fields := "SFATAL\x00C28P01\x00Mpassword authentication failed\x00\x00"
+		_, err := conn.Write(appendMessage(nil, 'E', []byte(fields)))
+		return err
+	}
+
+	saltedPassword := pbkdf2SHA256([]byte(password), []byte("salt"), 4096)
+	serverSignature := hmacSHA256(hmacSHA256(saltedPassword, []byte("Server Key")), []byte(authMessage))
+	serverFinal := "v=" + base64.StdEncoding.EncodeToString(serverSignature)
+
+	var out []byte
+	out = appendMessage(out, 'R', append([]byte{0, 0, 0, authSASLFinal}, serverFinal...))
+	out = appendMessage(out, 'R', []byte{0, 0, 0, authOK})
+	out = appendMessage(out, 'Z', []byte{'I'})
+	_, err = conn.Write(out)
+
+	return err
+}
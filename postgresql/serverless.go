@@ -0,0 +1,39 @@
+package postgresql
+
+import (
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// neonHostSuffix ends the host names of Neon's endpoints, e.g.
+	// ep-cool-darkness-123456.us-east-2.aws.neon.tech.
+	neonHostSuffix = ".neon.tech"
+
+	// neonConnectRetries is the default max_connect_retries with Neon,
+	// whose computes scaled to zero take a few seconds to wake up.
+	neonConnectRetries = 5
+
+	// neonWakeErrorMessage is the message of the errors of Neon's proxy
+	// failing to connect to a compute, e.g. one still waking up.
+	neonWakeErrorMessage = "Couldn't connect to compute node"
+)
+
+// isNeonHost returns whether any of the comma-separated hosts is a Neon
+// endpoint.
+func isNeonHost(hosts string) bool {
+	for _, host := range strings.Split(hosts, ",") {
+		if strings.HasSuffix(strings.ToLower(strings.TrimSpace(host)), neonHostSuffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// neonWakeError returns whether err is Neon's proxy failing to connect to a
+// compute, which a later attempt may not once the compute is up.
+func neonWakeError(err *pq.Error) bool {
+	return strings.Contains(err.Message, neonWakeErrorMessage)
+}
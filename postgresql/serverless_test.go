@@ -0,0 +1,25 @@
+package postgresql
+
+import (
+	"testing"
+)
+
+func TestIsNeonHost(t *testing.T) {
+	tests := []struct {
+		hosts string
+		neon  bool
+	}{
+		{"ep-cool-darkness-123456.us-east-2.aws.neon.tech", true},
+		{"ep-cool-darkness-123456-pooler.us-east-2.aws.NEON.tech", true},
+		{"db1.example.com, ep-cool-darkness-123456.eu-central-1.aws.neon.tech", true},
+		{"db.abcdefghijklmnop.supabase.co", false},
+		{"neon.tech.example.com", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if neon := isNeonHost(test.hosts); neon != test.neon {
+			t.Errorf("%q: expected %t, got %t", test.hosts, test.neon, neon)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package postgresql
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// sslRequestCode is the code of the message asking a PostgreSQL server to
+// switch the connection to SSL.
+const sslRequestCode = 80877103
+
+// sslVersions maps libpq's names of the TLS versions to lib/pq's.
+var sslVersions = map[string]string{
+	"TLSv1":   "TLSv1.0",
+	"TLSv1.1": "TLSv1.1",
+	"TLSv1.2": "TLSv1.2",
+	"TLSv1.3": "TLSv1.3",
+}
+
+// tlsVersions maps libpq's names of the TLS versions to Go's.
+var tlsVersions = map[string]uint16{
+	"TLSv1":   tls.VersionTLS10,
+	"TLSv1.1": tls.VersionTLS11,
+	"TLSv1.2": tls.VersionTLS12,
+	"TLSv1.3": tls.VersionTLS13,
+}
+
+// cipherSuiteIDs returns the IDs of the named cipher suites, e.g.
+// TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256.
+func cipherSuiteIDs(names []string) ([]uint16, error) {
+	known := make(map[string]uint16)
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		known[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown SSL cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// tlsConfigKeys numbers the TLS configurations registered with lib/pq.
+var tlsConfigKeys int32
+
+// registerTLSConfig registers tlsConfig with lib/pq and returns the sslmode
+// establishing SSL connections with it.  lib/pq adds the minimum TLS version,
+// SNI and the certificates given in the DSN to it, but verifies the server
+// certificate as tlsConfig says.
+func registerTLSConfig(tlsConfig *tls.Config) string {
+	mode := fmt.Sprintf("pqgo-terraform-%d", atomic.AddInt32(&tlsConfigKeys, 1))
+	pq.RegisterTLSConfig(mode, tlsConfig)
+
+	return mode
+}
+
+// newTLSConfig returns the TLS configuration of SSL connections with the
+// given sslmode, verifying the server with the PEM encoded CA certificates, or
+// the system's if there are none, and authenticating with the PEM encoded
+// client certificate and key, if any, for the certificates and keys lib/pq can
+// only read from files, or the cipher suites it does not configure.
+func newTLSConfig(mode string, rootCertPEM, certPEM, keyPEM []byte) (*tls.Config, error) {
+	switch mode {
+	case "", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		return nil, fmt.Errorf("sslmode %q can not be used with in-memory SSL certificates or ssl_cipher_suites", mode)
+	}
+
+	tlsConfig := &tls.Config{}
+
+	if len(certPEM) != 0 || len(keyPEM) != 0 {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SSL client certificate or key: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(rootCertPEM) != 0 {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(rootCertPEM) {
+			return nil, fmt.Errorf("invalid SSL root certificate")
+		}
+
+		// Like libpq, verify the CA in require mode when it is given.
+		if mode != "verify-full" {
+			mode = "verify-ca"
+		}
+	}
+
+	switch mode {
+	case "", "allow", "prefer", "require":
+		tlsConfig.InsecureSkipVerify = true
+	case "verify-ca":
+		// Go can not verify the certificate chain without the host name.
+		roots := tlsConfig.RootCAs
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyCertChain(rawCerts, roots)
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// verifyCertChain checks that the certificate chain chains to one of roots, or
+// of the system's certificate authorities if nil.
+func verifyCertChain(rawCerts [][]byte, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return fmt.Errorf("no server certificate")
+	}
+
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		certs = append(certs, cert)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+
+	return err
+}
+
+// defaultSSLFile returns the path of the file of libpq's directory of SSL
+// certificates, ~/.postgresql or %APPDATA%\postgresql on Windows, which lib/pq
+// reads the certificates not given from, or "" if it does not exist.
+func defaultSSLFile(name string) string {
+	var dir string
+	if runtime.GOOS == "windows" {
+		appData := os.Getenv("APPDATA")
+		if appData == "" {
+			return ""
+		}
+		dir = filepath.Join(appData, "postgresql")
+	} else if home, err := os.UserHomeDir(); err == nil {
+		dir = filepath.Join(home, ".postgresql")
+	} else {
+		return ""
+	}
+
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+
+	return path
+}
+
+// sslDialer is a pq.Dialer negotiating SSL itself, so that the connections it
+// establishes can be authenticated with SCRAM-SHA-256-PLUS on lib/pq's behalf,
+// see scramDialer.  lib/pq must then be configured with sslmode=disable.
+type sslDialer struct {
+	mode      string
+	tlsConfig *tls.Config
+	forward   pq.Dialer
+}
+
+// Dial implements pq.Dialer.
+func (d *sslDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialTimeout(network, address, 0)
+}
+
+// DialTimeout implements pq.Dialer.  Unix-domain sockets are not encrypted.
+func (d *sslDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	conn, err := d.forward.DialTimeout(network, address, timeout)
+	if err != nil || network == "unix" {
+		return conn, err
+	}
+
+	return d.upgrade(conn, network, address, timeout)
+}
+
+// upgrade returns conn with SSL.  With sslmode prefer, like lib/pq, the
+// connection is established again without SSL if it can not be.
+func (d *sslDialer) upgrade(conn net.Conn, network, address string, timeout time.Duration) (net.Conn, error) {
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	sslConn, err := d.negotiate(conn, address)
+	if err != nil {
+		conn.Close()
+		if d.mode == "prefer" {
+			return d.forward.DialTimeout(network, address, timeout)
+		}
+		return nil, err
+	}
+	sslConn.SetDeadline(time.Time{})
+
+	return sslConn, nil
+}
+
+// negotiate asks the server to switch conn to SSL and establishes it, or
+// returns conn itself with sslmode prefer if the server does not support SSL.
+func (d *sslDialer) negotiate(conn net.Conn, address string) (net.Conn, error) {
+	req := make([]byte, 8)
+	binary.BigEndian.PutUint32(req[0:4], 8)
+	binary.BigEndian.PutUint32(req[4:8], sslRequestCode)
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	resp := make([]byte, 1)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+	switch resp[0] {
+	case 'S':
+	case 'N':
+		if d.mode == "prefer" {
+			return conn, nil
+		}
+		return nil, pq.ErrSSLNotSupported
+	default:
+		return nil, fmt.Errorf("unexpected response %q to SSL request", resp[0])
+	}
+
+	// Like lib/pq, send the host name with SNI whatever the mode, which
+	// proxies such as Neon's route connections by.  It is only verified
+	// with verify-full.
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := d.tlsConfig.Clone()
+	tlsConfig.ServerName = host
+
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
@@ -0,0 +1,267 @@
+package postgresql
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// testCert returns a PEM encoded certificate and key for cn, signed by parent
+// or self-signed.
+func testCert(t *testing.T, cn string, parent *tls.Certificate) (certPEM, keyPEM []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+
+	issuer, issuerKey := template, interface{}(key)
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		issuer, _ = x509.ParseCertificate(parent.Certificate[0])
+		issuerKey = parent.PrivateKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func TestCipherSuiteIDs(t *testing.T) {
+	ids, err := cipherSuiteIDs([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_RSA_WITH_AES_128_CBC_SHA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_RSA_WITH_AES_128_CBC_SHA}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+
+	if _, err := cipherSuiteIDs([]string{"ECDHE-RSA-AES128-GCM-SHA256"}); err == nil {
+		t.Error("expected OpenSSL cipher names to be rejected")
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	caPEM, caKeyPEM := testCert(t, "Test CA", nil)
+	ca, err := tls.X509KeyPair(caPEM, caKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverPEM, serverKeyPEM := testCert(t, "localhost", &ca)
+	serverCert, _ := tls.X509KeyPair(serverPEM, serverKeyPEM)
+	clientPEM, clientKeyPEM := testCert(t, "terraform", &ca)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(caPEM)
+
+	// A server echoing the client certificate's name.
+	server := listen(t, func(conn net.Conn) {
+		tlsConn := tls.Server(conn, &tls.Config{
+			Certificates: []tls.Certificate{serverCert},
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			ClientCAs:    clientCAs,
+		})
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		io.WriteString(tlsConn, tlsConn.ConnectionState().PeerCertificates[0].Subject.CommonName)
+	})
+	defer server.Close()
+
+	otherCAPEM, _ := testCert(t, "Other CA", nil)
+
+	tests := []struct {
+		mode  string
+		caPEM []byte
+		host  string
+		valid bool
+	}{
+		{"require", nil, "localhost", true},
+		{"prefer", nil, "localhost", true},
+		{"require", otherCAPEM, "localhost", false},
+		{"verify-ca", caPEM, "127.0.0.1", true},
+		{"verify-full", caPEM, "localhost", true},
+		{"verify-full", caPEM, "127.0.0.1", false},
+		{"verify-full", otherCAPEM, "localhost", false},
+	}
+
+	for _, test := range tests {
+		tlsConfig, err := newTLSConfig(test.mode, test.caPEM, clientPEM, clientKeyPEM)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conn, err := net.DialTimeout("tcp", server.Addr().String(), 5*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		// lib/pq sends the host name with SNI, and verifies it unless
+		// InsecureSkipVerify is set.
+		tlsConfig.ServerName = test.host
+		tlsConn := tls.Client(conn, tlsConfig)
+		err = tlsConn.Handshake()
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%s to %s: expected the server certificate to be rejected", test.mode, test.host)
+			}
+			conn.Close()
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s to %s: %v", test.mode, test.host, err)
+			conn.Close()
+			continue
+		}
+
+		name := make([]byte, len("terraform"))
+		if _, err := io.ReadFull(tlsConn, name); err != nil || string(name) != "terraform" {
+			t.Errorf("%s to %s: expected to authenticate with the client certificate, got %q: %v", test.mode, test.host, name, err)
+		}
+		conn.Close()
+	}
+
+	if _, err := newTLSConfig("disable", caPEM, nil, nil); err == nil {
+		t.Error("expected sslmode disable to be rejected")
+	}
+	if _, err := newTLSConfig("require", nil, clientPEM, nil); err == nil {
+		t.Error("expected a client certificate without key to be rejected")
+	}
+}
+
+func TestSSLDialer(t *testing.T) {
+	certPEM, keyPEM := testCert(t, "localhost", nil)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A server answering SSL requests with ssl, then echoing the host name
+	// the client sent with SNI, or a plain "N" without SSL.
+	ssl := true
+	server := listen(t, func(conn net.Conn) {
+		io.ReadFull(conn, make([]byte, 8))
+		if !ssl {
+			conn.Write([]byte("N"))
+			io.Copy(conn, conn)
+			return
+		}
+		conn.Write([]byte("S"))
+
+		tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}})
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		io.WriteString(tlsConn, tlsConn.ConnectionState().ServerName)
+	})
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Addr().String())
+
+	tests := []struct {
+		mode  string
+		caPEM []byte
+		host  string
+		valid bool
+	}{
+		{"require", nil, "localhost", true},
+		{"verify-full", certPEM, "localhost", true},
+		{"verify-full", certPEM, "127.0.0.1", false},
+	}
+
+	for _, test := range tests {
+		tlsConfig, err := newTLSConfig(test.mode, test.caPEM, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := &sslDialer{mode: test.mode, tlsConfig: tlsConfig, forward: netDialer{}}
+
+		conn, err := d.DialTimeout("tcp", net.JoinHostPort(test.host, port), 5*time.Second)
+		if !test.valid {
+			if err == nil {
+				conn.Close()
+				t.Errorf("%s to %s: expected the server certificate to be rejected", test.mode, test.host)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s to %s: %v", test.mode, test.host, err)
+			continue
+		}
+
+		if _, ok := conn.(*tls.Conn); !ok {
+			t.Errorf("%s to %s: expected an SSL connection, got %T", test.mode, test.host, conn)
+		}
+		name := make([]byte, len(test.host))
+		if _, err := io.ReadFull(conn, name); err != nil || string(name) != test.host {
+			t.Errorf("%s to %s: expected the host name to be sent with SNI, got %q: %v", test.mode, test.host, name, err)
+		}
+		conn.Close()
+	}
+
+	ssl = false
+	tlsConfig, _ := newTLSConfig("require", nil, nil, nil)
+	d := &sslDialer{mode: "require", tlsConfig: tlsConfig, forward: netDialer{}}
+	if _, err := d.DialTimeout("tcp", server.Addr().String(), 5*time.Second); err != pq.ErrSSLNotSupported {
+		t.Errorf("expected require to fail without SSL, got %v", err)
+	}
+	d.mode = "prefer"
+	conn, err := d.DialTimeout("tcp", server.Addr().String(), 5*time.Second)
+	if err != nil {
+		t.Errorf("expected prefer to connect without SSL: %v", err)
+	} else {
+		if _, ok := conn.(*tls.Conn); ok {
+			t.Error("expected prefer to fall back to a plain connection")
+		}
+		conn.Close()
+	}
+}
+
+func TestConfigSSLModes(t *testing.T) {
+	tests := []struct {
+		sslMode  string
+		expected []string
+	}{
+		{"allow", []string{"disable", "pqgo-test"}},
+		{"prefer", []string{"pqgo-test", "disable"}},
+		{"require", []string{"pqgo-test"}},
+		{"verify-full", []string{"pqgo-test"}},
+	}
+
+	for _, test := range tests {
+		c := &Config{SSLMode: test.sslMode, tlsConfigMode: "pqgo-test"}
+		if modes := c.sslModes(); !reflect.DeepEqual(modes, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.sslMode, test.expected, modes)
+		}
+	}
+
+	if modes := (&Config{SSLMode: "prefer"}).sslModes(); modes != nil {
+		t.Errorf("expected lib/pq to handle prefer without a TLS configuration, got %v", modes)
+	}
+}
@@ -0,0 +1,215 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	// metricsPrefix prefixes the names of the metrics sent to StatsD and
+	// the Prometheus Pushgateway.
+	metricsPrefix = "terraform_postgresql_"
+
+	metricsPushTimeout = 10 * time.Second
+)
+
+// applyStats counts what the provider does to the server during an apply,
+// and optionally sends the counts to StatsD and a Prometheus Pushgateway.
+// Its methods do nothing on a nil *applyStats.
+type applyStats struct {
+	lock sync.Mutex
+
+	statements   int64
+	changes      int64
+	changeTime   time.Duration
+	retries      int64
+	lockTimeouts int64
+	errors       int64
+
+	// statsd, if set, is sent every event as it happens.
+	statsd net.Conn
+
+	// pushgatewayURL, if set, is the Prometheus Pushgateway the counts are
+	// pushed to after every change to a resource.
+	pushgatewayURL string
+	client         *http.Client
+}
+
+func newApplyStats(statsdAddress, pushgatewayURL string) (*applyStats, error) {
+	s := &applyStats{
+		pushgatewayURL: strings.TrimSuffix(pushgatewayURL, "/"),
+		client:         &http.Client{Timeout: metricsPushTimeout},
+	}
+	if statsdAddress != "" {
+		conn, err := net.Dial("udp", statsdAddress)
+		if err != nil {
+			return nil, err
+		}
+		s.statsd = conn
+	}
+
+	return s, nil
+}
+
+// statement counts a statement run for duration, changing the server unless
+// read is true, and failing with err if not nil.
+func (s *applyStats) statement(read bool, duration time.Duration, err error) {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	s.statements++
+	if !read {
+		s.changes++
+		s.changeTime += duration
+	}
+	if err != nil {
+		s.errors++
+		if sqlState(err) == "55P03" {
+			s.lockTimeouts++
+		}
+	}
+	s.lock.Unlock()
+
+	events := []string{"statements:1|c"}
+	if !read {
+		events = append(events, fmt.Sprintf("change_duration_ms:%.3f|ms", float64(duration)/float64(time.Millisecond)))
+	}
+	if err != nil {
+		events = append(events, "errors:1|c")
+		if sqlState(err) == "55P03" {
+			events = append(events, "lock_timeouts:1|c")
+		}
+	}
+	s.send(events...)
+}
+
+// retry counts a connection or statement retried.
+func (s *applyStats) retry() {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	s.retries++
+	s.lock.Unlock()
+
+	s.send("retries:1|c")
+}
+
+// send sends the StatsD events, if there is a StatsD server to send them to.
+// They are sent over UDP, so that a StatsD server which is not there does
+// not slow the apply down.
+func (s *applyStats) send(events ...string) {
+	if s.statsd == nil {
+		return
+	}
+
+	var b bytes.Buffer
+	for _, event := range events {
+		b.WriteString(metricsPrefix + event + "\n")
+	}
+	s.statsd.Write(b.Bytes())
+}
+
+// counts returns the counts by metric name.
+func (s *applyStats) counts() map[string]float64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return map[string]float64{
+		"statements":              float64(s.statements),
+		"changes":                 float64(s.changes),
+		"change_duration_seconds": s.changeTime.Seconds(),
+		"retries":                 float64(s.retries),
+		"lock_timeouts":           float64(s.lockTimeouts),
+		"errors":                  float64(s.errors),
+	}
+}
+
+// report logs the counts so far, and pushes them to the Prometheus
+// Pushgateway if any.
+func (s *applyStats) report() error {
+	if s == nil {
+		return nil
+	}
+
+	counts := s.counts()
+	fields := make(logFields, len(counts))
+	for name, count := range counts {
+		fields[name] = count
+	}
+	logEvent("INFO", "Apply statistics", fields)
+
+	if s.pushgatewayURL == "" {
+		return nil
+	}
+
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s%s %g\n", metricsPrefix, name, counts[name])
+	}
+
+	req, err := http.NewRequest("PUT", s.pushgatewayURL+"/metrics/job/terraform_postgresql", &b)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("PUT %s: %s", req.URL, resp.Status)
+	}
+
+	return nil
+}
+
+// pushStats reports the statistics of the apply so far, logging rather than
+// failing the change done when they cannot be pushed.
+func pushStats(c *Client) {
+	if err := c.config.stats.report(); err != nil {
+		logEvent("WARN", "Error pushing apply statistics", logFields{"error": err})
+	}
+}
+
+// reportStats makes the changes to the resource report the statistics of the
+// apply so far once done.  Terraform does not tell providers when the apply
+// ends, so the last report is the apply's.
+func reportStats(r *schema.Resource) {
+	wrap := func(f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+		if f == nil {
+			return nil
+		}
+		return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			diags := f(ctx, d, meta)
+			pushStats(meta.(*Client))
+
+			return diags
+		}
+	}
+
+	r.CreateContext = wrap(r.CreateContext)
+	r.UpdateContext = wrap(r.UpdateContext)
+	r.DeleteContext = wrap(r.DeleteContext)
+}
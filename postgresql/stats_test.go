@@ -0,0 +1,75 @@
+package postgresql
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestApplyStats(t *testing.T) {
+	statsd, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer statsd.Close()
+
+	pushed := make(chan string, 1)
+	pushgateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Method != "PUT" || r.URL.Path != "/metrics/job/terraform_postgresql" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		pushed <- string(body)
+	}))
+	defer pushgateway.Close()
+
+	stats, err := newApplyStats(statsd.LocalAddr().String(), pushgateway.URL+"/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats.statement(true, time.Millisecond, nil)
+	stats.statement(false, 2*time.Second, &pq.Error{Code: "55P03"})
+	stats.retry()
+
+	buf := make([]byte, 1024)
+	statsd.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := statsd.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events := string(buf[:n]); events != "terraform_postgresql_statements:1|c\n" {
+		t.Errorf("unexpected StatsD events %q", events)
+	}
+
+	if err := stats.report(); err != nil {
+		t.Fatal(err)
+	}
+	metrics := <-pushed
+	for _, metric := range []string{
+		"terraform_postgresql_statements 2\n",
+		"terraform_postgresql_changes 1\n",
+		"terraform_postgresql_change_duration_seconds 2\n",
+		"terraform_postgresql_retries 1\n",
+		"terraform_postgresql_lock_timeouts 1\n",
+		"terraform_postgresql_errors 1\n",
+	} {
+		if !strings.Contains(metrics, metric) {
+			t.Errorf("expected the metrics pushed to contain %q, got %q", metric, metrics)
+		}
+	}
+
+	var nilStats *applyStats
+	nilStats.statement(false, time.Second, nil)
+	nilStats.retry()
+	if err := nilStats.report(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,63 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+// yugabyteTableOptions returns the WITH (colocation = false) and SPLIT INTO
+// options of CREATE TABLE on YugabyteDB, if any.  Tables are colocated by
+// default in colocated databases, and colocation can not be set in the other
+// ones.
+func yugabyteTableOptions(d *schema.ResourceData, db *sql.DB) (string, error) {
+	var options string
+	if !d.Get(tableColocatedAttr).(bool) {
+		var colocatedDB bool
+		if err := db.QueryRow("SELECT yb_is_database_colocated()").Scan(&colocatedDB); err != nil {
+			return "", errwrap.Wrapf("Error reading the colocation of the database: {{err}}", err)
+		}
+		if colocatedDB {
+			options += " WITH (colocation = false)"
+		}
+	}
+	if tablets := d.Get(tableSplitIntoTabletsAttr).(int); tablets > 0 {
+		options += fmt.Sprintf(" SPLIT INTO %d TABLETS", tablets)
+	}
+
+	return options, nil
+}
+
+// checkYugabyteTableAttrs returns an error if the attributes only YugabyteDB
+// supports are set.
+func checkYugabyteTableAttrs(d attrChanges) error {
+	if _, ok := d.GetOk(tableSplitIntoTabletsAttr); ok {
+		return &attrError{attr: tableSplitIntoTabletsAttr, err: fmt.Errorf("%s is only supported on YugabyteDB", tableSplitIntoTabletsAttr)}
+	}
+	if valueKnown(d, tableColocatedAttr) && !d.Get(tableColocatedAttr).(bool) {
+		return &attrError{attr: tableColocatedAttr, err: fmt.Errorf("%s is only supported on YugabyteDB", tableColocatedAttr)}
+	}
+
+	return nil
+}
+
+// readYugabyteColocation reads whether the table is colocated on YugabyteDB,
+// when its database is colocated.  The number of tablets is not read: they
+// are split automatically as they grow.
+func readYugabyteColocation(d *schema.ResourceData, db *sql.DB, tableName string) error {
+	var colocatedDB bool
+	var colocated sql.NullBool
+	err := db.QueryRow("SELECT yb_is_database_colocated(), (SELECT p.is_colocated FROM yb_table_properties(pg_catalog.to_regclass($1)) p)",
+		pq.QuoteIdentifier(tableName)).Scan(&colocatedDB, &colocated)
+	if err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error reading the colocation of TABLE (%s): {{err}}", tableName), err)
+	}
+	if colocatedDB && colocated.Valid {
+		d.Set(tableColocatedAttr, colocated.Bool)
+	}
+
+	return nil
+}
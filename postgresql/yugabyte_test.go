@@ -0,0 +1,27 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestCheckYugabyteTableAttrs(t *testing.T) {
+	tests := []struct {
+		config map[string]interface{}
+		err    bool
+	}{
+		{config: map[string]interface{}{}},
+		{config: map[string]interface{}{"colocated": true}},
+		{config: map[string]interface{}{"colocated": false}, err: true},
+		{config: map[string]interface{}{"split_into_tablets": 8}, err: true},
+	}
+
+	for _, test := range tests {
+		test.config["name"] = "events"
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLTable().Schema, test.config)
+		if err := checkYugabyteTableAttrs(d); (err != nil) != test.err {
+			t.Errorf("%v: expected error %t, got %v", test.config, test.err, err)
+		}
+	}
+}
@@ -9,7 +9,7 @@ if ! which errcheck > /dev/null; then
 fi
 
 err_files=$(errcheck -ignoretests \
-                     -ignore 'github.com/hashicorp/terraform/helper/schema:Set' \
+                     -ignore 'github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema:Set' \
                      -ignore 'bytes:.*' \
                      -ignore 'io:Close|Write' \
                      $(go list ./...| grep -v /vendor/))